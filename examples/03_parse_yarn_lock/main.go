@@ -72,11 +72,14 @@ func main() {
 					if dep.ComponentDependencyEcosystem.Integrity != "" {
 						fmt.Printf("    Integrity: %s\n", dep.ComponentDependencyEcosystem.Integrity)
 					}
-					if dep.ComponentDependencyEcosystem.HasPeerDependencies {
-						fmt.Println("    有对等依赖")
-					}
-					if dep.ComponentDependencyEcosystem.Bundled {
-						fmt.Println("    已打包")
+					// yarn特有的信息
+					if yarn := dep.ComponentDependencyEcosystem.Yarn; yarn != nil {
+						if yarn.HasPeerDependencies {
+							fmt.Println("    有对等依赖")
+						}
+						if yarn.Bundled {
+							fmt.Println("    已打包")
+						}
 					}
 				}
 				fmt.Println()
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 
 	"github.com/scagogogo/package-json-parser/pkg/parser"
 )
@@ -23,64 +22,47 @@ func main() {
 	// 初始化上下文
 	ctx := context.Background()
 
-	// 解析 package.json
-	packageJsonPath := filepath.Join(projectDir, "package.json")
-	if _, err := os.Stat(packageJsonPath); err == nil {
-		fmt.Println("=== 解析 package.json ===")
-		parsePackageJson(ctx, packageJsonPath)
-		fmt.Println()
-	}
-
-	// 解析 package-lock.json
-	packageLockPath := filepath.Join(projectDir, "package-lock.json")
-	if _, err := os.Stat(packageLockPath); err == nil {
-		fmt.Println("=== 解析 package-lock.json ===")
-		parsePackageLock(ctx, packageLockPath)
-		fmt.Println()
+	projectParser := parser.NewProjectParser()
+	err = projectParser.Init(ctx)
+	if err != nil {
+		log.Fatalf("初始化项目解析器失败: %v\n", err)
 	}
+	defer projectParser.Close(ctx)
 
-	// 解析 yarn.lock
-	yarnLockPath := filepath.Join(projectDir, "yarn.lock")
-	if _, err := os.Stat(yarnLockPath); err == nil {
-		fmt.Println("=== 解析 yarn.lock ===")
-		parseYarnLock(ctx, yarnLockPath)
-		fmt.Println()
+	// 自动识别包管理器，使用package.json和它的lockfile解析项目
+	project, err := projectParser.Parse(ctx, &parser.ProjectParserInput{
+		ProjectRootDirectory: projectDir,
+	})
+	if err != nil {
+		log.Fatalf("解析项目失败: %v\n", err)
 	}
 
-	// 如果没有找到任何文件
-	_, err1 := os.Stat(packageJsonPath)
-	_, err2 := os.Stat(packageLockPath)
-	_, err3 := os.Stat(yarnLockPath)
-	if os.IsNotExist(err1) && os.IsNotExist(err2) && os.IsNotExist(err3) {
-		log.Fatalf("在指定目录中未找到任何可解析的文件 (package.json, package-lock.json, yarn.lock)")
+	// 打印识别结果
+	detection := project.ProjectEcosystem.Detection
+	fmt.Println("=== 识别包管理器 ===")
+	fmt.Printf("包管理器: %s\n", detection.PackageManager)
+	if detection.PackageManagerVersion != "" {
+		fmt.Printf("包管理器版本: %s\n", detection.PackageManagerVersion)
 	}
-}
-
-// 解析 package.json 文件
-func parsePackageJson(ctx context.Context, path string) {
-	packageJsonParser := &parser.PackageJsonParser{}
-	err := packageJsonParser.Init(ctx)
-	if err != nil {
-		fmt.Printf("初始化 package.json 解析器失败: %v\n", err)
-		return
+	fmt.Printf("清单文件: %s\n", detection.Manifest)
+	fmt.Printf("找到的lockfile: %v\n", detection.Lockfiles)
+	if detection.Lockfile != "" {
+		fmt.Printf("使用的lockfile: %s\n", detection.Lockfile)
+	} else {
+		fmt.Println("使用的lockfile: 无 (依赖来自package.json)")
 	}
-	defer packageJsonParser.Close(ctx)
-
-	input := &parser.PackageJsonParserInput{
-		PackageJsonPath: path,
+	for _, reason := range detection.Reasons {
+		fmt.Printf("原因: %s\n", reason)
 	}
-
-	project, err := packageJsonParser.Parse(ctx, input)
-	if err != nil {
-		fmt.Printf("解析 package.json 失败: %v\n", err)
-		return
+	for _, warning := range detection.Warnings {
+		fmt.Printf("警告: %s\n", warning)
 	}
+	fmt.Println()
 
-	// 打印基本信息
+	// 打印项目信息
+	fmt.Println("=== 解析结果 ===")
 	fmt.Printf("项目名称: %s\n", project.Name)
 	fmt.Printf("项目版本: %s\n", project.Version)
-
-	// 打印依赖数量
 	for _, module := range project.Modules {
 		fmt.Printf("模块 '%s' 包含 %d 个依赖\n", module.Name, len(module.Dependencies))
 
@@ -100,67 +82,3 @@ func parsePackageJson(ctx context.Context, path string) {
 		fmt.Printf("- 开发依赖: %d\n", devDeps)
 	}
 }
-
-// 解析 package-lock.json 文件
-func parsePackageLock(ctx context.Context, path string) {
-	packageLockParser := parser.NewPackageLockParser()
-	err := packageLockParser.Init(ctx)
-	if err != nil {
-		fmt.Printf("初始化 package-lock.json 解析器失败: %v\n", err)
-		return
-	}
-	defer packageLockParser.Close(ctx)
-
-	input := &parser.PackageLockJsonParserInput{
-		PackageLockJsonPath: path,
-	}
-
-	project, err := packageLockParser.Parse(ctx, input)
-	if err != nil {
-		fmt.Printf("解析 package-lock.json 失败: %v\n", err)
-		return
-	}
-
-	// 打印基本信息
-	fmt.Printf("项目名称: %s\n", project.Name)
-	fmt.Printf("项目版本: %s\n", project.Version)
-
-	// 打印依赖数量
-	for _, module := range project.Modules {
-		fmt.Printf("模块 '%s' 包含 %d 个锁定依赖\n", module.Name, len(module.Dependencies))
-	}
-}
-
-// 解析 yarn.lock 文件
-func parseYarnLock(ctx context.Context, path string) {
-	yarnLockParser := parser.NewYarnLockParser()
-	err := yarnLockParser.Init(ctx)
-	if err != nil {
-		fmt.Printf("初始化 yarn.lock 解析器失败: %v\n", err)
-		return
-	}
-	defer yarnLockParser.Close(ctx)
-
-	input := &parser.YarnLockParserInput{
-		YarnLockPath: path,
-	}
-
-	project, err := yarnLockParser.Parse(ctx, input)
-	if err != nil {
-		fmt.Printf("解析 yarn.lock 失败: %v\n", err)
-		return
-	}
-
-	// 打印基本信息
-	fmt.Printf("项目名称: %s\n", project.Name)
-	if project.Version != "" {
-		fmt.Printf("项目版本: %s\n", project.Version)
-	} else {
-		fmt.Println("项目版本: 未知 (yarn.lock不包含版本信息)")
-	}
-
-	// 打印依赖数量
-	for _, module := range project.Modules {
-		fmt.Printf("模块 '%s' 包含 %d 个锁定依赖\n", module.Name, len(module.Dependencies))
-	}
-}
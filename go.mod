@@ -6,10 +6,10 @@ require (
 	github.com/scagogogo/sca-base-module-components v0.0.0-20230824173316-3e77326b3331
 	github.com/scagogogo/sca-base-module-ecosystem-parser v0.0.0-20230822164526-7286e221bcfc
 	github.com/stretchr/testify v1.8.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,102 @@
+package models
+
+// BunLock 表示bun.lock文件（Bun的文本格式lockfile）的结构
+type BunLock struct {
+	LockfileVersion int
+
+	// 键是workspace相对于lockfile所在目录的路径，根workspace的键是空字符串
+	Workspaces map[string]*BunLockWorkspace
+
+	// 允许执行生命周期脚本的依赖
+	TrustedDependencies []string
+
+	Overrides           map[string]string
+	PatchedDependencies map[string]string
+	Catalog             map[string]string
+	Catalogs            map[string]map[string]string
+
+	// 键是包的安装路径，顶层的包就是包名，嵌套安装的包是 parent/child 这样的路径，
+	// 非根workspace专用的版本以workspace的包名作为前缀
+	Packages map[string]*BunLockPackage
+}
+
+// BunLockWorkspace 一个workspace在package.json中声明的信息
+type BunLockWorkspace struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalPeers        []string          `json:"optionalPeers"`
+}
+
+// BunLockPackage packages中的一个包，在文件中是一个数组：
+// npm包为 [resolution, registry, info, integrity]，workspace为 [resolution]，
+// git、github、tarball等来源为 [resolution, info, tag或integrity]
+type BunLockPackage struct {
+	// name@version 或者 name@workspace:packages/foo、name@github:user/repo#commit 这样的形式
+	Resolution string
+
+	// 从resolution中拆分出的真实包名和版本部分
+	Name    string
+	Version string
+
+	// npm包的registry地址，空字符串表示默认的registry
+	Registry  string
+	Integrity string
+
+	// git和github来源的包记录的提交标识
+	Tag string
+
+	Dependencies         map[string]string
+	OptionalDependencies map[string]string
+	PeerDependencies     map[string]string
+	OptionalPeers        []string
+	Bin                  map[string]string
+	Os                   []string
+	Cpu                  []string
+}
+
+// BunLockProjectEcosystem 项目生态系统特定信息
+type BunLockProjectEcosystem struct {
+	LockfileVersion     int
+	TrustedDependencies []string
+	Overrides           map[string]string
+	PatchedDependencies map[string]string
+	Catalog             map[string]string
+	Catalogs            map[string]map[string]string
+}
+
+// BunLockModuleEcosystem 模块生态系统特定信息
+type BunLockModuleEcosystem struct {
+	// workspace相对于lockfile所在目录的路径，根workspace为空字符串
+	WorkspacePath string
+}
+
+// BunLockComponentEcosystem 组件生态系统特定信息
+type BunLockComponentEcosystem struct {
+}
+
+// BunLockComponentDependencyEcosystem 组件依赖生态系统特定信息
+type BunLockComponentDependencyEcosystem struct {
+	// 包在packages中的键
+	Key        string
+	Resolution string
+
+	// 包的来源：npm、workspace、git、github、tarball、file、link、root
+	Source    string
+	Registry  string
+	Integrity string
+
+	// 只能通过开发依赖到达时为true，只能通过可选依赖到达时Optional为true
+	Dev      bool
+	Optional bool
+
+	// 是否是workspace的直接依赖，直接依赖会带上package.json中声明的Specifier
+	Direct    bool
+	Specifier string
+
+	// 依赖通过别名安装时的别名，此时DependencyName是真实的包名
+	Alias string
+}
@@ -0,0 +1,112 @@
+package models
+
+// DenoJson 表示deno.json（或deno.jsonc）中跟依赖有关的部分
+type DenoJson struct {
+	// 发布到JSR的包才需要名称和版本
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// import map，值是 npm:chalk@^5.3.0、jsr:@std/path@^1.0.0 或者URL
+	Imports map[string]string `json:"imports"`
+
+	// 只对某个路径下的模块生效的import map
+	Scopes map[string]map[string]string `json:"scopes"`
+
+	// workspace成员所在的目录
+	Workspace []string `json:"workspace"`
+}
+
+// DenoLock 表示deno.lock文件的结构，支持version 3、4和5，不同版本的差异在解析时统一
+type DenoLock struct {
+	Version string
+
+	// 声明到解析出的版本的映射，键是 npm:chalk@^5.3.0 这样的声明，值是 5.3.0 这样的版本，
+	// npm包的版本可能带有peer依赖后缀，比如 4.3.4_supports-color@9.4.0
+	Specifiers map[string]string
+
+	// 键是 @std/path@1.0.2
+	Jsr map[string]*DenoLockJsrPackage
+
+	// 键是 chalk@5.3.0，可能带有peer依赖后缀
+	Npm map[string]*DenoLockNpmPackage
+
+	// 远程模块的URL到hash的映射
+	Remote map[string]string
+
+	Workspace *DenoLockWorkspace
+}
+
+// DenoLockJsrPackage lockfile中的一个JSR包
+type DenoLockJsrPackage struct {
+	Integrity string
+
+	// 依赖的声明，可以是 jsr: 也可以是 npm:，通过Specifiers解析到具体的版本
+	Dependencies []string
+}
+
+// DenoLockNpmPackage lockfile中的一个npm包
+type DenoLockNpmPackage struct {
+	Integrity string
+
+	// 依赖的包名到Npm中的键的映射
+	Dependencies map[string]string
+}
+
+// DenoLockWorkspace lockfile中记录的workspace声明的依赖，用于判断lockfile是否过期
+type DenoLockWorkspace struct {
+	Dependencies []string
+
+	// 键是成员相对于lockfile所在目录的路径
+	Members map[string]*DenoLockWorkspaceMember
+}
+
+// DenoLockWorkspaceMember workspace中一个成员声明的依赖
+type DenoLockWorkspaceMember struct {
+	Dependencies []string
+}
+
+// DenoProjectEcosystem 项目生态系统特定信息
+type DenoProjectEcosystem struct {
+	// 没有deno.lock时为空
+	LockVersion string
+
+	// 从URL导入的远程模块
+	Remote map[string]string
+}
+
+// DenoModuleEcosystem 模块生态系统特定信息
+type DenoModuleEcosystem struct {
+	// workspace成员相对于项目根目录的路径，根模块为空字符串
+	WorkspacePath string
+}
+
+// DenoComponentEcosystem 组件生态系统特定信息
+type DenoComponentEcosystem struct {
+}
+
+// DenoDependencyEcosystemNpm 和 DenoDependencyEcosystemJsr 区分依赖来自npm还是JSR
+const (
+	DenoDependencyEcosystemNpm = "npm"
+	DenoDependencyEcosystemJsr = "jsr"
+)
+
+// DenoComponentDependencyEcosystem 组件依赖生态系统特定信息
+type DenoComponentDependencyEcosystem struct {
+	// npm 或者 jsr
+	Ecosystem string
+
+	// 包在lockfile中的键，没有lockfile时为空
+	Key string
+
+	// npm包的peer依赖后缀，比如 _supports-color@9.4.0
+	PeerSuffix string
+
+	Integrity string
+
+	// 是否是直接依赖，直接依赖会带上声明，比如 npm:chalk@^5.3.0
+	Direct    bool
+	Specifier string
+
+	// 没有lockfile时依赖的版本只是声明中的范围
+	Resolved bool
+}
@@ -0,0 +1,94 @@
+package models
+
+import "sort"
+
+// DependencyGraphEdgeType 依赖边的类型，对应依赖是在哪个字段中声明的
+type DependencyGraphEdgeType string
+
+const (
+	DependencyGraphEdgeProd     DependencyGraphEdgeType = "prod"
+	DependencyGraphEdgeDev      DependencyGraphEdgeType = "dev"
+	DependencyGraphEdgeOptional DependencyGraphEdgeType = "optional"
+	DependencyGraphEdgePeer     DependencyGraphEdgeType = "peer"
+)
+
+// DependencyGraph 从lockfile构建的依赖图，package-lock.json和yarn.lock构建出的是同一种图
+type DependencyGraph struct {
+	// 所有节点，键是节点的ID
+	Nodes map[string]*DependencyGraphNode `json:"nodes"`
+
+	// 根节点的ID：lockfile记录了项目本身时是项目节点，否则是没有被任何节点依赖的节点
+	Roots []string `json:"roots"`
+
+	// 在lockfile中找不到对应条目的依赖声明，出现时说明lockfile被破坏或者跟声明不同步
+	Dangling []*DanglingDependency `json:"dangling"`
+}
+
+// DependencyGraphNode 依赖图中的节点，对应lockfile中锁定的一个包
+type DependencyGraphNode struct {
+	// 节点的ID，package-lock.json中是安装路径，项目本身的路径是空字符串；
+	// yarn.lock v1中是 name@version，Berry格式中是resolution
+	ID string `json:"id"`
+
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Resolved  string `json:"resolved"`
+	Integrity string `json:"integrity"`
+
+	// 包声明的依赖解析到的节点，按声明的名称排序
+	Dependencies []*DependencyGraphEdge `json:"dependencies"`
+}
+
+// DependencyGraphEdge 依赖图中的一条边，从声明依赖的节点指向解析到的节点
+type DependencyGraphEdge struct {
+	// 声明依赖时使用的名称和版本范围，通过别名安装时Name是别名
+	Name  string `json:"name"`
+	Range string `json:"range"`
+
+	Type DependencyGraphEdgeType `json:"type"`
+
+	// 解析到的节点的ID
+	To string `json:"to"`
+}
+
+// DanglingDependency 无法在lockfile中解析到节点的依赖声明
+type DanglingDependency struct {
+	// 声明这个依赖的节点的ID
+	From string `json:"from"`
+
+	Name  string                  `json:"name"`
+	Range string                  `json:"range"`
+	Type  DependencyGraphEdgeType `json:"type"`
+}
+
+// NewDependencyGraph 创建一个空的依赖图
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		Nodes: make(map[string]*DependencyGraphNode),
+	}
+}
+
+// Children 返回节点直接依赖的节点，节点不存在时返回nil
+func (x *DependencyGraph) Children(id string) []*DependencyGraphNode {
+	node, ok := x.Nodes[id]
+	if !ok {
+		return nil
+	}
+	children := make([]*DependencyGraphNode, 0, len(node.Dependencies))
+	for _, edge := range node.Dependencies {
+		if child, ok := x.Nodes[edge.To]; ok {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// SortedNodeIDs 返回排好序的节点ID，便于稳定地遍历
+func (x *DependencyGraph) SortedNodeIDs() []string {
+	ids := make([]string, 0, len(x.Nodes))
+	for id := range x.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
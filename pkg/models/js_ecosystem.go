@@ -0,0 +1,112 @@
+package models
+
+import baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
+
+// JsProject 所有JavaScript解析器解析出的项目，处理依赖图、SBOM、差异对比的代码只需要针对这一种类型编写
+type JsProject = baseModels.Project[*JsProjectEcosystem, *JsModuleEcosystem, *JsComponentEcosystem, *JsComponentDependencyEcosystem]
+
+// JsModule 项目中的一个模块，比如一个workspace
+type JsModule = baseModels.Module[*JsModuleEcosystem, *JsComponentEcosystem, *JsComponentDependencyEcosystem]
+
+// JsComponentDependency 模块的一个依赖
+type JsComponentDependency = baseModels.ComponentDependency[*JsComponentDependencyEcosystem]
+
+// JsProjectEcosystem 所有JavaScript解析器共用的项目生态系统信息，
+// 包管理器特有的信息放在对应的扩展字段中，只有解析这种lockfile的解析器会设置
+type JsProjectEcosystem struct {
+	// 解析的lockfile所属的包管理器，只解析清单文件时为空
+	PackageManager PackageManager `json:"packageManager"`
+
+	// 通过ProjectParser自动识别包管理器时的识别结果，其它解析器不设置
+	Detection *ProjectDetection `json:"detection"`
+
+	// 通过WorkspaceParser解析monorepo时workspace之间的依赖关系，其它解析器不设置
+	Workspaces *WorkspaceGraph `json:"workspaces"`
+
+	// 通过NodeModulesParser扫描node_modules时实际安装的包，其它解析器不设置
+	NodeModules *NodeModulesInventory `json:"nodeModules"`
+
+	// 包管理器特有的信息
+	Yarn *YarnLockProjectEcosystem `json:"yarn,omitempty"`
+	Pnpm *PnpmLockProjectEcosystem `json:"pnpm,omitempty"`
+	Bun  *BunLockProjectEcosystem  `json:"bun,omitempty"`
+	Deno *DenoProjectEcosystem     `json:"deno,omitempty"`
+}
+
+// JsModuleEcosystem 所有JavaScript解析器共用的模块生态系统信息
+type JsModuleEcosystem struct {
+	// 模块对应的workspace相对于项目根目录的路径，根模块和不区分workspace的解析器为空字符串
+	WorkspacePath string `json:"workspacePath"`
+
+	// 包管理器特有的信息
+	Npm  *PackageLockModuleEcosystem `json:"npm,omitempty"`
+	Yarn *YarnLockModuleEcosystem    `json:"yarn,omitempty"`
+	Pnpm *PnpmLockModuleEcosystem    `json:"pnpm,omitempty"`
+	Bun  *BunLockModuleEcosystem     `json:"bun,omitempty"`
+	Deno *DenoModuleEcosystem        `json:"deno,omitempty"`
+}
+
+// JsComponentEcosystem 所有JavaScript解析器共用的组件生态系统信息
+type JsComponentEcosystem struct {
+}
+
+// DependencyEcosystemNpm 和 DependencyEcosystemJsr 区分依赖的包来自npm registry还是JSR
+const (
+	DependencyEcosystemNpm = "npm"
+	DependencyEcosystemJsr = "jsr"
+)
+
+// JsComponentDependencyEcosystem 所有JavaScript解析器共用的依赖生态系统信息，字段沿用package-lock.json的含义，
+// 其它lockfile中有对应信息时会转换过来，没有的保持零值
+type JsComponentDependencyEcosystem struct {
+	// 依赖所属的包生态系统：npm 或 jsr，只有Deno项目中会出现JSR包，名称不在npm的命名空间中，
+	// 查询npm registry和npm漏洞数据时需要跳过
+	Ecosystem string `json:"ecosystem"`
+
+	Resolved  string       `json:"resolved"`
+	Integrity string       `json:"integrity"`
+	Dev       *bool        `json:"dev"`
+	Requires  Dependencies `json:"requires"`
+
+	// 解析Resolved得到的来源：来自哪个registry，或者是git、本地文件、其它URL，没有Resolved时为nil
+	ResolvedSource *ResolvedSource `json:"resolvedSource"`
+
+	// 包的安装路径或者在lockfile中的键，比如package-lock.json中的 node_modules/a/node_modules/b、
+	// yarn Berry中的resolution、pnpm中的依赖路径；lockfileVersion 1的依赖树没有显式的路径，是根据嵌套关系拼出来的
+	Path string `json:"path"`
+
+	// 依赖通过npm别名安装时的别名，比如 "my-react": "npm:react@18.2.0" 中的my-react，
+	// 此时依赖的DependencyName是真实的包名react，不是别名时为空
+	Alias string `json:"alias"`
+
+	// 清单文件中原始的声明，比如 catalog: 或 workspace:^，lockfile没有记录或者声明没有被替换时为空；
+	// 经过ManifestMerger合并后，直接依赖在这里记录package.json中声明的版本范围
+	Specifier string `json:"specifier"`
+
+	// 是否是模块的直接依赖，解析器无法判断时为nil
+	Direct *bool `json:"direct"`
+
+	// 经过ManifestMerger合并后根据从package.json各个依赖字段出发的可达性得出的范围，
+	// 同时能从多个字段到达时依次优先prod、optional、peer、dev，无法到达或者没有合并时为空
+	Scope DependencyGraphEdgeType `json:"scope"`
+
+	// 以下标记来自lockfile中的依赖条目，没有出现时为nil
+	Optional         *bool `json:"optional"`
+	DevOptional      *bool `json:"devOptional"`
+	Peer             *bool `json:"peer"`
+	InBundle         *bool `json:"inBundle"`
+	Link             *bool `json:"link"`
+	HasInstallScript *bool `json:"hasInstallScript"`
+	Bundled          *bool `json:"bundled"`
+	Extraneous       *bool `json:"extraneous"`
+
+	// 包自己带有npm-shrinkwrap.json，它的传递依赖由包中的shrinkwrap锁定，而不是项目的lockfile，
+	// 来自package-lock.json的packages字段，项目本身是否是shrinkwrap记录在模块的Npm.Shrinkwrap中
+	HasShrinkwrap *bool `json:"hasShrinkwrap"`
+
+	// 包管理器特有的信息
+	Yarn *YarnLockComponentDependencyEcosystem `json:"yarn,omitempty"`
+	Pnpm *PnpmLockComponentDependencyEcosystem `json:"pnpm,omitempty"`
+	Bun  *BunLockComponentDependencyEcosystem  `json:"bun,omitempty"`
+	Deno *DenoComponentDependencyEcosystem     `json:"deno,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+// LockfileConversionIssueKind 转换lockfile时遇到的问题的类型
+type LockfileConversionIssueKind string
+
+const (
+	// LockfileConversionIssueConflict 同一个描述符在源lockfile中解析到了不同的版本，目标格式只能锁定其中一个
+	LockfileConversionIssueConflict LockfileConversionIssueKind = "conflict"
+
+	// LockfileConversionIssuePlacement 嵌套安装的重复包，yarn.lock不记录安装位置，重新安装时的位置可能不同
+	LockfileConversionIssuePlacement LockfileConversionIssueKind = "placement"
+
+	// LockfileConversionIssueUnreachable 没有任何依赖声明指向的包，转换后会被丢弃
+	LockfileConversionIssueUnreachable LockfileConversionIssueKind = "unreachable"
+
+	// LockfileConversionIssueMissing 依赖声明在源lockfile中找不到对应的条目
+	LockfileConversionIssueMissing LockfileConversionIssueKind = "missing"
+
+	// LockfileConversionIssueInferredRange 没有package.json中声明的版本范围，使用了推断出来的范围
+	LockfileConversionIssueInferredRange LockfileConversionIssueKind = "inferred-range"
+)
+
+// LockfileConversionIssue 转换lockfile时无法在目标格式中准确表示的内容
+type LockfileConversionIssue struct {
+	Kind LockfileConversionIssueKind
+
+	// 出问题的位置，package-lock.json中是安装路径，yarn.lock中是描述符
+	Location string
+
+	Name    string
+	Version string
+
+	// 对问题的描述
+	Message string
+}
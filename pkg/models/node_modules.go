@@ -0,0 +1,50 @@
+package models
+
+// NodeModulesInventory 扫描node_modules得到的实际安装的包，跟lockfile不同，它记录的是磁盘上真实存在的内容
+type NodeModulesInventory struct {
+	// 安装的包，同一个目录通过多个路径（比如符号链接）访问到时只出现一次，按Path排序
+	Packages []*InstalledPackage `json:"packages"`
+
+	// 各个node_modules/.bin中的命令，按Path排序
+	Bins []*InstalledBin `json:"bins"`
+}
+
+// InstalledPackage node_modules中安装的一个包
+type InstalledPackage struct {
+	// 包的package.json中的名称和版本，没有名称时使用目录名
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// 第一次访问到这个包的安装路径，相对于项目根目录，使用/分隔，比如 node_modules/a/node_modules/b
+	Path string `json:"path"`
+
+	// 包实际所在的目录，相对于项目根目录，不在项目根目录下时以../开头；没有通过符号链接访问时跟Path相同
+	RealPath string `json:"realPath"`
+
+	// 所有能访问到这个包的安装路径，第一个是Path
+	InstallPaths []string `json:"installPaths"`
+
+	// Path本身是一个符号链接，比如链接到workspace或者pnpm虚拟仓库中的包
+	Link bool `json:"link"`
+
+	// 安装时使用的目录名跟包名不同时是别名，比如通过 npm:react@18 安装到 node_modules/my-react
+	Alias string `json:"alias"`
+
+	// package.json中声明的命令，键是命令名称，值是相对于包目录的脚本路径
+	Bin map[string]string `json:"bin"`
+
+	// 旧版本npm写入package.json的 _resolved 和 _integrity
+	Resolved  string `json:"resolved"`
+	Integrity string `json:"integrity"`
+}
+
+// InstalledBin node_modules/.bin中的一个命令
+type InstalledBin struct {
+	Name string `json:"name"`
+
+	// 命令文件的路径，相对于项目根目录，比如 node_modules/.bin/tsc
+	Path string `json:"path"`
+
+	// 符号链接指向的脚本，相对于项目根目录，无法读取链接时为空
+	Target string `json:"target"`
+}
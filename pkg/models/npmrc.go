@@ -0,0 +1,174 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NpmDefaultRegistry 没有配置registry时npm使用的registry
+const NpmDefaultRegistry = "https://registry.npmjs.org/"
+
+// NpmConfigRedacted 输出时代替敏感配置的值，跟 npm config list 一致
+const NpmConfigRedacted = "(protected)"
+
+// NpmConfigSource 配置的来源，按优先级从高到低排列
+type NpmConfigSource string
+
+const (
+	// NpmConfigSourceEnv npm_config_* 环境变量
+	NpmConfigSourceEnv NpmConfigSource = "env"
+
+	// NpmConfigSourceProject 项目根目录下的.npmrc
+	NpmConfigSourceProject NpmConfigSource = "project"
+
+	// NpmConfigSourceUser 用户目录下的.npmrc，或者userconfig指定的文件
+	NpmConfigSourceUser NpmConfigSource = "user"
+
+	// NpmConfigSourceGlobal $PREFIX/etc/npmrc，或者globalconfig指定的文件
+	NpmConfigSourceGlobal NpmConfigSource = "global"
+
+	// NpmConfigSourceBuiltin npm安装目录中的npmrc
+	NpmConfigSourceBuiltin NpmConfigSource = "builtin"
+)
+
+// NpmConfig 按照npm的优先级合并后的配置
+type NpmConfig struct {
+	// 合并后的配置，键是配置名，比如 registry、@scope:registry、//npm.example.com/:_authToken
+	Values map[string]*NpmConfigValue `json:"values"`
+
+	// 读取到的配置文件，按优先级从高到低排列，不存在的文件不包括在内
+	Files []*NpmConfigFile `json:"files"`
+}
+
+// NpmConfigValue 一个配置项以及它生效的来源
+type NpmConfigValue struct {
+	Key    string          `json:"key"`
+	Value  string          `json:"value"`
+	Source NpmConfigSource `json:"source"`
+
+	// 配置所在的文件，来自环境变量时为空
+	Path string `json:"path"`
+}
+
+// NpmConfigFile 读取到的一个配置文件
+type NpmConfigFile struct {
+	Source NpmConfigSource `json:"source"`
+	Path   string          `json:"path"`
+}
+
+// NewNpmConfig 创建一个空的配置
+func NewNpmConfig() *NpmConfig {
+	return &NpmConfig{
+		Values: make(map[string]*NpmConfigValue),
+		Files:  make([]*NpmConfigFile, 0),
+	}
+}
+
+// Get 返回配置的值，没有配置时ok为false
+func (x *NpmConfig) Get(key string) (string, bool) {
+	value, ok := x.Values[key]
+	if !ok {
+		return "", false
+	}
+	return value.Value, true
+}
+
+// Bool 返回布尔类型配置的值，比如 always-auth、strict-ssl，没有配置或者无法解析时返回defaultValue
+func (x *NpmConfig) Bool(key string, defaultValue bool) bool {
+	value, ok := x.Get(key)
+	if !ok {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// Registry 默认的registry，总是以/结尾
+func (x *NpmConfig) Registry() string {
+	if registry, ok := x.Get("registry"); ok && registry != "" {
+		return withTrailingSlash(registry)
+	}
+	return NpmDefaultRegistry
+}
+
+// RegistryFor 返回安装这个包时使用的registry：作用域包优先使用 @scope:registry，否则使用默认的registry
+func (x *NpmConfig) RegistryFor(packageName string) string {
+	if strings.HasPrefix(packageName, "@") {
+		scope := packageName
+		if index := strings.Index(packageName, "/"); index >= 0 {
+			scope = packageName[:index]
+		}
+		if registry, ok := x.Get(scope + ":registry"); ok && registry != "" {
+			return withTrailingSlash(registry)
+		}
+	}
+	return x.Registry()
+}
+
+// SortedKeys 返回排好序的配置名
+func (x *NpmConfig) SortedKeys() []string {
+	keys := make([]string, 0, len(x.Values))
+	for key := range x.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Redacted 返回隐藏了敏感信息的配置，可以直接输出
+func (x *NpmConfig) Redacted() map[string]string {
+	redacted := make(map[string]string, len(x.Values))
+	for key, value := range x.Values {
+		redacted[key] = value.RedactedValue()
+	}
+	return redacted
+}
+
+// IsSecret 配置项是否是认证信息，比如 _authToken、_auth、_password 以及它们限定registry的写法，不区分大小写
+func (x *NpmConfigValue) IsSecret() bool {
+	key := x.Key
+	if index := strings.LastIndex(key, ":"); index >= 0 {
+		key = key[index+1:]
+	}
+	// 来自环境变量的配置名都是小写的，比如 npm_config__authToken 对应 _authtoken
+	switch strings.ToLower(key) {
+	case "_authtoken", "_auth", "_password":
+		return true
+	}
+	return false
+}
+
+// RedactedValue 返回隐藏了敏感信息的值：认证信息整个隐藏，URL中的密码会被替换
+func (x *NpmConfigValue) RedactedValue() string {
+	if x.IsSecret() {
+		return NpmConfigRedacted
+	}
+	if parsed, err := url.Parse(x.Value); err == nil && parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			return parsed.Redacted()
+		}
+	}
+	return x.Value
+}
+
+// MarshalJSON 序列化时隐藏敏感信息，避免配置被意外写进日志或报告
+func (x *NpmConfigValue) MarshalJSON() ([]byte, error) {
+	type npmConfigValue NpmConfigValue
+	redacted := npmConfigValue(*x)
+	redacted.Value = x.RedactedValue()
+	return json.Marshal(&redacted)
+}
+
+// withTrailingSlash registry地址统一以/结尾
+func withTrailingSlash(registry string) string {
+	if strings.HasSuffix(registry, "/") {
+		return registry
+	}
+	return registry + "/"
+}
@@ -0,0 +1,124 @@
+package models
+
+// OsvEcosystemNpm OSV中npm生态系统的名称
+const OsvEcosystemNpm = "npm"
+
+// OsvRangeType OSV中影响范围的类型
+type OsvRangeType string
+
+const (
+	// OsvRangeSemver 事件中的版本是语义化版本
+	OsvRangeSemver OsvRangeType = "SEMVER"
+
+	// OsvRangeEcosystem 事件中的版本按照生态系统的规则比较，npm的规则就是语义化版本
+	OsvRangeEcosystem OsvRangeType = "ECOSYSTEM"
+
+	// OsvRangeGit 事件中是git的commit，无法跟包的版本比较
+	OsvRangeGit OsvRangeType = "GIT"
+)
+
+// OsvAdvisory OSV格式（https://ossf.github.io/osv-schema/）的一条安全公告，只保留匹配和输出需要的字段
+type OsvAdvisory struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases"`
+	Summary   string   `json:"summary"`
+	Details   string   `json:"details"`
+	Modified  string   `json:"modified"`
+	Published string   `json:"published"`
+
+	// 撤回的时间，撤回的公告不会参与匹配
+	Withdrawn string `json:"withdrawn"`
+
+	Severity []*OsvSeverity `json:"severity"`
+	Affected []*OsvAffected `json:"affected"`
+
+	References []*OsvReference `json:"references"`
+
+	// GitHub Advisory Database等来源在这里记录 LOW、MODERATE、HIGH、CRITICAL 这样的严重程度
+	DatabaseSpecific *OsvDatabaseSpecific `json:"database_specific"`
+
+	// 公告所在的文件，比如目录中的路径或者zip中的文件名
+	Source string `json:"source"`
+}
+
+// OsvSeverity 严重程度的评分，比如CVSS向量
+type OsvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// OsvReference 公告的参考链接
+type OsvReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// OsvDatabaseSpecific 公告来源数据库特有的字段
+type OsvDatabaseSpecific struct {
+	Severity string `json:"severity"`
+}
+
+// OsvAffected 公告影响的一个包以及它受影响的版本
+type OsvAffected struct {
+	Package *OsvPackage `json:"package"`
+
+	Ranges []*OsvRange `json:"ranges"`
+
+	// 明确列出的受影响的版本
+	Versions []string `json:"versions"`
+}
+
+// OsvPackage 受影响的包
+type OsvPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Purl      string `json:"purl"`
+}
+
+// OsvRange 受影响的版本范围，由一组按版本排序的事件描述
+type OsvRange struct {
+	Type   OsvRangeType `json:"type"`
+	Events []*OsvEvent  `json:"events"`
+}
+
+// OsvEvent 范围中的一个事件，每个事件只会设置一个字段；introduced为0表示从第一个版本开始受影响
+type OsvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// VulnerabilityReport 把项目中的组件跟安全公告匹配的结果
+type VulnerabilityReport struct {
+	// 按照包名、版本和公告ID排序
+	Findings []*VulnerabilityFinding `json:"findings"`
+
+	// 参与匹配的公告数量
+	Advisories int `json:"advisories"`
+
+	// 参与匹配的组件（name@version）数量
+	Components int `json:"components"`
+}
+
+// VulnerabilityFinding 一个组件版本命中的一条公告
+type VulnerabilityFinding struct {
+	AdvisoryID string   `json:"advisoryId"`
+	Aliases    []string `json:"aliases"`
+	Summary    string   `json:"summary"`
+
+	// 公告来源数据库中的严重程度，没有时为空
+	Severity string `json:"severity"`
+
+	PackageName string `json:"packageName"`
+	Version     string `json:"version"`
+
+	// 比当前版本高的最近的修复版本，没有修复版本时为空
+	FixedVersion string `json:"fixedVersion"`
+
+	// 从依赖图的根节点到这个组件的依赖路径，每个元素是 name@version，没有提供依赖图时为空
+	Paths [][]string `json:"paths"`
+
+	// 组件在lockfile中的路径，比如 node_modules/a/node_modules/b
+	Locations []string `json:"locations"`
+}
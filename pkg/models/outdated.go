@@ -0,0 +1,80 @@
+package models
+
+// OutdatedUpdateType 两个版本之间的差距，按照语义化版本中最高的不同部分划分
+type OutdatedUpdateType string
+
+const (
+	// OutdatedUpdateNone 已经是这个版本，或者无法比较
+	OutdatedUpdateNone OutdatedUpdateType = ""
+
+	OutdatedUpdatePatch OutdatedUpdateType = "patch"
+	OutdatedUpdateMinor OutdatedUpdateType = "minor"
+	OutdatedUpdateMajor OutdatedUpdateType = "major"
+)
+
+// OutdatedReport 跟 npm outdated 类似的过期依赖报告
+type OutdatedReport struct {
+	// 分析过的直接依赖，按照名称和路径排序，包括没有过期的依赖
+	Dependencies []*OutdatedDependency `json:"dependencies"`
+
+	// 没有查询到元数据的包名，已排序
+	Missing []string `json:"missing"`
+
+	// 不是来自registry而跳过的直接依赖，比如git、本地文件和workspace依赖，已排序
+	Skipped []string `json:"skipped"`
+}
+
+// OutdatedDependency 一个直接依赖的版本状态
+type OutdatedDependency struct {
+	// 包名，通过npm别名安装时是真实的包名
+	Name string `json:"name"`
+
+	// npm别名，不是别名时为空
+	Alias string `json:"alias"`
+
+	// package.json中声明的版本范围或者dist-tag，npm别名中只保留版本范围部分
+	Range string `json:"range"`
+
+	// 依赖的范围：prod、dev、optional或peer
+	Scope DependencyGraphEdgeType `json:"scope"`
+
+	// 依赖在lockfile中的路径
+	Path string `json:"path"`
+
+	// lockfile中锁定的版本，没有安装时为空
+	Current string `json:"current"`
+
+	// 满足声明的版本范围的版本中npm会选择的版本，没有满足的版本时为空
+	Wanted string `json:"wanted"`
+
+	// latest对应的版本
+	Latest string `json:"latest"`
+
+	// 从Current升级到Wanted的差距，也就是不修改package.json就能得到的更新
+	WantedUpdateType OutdatedUpdateType `json:"wantedUpdateType"`
+
+	// 从Current升级到Latest的差距
+	UpdateType OutdatedUpdateType `json:"updateType"`
+
+	// 当前版本的弃用说明，没有弃用时为空
+	Deprecated string `json:"deprecated"`
+}
+
+// IsOutdated 是否需要在 npm outdated 中列出：没有安装，或者当前版本不是Wanted或Latest
+func (x *OutdatedDependency) IsOutdated() bool {
+	if x.Current == "" {
+		return true
+	}
+	return (x.Wanted != "" && x.Current != x.Wanted) || (x.Latest != "" && x.Current != x.Latest)
+}
+
+// Outdated 返回需要更新的依赖
+func (x *OutdatedReport) Outdated() []*OutdatedDependency {
+	outdated := make([]*OutdatedDependency, 0)
+	for _, dependency := range x.Dependencies {
+		if dependency.IsOutdated() {
+			outdated = append(outdated, dependency)
+		}
+	}
+	return outdated
+}
@@ -48,13 +48,16 @@ type PackageJson struct {
 	Cpu           []string          `json:"cpu"`
 	Funding       Funding           `json:"funding"`
 	Type          string            `json:"type"` // "module" 或 "commonjs"
-	Workspaces    []string          `json:"workspaces"`
+	Workspaces    WorkspacePatterns `json:"workspaces"`
 	Exports       interface{}       `json:"exports"` // 可以是字符串或复杂对象
 	Imports       map[string]string `json:"imports"`
 	EngineStrict  bool              `json:"engineStrict"`
 	PreferGlobal  bool              `json:"preferGlobal"`
 	PublishConfig PublishConfig     `json:"publishConfig"`
 	Config        Config            `json:"config"`
+
+	// corepack使用的包管理器声明，比如 pnpm@9.1.0 或 yarn@4.1.0+sha512.xxx
+	PackageManager string `json:"packageManager"`
 }
 
 // Author 表示作者或贡献者信息
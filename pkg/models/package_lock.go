@@ -1,54 +1,76 @@
 package models
 
 // PackageLock package-lock.json文件对应的model
+// 字段顺序和omitempty跟npm写出的lockfile保持一致，可以直接序列化为package-lock.json
 type PackageLock struct {
-	Name            string                            `json:"name"`
-	Version         string                            `json:"version"`
-	LockFileVersion uint                              `json:"lockfileVersion"`
-	Requires        *bool                             `json:"requires"`
-	Dependencies    map[string]*PackageLockDependency `json:"dependencies"`
+	Name            string `json:"name,omitempty"`
+	Version         string `json:"version,omitempty"`
+	LockFileVersion uint   `json:"lockfileVersion"`
+	Requires        *bool  `json:"requires,omitempty"`
 
 	// npm v7+ 新增字段
-	Packages map[string]*PackageLockPackage `json:"packages"`
+	Packages map[string]*PackageLockPackage `json:"packages,omitempty"`
+
+	Dependencies map[string]*PackageLockDependency `json:"dependencies,omitempty"`
 
 	// npm v7+ 新增字段，用于标识依赖树的根
-	Workspaces map[string]interface{} `json:"workspaces"`
+	Workspaces map[string]interface{} `json:"workspaces,omitempty"`
 }
 
 // PackageLockDependency package-lock.json中的依赖关系
 type PackageLockDependency struct {
-	Version   string       `json:"version"`
-	Resolved  string       `json:"resolved"`
-	Integrity string       `json:"integrity"`
-	Dev       *bool        `json:"dev"`
-	Requires  Dependencies `json:"requires"`
+	Version   string `json:"version"`
+	Resolved  string `json:"resolved,omitempty"`
+	Integrity string `json:"integrity,omitempty"`
+	Dev       *bool  `json:"dev,omitempty"`
 
 	// npm v5-v6 可能包含的字段
-	Bundled  *bool `json:"bundled"`
-	Optional *bool `json:"optional"`
+	Bundled  *bool `json:"bundled,omitempty"`
+	Optional *bool `json:"optional,omitempty"`
+
+	// npm v7+ 写lockfileVersion 2时在dependencies中也会带上的字段
+	Peer *bool `json:"peer,omitempty"`
+
+	Requires Dependencies `json:"requires,omitempty"`
 
 	// npm v6+ 可能包含的嵌套依赖
-	Dependencies map[string]*PackageLockDependency `json:"dependencies"`
+	Dependencies map[string]*PackageLockDependency `json:"dependencies,omitempty"`
 }
 
 // PackageLockPackage npm v7+ 引入的packages字段中的包定义
 type PackageLockPackage struct {
-	Version      string       `json:"version"`
-	Resolved     string       `json:"resolved"`
-	Integrity    string       `json:"integrity"`
-	Dev          *bool        `json:"dev"`
-	Requires     Dependencies `json:"requires"`
-	Dependencies Dependencies `json:"dependencies"`
+	// 包的真实名称，只有在跟安装路径推断出的名称不一致时（比如npm别名安装、workspace目录）npm才会写入
+	Name string `json:"name,omitempty"`
+
+	Version   string `json:"version,omitempty"`
+	Resolved  string `json:"resolved,omitempty"`
+	Integrity string `json:"integrity,omitempty"`
 
 	// npm v7+ 特有字段
-	Link             *bool             `json:"link"`
-	Engines          map[string]string `json:"engines"`
-	Os               []string          `json:"os"`
-	Cpu              []string          `json:"cpu"`
-	License          string            `json:"license"`
-	Bin              map[string]string `json:"bin"`
-	Funding          interface{}       `json:"funding"`
-	DevOptional      *bool             `json:"devOptional"`
-	InBundle         *bool             `json:"inBundle"`
-	HasInstallScript *bool             `json:"hasInstallScript"`
+	Link             *bool `json:"link,omitempty"`
+	Dev              *bool `json:"dev,omitempty"`
+	Optional         *bool `json:"optional,omitempty"`
+	DevOptional      *bool `json:"devOptional,omitempty"`
+	InBundle         *bool `json:"inBundle,omitempty"`
+	HasInstallScript *bool `json:"hasInstallScript,omitempty"`
+	Peer             *bool `json:"peer,omitempty"`
+	Extraneous       *bool `json:"extraneous,omitempty"`
+
+	// 包自己带有npm-shrinkwrap.json，安装时它的依赖版本由包中的shrinkwrap锁定
+	HasShrinkwrap *bool `json:"hasShrinkwrap,omitempty"`
+
+	Requires     Dependencies `json:"requires,omitempty"`
+	Dependencies Dependencies `json:"dependencies,omitempty"`
+
+	// 包声明的其它类型的依赖，devDependencies只有根包和workspace才会写入
+	DevDependencies      Dependencies `json:"devDependencies,omitempty"`
+	OptionalDependencies Dependencies `json:"optionalDependencies,omitempty"`
+	PeerDependencies     Dependencies `json:"peerDependencies,omitempty"`
+
+	Bin     map[string]string `json:"bin,omitempty"`
+	Engines map[string]string `json:"engines,omitempty"`
+	Os      []string          `json:"os,omitempty"`
+	Cpu     []string          `json:"cpu,omitempty"`
+	License string            `json:"license,omitempty"`
+	Funding interface{}       `json:"funding,omitempty"`
 }
@@ -1,8 +1,7 @@
 package models
 
-type PackageLockComponentDependencyEcosystem struct {
-	Resolved  string       `json:"resolved"`
-	Integrity string       `json:"integrity"`
-	Dev       *bool        `json:"dev"`
-	Requires  Dependencies `json:"requires"`
-}
+// PackageLockComponentDependencyEcosystem 是JsComponentDependencyEcosystem的旧名称，
+// package-lock.json依赖条目中的字段都已经是JsComponentDependencyEcosystem的公共字段
+//
+// Deprecated: 所有解析器都使用JsComponentDependencyEcosystem
+type PackageLockComponentDependencyEcosystem = JsComponentDependencyEcosystem
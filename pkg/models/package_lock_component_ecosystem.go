@@ -1,4 +1,6 @@
 package models
 
-type PackageLockComponentEcosystem struct {
-}
+// PackageLockComponentEcosystem 是JsComponentEcosystem的旧名称
+//
+// Deprecated: 所有解析器都使用JsComponentEcosystem
+type PackageLockComponentEcosystem = JsComponentEcosystem
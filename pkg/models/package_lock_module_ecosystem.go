@@ -1,9 +1,16 @@
 package models
 
+// PackageLockModuleEcosystem package-lock.json特有的模块信息，是JsModuleEcosystem中npm的扩展
 type PackageLockModuleEcosystem struct {
 	LockFileVersion uint  `json:"lockfileVersion"`
 	Requires        *bool `json:"requires"`
 
 	// package-lock.json的原始内容
 	PackageLockContent string `json:"package_lock_content"`
+
+	// 解析时实际使用的lockfile来源
+	Source PackageLockSource `json:"source"`
+
+	// 是否是npm-shrinkwrap.json，shrinkwrap会随包发布并约束使用方安装的依赖版本，需要在清单中单独标记出来
+	Shrinkwrap bool `json:"shrinkwrap"`
 }
@@ -1,4 +1,6 @@
 package models
 
-type PackageLockProjectEcosystem struct {
-}
+// PackageLockProjectEcosystem 是JsProjectEcosystem的旧名称
+//
+// Deprecated: 所有解析器都使用JsProjectEcosystem
+type PackageLockProjectEcosystem = JsProjectEcosystem
@@ -0,0 +1,24 @@
+package models
+
+// PackageLockSource 表示解析时实际使用的npm lockfile来源
+type PackageLockSource string
+
+const (
+	// PackageLockSourcePackageLock 项目根目录下的package-lock.json
+	PackageLockSourcePackageLock PackageLockSource = "package-lock.json"
+
+	// PackageLockSourceShrinkwrap 项目根目录下的npm-shrinkwrap.json，同时存在时优先级高于package-lock.json，
+	// 并且它会随包一起发布，安装这个包的使用方也会按照它锁定的版本安装依赖
+	PackageLockSourceShrinkwrap PackageLockSource = "npm-shrinkwrap.json"
+
+	// PackageLockSourceHidden npm v7+ 在node_modules/.package-lock.json中维护的隐藏lockfile，记录的是实际安装的依赖树
+	PackageLockSourceHidden PackageLockSource = "node_modules/.package-lock.json"
+
+	// PackageLockSourceContent 直接传入的lockfile内容，无法确定来源
+	PackageLockSourceContent PackageLockSource = "content"
+)
+
+// IsPropagatedToConsumers 这个来源的lockfile是否会传递给使用方，只有npm-shrinkwrap.json会
+func (x PackageLockSource) IsPropagatedToConsumers() bool {
+	return x == PackageLockSourceShrinkwrap
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// PackumentDistTagLatest 没有指定版本时安装的dist-tag
+const PackumentDistTagLatest = "latest"
+
+// Packument registry中一个包的元数据文档，也就是 GET <registry>/<name> 返回的内容。
+// 只保留分析依赖需要的字段，完整的文档和abbreviated（application/vnd.npm.install-v1+json）文档都可以解析
+type Packument struct {
+	Name string `json:"name"`
+
+	// dist-tag -> 版本，比如 latest -> 4.17.21
+	DistTags map[string]string `json:"distTags"`
+
+	// 版本 -> 这个版本的元数据
+	Versions map[string]*PackumentVersion `json:"versions"`
+
+	// 版本 -> 发布时间，abbreviated文档中没有这个字段
+	Time map[string]time.Time `json:"time"`
+
+	// 包的创建时间和最后修改时间，没有时是零值
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+
+	// 元数据的来源，比如文件路径或者URL
+	Source string `json:"source"`
+}
+
+// PackumentVersion 一个版本的元数据
+type PackumentVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// 弃用的说明，没有弃用时为空
+	Deprecated string `json:"deprecated"`
+
+	Dist *PackumentDist `json:"dist"`
+
+	Dependencies         Dependencies `json:"dependencies"`
+	OptionalDependencies Dependencies `json:"optionalDependencies"`
+	PeerDependencies     Dependencies `json:"peerDependencies"`
+}
+
+// PackumentDist 版本的tarball以及它的校验值
+type PackumentDist struct {
+	Tarball string `json:"tarball"`
+	Shasum  string `json:"shasum"`
+
+	// Subresource Integrity格式的校验值，比如 sha512-...，比较老的版本没有
+	Integrity string `json:"integrity"`
+}
+
+// Latest 返回latest对应的版本，没有这个dist-tag或者版本不存在时返回nil
+func (x *Packument) Latest() *PackumentVersion {
+	return x.Version(x.DistTags[PackumentDistTagLatest])
+}
+
+// Version 返回指定版本的元数据，版本不存在时返回nil
+func (x *Packument) Version(version string) *PackumentVersion {
+	if version == "" {
+		return nil
+	}
+	return x.Versions[version]
+}
+
+// IsDeprecated 指定的版本是否被弃用
+func (x *Packument) IsDeprecated(version string) bool {
+	v := x.Version(version)
+	return v != nil && v.Deprecated != ""
+}
+
+// PublishedAt 返回版本的发布时间，没有记录时ok为false
+func (x *Packument) PublishedAt(version string) (time.Time, bool) {
+	publishedAt, ok := x.Time[version]
+	return publishedAt, ok
+}
+
+// VersionNames 返回所有的版本号，按字符串排序，需要按语义化版本排序时由调用方处理
+func (x *Packument) VersionNames() []string {
+	versions := make([]string, 0, len(x.Versions))
+	for version := range x.Versions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Integrity 返回版本的校验值，优先使用integrity，没有时把十六进制的shasum转换为 sha1-<base64> 格式，都没有时为空
+func (x *PackumentVersion) Integrity() string {
+	if x.Dist == nil {
+		return ""
+	}
+	if x.Dist.Integrity != "" {
+		return x.Dist.Integrity
+	}
+	if digest, err := hex.DecodeString(x.Dist.Shasum); err == nil && len(digest) == sha1.Size {
+		return "sha1-" + base64.StdEncoding.EncodeToString(digest)
+	}
+	return ""
+}
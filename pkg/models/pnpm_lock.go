@@ -0,0 +1,157 @@
+package models
+
+// PnpmLock 表示pnpm-lock.yaml文件的结构，支持lockfileVersion 5.x、6.0和9.0，
+// 不同版本的差异在解析时统一：importers中的依赖都转换为specifier加version的形式，
+// 5.x只有一个项目时顶层的依赖被当做 "." importer
+type PnpmLock struct {
+	// lockfile格式的版本，比如 5.4、6.0、9.0
+	LockfileVersion string
+
+	Settings *PnpmLockSettings
+
+	// 覆盖依赖版本的配置，键可以是 foo、foo@1、bar>foo 这样的选择器
+	Overrides map[string]string
+
+	// 打了补丁的依赖，键是 name@version 或者 name
+	PatchedDependencies map[string]*PnpmLockPatchedDependency
+
+	// catalog名称到其中的依赖的映射，默认的catalog名称为default
+	Catalogs map[string]map[string]*PnpmLockImporterDependency
+
+	// 每个workspace项目对应一个importer，键是相对于lockfile所在目录的路径，根项目为 "."
+	Importers map[string]*PnpmLockImporter
+
+	// 包的元数据，5.x和6.0中键带有peer依赖后缀，同时也记录了包的依赖；9.0中键是 name@version
+	Packages map[string]*PnpmLockPackage
+
+	// 9.0新增，键是带peer依赖后缀的依赖路径，记录包在这个peer组合下的依赖
+	Snapshots map[string]*PnpmLockSnapshot
+}
+
+// PnpmLockSettings lockfile中记录的影响解析结果的配置
+type PnpmLockSettings struct {
+	AutoInstallPeers         bool `yaml:"autoInstallPeers"`
+	ExcludeLinksFromLockfile bool `yaml:"excludeLinksFromLockfile"`
+}
+
+// PnpmLockPatchedDependency 依赖的补丁信息
+type PnpmLockPatchedDependency struct {
+	Hash string `yaml:"hash"`
+	Path string `yaml:"path"`
+}
+
+// PnpmLockImporter 一个workspace项目声明的依赖
+type PnpmLockImporter struct {
+	Dependencies         map[string]*PnpmLockImporterDependency
+	DevDependencies      map[string]*PnpmLockImporterDependency
+	OptionalDependencies map[string]*PnpmLockImporterDependency
+}
+
+// PnpmLockImporterDependency importer中的一个依赖
+type PnpmLockImporterDependency struct {
+	// package.json中声明的版本范围，比如 ^1.0.0、workspace:*、catalog:
+	Specifier string `yaml:"specifier"`
+
+	// 解析到的版本，可能带有peer依赖后缀，也可能是 link:../foo 这样的链接
+	Version string `yaml:"version"`
+}
+
+// PnpmLockResolution 包的获取方式，registry中的包只有integrity，其它来源会有tarball、目录或者git仓库
+type PnpmLockResolution struct {
+	Integrity string `yaml:"integrity"`
+	Tarball   string `yaml:"tarball"`
+	Directory string `yaml:"directory"`
+	Repo      string `yaml:"repo"`
+	Commit    string `yaml:"commit"`
+	Type      string `yaml:"type"`
+}
+
+// PnpmLockPackage packages中的一个包
+type PnpmLockPackage struct {
+	Resolution *PnpmLockResolution `yaml:"resolution"`
+
+	// 只有不是从registry安装的包才会写入name和version
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+
+	// 9.0中这两个字段在snapshots里
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+
+	PeerDependencies           map[string]string `yaml:"peerDependencies"`
+	TransitivePeerDependencies []string          `yaml:"transitivePeerDependencies"`
+
+	// 5.x和6.0中的标记，dev为nil时表示既是开发依赖也是生产依赖
+	Dev      *bool `yaml:"dev"`
+	Optional bool  `yaml:"optional"`
+
+	HasBin        bool              `yaml:"hasBin"`
+	RequiresBuild bool              `yaml:"requiresBuild"`
+	Patched       bool              `yaml:"patched"`
+	Deprecated    string            `yaml:"deprecated"`
+	Engines       map[string]string `yaml:"engines"`
+	Os            []string          `yaml:"os"`
+	Cpu           []string          `yaml:"cpu"`
+	Libc          []string          `yaml:"libc"`
+}
+
+// PnpmLockSnapshot 9.0中包在某个peer依赖组合下的依赖
+type PnpmLockSnapshot struct {
+	Dependencies               map[string]string `yaml:"dependencies"`
+	OptionalDependencies       map[string]string `yaml:"optionalDependencies"`
+	TransitivePeerDependencies []string          `yaml:"transitivePeerDependencies"`
+	Optional                   bool              `yaml:"optional"`
+}
+
+// PnpmLockProjectEcosystem 项目生态系统特定信息
+type PnpmLockProjectEcosystem struct {
+	LockfileVersion     string
+	Settings            *PnpmLockSettings
+	Overrides           map[string]string
+	PatchedDependencies map[string]*PnpmLockPatchedDependency
+	Catalogs            map[string]map[string]*PnpmLockImporterDependency
+}
+
+// PnpmLockModuleEcosystem 模块生态系统特定信息
+type PnpmLockModuleEcosystem struct {
+	// importer相对于lockfile所在目录的路径，根项目为 "."
+	ImporterPath string
+}
+
+// PnpmLockComponentEcosystem 组件生态系统特定信息
+type PnpmLockComponentEcosystem struct {
+}
+
+// PnpmLockComponentDependencyEcosystem 组件依赖生态系统特定信息
+type PnpmLockComponentDependencyEcosystem struct {
+	// 包在lockfile中的键，带有peer依赖后缀，同一个版本在不同的peer组合下是不同的依赖
+	DepPath string
+
+	// peer依赖后缀，比如6.0、9.0中的 (react@18.2.0)，5.x中的 _react@18.2.0
+	PeerSuffix string
+
+	Resolved  string
+	Integrity string
+
+	// 只能通过开发依赖到达时为true，只能通过可选依赖到达时Optional为true
+	Dev      bool
+	Optional bool
+
+	// 是否是importer的直接依赖，直接依赖会带上package.json中声明的Specifier
+	Direct    bool
+	Specifier string
+
+	// 直接依赖使用catalog:声明时的catalog名称，catalog: 对应default
+	Catalog string
+
+	// 依赖通过别名安装时的别名，此时DependencyName是真实的包名
+	Alias string
+
+	// 指向workspace中另一个项目的链接，此时DependencyVersion是 link:../foo
+	Link bool
+
+	// 是否打了补丁、版本是否被overrides覆盖。overrides只识别按照包名和父级包选择的覆盖，
+	// 子级带有版本范围的选择器（比如 foo@<2）无法从lockfile判断，不会被标记
+	Patched    bool
+	Overridden bool
+}
@@ -0,0 +1,30 @@
+package models
+
+// PnpmWorkspace 表示pnpm-workspace.yaml文件的结构
+type PnpmWorkspace struct {
+	// workspace中项目所在目录的glob，以!开头的表示排除
+	Packages []string `yaml:"packages"`
+
+	// 默认catalog，package.json中通过 catalog: 或 catalog:default 引用
+	Catalog map[string]string `yaml:"catalog"`
+
+	// 命名的catalog，package.json中通过 catalog:<name> 引用
+	Catalogs map[string]map[string]string `yaml:"catalogs"`
+
+	Overrides             map[string]string `yaml:"overrides"`
+	PatchedDependencies   map[string]string `yaml:"patchedDependencies"`
+	OnlyBuiltDependencies []string          `yaml:"onlyBuiltDependencies"`
+}
+
+// CatalogRange 返回catalog中为包声明的版本范围，catalogName为空或者default时查找默认catalog，
+// 默认catalog既可以写在catalog里，也可以写在catalogs.default里
+func (x *PnpmWorkspace) CatalogRange(catalogName string, packageName string) (string, bool) {
+	if catalogName == "" || catalogName == "default" {
+		if versionRange, ok := x.Catalog[packageName]; ok {
+			return versionRange, true
+		}
+		catalogName = "default"
+	}
+	versionRange, ok := x.Catalogs[catalogName][packageName]
+	return versionRange, ok
+}
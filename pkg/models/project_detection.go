@@ -0,0 +1,35 @@
+package models
+
+// PackageManager JavaScript项目使用的包管理器
+type PackageManager string
+
+const (
+	PackageManagerNpm  PackageManager = "npm"
+	PackageManagerYarn PackageManager = "yarn"
+	PackageManagerPnpm PackageManager = "pnpm"
+	PackageManagerBun  PackageManager = "bun"
+	PackageManagerDeno PackageManager = "deno"
+)
+
+// ProjectDetection 自动识别项目使用的包管理器的结果，记录了选择的依据，方便排查识别错误的问题
+type ProjectDetection struct {
+	PackageManager PackageManager
+
+	// package.json的packageManager字段中声明的版本，没有声明时为空
+	PackageManagerVersion string
+
+	// 使用的清单文件，package.json或者deno.json，都不存在时为空
+	Manifest string
+
+	// 作为依赖来源的lockfile，没有可以使用的lockfile时为空，这时依赖只来自清单文件
+	Lockfile string
+
+	// 项目根目录中找到的所有lockfile
+	Lockfiles []string
+
+	// 做出选择的依据，按判断的顺序排列
+	Reasons []string
+
+	// 存在多个互相冲突的lockfile、声明的包管理器跟lockfile不一致等需要注意的问题
+	Warnings []string
+}
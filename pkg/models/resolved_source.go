@@ -0,0 +1,64 @@
+package models
+
+// ResolvedSourceType lockfile中resolved指向的来源类型
+type ResolvedSourceType string
+
+const (
+	// ResolvedSourceRegistry npm registry中的tarball
+	ResolvedSourceRegistry ResolvedSourceType = "registry"
+
+	// ResolvedSourceGit git仓库，包括 git+https://、git+ssh://、github: 等写法和GitHub的codeload归档
+	ResolvedSourceGit ResolvedSourceType = "git"
+
+	// ResolvedSourceFile 本地的文件或目录，包括 file:、link: 和相对路径
+	ResolvedSourceFile ResolvedSourceType = "file"
+
+	// ResolvedSourceTarball 不是registry布局的远程URL
+	ResolvedSourceTarball ResolvedSourceType = "tarball"
+
+	// ResolvedSourceUnknown 无法识别的写法
+	ResolvedSourceUnknown ResolvedSourceType = "unknown"
+)
+
+// RegistryKind 根据URL识别出的registry实现
+type RegistryKind string
+
+const (
+	RegistryKindNpmjs          RegistryKind = "npmjs"
+	RegistryKindYarnpkg        RegistryKind = "yarnpkg"
+	RegistryKindArtifactory    RegistryKind = "artifactory"
+	RegistryKindVerdaccio      RegistryKind = "verdaccio"
+	RegistryKindGithubPackages RegistryKind = "github-packages"
+
+	// RegistryKindCustom 使用registry布局（/<name>/-/<file>.tgz）但是无法识别实现的registry，比如Nexus或者自建的registry
+	RegistryKindCustom RegistryKind = "custom"
+)
+
+// ResolvedSource 解析resolved得到的来源信息
+type ResolvedSource struct {
+	// 原始的resolved
+	Raw string `json:"raw"`
+
+	Type ResolvedSourceType `json:"type"`
+
+	// URL的协议，比如 https、git+ssh、file，github: 这种简写是github
+	Protocol string `json:"protocol"`
+
+	// URL中的主机名，带有端口时包括端口
+	Host string `json:"host"`
+
+	// 以下字段只有registry来源会设置：registry的地址（以/结尾，Artifactory中包括仓库路径）、
+	// registry的实现以及从URL中解析出的包名和版本
+	Registry     string       `json:"registry"`
+	RegistryKind RegistryKind `json:"registryKind"`
+	Name         string       `json:"name"`
+	Version      string       `json:"version"`
+
+	// URL中#之后的部分，yarn v1中是tarball的sha1，git来源中是commit或者分支
+	Fragment string `json:"fragment"`
+}
+
+// IsRegistry 是否来自npm registry，git、本地文件和其它URL都不是
+func (x *ResolvedSource) IsRegistry() bool {
+	return x.Type == ResolvedSourceRegistry
+}
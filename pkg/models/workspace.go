@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// WorkspacePatterns package.json中的workspaces字段，既可以是glob数组，
+// 也可以是yarn classic使用的 {"packages": [...], "nohoist": [...]}，两种写法都解析为packages中的glob
+type WorkspacePatterns []string
+
+func (x *WorkspacePatterns) UnmarshalJSON(data []byte) error {
+	var patterns []string
+	if err := json.Unmarshal(data, &patterns); err == nil {
+		*x = patterns
+		return nil
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("workspaces must be an array of globs or an object with packages: %w", err)
+	}
+	*x = object.Packages
+	return nil
+}
+
+// WorkspaceGraph monorepo中所有的workspace以及它们之间的依赖关系，构建顺序和循环依赖由WorkspaceParser计算
+type WorkspaceGraph struct {
+	// 展开的workspace glob，来自根目录package.json的workspaces字段或者pnpm-workspace.yaml的packages
+	Patterns []string `json:"patterns"`
+
+	// 所有的workspace，键是workspace的名称
+	Workspaces map[string]*Workspace `json:"workspaces"`
+}
+
+// Workspace monorepo中的一个workspace
+type Workspace struct {
+	// package.json中的名称，没有名称时使用目录名
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// 相对于根目录的路径，使用/分隔
+	Path string `json:"path"`
+
+	PackageJson *PackageJson `json:"packageJson"`
+
+	// 对其它workspace的依赖，按名称排序
+	Dependencies []*WorkspaceDependency `json:"dependencies"`
+}
+
+// WorkspaceDependency 一个workspace对另一个workspace的依赖
+type WorkspaceDependency struct {
+	// 被依赖的workspace的名称
+	Name string `json:"name"`
+
+	// package.json中声明的版本范围，比如 workspace:^ 或 ^1.0.0
+	Range string `json:"range"`
+
+	Type DependencyGraphEdgeType `json:"type"`
+}
+
+// NewWorkspaceGraph 创建一个空的workspace依赖图
+func NewWorkspaceGraph() *WorkspaceGraph {
+	return &WorkspaceGraph{
+		Patterns:   make([]string, 0),
+		Workspaces: make(map[string]*Workspace),
+	}
+}
+
+// SortedNames 返回排好序的workspace名称，便于稳定地遍历
+func (x *WorkspaceGraph) SortedNames() []string {
+	names := make([]string, 0, len(x.Workspaces))
+	for name := range x.Workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Dependents 返回直接依赖这个workspace的workspace名称，按名称排序
+func (x *WorkspaceGraph) Dependents(name string) []string {
+	dependents := make([]string, 0)
+	for _, workspaceName := range x.SortedNames() {
+		for _, dependency := range x.Workspaces[workspaceName].Dependencies {
+			if dependency.Name == name {
+				dependents = append(dependents, workspaceName)
+				break
+			}
+		}
+	}
+	return dependents
+}
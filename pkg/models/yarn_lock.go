@@ -3,8 +3,32 @@ package models
 // YarnLock 表示yarn.lock文件的结构
 type YarnLock struct {
 	// yarn.lock文件被解析为依赖名称到依赖版本的映射
-	// 注意：yarn.lock中的依赖键格式通常为 "package-name@^1.0.0"
+	// 注意：yarn.lock中的依赖键格式通常为 "package-name@^1.0.0"，Berry格式为 "package-name@npm:^1.0.0"
 	Dependencies map[string]*YarnLockDependency
+
+	// Berry（yarn v2+）格式的lockfile中的__metadata，v1格式的lockfile为nil
+	Metadata *YarnLockMetadata
+
+	// 解析v1格式的lockfile时记录的原文件排版，写回时用来还原，为nil时按照yarn默认的排版写入
+	Layout *YarnLockLayout
+}
+
+// YarnLockLayout v1格式的lockfile中条目之外的内容
+type YarnLockLayout struct {
+	// 第一个条目之前的注释和空行
+	Header string
+
+	// 最后一个值之后的空白，yarn生成的文件是一个换行，手工编辑过的文件可能是空格或者没有
+	Trailer string
+}
+
+// YarnLockMetadata Berry格式lockfile中的__metadata
+type YarnLockMetadata struct {
+	// lockfile格式的版本，比如 4、6、8
+	Version string
+
+	// 缓存的key，缓存的格式变化时会变化
+	CacheKey string
 }
 
 // YarnLockDependency 表示yarn.lock中的单个依赖项
@@ -12,9 +36,12 @@ type YarnLockDependency struct {
 	// 依赖版本，如 "1.2.3"
 	Version string
 
-	// 依赖解析地址
+	// 依赖解析地址，不包含#后面的部分
 	Resolved string
 
+	// resolved地址中#后面的部分，v1格式中通常是tarball的sha1，git依赖则是commit，写回lockfile时需要拼接回去
+	ResolvedHash string
+
 	// 完整性校验和
 	Integrity string
 
@@ -35,16 +62,57 @@ type YarnLockDependency struct {
 
 	// 是否捆绑依赖
 	Bundled bool
+
+	// 以下是Berry格式特有的字段
+
+	// 依赖实际解析到的描述符，比如 "lodash@npm:4.17.21"、"app@workspace:."
+	Resolution string
+
+	// 包的缓存文件的校验和
+	Checksum string
+
+	// 链接类型，hard表示需要安装到node_modules中的包，soft表示链接到本地目录的包（比如workspace）
+	LinkType string
+
+	// 安装条件，比如 "os=darwin"
+	Conditions string
+
+	// 可执行文件
+	Bin map[string]string
+
+	// 依赖的额外元数据，键是依赖名称
+	DependenciesMeta map[string]*YarnLockDependencyMeta
+
+	// 对等依赖的额外元数据，键是依赖名称
+	PeerDependenciesMeta map[string]*YarnLockPeerDependencyMeta
+}
+
+// YarnLockDependencyMeta Berry格式中dependenciesMeta的一项
+type YarnLockDependencyMeta struct {
+	Optional  *bool `yaml:"optional"`
+	Built     *bool `yaml:"built"`
+	Unplugged *bool `yaml:"unplugged"`
+}
+
+// YarnLockPeerDependencyMeta Berry格式中peerDependenciesMeta的一项
+type YarnLockPeerDependencyMeta struct {
+	Optional *bool `yaml:"optional"`
 }
 
 // YarnLockProjectEcosystem 项目生态系统特定信息
 type YarnLockProjectEcosystem struct {
 	// 项目特定的yarn元数据
+
+	// Berry格式lockfile的__metadata，v1格式为nil
+	Metadata *YarnLockMetadata
 }
 
 // YarnLockModuleEcosystem 模块生态系统特定信息
 type YarnLockModuleEcosystem struct {
 	// 模块特定的yarn元数据
+
+	// Berry格式中workspace相对于项目根目录的路径，根workspace为"."
+	WorkspacePath string
 }
 
 // YarnLockComponentEcosystem 组件生态系统特定信息
@@ -61,4 +129,16 @@ type YarnLockComponentDependencyEcosystem struct {
 	LanguageName        string
 	Bundled             bool
 	HasPeerDependencies bool
+
+	// 以下是Berry格式特有的字段，Source是依赖的协议，比如 npm、workspace、patch、portal、link、exec
+	Resolution string
+	Checksum   string
+	LinkType   string
+	Conditions string
+
+	// 依赖通过别名安装时的别名，比如 "my-react@npm:react@18.2.0" 中的my-react，此时DependencyName是真实的包名
+	Alias string
+
+	// 是否是项目的直接依赖，v1格式需要能读取到项目的package.json才能判断，Berry格式根据workspace声明的依赖判断
+	Direct bool
 }
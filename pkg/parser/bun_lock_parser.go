@@ -0,0 +1,462 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
+	"github.com/scagogogo/sca-base-module-ecosystem-parser/pkg/parser"
+)
+
+// BunLockParser 用于解析Bun的文本格式lockfile bun.lock
+type BunLockParser struct {
+}
+
+var _ parser.Parser[*BunLockParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &BunLockParser{}
+
+func NewBunLockParser() *BunLockParser {
+	return &BunLockParser{}
+}
+
+const BunLockParserName = "bun-lock-parser"
+
+// bunLockMaxVersion 支持的最高lockfileVersion
+const bunLockMaxVersion = 1
+
+func (x *BunLockParser) GetName() string {
+	return BunLockParserName
+}
+
+func (x *BunLockParser) Init(ctx context.Context) error {
+	return nil
+}
+
+// bunLockFile bun.lock对应的json结构，packages中的每个包是一个元素类型不固定的数组
+type bunLockFile struct {
+	LockfileVersion     *int                                `json:"lockfileVersion"`
+	Workspaces          map[string]*models.BunLockWorkspace `json:"workspaces"`
+	TrustedDependencies []string                            `json:"trustedDependencies"`
+	Overrides           map[string]string                   `json:"overrides"`
+	PatchedDependencies map[string]string                   `json:"patchedDependencies"`
+	Catalog             map[string]string                   `json:"catalog"`
+	Catalogs            map[string]map[string]string        `json:"catalogs"`
+	Packages            map[string][]json.RawMessage        `json:"packages"`
+}
+
+// bunLockPackageInfo 包数组中记录依赖等信息的对象
+type bunLockPackageInfo struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalPeers        []string          `json:"optionalPeers"`
+	Bin                  json.RawMessage   `json:"bin"`
+	Os                   json.RawMessage   `json:"os"`
+	Cpu                  json.RawMessage   `json:"cpu"`
+}
+
+// Parse 解析bun.lock，返回所有JavaScript解析器共用的类型，BunLock特有的信息放在各个生态系统的Bun扩展字段中
+func (x *BunLockParser) Parse(ctx context.Context, input *BunLockParserInput) (*models.JsProject, error) {
+	project, err := x.parseProject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return toJsProject(project, &jsEcosystemConverter[*models.BunLockProjectEcosystem, *models.BunLockModuleEcosystem, *models.BunLockComponentDependencyEcosystem]{
+		project: func(ecosystem *models.BunLockProjectEcosystem) *models.JsProjectEcosystem {
+			return &models.JsProjectEcosystem{PackageManager: models.PackageManagerBun, Bun: ecosystem}
+		},
+		module: func(ecosystem *models.BunLockModuleEcosystem) *models.JsModuleEcosystem {
+			return &models.JsModuleEcosystem{WorkspacePath: ecosystem.WorkspacePath, Bun: ecosystem}
+		},
+		dependency: func(ecosystem *models.BunLockComponentDependencyEcosystem) *models.JsComponentDependencyEcosystem {
+			direct := ecosystem.Direct
+			return &models.JsComponentDependencyEcosystem{
+				Ecosystem: models.DependencyEcosystemNpm,
+				Integrity: ecosystem.Integrity,
+				Alias:     ecosystem.Alias,
+				Path:      ecosystem.Key,
+				Specifier: ecosystem.Specifier,
+				Direct:    &direct,
+				Dev:       trueOrNil(ecosystem.Dev),
+				Optional:  trueOrNil(ecosystem.Optional),
+				Link:      trueOrNil(ecosystem.Source == "workspace"),
+				Bun:       ecosystem,
+			}
+		},
+	}), nil
+}
+
+// parseProject 解析bun.lock文件，每个workspace对应一个模块，模块的依赖是从workspace出发可以到达的所有包，使用包管理器特有的生态系统类型
+func (x *BunLockParser) parseProject(ctx context.Context, input *BunLockParserInput) (*baseModels.Project[*models.BunLockProjectEcosystem, *models.BunLockModuleEcosystem, *models.BunLockComponentEcosystem, *models.BunLockComponentDependencyEcosystem], error) {
+	bunLock, err := x.ParseLockfile(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &baseModels.Project[*models.BunLockProjectEcosystem, *models.BunLockModuleEcosystem, *models.BunLockComponentEcosystem, *models.BunLockComponentDependencyEcosystem]{}
+	project.Name = "unknown"
+	project.ProjectEcosystem = &models.BunLockProjectEcosystem{
+		LockfileVersion:     bunLock.LockfileVersion,
+		TrustedDependencies: bunLock.TrustedDependencies,
+		Overrides:           bunLock.Overrides,
+		PatchedDependencies: bunLock.PatchedDependencies,
+		Catalog:             bunLock.Catalog,
+		Catalogs:            bunLock.Catalogs,
+	}
+
+	workspacePaths := make([]string, 0, len(bunLock.Workspaces))
+	for workspacePath := range bunLock.Workspaces {
+		workspacePaths = append(workspacePaths, workspacePath)
+	}
+	sort.Strings(workspacePaths)
+
+	for _, workspacePath := range workspacePaths {
+		workspace := bunLock.Workspaces[workspacePath]
+		module := x.createWorkspaceModule(bunLock, workspacePath, workspace)
+
+		// 根workspace就是项目本身，没有名称的workspace使用路径作为名称
+		if workspacePath == "" {
+			if workspace.Name != "" {
+				project.Name = workspace.Name
+			}
+			project.Version = workspace.Version
+			module.Name = project.Name
+		} else if module.Name == "" {
+			module.Name = workspacePath
+		}
+		project.SetModule(module.Name, module)
+	}
+
+	return project, nil
+}
+
+// ParseLockfile 把bun.lock解析为lockfile本身的模型，不转换为项目
+func (x *BunLockParser) ParseLockfile(ctx context.Context, input *BunLockParserInput) (*models.BunLock, error) {
+	bunLockBytes, err := input.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bun.lock: %w", err)
+	}
+
+	bunLock, err := x.parseBunLock(bunLockBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bun.lock: %w", err)
+	}
+	return bunLock, nil
+}
+
+// parseBunLock 解析bun.lock的内容，bun.lock是允许尾随逗号的JSONC
+func (x *BunLockParser) parseBunLock(data []byte) (*models.BunLock, error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, fmt.Errorf("bun.lock file is empty")
+	}
+
+	file := &bunLockFile{}
+	if err := json.Unmarshal(stripJsonc(data), file); err != nil {
+		return nil, fmt.Errorf("invalid bun lockfile: %w", err)
+	}
+	if file.LockfileVersion == nil {
+		return nil, fmt.Errorf("lockfileVersion not found")
+	}
+	if *file.LockfileVersion < 0 || *file.LockfileVersion > bunLockMaxVersion {
+		return nil, fmt.Errorf("unsupported lockfileVersion %d", *file.LockfileVersion)
+	}
+
+	bunLock := &models.BunLock{
+		LockfileVersion:     *file.LockfileVersion,
+		Workspaces:          file.Workspaces,
+		TrustedDependencies: file.TrustedDependencies,
+		Overrides:           file.Overrides,
+		PatchedDependencies: file.PatchedDependencies,
+		Catalog:             file.Catalog,
+		Catalogs:            file.Catalogs,
+		Packages:            make(map[string]*models.BunLockPackage, len(file.Packages)),
+	}
+	if bunLock.Workspaces == nil {
+		bunLock.Workspaces = make(map[string]*models.BunLockWorkspace)
+	}
+	for workspacePath, workspace := range bunLock.Workspaces {
+		if workspace == nil {
+			bunLock.Workspaces[workspacePath] = &models.BunLockWorkspace{}
+		}
+	}
+
+	for key, tuple := range file.Packages {
+		pkg, err := x.parseBunLockPackage(tuple)
+		if err != nil {
+			return nil, fmt.Errorf("invalid package %q: %w", key, err)
+		}
+		bunLock.Packages[key] = pkg
+	}
+
+	return bunLock, nil
+}
+
+// parseBunLockPackage 解析packages中的一个包数组
+func (x *BunLockParser) parseBunLockPackage(tuple []json.RawMessage) (*models.BunLockPackage, error) {
+	if len(tuple) == 0 {
+		return nil, fmt.Errorf("empty package entry")
+	}
+
+	pkg := &models.BunLockPackage{}
+	if err := json.Unmarshal(tuple[0], &pkg.Resolution); err != nil {
+		return nil, fmt.Errorf("resolution must be a string: %w", err)
+	}
+	pkg.Name, pkg.Version = splitPackageSpec(pkg.Resolution)
+	isNpm := bunLockSource(pkg.Version) == "npm"
+
+	// 除了resolution之外的元素按类型区分：对象是依赖等信息，字符串是registry、integrity或者提交标识
+	hasInfo := false
+	for i, raw := range tuple[1:] {
+		trimmed := strings.TrimSpace(string(raw))
+		if strings.HasPrefix(trimmed, "{") {
+			info := &bunLockPackageInfo{}
+			if err := json.Unmarshal(raw, info); err != nil {
+				return nil, err
+			}
+			pkg.Dependencies = info.Dependencies
+			pkg.OptionalDependencies = info.OptionalDependencies
+			pkg.PeerDependencies = info.PeerDependencies
+			pkg.OptionalPeers = info.OptionalPeers
+			pkg.Bin = decodeBunLockBin(info.Bin)
+			pkg.Os = decodeBunLockStrings(info.Os)
+			pkg.Cpu = decodeBunLockStrings(info.Cpu)
+			hasInfo = true
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("unexpected element %d: %s", i+1, trimmed)
+		}
+		switch {
+		case isIntegrity(value):
+			pkg.Integrity = value
+		case isNpm && !hasInfo:
+			pkg.Registry = value
+		default:
+			pkg.Tag = value
+		}
+	}
+
+	return pkg, nil
+}
+
+// isIntegrity 判断字符串是否是subresource integrity，比如 sha512-xxx
+func isIntegrity(value string) bool {
+	for _, algorithm := range []string{"sha1-", "sha256-", "sha384-", "sha512-"} {
+		if strings.HasPrefix(value, algorithm) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeBunLockStrings os和cpu既可能是一个字符串也可能是字符串数组
+func decodeBunLockStrings(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err == nil {
+		return values
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil && value != "" {
+		return []string{value}
+	}
+	return nil
+}
+
+// decodeBunLockBin bin通常是命令名到文件的映射，无法识别的格式忽略
+func decodeBunLockBin(raw json.RawMessage) map[string]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	bin := make(map[string]string)
+	if err := json.Unmarshal(raw, &bin); err != nil {
+		return nil
+	}
+	return bin
+}
+
+// bunLockSource 根据resolution的版本部分判断包的来源
+func bunLockSource(reference string) string {
+	switch {
+	case strings.HasPrefix(reference, "workspace:"):
+		return "workspace"
+	case strings.HasPrefix(reference, "link:"):
+		return "link"
+	case strings.HasPrefix(reference, "file:"):
+		return "file"
+	case strings.HasPrefix(reference, "root:"):
+		return "root"
+	case strings.HasPrefix(reference, "github:"):
+		return "github"
+	case strings.HasPrefix(reference, "git+") || strings.HasPrefix(reference, "git:"):
+		return "git"
+	case strings.HasPrefix(reference, "http://") || strings.HasPrefix(reference, "https://"):
+		return "tarball"
+	}
+	return "npm"
+}
+
+// splitBunLockKey 把packages中的键拆分为路径上的各个包名，会把作用域和包名合在一起
+func splitBunLockKey(key string) []string {
+	segments := strings.Split(key, "/")
+	names := make([]string, 0, len(segments))
+	for i := 0; i < len(segments); i++ {
+		if strings.HasPrefix(segments[i], "@") && i+1 < len(segments) {
+			names = append(names, segments[i]+"/"+segments[i+1])
+			i++
+			continue
+		}
+		names = append(names, segments[i])
+	}
+	return names
+}
+
+// resolveBunLockKey 按照node的模块查找规则找到从from出发依赖name时使用的包：
+// 先找 from/name，找不到再去掉from的最后一级继续向上找，最后是顶层的name
+func resolveBunLockKey(packages map[string]*models.BunLockPackage, from string, name string) (string, bool) {
+	names := splitBunLockKey(from)
+	if from == "" {
+		names = nil
+	}
+	for depth := len(names); depth >= 0; depth-- {
+		key := name
+		if depth > 0 {
+			key = strings.Join(names[:depth], "/") + "/" + name
+		}
+		if _, ok := packages[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// walkBunLockPackages 从给定的包出发广度优先遍历，返回可以到达的包，includeOptional为false时不经过可选依赖，
+// workspace包的依赖属于它自己的模块，不会继续遍历
+func walkBunLockPackages(packages map[string]*models.BunLockPackage, roots []string, includeOptional bool) map[string]bool {
+	visited := make(map[string]bool)
+	queue := make([]string, 0, len(roots))
+	for _, key := range roots {
+		if !visited[key] {
+			visited[key] = true
+			queue = append(queue, key)
+		}
+	}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		pkg := packages[key]
+		if bunLockSource(pkg.Version) == "workspace" {
+			continue
+		}
+
+		edges := []map[string]string{pkg.Dependencies}
+		if includeOptional {
+			edges = append(edges, pkg.OptionalDependencies)
+		}
+		for _, dependencies := range edges {
+			for _, childName := range sortedStringMapKeys(dependencies) {
+				childKey, ok := resolveBunLockKey(packages, key, childName)
+				if !ok || visited[childKey] {
+					continue
+				}
+				visited[childKey] = true
+				queue = append(queue, childKey)
+			}
+		}
+	}
+	return visited
+}
+
+// createWorkspaceModule 为一个workspace创建模块，dev和optional标记按照从这个workspace出发的可达性计算
+func (x *BunLockParser) createWorkspaceModule(bunLock *models.BunLock, workspacePath string, workspace *models.BunLockWorkspace) *baseModels.Module[*models.BunLockModuleEcosystem, *models.BunLockComponentEcosystem, *models.BunLockComponentDependencyEcosystem] {
+	module := &baseModels.Module[*models.BunLockModuleEcosystem, *models.BunLockComponentEcosystem, *models.BunLockComponentDependencyEcosystem]{}
+	module.Name = workspace.Name
+	module.Version = workspace.Version
+	module.ModuleEcosystem = &models.BunLockModuleEcosystem{WorkspacePath: workspacePath}
+
+	// 非根workspace专用的版本以workspace的包名作为键的前缀
+	from := ""
+	if workspacePath != "" {
+		from = workspace.Name
+	}
+
+	specifiers := make(map[string]string)
+	var allRoots, prodRoots, nonOptionalRoots []string
+	addRoots := func(dependencies map[string]string, dev bool, optional bool) {
+		for _, name := range sortedStringMapKeys(dependencies) {
+			key, ok := resolveBunLockKey(bunLock.Packages, from, name)
+			if !ok {
+				continue
+			}
+			if _, ok := specifiers[key]; !ok {
+				specifiers[key] = dependencies[name]
+			}
+			allRoots = append(allRoots, key)
+			if !dev {
+				prodRoots = append(prodRoots, key)
+			}
+			if !optional {
+				nonOptionalRoots = append(nonOptionalRoots, key)
+			}
+		}
+	}
+	addRoots(workspace.Dependencies, false, false)
+	addRoots(workspace.DevDependencies, true, false)
+	addRoots(workspace.OptionalDependencies, false, true)
+
+	reachable := walkBunLockPackages(bunLock.Packages, allRoots, true)
+	prodReachable := walkBunLockPackages(bunLock.Packages, prodRoots, true)
+	nonOptionalReachable := walkBunLockPackages(bunLock.Packages, nonOptionalRoots, false)
+
+	keys := make([]string, 0, len(reachable))
+	for key := range reachable {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dependencies := make([]*baseModels.ComponentDependency[*models.BunLockComponentDependencyEcosystem], 0, len(keys))
+	for _, key := range keys {
+		pkg := bunLock.Packages[key]
+		dependency := &baseModels.ComponentDependency[*models.BunLockComponentDependencyEcosystem]{}
+		dependency.DependencyName = pkg.Name
+		dependency.DependencyVersion = pkg.Version
+
+		ecosystem := &models.BunLockComponentDependencyEcosystem{
+			Key:        key,
+			Resolution: pkg.Resolution,
+			Source:     bunLockSource(pkg.Version),
+			Registry:   pkg.Registry,
+			Integrity:  pkg.Integrity,
+			Dev:        !prodReachable[key],
+			Optional:   !nonOptionalReachable[key],
+		}
+		if specifier, ok := specifiers[key]; ok {
+			ecosystem.Direct = true
+			ecosystem.Specifier = specifier
+		}
+		names := splitBunLockKey(key)
+		if installedName := names[len(names)-1]; installedName != pkg.Name {
+			ecosystem.Alias = installedName
+		}
+		dependency.ComponentDependencyEcosystem = ecosystem
+		dependencies = append(dependencies, dependency)
+	}
+
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		return dependencies[i].DependencyName < dependencies[j].DependencyName
+	})
+	module.Dependencies = dependencies
+	return module
+}
+
+func (x *BunLockParser) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"io/fs"
+)
+
+// BunLockFileName bun.lock的文件名
+const BunLockFileName = "bun.lock"
+
+// BunLockParserInput 解析器的输入，按照 BunLockContent、BunLockReader、BunLockPath、ProjectRootDirectory 的优先级读取bun.lock
+type BunLockParserInput struct {
+	// BunLockPath bun.lock文件的路径
+	BunLockPath string
+
+	// BunLockContent 直接传入的bun.lock内容
+	BunLockContent string
+
+	// BunLockReader 从reader中读取bun.lock的内容，只能被读取一次
+	BunLockReader io.Reader
+
+	// ProjectRootDirectory 项目根目录，会读取其中的bun.lock
+	ProjectRootDirectory string
+
+	// FileSystem 指定后BunLockPath和ProjectRootDirectory都是这个文件系统中的路径
+	FileSystem fs.FS
+}
+
+// Read 读取bun.lock文件内容
+func (x *BunLockParserInput) Read(ctx context.Context) ([]byte, error) {
+
+	if x.BunLockContent != "" {
+		return []byte(x.BunLockContent), nil
+	}
+
+	if x.BunLockReader != nil {
+		return readInputReader(x.BunLockReader)
+	}
+
+	if x.BunLockPath != "" {
+		return readInputFile(x.FileSystem, x.BunLockPath)
+	}
+
+	return readInputFile(x.FileSystem, joinInputPath(x.FileSystem, x.ProjectRootDirectory, BunLockFileName))
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBunLockParser_Parse(t *testing.T) {
+	project, err := NewBunLockParser().Parse(context.Background(), &BunLockParserInput{BunLockPath: "test_data/bun.lock/monorepo.lock"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "bun-monorepo", project.Name)
+	assert.Equal(t, 1, project.ProjectEcosystem.Bun.LockfileVersion)
+	assert.Equal(t, []string{"esbuild"}, project.ProjectEcosystem.Bun.TrustedDependencies)
+	require.Len(t, project.Modules, 2)
+
+	root := project.Modules["bun-monorepo"]
+	require.NotNil(t, root)
+	assert.Equal(t, "", root.ModuleEcosystem.Bun.WorkspacePath)
+	require.Len(t, root.Dependencies, 6)
+	// 共用字段中的Path是packages中的键
+	rootDependencies := indexDependencies(root, dependencyPathKey)
+
+	react := rootDependencies["react"]
+	require.NotNil(t, react)
+	assert.Equal(t, "18.3.1", react.DependencyVersion)
+	assert.Equal(t, "sha512-react-18", react.ComponentDependencyEcosystem.Bun.Integrity)
+	assert.Equal(t, "npm", react.ComponentDependencyEcosystem.Bun.Source)
+	assert.True(t, react.ComponentDependencyEcosystem.Bun.Direct)
+	assert.Equal(t, "^18.2.0", react.ComponentDependencyEcosystem.Bun.Specifier)
+	assert.False(t, react.ComponentDependencyEcosystem.Bun.Dev)
+	// 共用字段
+	assert.Equal(t, models.PackageManagerBun, project.ProjectEcosystem.PackageManager)
+	assert.Equal(t, "react", react.ComponentDependencyEcosystem.Path)
+	assert.Equal(t, "^18.2.0", react.ComponentDependencyEcosystem.Specifier)
+	assert.True(t, *react.ComponentDependencyEcosystem.Direct)
+	assert.Nil(t, react.ComponentDependencyEcosystem.Dev)
+
+	// 传递依赖使用顶层的js-tokens
+	jsTokens := rootDependencies["js-tokens"]
+	require.NotNil(t, jsTokens)
+	assert.Equal(t, "4.0.0", jsTokens.DependencyVersion)
+	assert.False(t, jsTokens.ComponentDependencyEcosystem.Bun.Direct)
+
+	typescript := rootDependencies["typescript"]
+	require.NotNil(t, typescript)
+	assert.True(t, typescript.ComponentDependencyEcosystem.Bun.Dev)
+	assert.Equal(t, "https://npm.example.com/", typescript.ComponentDependencyEcosystem.Bun.Registry)
+
+	fsevents := rootDependencies["fsevents"]
+	require.NotNil(t, fsevents)
+	assert.True(t, fsevents.ComponentDependencyEcosystem.Bun.Optional)
+
+	shared := rootDependencies["@demo/shared"]
+	require.NotNil(t, shared)
+	assert.Equal(t, "workspace", shared.ComponentDependencyEcosystem.Bun.Source)
+	assert.Equal(t, "workspace:packages/shared", shared.DependencyVersion)
+
+	// 非根workspace
+	sharedModule := project.Modules["@demo/shared"]
+	require.NotNil(t, sharedModule)
+	assert.Equal(t, "1.2.0", sharedModule.Version)
+	assert.Equal(t, "packages/shared", sharedModule.ModuleEcosystem.Bun.WorkspacePath)
+	require.Len(t, sharedModule.Dependencies, 5)
+	sharedDependencies := indexDependencies(sharedModule, dependencyPathKey)
+
+	// workspace专用的版本
+	nestedTokens := sharedDependencies["@demo/shared/js-tokens"]
+	require.NotNil(t, nestedTokens)
+	assert.Equal(t, "3.0.2", nestedTokens.DependencyVersion)
+	assert.True(t, nestedTokens.ComponentDependencyEcosystem.Bun.Direct)
+	assert.NotNil(t, sharedDependencies["js-tokens"], "loose-envify使用顶层的js-tokens")
+
+	ms := sharedDependencies["@demo/shared/my-ms"]
+	require.NotNil(t, ms)
+	assert.Equal(t, "ms", ms.DependencyName)
+	assert.Equal(t, "my-ms", ms.ComponentDependencyEcosystem.Bun.Alias)
+
+	tinyLib := sharedDependencies["@demo/shared/tiny-lib"]
+	require.NotNil(t, tinyLib)
+	assert.Equal(t, "github", tinyLib.ComponentDependencyEcosystem.Bun.Source)
+	assert.Equal(t, "github:demo/tiny-lib#a1b2c3d", tinyLib.DependencyVersion)
+	assert.Empty(t, tinyLib.ComponentDependencyEcosystem.Bun.Integrity)
+}
+
+func TestBunLockParser_ParseLockfile(t *testing.T) {
+	content, err := os.ReadFile("test_data/bun.lock/monorepo.lock")
+	require.NoError(t, err)
+	fileSystem := fstest.MapFS{"app/bun.lock": &fstest.MapFile{Data: content}}
+
+	bunLock, err := NewBunLockParser().ParseLockfile(context.Background(), &BunLockParserInput{FileSystem: fileSystem, ProjectRootDirectory: "app"})
+	require.NoError(t, err)
+	require.Len(t, bunLock.Packages, 9)
+
+	looseEnvify := bunLock.Packages["loose-envify"]
+	assert.Equal(t, "loose-envify", looseEnvify.Name)
+	assert.Equal(t, "1.4.0", looseEnvify.Version)
+	assert.Equal(t, "", looseEnvify.Registry)
+	assert.Equal(t, "sha512-loose-envify-1", looseEnvify.Integrity)
+	assert.Equal(t, map[string]string{"js-tokens": "^3.0.0 || ^4.0.0"}, looseEnvify.Dependencies)
+	assert.Equal(t, map[string]string{"loose-envify": "cli.js"}, looseEnvify.Bin)
+
+	assert.Equal(t, []string{"darwin"}, bunLock.Packages["fsevents"].Os)
+	assert.Equal(t, "demo-tiny-lib-a1b2c3d", bunLock.Packages["@demo/shared/tiny-lib"].Tag)
+	assert.Equal(t, "@demo/shared@workspace:packages/shared", bunLock.Packages["@demo/shared"].Resolution)
+}
+
+func TestBunLockParser_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "空内容", content: "  \n"},
+		{name: "没有lockfileVersion", content: `{"packages": {}}`},
+		{name: "不支持的版本", content: `{"lockfileVersion": 2}`},
+		{name: "不是JSON", content: `{"lockfileVersion": 1`},
+		{name: "空的包数组", content: `{"lockfileVersion": 1, "packages": {"a": []}}`},
+		{name: "resolution不是字符串", content: `{"lockfileVersion": 1, "packages": {"a": [1]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBunLockParser().Parse(context.Background(), &BunLockParserInput{BunLockReader: strings.NewReader(tt.content)})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestResolveBunLockKey(t *testing.T) {
+	packages := map[string]*models.BunLockPackage{
+		"a":            {},
+		"b":            {},
+		"a/b":          {},
+		"@s/c":         {},
+		"@s/c/@s/d":    {},
+		"@s/c/@s/d/b":  {},
+		"@ws/app/b":    {},
+		"@ws/app/a/x":  {},
+		"@ws/app/a":    {},
+		"standalone/x": {},
+	}
+
+	tests := []struct {
+		from string
+		name string
+		want string
+	}{
+		{from: "", name: "a", want: "a"},
+		{from: "a", name: "b", want: "a/b"},
+		{from: "@s/c", name: "b", want: "b"},
+		{from: "@s/c/@s/d", name: "b", want: "@s/c/@s/d/b"},
+		{from: "@s/c/@s/d", name: "a", want: "a"},
+		{from: "@ws/app", name: "b", want: "@ws/app/b"},
+		{from: "@ws/app/a", name: "x", want: "@ws/app/a/x"},
+		{from: "", name: "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+">"+tt.name, func(t *testing.T) {
+			got, ok := resolveBunLockKey(packages, tt.from, tt.name)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// cacacheRequestKeyPrefix npm通过make-fetch-happen缓存HTTP响应时使用的键的前缀，后面是请求的URL
+const cacacheRequestKeyPrefix = "make-fetch-happen:request-cache:"
+
+// CacacheSource 从npm的缓存目录（~/.npm/_cacache）中读取曾经请求过的packument，不会访问网络。
+// cacache的索引在 index-v5/ 中，文件名是键的sha256，每行是 <行内容的sha1>\t<JSON>，后写入的行覆盖前面的行；
+// 内容在 content-v2/<算法>/ 中，文件名是内容的校验值
+type CacacheSource struct {
+	// CacheDirectory _cacache目录
+	CacheDirectory string
+
+	// Registry 指定后按照这个registry的URL查找缓存，否则按照Config选择registry
+	Registry string
+
+	// Config .npmrc中的配置，用于选择registry，可以为空
+	Config *models.NpmConfig
+
+	// FileSystem 指定后CacheDirectory是这个文件系统中的路径，否则从本地文件系统读取
+	FileSystem fs.FS
+}
+
+var _ RegistryMetadataSource = &CacacheSource{}
+
+func NewCacacheSource(cacheDirectory string, config *models.NpmConfig) *CacacheSource {
+	return &CacacheSource{
+		CacheDirectory: cacheDirectory,
+		Config:         config,
+	}
+}
+
+// cacacheIndexEntry 索引中的一条记录，integrity为空表示这条缓存已经被删除
+type cacacheIndexEntry struct {
+	Key       string `json:"key"`
+	Integrity string `json:"integrity"`
+	Time      int64  `json:"time"`
+	Size      int64  `json:"size"`
+}
+
+// Fetch 查找 <registry>/<包名> 这个请求的缓存，没有缓存时返回nil
+func (x *CacacheSource) Fetch(ctx context.Context, packageName string) (*models.Packument, error) {
+	if x.CacheDirectory == "" {
+		return nil, fmt.Errorf("cacache directory cannot be empty")
+	}
+	key := cacacheRequestKeyPrefix + x.registryFor(packageName) + escapePackageName(packageName)
+	entry, err := x.findEntry(key)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	data, err := x.readContent(entry.Integrity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached content of %s: %w", key, err)
+	}
+	return parsePackument(data, strings.TrimPrefix(key, cacacheRequestKeyPrefix))
+}
+
+// registryFor 查询这个包使用的registry，总是以/结尾
+func (x *CacacheSource) registryFor(packageName string) string {
+	if x.Registry != "" {
+		return withRegistryTrailingSlash(x.Registry)
+	}
+	if x.Config != nil {
+		return x.Config.RegistryFor(packageName)
+	}
+	return models.NpmDefaultRegistry
+}
+
+// findEntry 读取键所在的索引文件，返回这个键最后写入的记录，校验失败的行会被忽略，跟cacache的行为一致
+func (x *CacacheSource) findEntry(key string) (*cacacheIndexEntry, error) {
+	digest := sha256.Sum256([]byte(key))
+	hashed := hex.EncodeToString(digest[:])
+	indexPath := joinInputPath(x.FileSystem, x.CacheDirectory, "index-v5", hashed[:2], hashed[2:4], hashed[4:])
+	data, err := readInputFile(x.FileSystem, indexPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var found *cacacheIndexEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		lineHash, content, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		contentHash := sha1.Sum([]byte(content))
+		if hex.EncodeToString(contentHash[:]) != lineHash {
+			continue
+		}
+		entry := &cacacheIndexEntry{}
+		if err := json.Unmarshal([]byte(content), entry); err != nil || entry.Key != key {
+			continue
+		}
+		found = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if found == nil || found.Integrity == "" {
+		return nil, nil
+	}
+	return found, nil
+}
+
+// readContent 按照校验值读取缓存的内容并校验，integrity中有多个校验值时使用第一个支持的算法
+func (x *CacacheSource) readContent(integrity string) ([]byte, error) {
+	for _, item := range strings.Fields(integrity) {
+		algorithm, encoded, ok := strings.Cut(item, "-")
+		if !ok {
+			continue
+		}
+		newHash := cacacheHashes[algorithm]
+		if newHash == nil {
+			continue
+		}
+		// 校验值后面可能有?opt这样的选项
+		if index := strings.Index(encoded, "?"); index >= 0 {
+			encoded = encoded[:index]
+		}
+		digest, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integrity %s: %w", item, err)
+		}
+		hashed := hex.EncodeToString(digest)
+		if len(hashed) < 5 {
+			return nil, fmt.Errorf("invalid integrity %s", item)
+		}
+		contentPath := joinInputPath(x.FileSystem, x.CacheDirectory, "content-v2", algorithm, hashed[:2], hashed[2:4], hashed[4:])
+		data, err := readInputFile(x.FileSystem, contentPath)
+		if err != nil {
+			return nil, err
+		}
+		h := newHash()
+		h.Write(data)
+		if !bytes.Equal(h.Sum(nil), digest) {
+			return nil, fmt.Errorf("integrity check failed for %s", contentPath)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("unsupported integrity %s", integrity)
+}
+
+// cacacheHashes cacache支持的校验算法
+var cacacheHashes = map[string]func() hash.Hash{
+	"sha512": sha512.New,
+	"sha384": sha512.New384,
+	"sha256": sha256.New,
+	"sha1":   sha1.New,
+}
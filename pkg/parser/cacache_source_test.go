@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addCacacheTestEntry 按照cacache的布局把内容和索引写入MapFS，content为空时写入一条删除记录
+func addCacacheTestEntry(fileSystem fstest.MapFS, cacheDirectory, key, content string) {
+	integrity := ""
+	if content != "" {
+		digest := sha512.Sum512([]byte(content))
+		integrity = "sha512-" + base64.StdEncoding.EncodeToString(digest[:])
+		hashed := hex.EncodeToString(digest[:])
+		fileSystem[path.Join(cacheDirectory, "content-v2", "sha512", hashed[:2], hashed[2:4], hashed[4:])] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	entry := map[string]any{"key": key, "time": 1700000000000, "size": len(content)}
+	if integrity != "" {
+		entry["integrity"] = integrity
+	} else {
+		entry["integrity"] = nil
+	}
+	data, _ := json.Marshal(entry)
+	lineHash := sha1.Sum(data)
+	line := hex.EncodeToString(lineHash[:]) + "\t" + string(data)
+
+	keyHash := sha256.Sum256([]byte(key))
+	hashed := hex.EncodeToString(keyHash[:])
+	indexPath := path.Join(cacheDirectory, "index-v5", hashed[:2], hashed[2:4], hashed[4:])
+	previous := ""
+	if file, ok := fileSystem[indexPath]; ok {
+		previous = string(file.Data)
+	}
+	fileSystem[indexPath] = &fstest.MapFile{Data: []byte(previous + "\n" + line)}
+}
+
+func TestCacacheSource_Fetch(t *testing.T) {
+	fileSystem := fstest.MapFS{}
+	addCacacheTestEntry(fileSystem, "npm/_cacache", "make-fetch-happen:request-cache:https://registry.npmjs.org/lodash", `{"name": "lodash", "dist-tags": {"latest": "4.17.20"}}`)
+	addCacacheTestEntry(fileSystem, "npm/_cacache", "make-fetch-happen:request-cache:https://registry.npmjs.org/lodash", testPackuments["lodash"])
+	addCacacheTestEntry(fileSystem, "npm/_cacache", "make-fetch-happen:request-cache:https://npm.acme.internal/@acme%2fui", testPackuments["@acme/ui"])
+	addCacacheTestEntry(fileSystem, "npm/_cacache", "make-fetch-happen:request-cache:https://registry.npmjs.org/left-pad", `{"name": "left-pad"}`)
+	addCacacheTestEntry(fileSystem, "npm/_cacache", "make-fetch-happen:request-cache:https://registry.npmjs.org/left-pad", "")
+
+	source := &CacacheSource{CacheDirectory: "npm/_cacache", FileSystem: fileSystem}
+
+	lodash, err := source.Fetch(context.Background(), "lodash")
+	require.NoError(t, err)
+	require.NotNil(t, lodash)
+	assert.Equal(t, "4.17.21", lodash.Latest().Version, "后写入的记录生效")
+	assert.Equal(t, "https://registry.npmjs.org/lodash", lodash.Source)
+
+	leftPad, err := source.Fetch(context.Background(), "left-pad")
+	require.NoError(t, err)
+	assert.Nil(t, leftPad, "缓存已经被删除")
+
+	missing, err := source.Fetch(context.Background(), "@acme/ui")
+	require.NoError(t, err)
+	assert.Nil(t, missing, "默认的registry中没有缓存")
+
+	// 按照配置选择registry
+	config, err := NewNpmrcParser().Parse(context.Background(), &NpmrcParserInput{
+		ProjectRootDirectory: "app",
+		FileSystem:           newWorkspaceTestFileSystem(map[string]string{"app/.npmrc": "@acme:registry=https://npm.acme.internal"}),
+		Environment:          map[string]string{},
+	})
+	require.NoError(t, err)
+	source.Config = config
+	ui, err := source.Fetch(context.Background(), "@acme/ui")
+	require.NoError(t, err)
+	require.NotNil(t, ui)
+	assert.Equal(t, "2.0.0", ui.Latest().Version)
+
+	// 校验失败的索引行被忽略
+	keyHash := sha256.Sum256([]byte("make-fetch-happen:request-cache:https://registry.npmjs.org/lodash"))
+	hashed := hex.EncodeToString(keyHash[:])
+	indexPath := path.Join("npm/_cacache", "index-v5", hashed[:2], hashed[2:4], hashed[4:])
+	fileSystem[indexPath].Data = append(fileSystem[indexPath].Data, []byte("\n0000\t{\"key\":\"make-fetch-happen:request-cache:https://registry.npmjs.org/lodash\",\"integrity\":null}")...)
+	lodash, err = source.Fetch(context.Background(), "lodash")
+	require.NoError(t, err)
+	require.NotNil(t, lodash)
+
+	// 内容被篡改
+	for filePath, file := range fileSystem {
+		if path.Base(path.Dir(path.Dir(path.Dir(filePath)))) == "sha512" && string(file.Data) == testPackuments["lodash"] {
+			file.Data = []byte(`{"name": "lodash"}`)
+		}
+	}
+	_, err = source.Fetch(context.Background(), "lodash")
+	assert.Error(t, err)
+
+	_, err = (&CacacheSource{}).Fetch(context.Background(), "lodash")
+	assert.Error(t, err)
+}
@@ -0,0 +1,551 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
+	"github.com/scagogogo/sca-base-module-ecosystem-parser/pkg/parser"
+)
+
+// DenoParser 用于解析Deno项目的deno.json和deno.lock，只关心通过 npm: 和 jsr: 引入的包
+type DenoParser struct {
+}
+
+var _ parser.Parser[*DenoParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &DenoParser{}
+
+func NewDenoParser() *DenoParser {
+	return &DenoParser{}
+}
+
+const DenoParserName = "deno-parser"
+
+// denoNpmPrefix 和 denoJsrPrefix npm包和JSR包的声明前缀
+const (
+	denoNpmPrefix = "npm:"
+	denoJsrPrefix = "jsr:"
+)
+
+func (x *DenoParser) GetName() string {
+	return DenoParserName
+}
+
+func (x *DenoParser) Init(ctx context.Context) error {
+	return nil
+}
+
+// denoLockWorkspaceFile lockfile中的workspace部分，3、4、5版本的结构相同
+type denoLockWorkspaceFile struct {
+	Dependencies []string `json:"dependencies"`
+	PackageJson  *struct {
+		Dependencies []string `json:"dependencies"`
+	} `json:"packageJson"`
+	Members map[string]*struct {
+		Dependencies []string `json:"dependencies"`
+	} `json:"members"`
+}
+
+// denoLockJsrFile JSR包在lockfile中的结构，3、4、5版本的结构相同
+type denoLockJsrFile struct {
+	Integrity    string   `json:"integrity"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// denoLockV3File version 3的deno.lock，声明和包都在packages下，npm包的依赖是包名到键的映射
+type denoLockV3File struct {
+	Packages *struct {
+		Specifiers map[string]string           `json:"specifiers"`
+		Jsr        map[string]*denoLockJsrFile `json:"jsr"`
+		Npm        map[string]*struct {
+			Integrity    string            `json:"integrity"`
+			Dependencies map[string]string `json:"dependencies"`
+		} `json:"npm"`
+	} `json:"packages"`
+	Remote    map[string]string      `json:"remote"`
+	Workspace *denoLockWorkspaceFile `json:"workspace"`
+}
+
+// denoLockV4File version 4和5的deno.lock，声明和包都在顶层，npm包的依赖是简写的包名列表
+type denoLockV4File struct {
+	Specifiers map[string]string           `json:"specifiers"`
+	Jsr        map[string]*denoLockJsrFile `json:"jsr"`
+	Npm        map[string]*struct {
+		Integrity            string   `json:"integrity"`
+		Dependencies         []string `json:"dependencies"`
+		OptionalDependencies []string `json:"optionalDependencies"`
+	} `json:"npm"`
+	Remote    map[string]string      `json:"remote"`
+	Workspace *denoLockWorkspaceFile `json:"workspace"`
+}
+
+// denoNode 依赖图中的一个节点
+type denoNode struct {
+	Ecosystem string
+	Key       string
+}
+
+// Parse 解析deno.json和deno.lock，返回所有JavaScript解析器共用的类型，Deno特有的信息放在各个生态系统的Deno扩展字段中
+func (x *DenoParser) Parse(ctx context.Context, input *DenoParserInput) (*models.JsProject, error) {
+	project, err := x.parseProject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return toJsProject(project, &jsEcosystemConverter[*models.DenoProjectEcosystem, *models.DenoModuleEcosystem, *models.DenoComponentDependencyEcosystem]{
+		project: func(ecosystem *models.DenoProjectEcosystem) *models.JsProjectEcosystem {
+			return &models.JsProjectEcosystem{PackageManager: models.PackageManagerDeno, Deno: ecosystem}
+		},
+		module: func(ecosystem *models.DenoModuleEcosystem) *models.JsModuleEcosystem {
+			return &models.JsModuleEcosystem{WorkspacePath: ecosystem.WorkspacePath, Deno: ecosystem}
+		},
+		dependency: func(ecosystem *models.DenoComponentDependencyEcosystem) *models.JsComponentDependencyEcosystem {
+			direct := ecosystem.Direct
+			converted := &models.JsComponentDependencyEcosystem{
+				Ecosystem: models.DependencyEcosystemNpm,
+				Integrity: ecosystem.Integrity,
+				Path:      ecosystem.Key,
+				Specifier: ecosystem.Specifier,
+				Direct:    &direct,
+				Deno:      ecosystem,
+			}
+			// JSR包记录所属的生态系统，依赖路径前面也加上 jsr: 以跟npm包区分
+			if ecosystem.Ecosystem == models.DenoDependencyEcosystemJsr {
+				converted.Ecosystem = models.DependencyEcosystemJsr
+				converted.Path = denoJsrPrefix + ecosystem.Key
+			}
+			return converted
+		},
+	}), nil
+}
+
+// parseProject 解析deno.json和deno.lock，根目录对应一个模块，lockfile中记录的每个workspace成员各对应一个模块，使用包管理器特有的生态系统类型
+func (x *DenoParser) parseProject(ctx context.Context, input *DenoParserInput) (*baseModels.Project[*models.DenoProjectEcosystem, *models.DenoModuleEcosystem, *models.DenoComponentEcosystem, *models.DenoComponentDependencyEcosystem], error) {
+	denoJson, err := x.ParseDenoJson(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	denoLock, err := x.ParseDenoLock(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if denoJson == nil && denoLock == nil {
+		return nil, fmt.Errorf("neither deno.json nor deno.lock found")
+	}
+
+	project := &baseModels.Project[*models.DenoProjectEcosystem, *models.DenoModuleEcosystem, *models.DenoComponentEcosystem, *models.DenoComponentDependencyEcosystem]{}
+	project.Name = "unknown"
+	project.ProjectEcosystem = &models.DenoProjectEcosystem{}
+	if denoJson != nil && denoJson.Name != "" {
+		project.Name = denoJson.Name
+		project.Version = denoJson.Version
+	}
+	if denoLock == nil {
+		denoLock = &models.DenoLock{}
+	} else {
+		project.ProjectEcosystem.LockVersion = denoLock.Version
+		project.ProjectEcosystem.Remote = denoLock.Remote
+	}
+
+	// 根模块的依赖优先使用deno.json中的声明，没有deno.json时使用lockfile记录的声明
+	var rootSpecifiers []string
+	if denoJson != nil {
+		rootSpecifiers = denoJsonSpecifiers(denoJson)
+	} else if denoLock.Workspace != nil {
+		rootSpecifiers = denoLock.Workspace.Dependencies
+	}
+	rootModule := x.createModule(denoLock, rootSpecifiers)
+	rootModule.Name = project.Name
+	rootModule.Version = project.Version
+	rootModule.ModuleEcosystem = &models.DenoModuleEcosystem{}
+	project.SetModule(rootModule.Name, rootModule)
+
+	if denoLock.Workspace != nil {
+		memberPaths := make([]string, 0, len(denoLock.Workspace.Members))
+		for memberPath := range denoLock.Workspace.Members {
+			memberPaths = append(memberPaths, memberPath)
+		}
+		sort.Strings(memberPaths)
+		for _, memberPath := range memberPaths {
+			module := x.createModule(denoLock, denoLock.Workspace.Members[memberPath].Dependencies)
+			module.Name = memberPath
+			module.ModuleEcosystem = &models.DenoModuleEcosystem{WorkspacePath: memberPath}
+			project.SetModule(module.Name, module)
+		}
+	}
+
+	return project, nil
+}
+
+// ParseDenoJson 解析deno.json，没有deno.json时返回nil
+func (x *DenoParser) ParseDenoJson(ctx context.Context, input *DenoParserInput) (*models.DenoJson, error) {
+	data, err := input.ReadDenoJson(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deno.json: %w", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	denoJson := &models.DenoJson{}
+	if err := json.Unmarshal(stripJsonc(data), denoJson); err != nil {
+		return nil, fmt.Errorf("failed to parse deno.json: %w", err)
+	}
+	return denoJson, nil
+}
+
+// ParseDenoLock 解析deno.lock并统一不同版本的差异，没有deno.lock时返回nil
+func (x *DenoParser) ParseDenoLock(ctx context.Context, input *DenoParserInput) (*models.DenoLock, error) {
+	data, err := input.ReadDenoLock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deno.lock: %w", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	denoLock, err := x.parseDenoLock(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse deno.lock: %w", err)
+	}
+	return denoLock, nil
+}
+
+// parseDenoLock 按照version选择对应的结构解析deno.lock
+func (x *DenoParser) parseDenoLock(data []byte) (*models.DenoLock, error) {
+	header := &struct {
+		Version string `json:"version"`
+	}{}
+	if err := json.Unmarshal(data, header); err != nil {
+		return nil, fmt.Errorf("invalid deno lockfile: %w", err)
+	}
+
+	denoLock := &models.DenoLock{
+		Version:    header.Version,
+		Specifiers: make(map[string]string),
+		Jsr:        make(map[string]*models.DenoLockJsrPackage),
+		Npm:        make(map[string]*models.DenoLockNpmPackage),
+	}
+
+	var workspace *denoLockWorkspaceFile
+	switch header.Version {
+	case "3":
+		file := &denoLockV3File{}
+		if err := json.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("invalid deno lockfile: %w", err)
+		}
+		denoLock.Remote = file.Remote
+		workspace = file.Workspace
+		if file.Packages != nil {
+			// version 3中声明解析的结果是完整的 npm:chalk@5.3.0，统一为只有版本
+			for specifier, resolved := range file.Packages.Specifiers {
+				_, version := splitPackageSpec(trimDenoPrefix(resolved))
+				denoLock.Specifiers[specifier] = version
+			}
+			x.addJsrPackages(denoLock, file.Packages.Jsr)
+			for key, pkg := range file.Packages.Npm {
+				if pkg == nil {
+					continue
+				}
+				denoLock.Npm[key] = &models.DenoLockNpmPackage{Integrity: pkg.Integrity, Dependencies: pkg.Dependencies}
+			}
+		}
+	case "4", "5":
+		file := &denoLockV4File{}
+		if err := json.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("invalid deno lockfile: %w", err)
+		}
+		denoLock.Remote = file.Remote
+		workspace = file.Workspace
+		for specifier, version := range file.Specifiers {
+			denoLock.Specifiers[specifier] = version
+		}
+		x.addJsrPackages(denoLock, file.Jsr)
+
+		// 依赖的包在lockfile中只有一个版本时只写包名，需要根据包名找到唯一的键
+		keysByName := make(map[string][]string)
+		for key := range file.Npm {
+			name, _ := splitPackageSpec(key)
+			keysByName[name] = append(keysByName[name], key)
+		}
+		for key, pkg := range file.Npm {
+			if pkg == nil {
+				continue
+			}
+			converted := &models.DenoLockNpmPackage{Integrity: pkg.Integrity, Dependencies: make(map[string]string)}
+			for _, dependency := range append(append([]string{}, pkg.Dependencies...), pkg.OptionalDependencies...) {
+				name, dependencyKey, ok := resolveDenoNpmDependency(dependency, keysByName)
+				if ok {
+					converted.Dependencies[name] = dependencyKey
+				}
+			}
+			denoLock.Npm[key] = converted
+		}
+	default:
+		return nil, fmt.Errorf("unsupported deno lockfile version %q", header.Version)
+	}
+
+	if workspace != nil {
+		denoLock.Workspace = &models.DenoLockWorkspace{
+			Dependencies: workspace.Dependencies,
+			Members:      make(map[string]*models.DenoLockWorkspaceMember),
+		}
+		// package.json中声明的依赖也会被记录，它们都是npm包
+		if workspace.PackageJson != nil {
+			for _, dependency := range workspace.PackageJson.Dependencies {
+				denoLock.Workspace.Dependencies = append(denoLock.Workspace.Dependencies, dependency)
+			}
+		}
+		for memberPath, member := range workspace.Members {
+			converted := &models.DenoLockWorkspaceMember{}
+			if member != nil {
+				converted.Dependencies = member.Dependencies
+			}
+			denoLock.Workspace.Members[memberPath] = converted
+		}
+	}
+
+	return denoLock, nil
+}
+
+// addJsrPackages 把lockfile中的JSR包转换为模型
+func (x *DenoParser) addJsrPackages(denoLock *models.DenoLock, jsr map[string]*denoLockJsrFile) {
+	for key, pkg := range jsr {
+		if pkg == nil {
+			continue
+		}
+		denoLock.Jsr[key] = &models.DenoLockJsrPackage{Integrity: pkg.Integrity, Dependencies: pkg.Dependencies}
+	}
+}
+
+// resolveDenoNpmDependency 解析version 4中npm包的依赖：ms、ms@2.1.2 或者别名 alias@npm:real@1.0.0
+func resolveDenoNpmDependency(dependency string, keysByName map[string][]string) (name string, key string, ok bool) {
+	name, version := splitPackageSpec(dependency)
+	if version == "" {
+		if keys := keysByName[name]; len(keys) == 1 {
+			return name, keys[0], true
+		}
+		return "", "", false
+	}
+	if strings.HasPrefix(version, denoNpmPrefix) {
+		return name, strings.TrimPrefix(version, denoNpmPrefix), true
+	}
+	return name, dependency, true
+}
+
+// trimDenoPrefix 去掉声明开头的 npm: 或 jsr:，有些声明的包名前面还会多一个/
+func trimDenoPrefix(specifier string) string {
+	for _, prefix := range []string{denoNpmPrefix, denoJsrPrefix} {
+		if strings.HasPrefix(specifier, prefix) {
+			return strings.TrimPrefix(strings.TrimPrefix(specifier, prefix), "/")
+		}
+	}
+	return specifier
+}
+
+// normalizeDenoSpecifier 把import map中的声明转换为lockfile中使用的形式：去掉子路径，比如
+// npm:preact@^10.0.0/hooks -> npm:preact@^10.0.0，返回生态系统、包名和版本范围，不是npm或者JSR包时ok返回false
+func normalizeDenoSpecifier(specifier string) (normalized string, ecosystem string, name string, versionRange string, ok bool) {
+	switch {
+	case strings.HasPrefix(specifier, denoNpmPrefix):
+		ecosystem = models.DenoDependencyEcosystemNpm
+	case strings.HasPrefix(specifier, denoJsrPrefix):
+		ecosystem = models.DenoDependencyEcosystemJsr
+	default:
+		return "", "", "", "", false
+	}
+
+	name, versionRange = splitPackageSpec(trimDenoPrefix(specifier))
+	if versionRange == "" {
+		// 没有版本时子路径直接跟在包名后面
+		segments := strings.Split(name, "/")
+		if strings.HasPrefix(name, "@") && len(segments) > 2 {
+			name = strings.Join(segments[:2], "/")
+		} else if !strings.HasPrefix(name, "@") && len(segments) > 1 {
+			name = segments[0]
+		}
+	} else if slashIndex := strings.Index(versionRange, "/"); slashIndex >= 0 {
+		versionRange = versionRange[:slashIndex]
+	}
+	if name == "" {
+		return "", "", "", "", false
+	}
+
+	normalized = ecosystem + ":" + name
+	if versionRange != "" {
+		normalized += "@" + versionRange
+	}
+	return normalized, ecosystem, name, versionRange, true
+}
+
+// denoJsonSpecifiers 返回deno.json的imports和scopes中所有的声明，去重并排序
+func denoJsonSpecifiers(denoJson *models.DenoJson) []string {
+	unique := make(map[string]bool)
+	for _, specifier := range denoJson.Imports {
+		unique[specifier] = true
+	}
+	for _, scope := range denoJson.Scopes {
+		for _, specifier := range scope {
+			unique[specifier] = true
+		}
+	}
+	specifiers := make([]string, 0, len(unique))
+	for specifier := range unique {
+		specifiers = append(specifiers, specifier)
+	}
+	sort.Strings(specifiers)
+	return specifiers
+}
+
+// resolveDenoSpecifier 通过lockfile中的声明找到包在lockfile中的节点
+func resolveDenoSpecifier(denoLock *models.DenoLock, specifier string) (*denoNode, bool) {
+	normalized, ecosystem, name, versionRange, ok := normalizeDenoSpecifier(specifier)
+	if !ok {
+		return nil, false
+	}
+	version, ok := denoLock.Specifiers[normalized]
+	if !ok && versionRange == "" {
+		// version 4中JSR包的依赖在包只有一个版本时只写包名
+		version, ok = uniqueDenoSpecifierVersion(denoLock, normalized+"@")
+	}
+	if !ok {
+		return nil, false
+	}
+	node := &denoNode{Ecosystem: ecosystem, Key: name + "@" + version}
+	if ecosystem == models.DenoDependencyEcosystemNpm {
+		_, ok = denoLock.Npm[node.Key]
+	} else {
+		_, ok = denoLock.Jsr[node.Key]
+	}
+	return node, ok
+}
+
+// uniqueDenoSpecifierVersion 找到以prefix开头的声明解析出的版本，只有所有声明都解析到同一个版本时ok才返回true
+func uniqueDenoSpecifierVersion(denoLock *models.DenoLock, prefix string) (string, bool) {
+	version := ""
+	for specifier, resolved := range denoLock.Specifiers {
+		if !strings.HasPrefix(specifier, prefix) {
+			continue
+		}
+		if version != "" && version != resolved {
+			return "", false
+		}
+		version = resolved
+	}
+	return version, version != ""
+}
+
+// createModule 根据声明创建模块，声明可以在lockfile中解析到具体版本时，会包含从它出发可以到达的所有包
+func (x *DenoParser) createModule(denoLock *models.DenoLock, specifiers []string) *baseModels.Module[*models.DenoModuleEcosystem, *models.DenoComponentEcosystem, *models.DenoComponentDependencyEcosystem] {
+	module := &baseModels.Module[*models.DenoModuleEcosystem, *models.DenoComponentEcosystem, *models.DenoComponentDependencyEcosystem]{}
+	dependencies := make([]*baseModels.ComponentDependency[*models.DenoComponentDependencyEcosystem], 0)
+
+	// 广度优先遍历，visited的键是 生态系统:键
+	visited := make(map[string]*denoNode)
+	direct := make(map[string]string)
+	queue := make([]*denoNode, 0)
+	visit := func(node *denoNode) string {
+		id := node.Ecosystem + ":" + node.Key
+		if _, ok := visited[id]; !ok {
+			visited[id] = node
+			queue = append(queue, node)
+		}
+		return id
+	}
+
+	for _, specifier := range specifiers {
+		_, ecosystem, name, versionRange, ok := normalizeDenoSpecifier(specifier)
+		if !ok {
+			continue
+		}
+		node, resolved := resolveDenoSpecifier(denoLock, specifier)
+		if !resolved {
+			// 没有lockfile或者lockfile过期时只能使用声明中的范围
+			dependency := &baseModels.ComponentDependency[*models.DenoComponentDependencyEcosystem]{}
+			dependency.DependencyName = name
+			dependency.DependencyVersion = versionRange
+			dependency.ComponentDependencyEcosystem = &models.DenoComponentDependencyEcosystem{
+				Ecosystem: ecosystem,
+				Direct:    true,
+				Specifier: specifier,
+			}
+			dependencies = append(dependencies, dependency)
+			continue
+		}
+		id := visit(node)
+		if _, ok := direct[id]; !ok {
+			direct[id] = specifier
+		}
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node.Ecosystem == models.DenoDependencyEcosystemNpm {
+			pkg := denoLock.Npm[node.Key]
+			for _, name := range sortedStringMapKeys(pkg.Dependencies) {
+				if _, ok := denoLock.Npm[pkg.Dependencies[name]]; ok {
+					visit(&denoNode{Ecosystem: models.DenoDependencyEcosystemNpm, Key: pkg.Dependencies[name]})
+				}
+			}
+			continue
+		}
+		// JSR包的依赖是声明，既可能是JSR包也可能是npm包
+		for _, specifier := range denoLock.Jsr[node.Key].Dependencies {
+			if child, ok := resolveDenoSpecifier(denoLock, specifier); ok {
+				visit(child)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(visited))
+	for id := range visited {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		node := visited[id]
+		name, version := splitPackageSpec(node.Key)
+		ecosystem := &models.DenoComponentDependencyEcosystem{
+			Ecosystem: node.Ecosystem,
+			Key:       node.Key,
+			Resolved:  true,
+		}
+		if node.Ecosystem == models.DenoDependencyEcosystemNpm {
+			ecosystem.Integrity = denoLock.Npm[node.Key].Integrity
+			// 版本中不会有下划线，下划线之后是peer依赖后缀
+			if underscoreIndex := strings.Index(version, "_"); underscoreIndex >= 0 {
+				ecosystem.PeerSuffix = version[underscoreIndex:]
+				version = version[:underscoreIndex]
+			}
+		} else {
+			ecosystem.Integrity = denoLock.Jsr[node.Key].Integrity
+		}
+		if specifier, ok := direct[id]; ok {
+			ecosystem.Direct = true
+			ecosystem.Specifier = specifier
+		}
+
+		dependency := &baseModels.ComponentDependency[*models.DenoComponentDependencyEcosystem]{}
+		dependency.DependencyName = name
+		dependency.DependencyVersion = version
+		dependency.ComponentDependencyEcosystem = ecosystem
+		dependencies = append(dependencies, dependency)
+	}
+
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		if dependencies[i].ComponentDependencyEcosystem.Ecosystem != dependencies[j].ComponentDependencyEcosystem.Ecosystem {
+			return dependencies[i].ComponentDependencyEcosystem.Ecosystem < dependencies[j].ComponentDependencyEcosystem.Ecosystem
+		}
+		return dependencies[i].DependencyName < dependencies[j].DependencyName
+	})
+	module.Dependencies = dependencies
+	return module
+}
+
+func (x *DenoParser) Close(ctx context.Context) error {
+	return nil
+}
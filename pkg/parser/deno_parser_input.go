@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// DenoJsonFileName 和 DenoJsoncFileName deno配置文件的文件名，两个都存在时使用deno.json
+const (
+	DenoJsonFileName  = "deno.json"
+	DenoJsoncFileName = "deno.jsonc"
+)
+
+// DenoLockFileName deno.lock的文件名
+const DenoLockFileName = "deno.lock"
+
+// DenoParserInput 解析器的输入，deno.json和deno.lock分别按照 Content、Reader、Path、ProjectRootDirectory 的优先级读取，
+// 两个文件至少要有一个，只有deno.json时依赖的版本是声明中的范围，只有deno.lock时依赖来自lockfile中记录的workspace声明
+type DenoParserInput struct {
+	// DenoJsonPath deno.json或deno.jsonc文件的路径
+	DenoJsonPath string
+
+	// DenoJsonContent 直接传入的deno.json内容，允许注释和尾随逗号
+	DenoJsonContent string
+
+	// DenoJsonReader 从reader中读取deno.json的内容，只能被读取一次
+	DenoJsonReader io.Reader
+
+	// DenoLockPath deno.lock文件的路径
+	DenoLockPath string
+
+	// DenoLockContent 直接传入的deno.lock内容
+	DenoLockContent string
+
+	// DenoLockReader 从reader中读取deno.lock的内容，只能被读取一次
+	DenoLockReader io.Reader
+
+	// ProjectRootDirectory 项目根目录，会读取其中没有通过其它方式指定的deno.json（或deno.jsonc）和deno.lock
+	ProjectRootDirectory string
+
+	// FileSystem 指定后路径和ProjectRootDirectory都是这个文件系统中的路径
+	FileSystem fs.FS
+}
+
+// ReadDenoJson 读取deno.json的内容，没有指定并且项目根目录中也不存在时返回nil
+func (x *DenoParserInput) ReadDenoJson(ctx context.Context) ([]byte, error) {
+	if x.DenoJsonContent != "" {
+		return []byte(x.DenoJsonContent), nil
+	}
+	if x.DenoJsonReader != nil {
+		return readInputReader(x.DenoJsonReader)
+	}
+	if x.DenoJsonPath != "" {
+		return readInputFile(x.FileSystem, x.DenoJsonPath)
+	}
+	if x.ProjectRootDirectory == "" {
+		return nil, nil
+	}
+	for _, fileName := range []string{DenoJsonFileName, DenoJsoncFileName} {
+		data, err := readInputFile(x.FileSystem, joinInputPath(x.FileSystem, x.ProjectRootDirectory, fileName))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return data, err
+	}
+	return nil, nil
+}
+
+// ReadDenoLock 读取deno.lock的内容，没有指定并且项目根目录中也不存在时返回nil
+func (x *DenoParserInput) ReadDenoLock(ctx context.Context) ([]byte, error) {
+	if x.DenoLockContent != "" {
+		return []byte(x.DenoLockContent), nil
+	}
+	if x.DenoLockReader != nil {
+		return readInputReader(x.DenoLockReader)
+	}
+	if x.DenoLockPath != "" {
+		return readInputFile(x.FileSystem, x.DenoLockPath)
+	}
+	if x.ProjectRootDirectory == "" {
+		return nil, nil
+	}
+	data, err := readInputFile(x.FileSystem, joinInputPath(x.FileSystem, x.ProjectRootDirectory, DenoLockFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// denoDependencyKey 使用 生态系统:名称 作为key，区分同名的jsr和npm依赖
+func denoDependencyKey(dependency *models.JsComponentDependency) string {
+	return dependency.ComponentDependencyEcosystem.Deno.Ecosystem + ":" + dependency.DependencyName
+}
+
+func TestDenoParser_ParseV3WithDenoJson(t *testing.T) {
+	denoJson, err := os.ReadFile("test_data/deno/deno.jsonc")
+	require.NoError(t, err)
+	denoLock, err := os.ReadFile("test_data/deno/v3.lock")
+	require.NoError(t, err)
+	fileSystem := fstest.MapFS{
+		"server/deno.jsonc": &fstest.MapFile{Data: denoJson},
+		"server/deno.lock":  &fstest.MapFile{Data: denoLock},
+	}
+
+	project, err := NewDenoParser().Parse(context.Background(), &DenoParserInput{FileSystem: fileSystem, ProjectRootDirectory: "server"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "@demo/server", project.Name)
+	assert.Equal(t, "0.3.0", project.Version)
+	assert.Equal(t, models.PackageManagerDeno, project.ProjectEcosystem.PackageManager)
+	assert.Equal(t, "3", project.ProjectEcosystem.Deno.LockVersion)
+	assert.Contains(t, project.ProjectEcosystem.Deno.Remote, "https://deno.land/x/oak@v12.6.1/mod.ts")
+	require.Len(t, project.Modules, 1)
+
+	module := project.Modules["@demo/server"]
+	require.NotNil(t, module)
+	assert.Equal(t, map[string]string{
+		"jsr:@std/assert":    "1.0.2",
+		"jsr:@std/path":      "1.0.2",
+		"npm:chalk":          "5.3.0",
+		"npm:debug":          "4.3.4",
+		"npm:ms":             "2.1.2",
+		"npm:preact":         "10.19.3",
+		"npm:supports-color": "9.4.0",
+	}, dependencyVersions(indexDependencies(module, denoDependencyKey)))
+
+	// JSR包排在前面
+	first := module.Dependencies[0]
+	assert.Equal(t, "@std/assert", first.DependencyName)
+	assert.False(t, first.ComponentDependencyEcosystem.Deno.Direct)
+
+	for _, dependency := range module.Dependencies {
+		ecosystem := dependency.ComponentDependencyEcosystem.Deno
+		assert.True(t, ecosystem.Resolved)
+		switch dependency.DependencyName {
+		case "debug":
+			assert.Equal(t, models.DependencyEcosystemNpm, dependency.ComponentDependencyEcosystem.Ecosystem)
+			assert.Equal(t, "_supports-color@9.4.0", ecosystem.PeerSuffix)
+			assert.Equal(t, "sha512-debug-4", ecosystem.Integrity)
+			assert.True(t, ecosystem.Direct)
+		case "preact":
+			assert.Equal(t, "npm:preact@^10.19.0/hooks", ecosystem.Specifier, "带子路径的声明")
+		case "@std/path":
+			assert.Equal(t, models.DenoDependencyEcosystemJsr, ecosystem.Ecosystem)
+			assert.Equal(t, "@std/path@1.0.2", ecosystem.Key)
+			assert.True(t, ecosystem.Direct)
+			// 共用字段中JSR包有单独的生态系统，路径带有 jsr: 前缀
+			assert.Equal(t, models.DependencyEcosystemJsr, dependency.ComponentDependencyEcosystem.Ecosystem)
+			assert.Equal(t, "jsr:@std/path@1.0.2", dependency.ComponentDependencyEcosystem.Path)
+			assert.Equal(t, true, *dependency.ComponentDependencyEcosystem.Direct)
+		}
+	}
+}
+
+func TestDenoParser_ParseV4LockOnly(t *testing.T) {
+	project, err := NewDenoParser().Parse(context.Background(), &DenoParserInput{DenoLockPath: "test_data/deno/v4.lock"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "unknown", project.Name)
+	assert.Equal(t, "4", project.ProjectEcosystem.Deno.LockVersion)
+	require.Len(t, project.Modules, 2)
+
+	// 没有deno.json时使用lockfile记录的声明，JSR包的依赖可以只写包名
+	assert.Equal(t, map[string]string{
+		"jsr:@std/assert":    "1.0.2",
+		"jsr:@std/path":      "1.0.2",
+		"npm:chalk":          "5.3.0",
+		"npm:debug":          "4.3.4",
+		"npm:ms":             "2.1.2",
+		"npm:preact":         "10.19.3",
+		"npm:supports-color": "9.4.0",
+	}, dependencyVersions(indexDependencies(project.Modules["unknown"], denoDependencyKey)))
+
+	member := project.Modules["packages/api"]
+	require.NotNil(t, member)
+	assert.Equal(t, "packages/api", member.ModuleEcosystem.Deno.WorkspacePath)
+	assert.Equal(t, map[string]string{"npm:hono": "4.4.0"}, dependencyVersions(indexDependencies(member, denoDependencyKey)))
+	assert.True(t, member.Dependencies[0].ComponentDependencyEcosystem.Deno.Direct)
+}
+
+func TestDenoParser_ParseDenoJsonOnly(t *testing.T) {
+	project, err := NewDenoParser().Parse(context.Background(), &DenoParserInput{DenoJsonPath: "test_data/deno/deno.jsonc"})
+	require.NoError(t, err)
+
+	module := project.TakeFirstModule()
+	require.NotNil(t, module)
+	assert.Equal(t, map[string]string{
+		"jsr:@std/path": "^1.0.0",
+		"npm:chalk":     "^5.3.0",
+		"npm:debug":     "^4.3.4",
+		"npm:preact":    "^10.19.0",
+	}, dependencyVersions(indexDependencies(module, denoDependencyKey)))
+	for _, dependency := range module.Dependencies {
+		assert.False(t, dependency.ComponentDependencyEcosystem.Deno.Resolved)
+		assert.True(t, dependency.ComponentDependencyEcosystem.Deno.Direct)
+	}
+}
+
+func TestDenoParser_ParseDenoLock(t *testing.T) {
+	v3, err := NewDenoParser().ParseDenoLock(context.Background(), &DenoParserInput{DenoLockPath: "test_data/deno/v3.lock"})
+	require.NoError(t, err)
+	v4, err := NewDenoParser().ParseDenoLock(context.Background(), &DenoParserInput{DenoLockPath: "test_data/deno/v4.lock"})
+	require.NoError(t, err)
+
+	// 两个版本解析后的结构一致
+	for _, denoLock := range []*models.DenoLock{v3, v4} {
+		assert.Equal(t, "4.3.4_supports-color@9.4.0", denoLock.Specifiers["npm:debug@^4.3.4"])
+		assert.Equal(t, "1.0.2", denoLock.Specifiers["jsr:@std/path@^1.0.0"])
+		assert.Equal(t, map[string]string{"ms": "ms@2.1.2", "supports-color": "supports-color@9.4.0"}, denoLock.Npm["debug@4.3.4_supports-color@9.4.0"].Dependencies)
+	}
+}
+
+func TestDenoParser_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *DenoParserInput
+	}{
+		{name: "没有任何文件", input: &DenoParserInput{FileSystem: fstest.MapFS{}, ProjectRootDirectory: "."}},
+		{name: "不支持的lockfile版本", input: &DenoParserInput{DenoLockContent: `{"version": "2", "remote": {}}`}},
+		{name: "lockfile不是JSON", input: &DenoParserInput{DenoLockContent: `{"version": `}},
+		{name: "deno.json不是JSON", input: &DenoParserInput{DenoJsonContent: `{"imports": `}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDenoParser().Parse(context.Background(), tt.input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNormalizeDenoSpecifier(t *testing.T) {
+	tests := []struct {
+		specifier  string
+		normalized string
+		ecosystem  string
+		name       string
+	}{
+		{specifier: "npm:chalk@^5.3.0", normalized: "npm:chalk@^5.3.0", ecosystem: "npm", name: "chalk"},
+		{specifier: "npm:preact@^10.19.0/hooks", normalized: "npm:preact@^10.19.0", ecosystem: "npm", name: "preact"},
+		{specifier: "npm:/@types/node@20", normalized: "npm:@types/node@20", ecosystem: "npm", name: "@types/node"},
+		{specifier: "npm:express/router", normalized: "npm:express", ecosystem: "npm", name: "express"},
+		{specifier: "jsr:@std/path@^1.0.0/posix", normalized: "jsr:@std/path@^1.0.0", ecosystem: "jsr", name: "@std/path"},
+		{specifier: "jsr:@std/assert", normalized: "jsr:@std/assert", ecosystem: "jsr", name: "@std/assert"},
+		{specifier: "https://deno.land/x/oak/mod.ts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.specifier, func(t *testing.T) {
+			normalized, ecosystem, name, _, ok := normalizeDenoSpecifier(tt.specifier)
+			assert.Equal(t, tt.normalized != "", ok)
+			assert.Equal(t, tt.normalized, normalized)
+			assert.Equal(t, tt.ecosystem, ecosystem)
+			assert.Equal(t, tt.name, name)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// finishDependencyGraph 对依赖图中的边和悬空依赖排序，保证输出稳定，
+// 没有指定根节点时把没有被任何节点依赖的节点作为根节点
+func finishDependencyGraph(graph *models.DependencyGraph) {
+	referenced := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		sort.SliceStable(node.Dependencies, func(i, j int) bool {
+			return node.Dependencies[i].Name < node.Dependencies[j].Name
+		})
+		for _, edge := range node.Dependencies {
+			if edge.To != node.ID {
+				referenced[edge.To] = true
+			}
+		}
+	}
+
+	sort.SliceStable(graph.Dangling, func(i, j int) bool {
+		if graph.Dangling[i].From != graph.Dangling[j].From {
+			return graph.Dangling[i].From < graph.Dangling[j].From
+		}
+		return graph.Dangling[i].Name < graph.Dangling[j].Name
+	})
+
+	if graph.Roots != nil {
+		sort.Strings(graph.Roots)
+		return
+	}
+	graph.Roots = make([]string, 0)
+	for _, id := range graph.SortedNodeIDs() {
+		if !referenced[id] {
+			graph.Roots = append(graph.Roots, id)
+		}
+	}
+}
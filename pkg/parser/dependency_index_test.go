@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// indexDependencies 把模块的依赖转换为 key -> 依赖 的映射，同一个key只保留第一个依赖。
+// 各个解析器的测试都通过它查找依赖，key一般是dependencyPathKey或dependencyNameKey
+func indexDependencies(module *models.JsModule, key func(dependency *models.JsComponentDependency) string) map[string]*models.JsComponentDependency {
+	dependencies := make(map[string]*models.JsComponentDependency)
+	for _, dependency := range module.Dependencies {
+		dependencyKey := key(dependency)
+		if _, ok := dependencies[dependencyKey]; !ok {
+			dependencies[dependencyKey] = dependency
+		}
+	}
+	return dependencies
+}
+
+// dependencyPathKey 使用共用字段中的Path作为key，没有路径时（比如yarn v1和链接的依赖）使用 name@version
+func dependencyPathKey(dependency *models.JsComponentDependency) string {
+	if ecosystem := dependency.ComponentDependencyEcosystem; ecosystem != nil && ecosystem.Path != "" {
+		return ecosystem.Path
+	}
+	return dependency.DependencyName + "@" + dependency.DependencyVersion
+}
+
+// dependencyNameKey 使用依赖名称作为key
+func dependencyNameKey(dependency *models.JsComponentDependency) string {
+	return dependency.DependencyName
+}
+
+// dependencyVersions 把 key -> 依赖 的映射转换为 key -> 版本 的映射，方便整体比较
+func dependencyVersions(dependencies map[string]*models.JsComponentDependency) map[string]string {
+	versions := make(map[string]string, len(dependencies))
+	for key, dependency := range dependencies {
+		versions[key] = dependency.DependencyVersion
+	}
+	return versions
+}
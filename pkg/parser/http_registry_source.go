@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// httpRegistryResponseLimit 一个packument最大的字节数，避免异常的响应占满内存
+const httpRegistryResponseLimit = 256 << 20
+
+// HttpRegistrySource 通过HTTP从registry查询packument，用于内网中的registry镜像，比如Verdaccio、Nexus或者Artifactory。
+// 请求完整的文档而不是abbreviated文档，因为只有完整的文档中有发布时间
+type HttpRegistrySource struct {
+	// Registry 指定后所有的包都从这个registry查询，否则按照Config中的 @scope:registry 和 registry 选择
+	Registry string
+
+	// Config .npmrc中的配置，用于选择registry和读取 //host/path/:_authToken 这样的认证信息，可以为空
+	Config *models.NpmConfig
+
+	// Client 发送请求的客户端，为空时使用http.DefaultClient
+	Client *http.Client
+}
+
+var _ RegistryMetadataSource = &HttpRegistrySource{}
+
+func NewHttpRegistrySource(registry string, config *models.NpmConfig) *HttpRegistrySource {
+	return &HttpRegistrySource{
+		Registry: registry,
+		Config:   config,
+	}
+}
+
+// Fetch 请求 <registry>/<包名>，registry返回404时返回nil
+func (x *HttpRegistrySource) Fetch(ctx context.Context, packageName string) (*models.Packument, error) {
+	registry := x.registryFor(packageName)
+	requestURL := registry + escapePackageName(packageName)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if authorization := registryAuthorization(x.Config, registry); authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	client := x.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry %s responded %s", requestURL, response.Status)
+	}
+	data, err := readInputReader(io.LimitReader(response.Body, httpRegistryResponseLimit))
+	if err != nil {
+		return nil, err
+	}
+	return parsePackument(data, requestURL)
+}
+
+// registryFor 查询这个包使用的registry，总是以/结尾
+func (x *HttpRegistrySource) registryFor(packageName string) string {
+	if x.Registry != "" {
+		return withRegistryTrailingSlash(x.Registry)
+	}
+	if x.Config != nil {
+		return x.Config.RegistryFor(packageName)
+	}
+	return models.NpmDefaultRegistry
+}
+
+// registryAuthorization 按照npm的规则查找registry的认证信息：配置的键是去掉协议的registry地址，比如 //npm.example.com/repo/:_authToken，
+// 从完整的路径开始逐级向上匹配。_authToken使用Bearer认证，_auth使用Basic认证，没有配置时返回空
+func registryAuthorization(config *models.NpmConfig, registry string) string {
+	if config == nil {
+		return ""
+	}
+	registryURL, err := url.Parse(registry)
+	if err != nil || registryURL.Host == "" {
+		return ""
+	}
+	registryPath := strings.TrimSuffix(registryURL.Path, "/")
+	for {
+		prefix := "//" + registryURL.Host + registryPath + "/:"
+		if token, ok := config.Get(prefix + "_authToken"); ok && token != "" {
+			return "Bearer " + token
+		}
+		if auth, ok := config.Get(prefix + "_auth"); ok && auth != "" {
+			return "Basic " + auth
+		}
+		if registryPath == "" {
+			return ""
+		}
+		registryPath = registryPath[:strings.LastIndex(registryPath, "/")]
+	}
+}
+
+// withRegistryTrailingSlash registry地址统一以/结尾
+func withRegistryTrailingSlash(registry string) string {
+	if strings.HasSuffix(registry, "/") {
+		return registry
+	}
+	return registry + "/"
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpRegistrySource_Fetch(t *testing.T) {
+	requests := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.EscapedPath()+" "+r.Header.Get("Authorization"))
+		switch r.URL.EscapedPath() {
+		case "/lodash":
+			_, _ = w.Write([]byte(testPackuments["lodash"]))
+		case "/scoped/@acme%2fui":
+			_, _ = w.Write([]byte(testPackuments["@acme/ui"]))
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// 作用域包使用 @acme:registry，认证信息按照registry的路径逐级匹配
+	config := models.NewNpmConfig()
+	for key, value := range map[string]string{
+		"registry":       server.URL,
+		"@acme:registry": server.URL + "/scoped/",
+		"//" + server.Listener.Addr().String() + "/:_authToken":   "default-token",
+		"//" + server.Listener.Addr().String() + "/scoped/:_auth": "c2NvcGVkOnNlY3JldA==",
+	} {
+		config.Values[key] = &models.NpmConfigValue{Key: key, Value: value}
+	}
+	source := NewHttpRegistrySource("", config)
+
+	lodash, err := source.Fetch(context.Background(), "lodash")
+	require.NoError(t, err)
+	require.NotNil(t, lodash)
+	assert.Equal(t, server.URL+"/lodash", lodash.Source)
+	assert.Equal(t, "4.17.21", lodash.Latest().Version)
+
+	ui, err := source.Fetch(context.Background(), "@acme/ui")
+	require.NoError(t, err)
+	require.NotNil(t, ui)
+	assert.True(t, ui.IsDeprecated("1.0.0"))
+
+	missing, err := source.Fetch(context.Background(), "not-exists")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	_, err = source.Fetch(context.Background(), "broken")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"/lodash Bearer default-token",
+		"/scoped/@acme%2fui Basic c2NvcGVkOnNlY3JldA==",
+		"/not-exists Bearer default-token",
+		"/broken Bearer default-token",
+	}, requests)
+
+	// 指定了registry时忽略配置，没有认证信息时不发送Authorization
+	requests = requests[:0]
+	lodash, err = NewHttpRegistrySource(server.URL, nil).Fetch(context.Background(), "lodash")
+	require.NoError(t, err)
+	require.NotNil(t, lodash)
+	assert.Equal(t, []string{"/lodash "}, requests)
+
+	// registry无法访问
+	server.Close()
+	_, err = NewHttpRegistrySource(server.URL, nil).Fetch(context.Background(), "lodash")
+	assert.Error(t, err)
+}
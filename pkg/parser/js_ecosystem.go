@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
+)
+
+// jsEcosystemConverter 把某个包管理器特有的生态系统信息转换为共用的类型，特有的信息放到扩展字段中
+type jsEcosystemConverter[P, M, D any] struct {
+	project    func(P) *models.JsProjectEcosystem
+	module     func(M) *models.JsModuleEcosystem
+	dependency func(D) *models.JsComponentDependencyEcosystem
+}
+
+// toJsProject 把包管理器特有类型的项目转换为共用的类型，模块的键、名称、版本和依赖的顺序保持不变，
+// 同时解析依赖的resolved得到来源
+func toJsProject[P, M, C, D any](source *baseModels.Project[P, M, C, D], converter *jsEcosystemConverter[P, M, D]) *models.JsProject {
+	project := &models.JsProject{}
+	project.Name = source.Name
+	project.Version = source.Version
+	project.ProjectEcosystem = converter.project(source.ProjectEcosystem)
+	for moduleName, sourceModule := range source.Modules {
+		module := &models.JsModule{}
+		module.Name = sourceModule.Name
+		module.Version = sourceModule.Version
+		module.ModuleEcosystem = converter.module(sourceModule.ModuleEcosystem)
+		module.Dependencies = make([]*models.JsComponentDependency, 0, len(sourceModule.Dependencies))
+		for _, sourceDependency := range sourceModule.Dependencies {
+			dependency := &models.JsComponentDependency{}
+			dependency.DependencyName = sourceDependency.DependencyName
+			dependency.DependencyVersion = sourceDependency.DependencyVersion
+			dependency.ComponentDependencyEcosystem = converter.dependency(sourceDependency.ComponentDependencyEcosystem)
+			dependency.ComponentDependencyEcosystem.ResolvedSource = parseResolvedSource(dependency.ComponentDependencyEcosystem.Resolved)
+			module.Dependencies = append(module.Dependencies, dependency)
+		}
+		project.SetModule(moduleName, module)
+	}
+	return project
+}
+
+// isNpmDependency 依赖是否属于npm生态系统，没有设置Ecosystem的依赖（比如手动构造的项目）按npm处理
+func isNpmDependency(dependency *models.JsComponentDependency) bool {
+	ecosystem := dependency.ComponentDependencyEcosystem
+	return ecosystem == nil || ecosystem.Ecosystem == "" || ecosystem.Ecosystem == models.DependencyEcosystemNpm
+}
+
+// trueOrNil 把bool转换为lockfile中的标记，false时跟lockfile一样不出现
+func trueOrNil(value bool) *bool {
+	if !value {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,68 @@
+package parser
+
+// stripJsonc 把JSONC（带注释和尾随逗号的JSON，bun.lock和deno.jsonc使用这种格式）转换为标准的JSON，
+// 注释替换为空白以保持行列号不变，字符串中的内容原样保留
+func stripJsonc(data []byte) []byte {
+	result := make([]byte, 0, len(data))
+	// pendingComma 是还没有确定是否要保留的逗号在result中的位置
+	pendingComma := -1
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			pendingComma = -1
+			start := i
+			for i++; i < len(data); i++ {
+				if data[i] == '\\' {
+					i++
+					continue
+				}
+				if data[i] == '"' {
+					break
+				}
+			}
+			if i >= len(data) {
+				i = len(data) - 1
+			}
+			result = append(result, data[start:i+1]...)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for ; i < len(data) && data[i] != '\n'; i++ {
+				result = append(result, ' ')
+			}
+			if i < len(data) {
+				result = append(result, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			result = append(result, ' ', ' ')
+			for i += 2; i < len(data); i++ {
+				if data[i] == '*' && i+1 < len(data) && data[i+1] == '/' {
+					result = append(result, ' ', ' ')
+					i++
+					break
+				}
+				if data[i] == '\n' {
+					result = append(result, '\n')
+				} else {
+					result = append(result, ' ')
+				}
+			}
+		case c == ',':
+			pendingComma = len(result)
+			result = append(result, c)
+		case c == '}' || c == ']':
+			// 尾随逗号：逗号之后只有空白和注释就遇到了结束符
+			if pendingComma >= 0 {
+				result[pendingComma] = ' '
+				pendingComma = -1
+			}
+			result = append(result, c)
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			result = append(result, c)
+		default:
+			pendingComma = -1
+			result = append(result, c)
+		}
+	}
+	return result
+}
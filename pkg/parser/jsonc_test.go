@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripJsonc(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "尾随逗号", input: `{"a": [1, 2,], "b": {"c": 1,},}`, want: `{"a":[1,2],"b":{"c":1}}`},
+		{name: "行注释", input: "{\n  // comment, with comma\n  \"a\": 1, // trailing\n}", want: `{"a":1}`},
+		{name: "块注释", input: `{"a": /* x, */ 1, /* y */ }`, want: `{"a":1}`},
+		{name: "字符串中的注释和逗号", input: `{"a": "// not a comment, /* nor this */", "b": ",}"}`, want: `{"a":"// not a comment, /* nor this */","b":",}"}`},
+		{name: "字符串中的转义引号", input: `{"a": "say \"hi\",", }`, want: `{"a":"say \"hi\","}`},
+		{name: "标准JSON", input: `{"a": [1, {"b": null}]}`, want: `{"a":[1,{"b":null}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got interface{}
+			require.NoError(t, json.Unmarshal(stripJsonc([]byte(tt.input)), &got))
+			compact, err := json.Marshal(got)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(compact))
+		})
+	}
+
+	// 注释被替换为空白，行号不变
+	assert.Equal(t, "{\n          \n\"a\": 1 }", string(stripJsonc([]byte("{\n// comment\n\"a\": 1,}"))))
+}
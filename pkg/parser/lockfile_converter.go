@@ -0,0 +1,515 @@
+package parser
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// lockfileConverterMaxDepth 转换为package-lock.json时嵌套安装的最大层数，防止异常的依赖关系导致无限嵌套
+const lockfileConverterMaxDepth = 64
+
+// LockfileConverter 在package-lock.json和yarn v1格式的yarn.lock之间转换，保留锁定的版本、resolved和integrity，
+// 无法在目标格式中准确表示的内容会作为问题返回，而不是静默丢弃
+type LockfileConverter struct {
+}
+
+func NewLockfileConverter() *LockfileConverter {
+	return &LockfileConverter{}
+}
+
+// packageLockEdge 转换为package-lock.json时记录的依赖边，用于计算dev、optional标记
+type packageLockEdge struct {
+	to       string
+	edgeType models.DependencyGraphEdgeType
+}
+
+// PackageLockToYarnLock 把lockfileVersion 1、2、3的package-lock.json转换为yarn v1格式的yarn.lock。
+// yarn.lock的键是 name@range 描述符，每个包都会以依赖它的包声明的范围作为描述符；
+// 项目自己的依赖优先使用packageJson中声明的范围，packageJson为nil时使用lockfile中根包的声明
+func (x *LockfileConverter) PackageLockToYarnLock(packageLock *models.PackageLock, packageJson *models.PackageJson) (*models.YarnLock, []*models.LockfileConversionIssue, error) {
+	if packageLock == nil {
+		return nil, nil, fmt.Errorf("package-lock.json is nil")
+	}
+
+	packages := packageLock.Packages
+	if len(packages) == 0 {
+		packages = flattenPackageLockDependencies(packageLock.Dependencies)
+	}
+	if len(packages) == 0 {
+		return nil, nil, fmt.Errorf("no packages found in package-lock.json")
+	}
+
+	yarnLock := &models.YarnLock{Dependencies: make(map[string]*models.YarnLockDependency)}
+	issues := make([]*models.LockfileConversionIssue, 0)
+
+	// 同一个包（包名、版本、地址都相同）安装在多个位置时共享同一个条目，写出时会合并描述符
+	entries := make(map[string]*models.YarnLockDependency)
+	descriptorTargets := make(map[string]string)
+	reached := make(map[string]bool)
+
+	addDescriptor := func(fromPath string, name string, versionRange string) {
+		target, ok := resolvePackageLockPath(packages, fromPath, name)
+		if !ok || !isPackageLockInstallPath(target) {
+			// 没有安装的依赖和workspace本身不会出现在yarn.lock中
+			return
+		}
+		pkg := packages[target]
+		if pkg.InBundle != nil && *pkg.InBundle {
+			// 打包在父包中的依赖由父包的tarball提供，yarn.lock不记录
+			return
+		}
+		reached[target] = true
+
+		descriptor := name + "@" + versionRange
+		if existing, ok := descriptorTargets[descriptor]; ok {
+			if existing != target && packageLockEntryKey(packages, existing) != packageLockEntryKey(packages, target) {
+				issues = append(issues, &models.LockfileConversionIssue{
+					Kind:     models.LockfileConversionIssueConflict,
+					Location: target,
+					Name:     name,
+					Version:  pkg.Version,
+					Message:  fmt.Sprintf("descriptor %s resolves to %s at %s and %s at %s, yarn.lock can only lock the first one", descriptor, packages[existing].Version, existing, pkg.Version, target),
+				})
+			}
+			return
+		}
+		descriptorTargets[descriptor] = target
+
+		key := packageLockEntryKey(packages, target)
+		entry, ok := entries[key]
+		if !ok {
+			entry = x.createYarnLockEntry(pkg)
+			entries[key] = entry
+		}
+		yarnLock.Dependencies[descriptor] = entry
+	}
+
+	// 项目自己的依赖
+	rootDependencies := x.rootDependencies(packages, packageJson)
+	for _, dependencies := range rootDependencies {
+		for _, name := range sortedDependencyNames(dependencies) {
+			addDescriptor("", name, dependencies[name])
+		}
+	}
+
+	// 按安装深度从浅到深处理，提升到顶层的包优先占用描述符
+	pkgPaths := make([]string, 0, len(packages))
+	for pkgPath, pkg := range packages {
+		if pkgPath == "" || pkg == nil || (pkg.Link != nil && *pkg.Link) {
+			continue
+		}
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.SliceStable(pkgPaths, func(i, j int) bool {
+		iDepth, jDepth := strings.Count(pkgPaths[i], "node_modules/"), strings.Count(pkgPaths[j], "node_modules/")
+		if iDepth != jDepth {
+			return iDepth < jDepth
+		}
+		return pkgPaths[i] < pkgPaths[j]
+	})
+	for _, pkgPath := range pkgPaths {
+		pkg := packages[pkgPath]
+		declared := []models.Dependencies{pkg.Dependencies, pkg.OptionalDependencies}
+		if !isPackageLockInstallPath(pkgPath) {
+			// workspace会安装它的开发依赖
+			declared = append(declared, pkg.DevDependencies)
+		}
+		for _, dependencies := range declared {
+			for _, name := range sortedDependencyNames(dependencies) {
+				addDescriptor(pkgPath, name, dependencies[name])
+			}
+		}
+	}
+
+	// lockfileVersion 1没有记录项目自己的依赖，没有package.json时顶层的包只能使用锁定的版本作为范围
+	if len(rootDependencies) == 0 {
+		for _, pkgPath := range pkgPaths {
+			if reached[pkgPath] || strings.Count(pkgPath, "node_modules/") != 1 || !isPackageLockInstallPath(pkgPath) {
+				continue
+			}
+			pkg := packages[pkgPath]
+			name := extractPackageNameFromPath(pkgPath)
+			versionRange := pkg.Version
+			if pkg.Name != "" && pkg.Name != name {
+				versionRange = NpmAliasPrefix + pkg.Name + "@" + pkg.Version
+			}
+			addDescriptor("", name, versionRange)
+			issues = append(issues, &models.LockfileConversionIssue{
+				Kind:     models.LockfileConversionIssueInferredRange,
+				Location: pkgPath,
+				Name:     name,
+				Version:  pkg.Version,
+				Message:  fmt.Sprintf("no declared range for %s, using the locked version %s", name, pkg.Version),
+			})
+		}
+	}
+
+	// 检查没有被转换的包和嵌套安装的重复包
+	versionsByName := make(map[string]map[string]bool)
+	for _, pkgPath := range pkgPaths {
+		if isPackageLockInstallPath(pkgPath) {
+			name := packageLockPackageName(packages, pkgPath)
+			if versionsByName[name] == nil {
+				versionsByName[name] = make(map[string]bool)
+			}
+			versionsByName[name][packages[pkgPath].Version] = true
+		}
+	}
+	for _, pkgPath := range pkgPaths {
+		pkg := packages[pkgPath]
+		if !isPackageLockInstallPath(pkgPath) || (pkg.InBundle != nil && *pkg.InBundle) {
+			continue
+		}
+		name := packageLockPackageName(packages, pkgPath)
+		switch {
+		case !reached[pkgPath]:
+			issues = append(issues, &models.LockfileConversionIssue{
+				Kind:     models.LockfileConversionIssueUnreachable,
+				Location: pkgPath,
+				Name:     name,
+				Version:  pkg.Version,
+				Message:  fmt.Sprintf("%s@%s is not required by any package and is dropped", name, pkg.Version),
+			})
+		case strings.Count(pkgPath, "node_modules/") > 1 && len(versionsByName[name]) > 1:
+			issues = append(issues, &models.LockfileConversionIssue{
+				Kind:     models.LockfileConversionIssuePlacement,
+				Location: pkgPath,
+				Name:     name,
+				Version:  pkg.Version,
+				Message:  fmt.Sprintf("%s@%s is a nested duplicate, yarn.lock does not record where it is installed", name, pkg.Version),
+			})
+		}
+	}
+
+	return yarnLock, issues, nil
+}
+
+// rootDependencies 项目自己声明的依赖，peer依赖不会被安装，不需要转换
+func (x *LockfileConverter) rootDependencies(packages map[string]*models.PackageLockPackage, packageJson *models.PackageJson) []models.Dependencies {
+	if packageJson != nil {
+		return nonEmptyDependencies(packageJson.Dependencies, packageJson.DevDependencies, packageJson.OptionalDependencies)
+	}
+	if root, ok := packages[""]; ok && root != nil {
+		return nonEmptyDependencies(root.Dependencies, root.DevDependencies, root.OptionalDependencies)
+	}
+	return nil
+}
+
+// createYarnLockEntry 把package-lock.json中的包转换为yarn.lock中的条目
+func (x *LockfileConverter) createYarnLockEntry(pkg *models.PackageLockPackage) *models.YarnLockDependency {
+	entry := &models.YarnLockDependency{
+		Version:              pkg.Version,
+		Resolved:             pkg.Resolved,
+		Integrity:            pkg.Integrity,
+		Dependencies:         copyDependencies(pkg.Dependencies),
+		OptionalDependencies: copyDependencies(pkg.OptionalDependencies),
+		PeerDependencies:     make(map[string]string),
+	}
+	// yarn在resolved地址后面带上tarball的sha1，integrity中有sha1时可以还原出来
+	if sha1 := integritySha1Hex(pkg.Integrity); sha1 != "" {
+		entry.ResolvedHash = sha1
+	}
+	return entry
+}
+
+// YarnLockToPackageLock 把yarn v1格式的yarn.lock转换为lockfileVersion 3的package-lock.json，
+// 按照npm的提升规则放置包：能放到顶层时放到顶层，跟顶层的版本冲突时嵌套在依赖它的包下面。
+// packageJson提供项目自己的依赖，为nil时把没有被其它条目依赖的条目当做项目的依赖
+func (x *LockfileConverter) YarnLockToPackageLock(yarnLock *models.YarnLock, packageJson *models.PackageJson) (*models.PackageLock, []*models.LockfileConversionIssue, error) {
+	if yarnLock == nil {
+		return nil, nil, fmt.Errorf("yarn.lock is nil")
+	}
+	if yarnLock.Metadata != nil {
+		return nil, nil, fmt.Errorf("yarn berry lockfiles can not be converted, only yarn lockfile v1 is supported")
+	}
+
+	issues := make([]*models.LockfileConversionIssue, 0)
+	requires := true
+	packageLock := &models.PackageLock{
+		LockFileVersion: 3,
+		Requires:        &requires,
+		Packages:        make(map[string]*models.PackageLockPackage),
+	}
+
+	root := &models.PackageLockPackage{}
+	if packageJson != nil {
+		packageLock.Name = packageJson.Name
+		packageLock.Version = packageJson.Version
+		root.Name = packageJson.Name
+		root.Version = packageJson.Version
+		root.Dependencies = copyDependencies(packageJson.Dependencies)
+		root.DevDependencies = copyDependencies(packageJson.DevDependencies)
+		root.OptionalDependencies = copyDependencies(packageJson.OptionalDependencies)
+		root.PeerDependencies = copyDependencies(packageJson.PeerDependencies)
+	} else {
+		root.Dependencies = x.inferRootDependencies(yarnLock, &issues)
+	}
+	packages := packageLock.Packages
+	packages[""] = root
+
+	entryAt := make(map[string]*models.YarnLockDependency)
+	edges := make(map[string][]*packageLockEdge)
+	queue := []string{""}
+
+	place := func(fromPath string, name string, versionRange string, edgeType models.DependencyGraphEdgeType) {
+		descriptor, entry := resolveYarnBerryDescriptor(yarnLock, name, versionRange)
+		if entry == nil {
+			issues = append(issues, &models.LockfileConversionIssue{
+				Kind:     models.LockfileConversionIssueMissing,
+				Location: name + "@" + versionRange,
+				Name:     name,
+				Message:  fmt.Sprintf("%s@%s required by %q is not locked in yarn.lock", name, versionRange, fromPath),
+			})
+			return
+		}
+
+		// 已经能解析到同一个条目时不需要再放置
+		current, found := resolvePackageLockPath(packages, fromPath, name)
+		if found && entryAt[current] == entry {
+			edges[fromPath] = append(edges[fromPath], &packageLockEdge{to: current, edgeType: edgeType})
+			return
+		}
+
+		// 没有任何位置能解析到这个名称时放到顶层，否则嵌套在依赖它的包下面
+		target := path.Join("node_modules", name)
+		if found {
+			target = path.Join(fromPath, "node_modules", name)
+		}
+		if _, taken := packages[target]; taken || strings.Count(target, "node_modules/") > lockfileConverterMaxDepth {
+			issues = append(issues, &models.LockfileConversionIssue{
+				Kind:     models.LockfileConversionIssueConflict,
+				Location: descriptor,
+				Name:     name,
+				Version:  entry.Version,
+				Message:  fmt.Sprintf("can not place %s@%s at %s", name, entry.Version, target),
+			})
+			return
+		}
+
+		pkg := &models.PackageLockPackage{
+			Version:              entry.Version,
+			Resolved:             entry.Resolved,
+			Integrity:            entry.Integrity,
+			Dependencies:         copyDependencies(entry.Dependencies),
+			OptionalDependencies: copyDependencies(entry.OptionalDependencies),
+			PeerDependencies:     copyDependencies(entry.PeerDependencies),
+		}
+		// 通过别名安装时记录真实的包名
+		if realName, _ := x.realYarnLockName(descriptor); realName != name {
+			pkg.Name = realName
+		}
+		if pkg.Integrity == "" {
+			pkg.Integrity = sha1HexIntegrity(entry.ResolvedHash)
+		}
+		packages[target] = pkg
+		entryAt[target] = entry
+		edges[fromPath] = append(edges[fromPath], &packageLockEdge{to: target, edgeType: edgeType})
+		queue = append(queue, target)
+	}
+
+	// 广度优先放置，浅层的依赖优先占用顶层的位置
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		pkg := packages[current]
+		declared := []struct {
+			dependencies models.Dependencies
+			edgeType     models.DependencyGraphEdgeType
+		}{
+			{dependencies: pkg.Dependencies, edgeType: models.DependencyGraphEdgeProd},
+			{dependencies: pkg.OptionalDependencies, edgeType: models.DependencyGraphEdgeOptional},
+		}
+		if current == "" {
+			declared = append(declared, struct {
+				dependencies models.Dependencies
+				edgeType     models.DependencyGraphEdgeType
+			}{dependencies: pkg.DevDependencies, edgeType: models.DependencyGraphEdgeDev})
+		}
+		for _, group := range declared {
+			for _, name := range sortedDependencyNames(group.dependencies) {
+				place(current, name, group.dependencies[name], group.edgeType)
+			}
+		}
+	}
+
+	x.markPackageLockFlags(packages, edges)
+	return packageLock, issues, nil
+}
+
+// inferRootDependencies 没有package.json时，把没有被其它条目依赖的条目当做项目的依赖，使用它的第一个描述符
+func (x *LockfileConverter) inferRootDependencies(yarnLock *models.YarnLock, issues *[]*models.LockfileConversionIssue) models.Dependencies {
+	referenced := make(map[*models.YarnLockDependency]bool)
+	for _, entry := range yarnLock.Dependencies {
+		for _, dependencies := range []map[string]string{entry.Dependencies, entry.OptionalDependencies} {
+			for name, versionRange := range dependencies {
+				if _, child := resolveYarnBerryDescriptor(yarnLock, name, versionRange); child != nil && child != entry {
+					referenced[child] = true
+				}
+			}
+		}
+	}
+
+	dependencies := make(models.Dependencies)
+	seen := make(map[*models.YarnLockDependency]bool)
+	for _, descriptor := range sortedYarnLockDescriptors(yarnLock) {
+		entry := yarnLock.Dependencies[descriptor]
+		if referenced[entry] || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		name, versionRange := splitPackageSpec(descriptor)
+		if _, ok := dependencies[name]; ok {
+			continue
+		}
+		dependencies[name] = versionRange
+		*issues = append(*issues, &models.LockfileConversionIssue{
+			Kind:     models.LockfileConversionIssueInferredRange,
+			Location: descriptor,
+			Name:     name,
+			Version:  entry.Version,
+			Message:  fmt.Sprintf("no package.json, treating %s as a dependency of the project", descriptor),
+		})
+	}
+	return dependencies
+}
+
+// markPackageLockFlags 根据从项目出发的可达性计算npm的dev、optional、devOptional标记：
+// 只能通过开发依赖到达的是dev，只能通过可选依赖到达的是optional，
+// 两者都不是但是只能通过开发依赖或者可选依赖到达的是devOptional
+func (x *LockfileConverter) markPackageLockFlags(packages map[string]*models.PackageLockPackage, edges map[string][]*packageLockEdge) {
+	reach := func(follow func(from string, edge *packageLockEdge) bool) map[string]bool {
+		visited := map[string]bool{"": true}
+		queue := []string{""}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, edge := range edges[current] {
+				if visited[edge.to] || !follow(current, edge) {
+					continue
+				}
+				visited[edge.to] = true
+				queue = append(queue, edge.to)
+			}
+		}
+		return visited
+	}
+
+	nonDev := reach(func(from string, edge *packageLockEdge) bool {
+		return from != "" || edge.edgeType != models.DependencyGraphEdgeDev
+	})
+	nonOptional := reach(func(from string, edge *packageLockEdge) bool {
+		return edge.edgeType != models.DependencyGraphEdgeOptional
+	})
+	required := reach(func(from string, edge *packageLockEdge) bool {
+		return edge.edgeType != models.DependencyGraphEdgeOptional && (from != "" || edge.edgeType != models.DependencyGraphEdgeDev)
+	})
+
+	flag := true
+	for pkgPath, pkg := range packages {
+		if pkgPath == "" {
+			continue
+		}
+		dev, optional := !nonDev[pkgPath], !nonOptional[pkgPath]
+		if dev {
+			pkg.Dev = &flag
+		}
+		if optional {
+			pkg.Optional = &flag
+		}
+		if !dev && !optional && !required[pkgPath] {
+			pkg.DevOptional = &flag
+		}
+	}
+}
+
+// realYarnLockName 从描述符中解析出真实的包名，别名描述符返回被别名的包名
+func (x *LockfileConverter) realYarnLockName(descriptor string) (string, string) {
+	name, versionRange := splitPackageSpec(descriptor)
+	if realName, realRange, ok := parseNpmAlias(versionRange); ok {
+		return realName, realRange
+	}
+	return name, versionRange
+}
+
+// isPackageLockInstallPath 安装路径在node_modules中，workspace的目录不在node_modules中
+func isPackageLockInstallPath(pkgPath string) bool {
+	return strings.HasPrefix(pkgPath, "node_modules/") || strings.Contains(pkgPath, "/node_modules/")
+}
+
+// packageLockPackageName 安装路径上的包的真实名称
+func packageLockPackageName(packages map[string]*models.PackageLockPackage, pkgPath string) string {
+	if pkg := packages[pkgPath]; pkg != nil && pkg.Name != "" {
+		return pkg.Name
+	}
+	return extractPackageNameFromPath(pkgPath)
+}
+
+// packageLockEntryKey 判断两个安装位置是否是同一个包
+func packageLockEntryKey(packages map[string]*models.PackageLockPackage, pkgPath string) string {
+	pkg := packages[pkgPath]
+	return packageLockPackageName(packages, pkgPath) + "@" + pkg.Version + "#" + pkg.Resolved
+}
+
+// integritySha1Hex 从integrity中取出sha1并转换为yarn在resolved中使用的十六进制形式，没有sha1时返回空字符串
+func integritySha1Hex(integrity string) string {
+	for _, hash := range strings.Fields(integrity) {
+		if !strings.HasPrefix(hash, "sha1-") {
+			continue
+		}
+		digest, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(hash, "sha1-"))
+		if err != nil || len(digest) != 20 {
+			return ""
+		}
+		return hex.EncodeToString(digest)
+	}
+	return ""
+}
+
+// sha1HexIntegrity 把yarn resolved中的十六进制sha1转换为integrity，不是sha1时返回空字符串
+func sha1HexIntegrity(sha1Hex string) string {
+	digest, err := hex.DecodeString(sha1Hex)
+	if err != nil || len(digest) != 20 {
+		return ""
+	}
+	return "sha1-" + base64.StdEncoding.EncodeToString(digest)
+}
+
+// sortedYarnLockDescriptors 返回排好序的描述符
+func sortedYarnLockDescriptors(yarnLock *models.YarnLock) []string {
+	descriptors := make([]string, 0, len(yarnLock.Dependencies))
+	for descriptor := range yarnLock.Dependencies {
+		descriptors = append(descriptors, descriptor)
+	}
+	sort.Strings(descriptors)
+	return descriptors
+}
+
+// nonEmptyDependencies 过滤掉空的依赖声明
+func nonEmptyDependencies(groups ...models.Dependencies) []models.Dependencies {
+	result := make([]models.Dependencies, 0, len(groups))
+	for _, dependencies := range groups {
+		if len(dependencies) > 0 {
+			result = append(result, dependencies)
+		}
+	}
+	return result
+}
+
+// copyDependencies 复制依赖声明，避免转换结果跟源lockfile共享同一个map
+func copyDependencies(dependencies map[string]string) models.Dependencies {
+	if len(dependencies) == 0 {
+		return nil
+	}
+	result := make(models.Dependencies, len(dependencies))
+	for name, versionRange := range dependencies {
+		result[name] = versionRange
+	}
+	return result
+}
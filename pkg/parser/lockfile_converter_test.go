@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const converterPackageLockV3 = `{
+  "name": "convert-app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "requires": true,
+  "packages": {
+    "": {
+      "name": "convert-app",
+      "version": "1.0.0",
+      "workspaces": ["packages/*"],
+      "dependencies": {"a": "^1.0.0", "b": "^2.0.0", "my-c": "npm:c@^1.0.0"},
+      "devDependencies": {"d": "^1.0.0"}
+    },
+    "node_modules/a": {
+      "version": "1.0.0",
+      "resolved": "https://registry.npmjs.org/a/-/a-1.0.0.tgz",
+      "integrity": "sha512-aaa sha1-mrTz6EJa81qB5vPYD0+y12X79Ic=",
+      "dependencies": {"b": "^1.0.0"}
+    },
+    "node_modules/a/node_modules/b": {
+      "version": "1.5.0",
+      "resolved": "https://registry.npmjs.org/b/-/b-1.5.0.tgz",
+      "integrity": "sha512-b15"
+    },
+    "node_modules/b": {
+      "version": "2.0.0",
+      "resolved": "https://registry.npmjs.org/b/-/b-2.0.0.tgz",
+      "integrity": "sha512-b20"
+    },
+    "node_modules/my-c": {
+      "name": "c",
+      "version": "1.2.0",
+      "resolved": "https://registry.npmjs.org/c/-/c-1.2.0.tgz",
+      "integrity": "sha512-c12"
+    },
+    "node_modules/d": {
+      "version": "1.0.0",
+      "resolved": "https://registry.npmjs.org/d/-/d-1.0.0.tgz",
+      "integrity": "sha512-d10",
+      "dev": true,
+      "dependencies": {"b": "^1.0.0"}
+    },
+    "node_modules/d/node_modules/b": {
+      "version": "1.4.0",
+      "resolved": "https://registry.npmjs.org/b/-/b-1.4.0.tgz",
+      "integrity": "sha512-b14",
+      "dev": true
+    },
+    "node_modules/orphan": {
+      "version": "0.1.0",
+      "extraneous": true
+    },
+    "node_modules/ws": {"resolved": "packages/ws", "link": true},
+    "packages/ws": {
+      "version": "0.0.1",
+      "dependencies": {"a": "^1.0.0"}
+    }
+  }
+}`
+
+func TestLockfileConverter_PackageLockToYarnLock(t *testing.T) {
+	packageLock, err := NewPackageLockParser().ParseLockfile(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: converterPackageLockV3})
+	require.NoError(t, err)
+
+	yarnLock, issues, err := NewLockfileConverter().PackageLockToYarnLock(packageLock, nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"a@^1.0.0", "b@^1.0.0", "b@^2.0.0", "my-c@npm:c@^1.0.0", "d@^1.0.0"}, sortedYarnLockDescriptors(yarnLock))
+
+	a := yarnLock.Dependencies["a@^1.0.0"]
+	assert.Equal(t, "1.0.0", a.Version)
+	assert.Equal(t, "https://registry.npmjs.org/a/-/a-1.0.0.tgz", a.Resolved)
+	assert.Equal(t, "9ab4f3e8425af35a81e6f3d80f4fb2d765fbf487", a.ResolvedHash, "从integrity中的sha1还原")
+	assert.Equal(t, map[string]string{"b": "^1.0.0"}, a.Dependencies)
+
+	// 使用最浅的安装位置
+	assert.Equal(t, "1.5.0", yarnLock.Dependencies["b@^1.0.0"].Version)
+	assert.Equal(t, "2.0.0", yarnLock.Dependencies["b@^2.0.0"].Version)
+	assert.Equal(t, "1.2.0", yarnLock.Dependencies["my-c@npm:c@^1.0.0"].Version)
+
+	reported := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		reported = append(reported, string(issue.Kind)+" "+issue.Location)
+	}
+	assert.ElementsMatch(t, []string{
+		// d依赖的b@^1.0.0嵌套安装了另一个版本，yarn.lock中同一个描述符只能锁定一个版本
+		"conflict node_modules/d/node_modules/b",
+		// yarn.lock不记录嵌套安装的位置
+		"placement node_modules/a/node_modules/b",
+		"placement node_modules/d/node_modules/b",
+		"unreachable node_modules/orphan",
+	}, reported)
+
+	// 转换结果可以被写为yarn.lock并重新解析
+	content, err := NewYarnLockWriter().Marshal(yarnLock)
+	require.NoError(t, err)
+	reparsed, err := NewYarnLockParser().ParseLockfile(context.Background(), &YarnLockParserInput{YarnLockContent: string(content)})
+	require.NoError(t, err)
+	assert.Equal(t, "9ab4f3e8425af35a81e6f3d80f4fb2d765fbf487", reparsed.Dependencies["a@^1.0.0"].ResolvedHash)
+}
+
+func TestLockfileConverter_PackageLockV1ToYarnLock(t *testing.T) {
+	content := `{
+  "name": "v1-app",
+  "lockfileVersion": 1,
+  "dependencies": {
+    "a": {
+      "version": "1.0.0",
+      "resolved": "https://registry.npmjs.org/a/-/a-1.0.0.tgz",
+      "requires": {"b": "^1.0.0"}
+    },
+    "b": {"version": "1.1.0"}
+  }
+}`
+	packageLock, err := NewPackageLockParser().ParseLockfile(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: content})
+	require.NoError(t, err)
+
+	// 有package.json时使用其中声明的范围
+	yarnLock, issues, err := NewLockfileConverter().PackageLockToYarnLock(packageLock, &models.PackageJson{
+		Name:         "v1-app",
+		Dependencies: models.Dependencies{"a": "^1.0.0"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	assert.ElementsMatch(t, []string{"a@^1.0.0", "b@^1.0.0"}, sortedYarnLockDescriptors(yarnLock))
+
+	// 没有package.json时只能使用锁定的版本
+	yarnLock, issues, err = NewLockfileConverter().PackageLockToYarnLock(packageLock, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a@1.0.0", "b@^1.0.0"}, sortedYarnLockDescriptors(yarnLock))
+	require.Len(t, issues, 1)
+	assert.Equal(t, models.LockfileConversionIssueInferredRange, issues[0].Kind)
+}
+
+func TestLockfileConverter_YarnLockToPackageLock(t *testing.T) {
+	content := `# yarn lockfile v1
+
+
+a@^1.0.0:
+  version "1.0.0"
+  resolved "https://registry.yarnpkg.com/a/-/a-1.0.0.tgz#9ab4f3e8425af35a81e6f3d80f4fb2d765fbf487"
+  dependencies:
+    b "^1.0.0"
+  optionalDependencies:
+    fsevents "^2.0.0"
+
+b@^1.0.0:
+  version "1.5.0"
+  integrity sha512-b15
+
+b@^2.0.0:
+  version "2.0.0"
+  integrity sha512-b20
+
+d@^1.0.0:
+  version "1.0.0"
+  dependencies:
+    b "^2.0.0"
+
+fsevents@^2.0.0:
+  version "2.3.3"
+
+"my-c@npm:c@^1.0.0":
+  version "1.2.0"
+`
+	yarnLock, err := NewYarnLockParser().ParseLockfile(context.Background(), &YarnLockParserInput{YarnLockContent: content})
+	require.NoError(t, err)
+
+	packageJson := &models.PackageJson{
+		Name:            "convert-app",
+		Version:         "1.0.0",
+		Dependencies:    models.Dependencies{"a": "^1.0.0", "b": "^2.0.0", "my-c": "npm:c@^1.0.0"},
+		DevDependencies: models.Dependencies{"d": "^1.0.0", "missing": "^1.0.0"},
+	}
+	packageLock, issues, err := NewLockfileConverter().YarnLockToPackageLock(yarnLock, packageJson)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint(3), packageLock.LockFileVersion)
+	assert.Equal(t, "convert-app", packageLock.Name)
+	packages := packageLock.Packages
+	assert.Equal(t, packageJson.Dependencies, packages[""].Dependencies)
+
+	// b@2.0.0是项目的直接依赖，占用了顶层，a依赖的b@1.5.0嵌套在a下面
+	require.Contains(t, packages, "node_modules/b")
+	assert.Equal(t, "2.0.0", packages["node_modules/b"].Version)
+	require.Contains(t, packages, "node_modules/a/node_modules/b")
+	assert.Equal(t, "1.5.0", packages["node_modules/a/node_modules/b"].Version)
+	assert.NotContains(t, packages, "node_modules/d/node_modules/b", "d可以使用顶层的b@2.0.0")
+
+	a := packages["node_modules/a"]
+	assert.Equal(t, "https://registry.yarnpkg.com/a/-/a-1.0.0.tgz", a.Resolved)
+	assert.Equal(t, "sha1-mrTz6EJa81qB5vPYD0+y12X79Ic=", a.Integrity, "从resolved中的sha1还原")
+	assert.Equal(t, "c", packages["node_modules/my-c"].Name)
+
+	// dev和optional标记
+	assert.Nil(t, a.Dev)
+	assert.True(t, *packages["node_modules/d"].Dev)
+	assert.True(t, *packages["node_modules/fsevents"].Optional)
+	assert.Nil(t, packages["node_modules/fsevents"].Dev)
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, models.LockfileConversionIssueMissing, issues[0].Kind)
+	assert.Equal(t, "missing", issues[0].Name)
+
+	// 转换回yarn.lock时描述符保持一致
+	converted, _, err := NewLockfileConverter().PackageLockToYarnLock(packageLock, packageJson)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, sortedYarnLockDescriptors(yarnLock), sortedYarnLockDescriptors(converted))
+	for descriptor, entry := range yarnLock.Dependencies {
+		assert.Equal(t, entry.Version, converted.Dependencies[descriptor].Version, descriptor)
+		assert.Equal(t, entry.Resolved, converted.Dependencies[descriptor].Resolved, descriptor)
+	}
+
+	// Berry格式不支持
+	_, _, err = NewLockfileConverter().YarnLockToPackageLock(&models.YarnLock{Metadata: &models.YarnLockMetadata{}}, nil)
+	assert.Error(t, err)
+}
+
+func TestLockfileConverter_YarnLockToPackageLockWithoutPackageJson(t *testing.T) {
+	content := `a@^1.0.0:
+  version "1.0.0"
+  dependencies:
+    b "^1.0.0"
+
+b@^1.0.0:
+  version "1.5.0"
+`
+	yarnLock, err := NewYarnLockParser().ParseLockfile(context.Background(), &YarnLockParserInput{YarnLockContent: content})
+	require.NoError(t, err)
+
+	packageLock, issues, err := NewLockfileConverter().YarnLockToPackageLock(yarnLock, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.Dependencies{"a": "^1.0.0"}, packageLock.Packages[""].Dependencies)
+	assert.Contains(t, packageLock.Packages, "node_modules/b")
+	require.Len(t, issues, 1)
+	assert.Equal(t, models.LockfileConversionIssueInferredRange, issues[0].Kind)
+}
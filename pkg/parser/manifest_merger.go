@@ -0,0 +1,274 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/scagogogo/sca-base-module-ecosystem-parser/pkg/parser"
+)
+
+// ManifestMerger 合并package.json和lockfile的解析结果：lockfile中的依赖是扁平的，
+// 无法区分哪些是项目直接声明的，合并后每个依赖都会标记是直接依赖还是传递依赖、直接依赖声明的版本范围，
+// 以及根据从package.json各个依赖字段出发的可达性得出的prod、dev、optional、peer范围
+type ManifestMerger struct {
+}
+
+var _ parser.Parser[*ManifestMergerInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &ManifestMerger{}
+
+func NewManifestMerger() *ManifestMerger {
+	return &ManifestMerger{}
+}
+
+const ManifestMergerName = "manifest-merger"
+
+// manifestScopeRanks 范围的优先级，数字越小越优先，同时能从多个依赖字段到达时使用优先级最高的
+var manifestScopeRanks = map[models.DependencyGraphEdgeType]int{
+	models.DependencyGraphEdgeProd:     0,
+	models.DependencyGraphEdgeOptional: 1,
+	models.DependencyGraphEdgePeer:     2,
+	models.DependencyGraphEdgeDev:      3,
+}
+
+// manifestDeclaration package.json中的一个依赖声明
+type manifestDeclaration struct {
+	Name  string
+	Range string
+	Scope models.DependencyGraphEdgeType
+}
+
+func (x *ManifestMerger) GetName() string {
+	return ManifestMergerName
+}
+
+func (x *ManifestMerger) Init(ctx context.Context) error {
+	return nil
+}
+
+// Parse 读取项目根目录中的package.json和lockfile并合并，跟ProjectParser.Parse的结果一样，
+// 只是要求项目中必须有package.json和可以解析的lockfile。package-lock.json、npm-shrinkwrap.json和yarn.lock可以构建出依赖图，
+// 用来计算可达性；pnpm-lock.yaml、bun.lock和deno.lock没有依赖图，只按照名称标记直接依赖，不计算范围
+func (x *ManifestMerger) Parse(ctx context.Context, input *ManifestMergerInput) (*models.JsProject, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	projectParser := NewProjectParser()
+	detection, manifest, err := projectParser.detect(ctx, input.projectInput())
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s not found", PackageJsonFileName)
+	}
+	if detection.Lockfile == "" {
+		return nil, fmt.Errorf("no lockfile found")
+	}
+	return projectParser.Parse(ctx, input.projectInput())
+}
+
+// Merge 使用package.json中声明的依赖标记lockfile解析结果中根模块的依赖，直接修改并返回lockfile。
+// graph是同一个lockfile构建的依赖图，依赖通过安装路径、resolution或者 name@version 对应到图中的节点，
+// 对应不到节点的依赖（比如lockfile中多余的包）不做标记。graph为nil时只按照名称标记直接依赖，见mergeWithoutGraph
+func (x *ManifestMerger) Merge(manifest *models.PackageJson, lockfile *models.JsProject, graph *models.DependencyGraph) (*models.JsProject, error) {
+	if manifest == nil || lockfile == nil {
+		return nil, fmt.Errorf("manifest and lockfile cannot be nil")
+	}
+	module := lockfile.Modules[manifest.Name]
+	if module == nil && len(lockfile.Modules) == 1 {
+		module = lockfile.TakeFirstModule()
+	}
+	if module == nil {
+		return nil, fmt.Errorf("root module %s not found in lockfile", manifest.Name)
+	}
+	if graph == nil {
+		x.mergeWithoutGraph(manifest, module)
+		return lockfile, nil
+	}
+
+	// 每个声明解析到的节点，先处理优先级高的字段，同一个节点只记录第一个声明
+	declared := make(map[string]*manifestDeclaration)
+	scopes := make(map[string]models.DependencyGraphEdgeType)
+	queue := make([]string, 0)
+	root := x.findRoot(graph)
+	for _, declaration := range x.declarations(manifest) {
+		id, ok := x.resolveDeclaration(graph, root, declaration.Name)
+		if !ok {
+			continue
+		}
+		if _, ok := declared[id]; !ok {
+			declared[id] = declaration
+		}
+		if x.improveScope(scopes, id, declaration.Scope) {
+			queue = append(queue, id)
+		}
+	}
+
+	// 沿着依赖图传播范围：可选依赖之后的包最多是optional，包自己的开发依赖不会被安装
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		node := graph.Nodes[current]
+		if node == nil {
+			continue
+		}
+		for _, edge := range node.Dependencies {
+			scope := scopes[current]
+			switch {
+			case edge.Type == models.DependencyGraphEdgeDev:
+				continue
+			case edge.Type == models.DependencyGraphEdgeOptional && scope == models.DependencyGraphEdgeProd:
+				scope = models.DependencyGraphEdgeOptional
+			}
+			if x.improveScope(scopes, edge.To, scope) {
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+
+	for _, dependency := range module.Dependencies {
+		ecosystem := dependency.ComponentDependencyEcosystem
+		if ecosystem == nil {
+			ecosystem = &models.JsComponentDependencyEcosystem{Ecosystem: models.DependencyEcosystemNpm}
+			dependency.ComponentDependencyEcosystem = ecosystem
+		}
+		id, ok := x.nodeID(graph, dependency)
+		if !ok {
+			continue
+		}
+		declaration, direct := declared[id]
+		ecosystem.Direct = &direct
+		ecosystem.Scope = scopes[id]
+		if direct && ecosystem.Specifier == "" {
+			ecosystem.Specifier = declaration.Range
+		}
+	}
+	return lockfile, nil
+}
+
+// mergeWithoutGraph 没有依赖图时的合并：lockfile解析器已经标记了Direct的依赖保持不变，
+// 没有标记的依赖按照名称（通过别名安装时是别名）判断是否是package.json中声明的，
+// 直接依赖补充声明的版本范围。无法计算可达性，所以不设置Scope
+func (x *ManifestMerger) mergeWithoutGraph(manifest *models.PackageJson, module *models.JsModule) {
+	declared := make(map[string]*manifestDeclaration)
+	for _, declaration := range x.declarations(manifest) {
+		if _, ok := declared[declaration.Name]; !ok {
+			declared[declaration.Name] = declaration
+		}
+	}
+
+	for _, dependency := range module.Dependencies {
+		ecosystem := dependency.ComponentDependencyEcosystem
+		if ecosystem == nil {
+			ecosystem = &models.JsComponentDependencyEcosystem{Ecosystem: models.DependencyEcosystemNpm}
+			dependency.ComponentDependencyEcosystem = ecosystem
+		}
+		name := dependency.DependencyName
+		if ecosystem.Alias != "" {
+			name = ecosystem.Alias
+		}
+		declaration, ok := declared[name]
+		if ecosystem.Direct == nil {
+			direct := ok
+			ecosystem.Direct = &direct
+		}
+		if ok && *ecosystem.Direct && ecosystem.Specifier == "" {
+			ecosystem.Specifier = declaration.Range
+		}
+	}
+}
+
+// declarations 按照范围的优先级返回package.json中所有的依赖声明
+func (x *ManifestMerger) declarations(manifest *models.PackageJson) []*manifestDeclaration {
+	sections := []struct {
+		dependencies models.Dependencies
+		scope        models.DependencyGraphEdgeType
+	}{
+		{dependencies: manifest.Dependencies, scope: models.DependencyGraphEdgeProd},
+		{dependencies: manifest.OptionalDependencies, scope: models.DependencyGraphEdgeOptional},
+		{dependencies: manifest.PeerDependencies, scope: models.DependencyGraphEdgePeer},
+		{dependencies: manifest.DevDependencies, scope: models.DependencyGraphEdgeDev},
+	}
+	declarations := make([]*manifestDeclaration, 0)
+	for _, section := range sections {
+		for _, name := range sortedDependencyNames(section.dependencies) {
+			declarations = append(declarations, &manifestDeclaration{Name: name, Range: section.dependencies[name], Scope: section.scope})
+		}
+	}
+	return declarations
+}
+
+// findRoot 找到依赖图中代表项目本身的节点：package-lock.json和yarn v1中ID为空字符串，
+// yarn Berry中是路径为 . 的workspace，找不到时返回nil
+func (x *ManifestMerger) findRoot(graph *models.DependencyGraph) *models.DependencyGraphNode {
+	if root, ok := graph.Nodes[""]; ok {
+		return root
+	}
+	for _, id := range graph.Roots {
+		if strings.HasSuffix(id, "@workspace:.") {
+			return graph.Nodes[id]
+		}
+	}
+	return nil
+}
+
+// resolveDeclaration 把package.json中声明的依赖名称解析到依赖图中的节点：优先使用根节点的边，
+// lockfileVersion 1没有根节点时使用顶层的安装路径，都找不到时使用图中唯一的同名包
+func (x *ManifestMerger) resolveDeclaration(graph *models.DependencyGraph, root *models.DependencyGraphNode, name string) (string, bool) {
+	if root != nil {
+		for _, edge := range root.Dependencies {
+			if edge.Name == name {
+				return edge.To, true
+			}
+		}
+	}
+	if _, ok := graph.Nodes["node_modules/"+name]; ok {
+		return "node_modules/" + name, true
+	}
+
+	found := ""
+	for _, id := range graph.SortedNodeIDs() {
+		node := graph.Nodes[id]
+		if node.Name != name || (root != nil && id == root.ID) {
+			continue
+		}
+		if found != "" {
+			return "", false
+		}
+		found = id
+	}
+	return found, found != ""
+}
+
+// nodeID 找到依赖在依赖图中对应的节点，package-lock.json中的链接对应它指向的目录
+func (x *ManifestMerger) nodeID(graph *models.DependencyGraph, dependency *models.JsComponentDependency) (string, bool) {
+	ecosystem := dependency.ComponentDependencyEcosystem
+	candidates := []string{ecosystem.Path}
+	if link := ecosystem.Link; link != nil && *link {
+		candidates = append(candidates, ecosystem.Resolved)
+	}
+	candidates = append(candidates, dependency.DependencyName+"@"+dependency.DependencyVersion)
+	for _, id := range candidates {
+		if id == "" {
+			continue
+		}
+		if _, ok := graph.Nodes[id]; ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// improveScope 节点还没有范围或者新的范围优先级更高时更新，返回是否更新了
+func (x *ManifestMerger) improveScope(scopes map[string]models.DependencyGraphEdgeType, id string, scope models.DependencyGraphEdgeType) bool {
+	current, ok := scopes[id]
+	if ok && manifestScopeRanks[current] <= manifestScopeRanks[scope] {
+		return false
+	}
+	scopes[id] = scope
+	return true
+}
+
+func (x *ManifestMerger) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,17 @@
+package parser
+
+import "io/fs"
+
+// ManifestMergerInput 合并package.json和lockfile时的输入，只需要指定项目根目录
+type ManifestMergerInput struct {
+	// ProjectRootDirectory 项目根目录，其中需要有package.json和一个lockfile
+	ProjectRootDirectory string
+
+	// FileSystem 指定后ProjectRootDirectory是这个文件系统中的路径
+	FileSystem fs.FS
+}
+
+// projectInput 转换为ProjectParser的输入，用来识别使用的lockfile
+func (x *ManifestMergerInput) projectInput() *ProjectParserInput {
+	return &ProjectParserInput{ProjectRootDirectory: x.ProjectRootDirectory, FileSystem: x.FileSystem}
+}
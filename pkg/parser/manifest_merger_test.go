@@ -0,0 +1,268 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifestMergerTestPackageJson = `{
+  "name": "app",
+  "version": "1.0.0",
+  "dependencies": {"express": "^4.18.0", "my-lodash": "npm:lodash@^4.17.0"},
+  "optionalDependencies": {"fsevents": "^2.3.0"},
+  "peerDependencies": {"react": "^18.0.0"},
+  "devDependencies": {"jest": "^29.0.0", "debug": "^4.0.0"}
+}`
+
+func TestManifestMerger_ParsePackageLock(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"app/package.json": manifestMergerTestPackageJson,
+		"app/package-lock.json": `{
+  "name": "app", "version": "1.0.0", "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0",
+      "dependencies": {"express": "^4.18.0", "my-lodash": "npm:lodash@^4.17.0"},
+      "optionalDependencies": {"fsevents": "^2.3.0"},
+      "peerDependencies": {"react": "^18.0.0"},
+      "devDependencies": {"jest": "^29.0.0", "debug": "^4.0.0"}},
+    "node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9", "bufferutil": "^4.0.0"}, "peerDependencies": {"ws": "^8.0.0"}},
+    "node_modules/express/node_modules/debug": {"version": "2.6.9", "dependencies": {"ms": "2.0.0"}},
+    "node_modules/bufferutil": {"version": "4.0.8", "optional": true},
+    "node_modules/ws": {"version": "8.16.0"},
+    "node_modules/ms": {"version": "2.0.0"},
+    "node_modules/my-lodash": {"name": "lodash", "version": "4.17.21"},
+    "node_modules/fsevents": {"version": "2.3.3", "optional": true},
+    "node_modules/react": {"version": "18.2.0", "peer": true, "dependencies": {"loose-envify": "^1.1.0"}},
+    "node_modules/loose-envify": {"version": "1.4.0", "peer": true},
+    "node_modules/jest": {"version": "29.7.0", "dev": true, "dependencies": {"ms": "2.0.0"}},
+    "node_modules/debug": {"version": "4.3.4", "dev": true},
+    "node_modules/left-pad": {"version": "1.3.0", "extraneous": true}
+  }
+}`,
+	})
+
+	project, err := NewManifestMerger().Parse(context.Background(), &ManifestMergerInput{ProjectRootDirectory: "app", FileSystem: fileSystem})
+	require.NoError(t, err)
+	dependencies := indexDependencies(project.TakeFirstModule(), dependencyPathKey)
+
+	tests := []struct {
+		path      string
+		direct    bool
+		specifier string
+		scope     models.DependencyGraphEdgeType
+	}{
+		{path: "node_modules/express", direct: true, specifier: "^4.18.0", scope: models.DependencyGraphEdgeProd},
+		{path: "node_modules/my-lodash", direct: true, specifier: "npm:lodash@^4.17.0", scope: models.DependencyGraphEdgeProd},
+		{path: "node_modules/express/node_modules/debug", scope: models.DependencyGraphEdgeProd},
+		{path: "node_modules/ws", scope: models.DependencyGraphEdgeProd},
+		{path: "node_modules/bufferutil", scope: models.DependencyGraphEdgeOptional},
+		{path: "node_modules/fsevents", direct: true, specifier: "^2.3.0", scope: models.DependencyGraphEdgeOptional},
+		{path: "node_modules/react", direct: true, specifier: "^18.0.0", scope: models.DependencyGraphEdgePeer},
+		{path: "node_modules/loose-envify", scope: models.DependencyGraphEdgePeer},
+		{path: "node_modules/jest", direct: true, specifier: "^29.0.0", scope: models.DependencyGraphEdgeDev},
+		{path: "node_modules/debug", direct: true, specifier: "^4.0.0", scope: models.DependencyGraphEdgeDev},
+		// 同时能从生产依赖和开发依赖到达
+		{path: "node_modules/ms", scope: models.DependencyGraphEdgeProd},
+		// lockfile中多余的包无法到达
+		{path: "node_modules/left-pad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			dependency := dependencies[tt.path]
+			require.NotNil(t, dependency)
+			ecosystem := dependency.ComponentDependencyEcosystem
+			require.NotNil(t, ecosystem.Direct)
+			assert.Equal(t, tt.direct, *ecosystem.Direct)
+			assert.Equal(t, tt.specifier, ecosystem.Specifier)
+			assert.Equal(t, tt.scope, ecosystem.Scope)
+		})
+	}
+	assert.Equal(t, "lodash", dependencies["node_modules/my-lodash"].DependencyName)
+	assert.Equal(t, "4.17.21", dependencies["node_modules/my-lodash"].DependencyVersion, "声明的范围和解析到的版本都保留")
+}
+
+func TestManifestMerger_ParseYarnLock(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"package.json": `{"name": "app", "dependencies": {"chokidar": "^3.5.3"}, "optionalDependencies": {"fsevents": "~2.3.2"}, "devDependencies": {"typescript": "^5.0.0"}}`,
+		"yarn.lock": `# yarn lockfile v1
+
+
+chokidar@^3.5.3:
+  version "3.5.3"
+  dependencies:
+    picomatch "^2.0.4"
+  optionalDependencies:
+    fsevents "~2.3.2"
+
+fsevents@~2.3.2:
+  version "2.3.3"
+
+picomatch@^2.0.4:
+  version "2.3.1"
+
+typescript@^5.0.0:
+  version "5.3.3"
+`,
+	})
+
+	project, err := NewManifestMerger().Parse(context.Background(), &ManifestMergerInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+	require.NoError(t, err)
+	dependencies := indexDependencies(project.TakeFirstModule(), dependencyPathKey)
+	require.Len(t, dependencies, 4)
+
+	chokidar := dependencies["chokidar@3.5.3"].ComponentDependencyEcosystem
+	assert.True(t, *chokidar.Direct)
+	assert.Equal(t, "^3.5.3", chokidar.Specifier)
+	assert.Equal(t, models.DependencyGraphEdgeProd, chokidar.Scope)
+
+	picomatch := dependencies["picomatch@2.3.1"].ComponentDependencyEcosystem
+	assert.False(t, *picomatch.Direct)
+	assert.Empty(t, picomatch.Specifier)
+	assert.Equal(t, models.DependencyGraphEdgeProd, picomatch.Scope)
+
+	// yarn v1的根节点只有dependencies和devDependencies，可选依赖通过唯一的同名包解析
+	fsevents := dependencies["fsevents@2.3.3"].ComponentDependencyEcosystem
+	assert.True(t, *fsevents.Direct)
+	assert.Equal(t, "~2.3.2", fsevents.Specifier)
+	assert.Equal(t, models.DependencyGraphEdgeOptional, fsevents.Scope)
+
+	typescript := dependencies["typescript@5.3.3"].ComponentDependencyEcosystem
+	assert.True(t, *typescript.Direct)
+	assert.Equal(t, models.DependencyGraphEdgeDev, typescript.Scope)
+}
+
+func TestManifestMerger_ParseWithoutGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockfile string
+		content  string
+	}{
+		{
+			name:     "pnpm",
+			lockfile: "pnpm-lock.yaml",
+			content: `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react:
+        specifier: ^18.2.0
+        version: 18.3.1
+    devDependencies:
+      typescript:
+        specifier: ^5.4.0
+        version: 5.4.5
+
+packages:
+
+  js-tokens@4.0.0:
+    resolution: {integrity: sha512-js-tokens-4}
+
+  react@18.3.1:
+    resolution: {integrity: sha512-react-18}
+
+  typescript@5.4.5:
+    resolution: {integrity: sha512-typescript-5}
+
+snapshots:
+
+  js-tokens@4.0.0: {}
+
+  react@18.3.1:
+    dependencies:
+      js-tokens: 4.0.0
+
+  typescript@5.4.5: {}
+`,
+		},
+		{
+			name:     "bun",
+			lockfile: "bun.lock",
+			content: `{
+  "lockfileVersion": 1,
+  "workspaces": {
+    "": {
+      "name": "app",
+      "dependencies": {"react": "^18.2.0"},
+      "devDependencies": {"typescript": "^5.4.0"},
+    },
+  },
+  "packages": {
+    "js-tokens": ["js-tokens@4.0.0", "", {}, "sha512-js-tokens-4"],
+    "react": ["react@18.3.1", "", {"dependencies": {"js-tokens": "^4.0.0"}}, "sha512-react-18"],
+    "typescript": ["typescript@5.4.5", "", {}, "sha512-typescript-5"],
+  }
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileSystem := newWorkspaceTestFileSystem(map[string]string{
+				"package.json": `{"name": "app", "dependencies": {"react": "^18.2.0"}, "devDependencies": {"typescript": "^5.4.0"}}`,
+				tt.lockfile:    tt.content,
+			})
+			project, err := NewManifestMerger().Parse(context.Background(), &ManifestMergerInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+			require.NoError(t, err)
+
+			dependencies := indexDependencies(project.Modules["app"], dependencyNameKey)
+			require.Len(t, dependencies, 3)
+			assert.Equal(t, "18.3.1", dependencies["react"].DependencyVersion)
+			assert.True(t, *dependencies["react"].ComponentDependencyEcosystem.Direct)
+			assert.Equal(t, "^18.2.0", dependencies["react"].ComponentDependencyEcosystem.Specifier)
+			assert.Equal(t, "5.4.5", dependencies["typescript"].DependencyVersion)
+			assert.True(t, *dependencies["typescript"].ComponentDependencyEcosystem.Direct)
+			assert.Equal(t, "4.0.0", dependencies["js-tokens"].DependencyVersion)
+			assert.False(t, *dependencies["js-tokens"].ComponentDependencyEcosystem.Direct)
+			for _, dependency := range dependencies {
+				assert.Empty(t, dependency.ComponentDependencyEcosystem.Scope, "没有依赖图时不计算范围")
+			}
+		})
+	}
+
+	// 没有依赖图时按照名称标记，通过别名安装的依赖使用别名
+	transitive := false
+	lockfile := &models.JsProject{}
+	lockfile.SetModule("app", &models.JsModule{Dependencies: []*models.JsComponentDependency{
+		{DependencyName: "lodash", DependencyVersion: "4.17.21", ComponentDependencyEcosystem: &models.JsComponentDependencyEcosystem{Alias: "my-lodash"}},
+		{DependencyName: "debug", DependencyVersion: "4.3.4"},
+		{DependencyName: "ms", DependencyVersion: "2.1.2", ComponentDependencyEcosystem: &models.JsComponentDependencyEcosystem{Direct: &transitive}},
+	}})
+	manifest := &models.PackageJson{Name: "app", Dependencies: models.Dependencies{"my-lodash": "npm:lodash@^4.17.0", "ms": "^2.1.0"}, DevDependencies: models.Dependencies{"debug": "^4.0.0"}}
+	merged, err := NewManifestMerger().Merge(manifest, lockfile, nil)
+	require.NoError(t, err)
+	dependencies := merged.TakeFirstModule().Dependencies
+	assert.True(t, *dependencies[0].ComponentDependencyEcosystem.Direct)
+	assert.Equal(t, "npm:lodash@^4.17.0", dependencies[0].ComponentDependencyEcosystem.Specifier)
+	assert.True(t, *dependencies[1].ComponentDependencyEcosystem.Direct)
+	assert.Equal(t, "^4.0.0", dependencies[1].ComponentDependencyEcosystem.Specifier)
+	assert.False(t, *dependencies[2].ComponentDependencyEcosystem.Direct, "lockfile解析器的标记保持不变")
+	assert.Empty(t, dependencies[2].ComponentDependencyEcosystem.Specifier)
+}
+
+func TestManifestMerger_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *ManifestMergerInput
+	}{
+		{name: "输入为nil"},
+		{name: "没有package.json", input: &ManifestMergerInput{ProjectRootDirectory: ".", FileSystem: newWorkspaceTestFileSystem(map[string]string{"package-lock.json": `{}`})}},
+		{name: "没有lockfile", input: &ManifestMergerInput{ProjectRootDirectory: ".", FileSystem: newWorkspaceTestFileSystem(map[string]string{"package.json": `{"name": "app"}`})}},
+		{name: "lockfile格式错误", input: &ManifestMergerInput{ProjectRootDirectory: ".", FileSystem: newWorkspaceTestFileSystem(map[string]string{"package.json": `{"name": "app"}`, "bun.lock": `{"lockfileVersion": 2}`})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewManifestMerger().Parse(context.Background(), tt.input)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := NewManifestMerger().Merge(&models.PackageJson{Name: "app"}, nil, models.NewDependencyGraph())
+	assert.Error(t, err)
+}
@@ -0,0 +1,365 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/scagogogo/sca-base-module-ecosystem-parser/pkg/parser"
+)
+
+// NodeModulesParser 扫描项目中实际安装的依赖：遍历node_modules（包括嵌套的node_modules、scope目录、.bin、
+// 链接到workspace的符号链接和pnpm的.pnpm虚拟仓库），读取每个包的package.json。
+// 解析出的项目跟lockfile解析器使用同样的模型，每个实际安装的包是一个依赖，Path是安装路径
+type NodeModulesParser struct {
+}
+
+var _ parser.Parser[*NodeModulesParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &NodeModulesParser{}
+
+func NewNodeModulesParser() *NodeModulesParser {
+	return &NodeModulesParser{}
+}
+
+const NodeModulesParserName = "node-modules-parser"
+
+// nodeModulesMaxDepth node_modules最多嵌套的层数，fs.FS无法识别符号链接，依靠这个限制避免符号链接循环导致无限遍历
+const nodeModulesMaxDepth = 32
+
+// pnpmVirtualStoreDirectoryName pnpm虚拟仓库的目录名
+const pnpmVirtualStoreDirectoryName = ".pnpm"
+
+// nodeModulesBinDirectoryName 安装命令的目录名
+const nodeModulesBinDirectoryName = ".bin"
+
+// installedPackageJson 已安装的包的package.json中需要的字段，
+// 不使用PackageJson是因为已安装的包的author、bin等字段有多种写法，只关心这些字段时不应该因为其它字段解析失败
+type installedPackageJson struct {
+	Name      string          `json:"name"`
+	Version   string          `json:"version"`
+	Bin       json.RawMessage `json:"bin"`
+	Resolved  string          `json:"_resolved"`
+	Integrity string          `json:"_integrity"`
+}
+
+func (x *NodeModulesParser) GetName() string {
+	return NodeModulesParserName
+}
+
+func (x *NodeModulesParser) Init(ctx context.Context) error {
+	return nil
+}
+
+// Parse 扫描项目根目录下的node_modules，项目的名称和版本来自根目录的package.json，没有时为unknown；
+// 每个实际安装的包是唯一模块的一个依赖，同一个目录通过多个路径访问到时只记录一次，完整的扫描结果在ProjectEcosystem.NodeModules中
+func (x *NodeModulesParser) Parse(ctx context.Context, input *NodeModulesParserInput) (*models.JsProject, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	fileSystem, err := input.fileSystem()
+	if err != nil {
+		return nil, err
+	}
+	inventory, err := x.scan(ctx, fileSystem)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &models.JsProject{}
+	project.Name = "unknown"
+	data, err := fileSystem.readFile(PackageJsonFileName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		root := &installedPackageJson{}
+		if err := json.Unmarshal(data, root); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", PackageJsonFileName, err)
+		}
+		if root.Name != "" {
+			project.Name = root.Name
+		}
+		project.Version = root.Version
+	}
+
+	module := &models.JsModule{}
+	module.Name = project.Name
+	module.Version = project.Version
+	module.ModuleEcosystem = &models.JsModuleEcosystem{}
+	module.Dependencies = make([]*models.JsComponentDependency, 0, len(inventory.Packages))
+	for _, installed := range inventory.Packages {
+		dependency := &models.JsComponentDependency{}
+		dependency.DependencyName = installed.Name
+		dependency.DependencyVersion = installed.Version
+		dependency.ComponentDependencyEcosystem = &models.JsComponentDependencyEcosystem{
+			Ecosystem:      models.DependencyEcosystemNpm,
+			Resolved:       installed.Resolved,
+			ResolvedSource: parseResolvedSource(installed.Resolved),
+			Integrity:      installed.Integrity,
+			Path:           installed.Path,
+			Alias:          installed.Alias,
+			Link:           trueOrNil(installed.Link),
+		}
+		module.Dependencies = append(module.Dependencies, dependency)
+	}
+	project.SetModule(project.Name, module)
+
+	project.ProjectEcosystem = &models.JsProjectEcosystem{NodeModules: inventory}
+	return project, nil
+}
+
+// ParseInventory 扫描node_modules，返回实际安装的包和命令，不转换为项目
+func (x *NodeModulesParser) ParseInventory(ctx context.Context, input *NodeModulesParserInput) (*models.NodeModulesInventory, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	fileSystem, err := input.fileSystem()
+	if err != nil {
+		return nil, err
+	}
+	return x.scan(ctx, fileSystem)
+}
+
+// scan 扫描项目根目录下的node_modules
+func (x *NodeModulesParser) scan(ctx context.Context, fileSystem nodeModulesFileSystem) (*models.NodeModulesInventory, error) {
+	if _, err := fileSystem.readDir(NodeModulesDirectoryName); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", NodeModulesDirectoryName, err)
+	}
+
+	scanner := &nodeModulesScanner{
+		fileSystem: fileSystem,
+		packages:   make(map[string]*models.InstalledPackage),
+		bins:       make(map[string]*models.InstalledBin),
+		scanned:    make(map[string]bool),
+	}
+	if err := scanner.scan(ctx); err != nil {
+		return nil, err
+	}
+
+	inventory := &models.NodeModulesInventory{
+		Packages: make([]*models.InstalledPackage, 0, len(scanner.packages)),
+		Bins:     make([]*models.InstalledBin, 0, len(scanner.bins)),
+	}
+	for _, installed := range scanner.packages {
+		inventory.Packages = append(inventory.Packages, installed)
+	}
+	sort.Slice(inventory.Packages, func(i, j int) bool {
+		return inventory.Packages[i].Path < inventory.Packages[j].Path
+	})
+	for _, bin := range scanner.bins {
+		inventory.Bins = append(inventory.Bins, bin)
+	}
+	sort.Slice(inventory.Bins, func(i, j int) bool {
+		return inventory.Bins[i].Path < inventory.Bins[j].Path
+	})
+	return inventory, nil
+}
+
+// nodeModulesScanner 扫描过程中的状态
+type nodeModulesScanner struct {
+	fileSystem nodeModulesFileSystem
+
+	// 已经找到的包，键是包实际所在的目录，同一个目录只读取和向下遍历一次，这也保证了符号链接循环时遍历会结束
+	packages map[string]*models.InstalledPackage
+
+	// 找到的命令，键是命令的路径
+	bins map[string]*models.InstalledBin
+
+	// 已经扫描过的node_modules目录实际所在的路径，通过符号链接再次访问到时不重复扫描
+	scanned map[string]bool
+
+	// 等待扫描的node_modules目录
+	queue []*nodeModulesDirectory
+}
+
+// nodeModulesDirectory 等待扫描的node_modules目录和它嵌套的层数
+type nodeModulesDirectory struct {
+	path  string
+	depth int
+}
+
+// scan 从项目根目录的node_modules开始按层扫描，先扫描的安装路径更短，所以包的Path总是最短的安装路径
+func (x *nodeModulesScanner) scan(ctx context.Context) error {
+	x.queue = append(x.queue, &nodeModulesDirectory{path: NodeModulesDirectoryName})
+	for len(x.queue) > 0 {
+		directory := x.queue[0]
+		x.queue = x.queue[1:]
+		if err := x.scanNodeModules(ctx, directory.path, directory.depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanNodeModules 扫描一个node_modules目录，包下面的node_modules和.pnpm虚拟仓库中的node_modules加入到队列中等待扫描
+func (x *nodeModulesScanner) scanNodeModules(ctx context.Context, directory string, depth int) error {
+	if depth >= nodeModulesMaxDepth {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	realPath, err := x.fileSystem.realPath(directory)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", directory, err)
+	}
+	if x.scanned[realPath] {
+		return nil
+	}
+	x.scanned[realPath] = true
+	entries, err := x.fileSystem.readDir(directory)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", directory, err)
+	}
+
+	hasVirtualStore := false
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case name == nodeModulesBinDirectoryName:
+			x.scanBins(path.Join(directory, name))
+		case name == pnpmVirtualStoreDirectoryName:
+			hasVirtualStore = true
+		case strings.HasPrefix(name, "."):
+			// .package-lock.json、.modules.yaml、.cache等不是包
+		case strings.HasPrefix(name, "@"):
+			scopeEntries, err := x.fileSystem.readDir(path.Join(directory, name))
+			if err != nil {
+				continue
+			}
+			for _, scopeEntry := range scopeEntries {
+				if err := x.scanPackage(ctx, path.Join(directory, name, scopeEntry.Name()), name+"/"+scopeEntry.Name(), scopeEntry, depth); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := x.scanPackage(ctx, path.Join(directory, name), name, entry, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if hasVirtualStore {
+		x.scanVirtualStore(path.Join(directory, pnpmVirtualStoreDirectoryName), depth)
+	}
+	return nil
+}
+
+// scanVirtualStore 扫描pnpm的虚拟仓库，每个 .pnpm/<name>@<version>/node_modules 中是这个包本身和指向它的依赖的符号链接，
+// .pnpm/node_modules 是提升的依赖
+func (x *nodeModulesScanner) scanVirtualStore(directory string, depth int) {
+	entries, err := x.fileSystem.readDir(directory)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == NodeModulesDirectoryName {
+			continue
+		}
+		x.queue = append(x.queue, &nodeModulesDirectory{path: path.Join(directory, entry.Name(), NodeModulesDirectoryName), depth: depth + 1})
+	}
+	x.queue = append(x.queue, &nodeModulesDirectory{path: path.Join(directory, NodeModulesDirectoryName), depth: depth + 1})
+}
+
+// scanPackage 读取安装路径上的包，它下面的node_modules稍后扫描，没有package.json的目录不是包，被忽略
+func (x *nodeModulesScanner) scanPackage(ctx context.Context, installPath string, directoryName string, entry fs.DirEntry, depth int) error {
+	link := entry.Type()&fs.ModeSymlink != 0
+	if !entry.IsDir() && !link {
+		return nil
+	}
+	// 无法解析的符号链接指向的目录已经不存在了
+	realPath, err := x.fileSystem.realPath(installPath)
+	if err != nil {
+		return nil
+	}
+	if installed, ok := x.packages[realPath]; ok {
+		installed.InstallPaths = append(installed.InstallPaths, installPath)
+		return nil
+	}
+
+	data, err := x.fileSystem.readFile(path.Join(installPath, PackageJsonFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path.Join(installPath, PackageJsonFileName), err)
+	}
+	packageJson := &installedPackageJson{}
+	if err := json.Unmarshal(data, packageJson); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path.Join(installPath, PackageJsonFileName), err)
+	}
+
+	installed := &models.InstalledPackage{
+		Name:         packageJson.Name,
+		Version:      packageJson.Version,
+		Path:         installPath,
+		RealPath:     realPath,
+		InstallPaths: []string{installPath},
+		Link:         link,
+		Bin:          decodeInstalledBin(packageJson),
+		Resolved:     packageJson.Resolved,
+		Integrity:    packageJson.Integrity,
+	}
+	if installed.Name == "" {
+		installed.Name = directoryName
+	} else if installed.Name != directoryName {
+		installed.Alias = directoryName
+	}
+	x.packages[realPath] = installed
+
+	x.queue = append(x.queue, &nodeModulesDirectory{path: path.Join(installPath, NodeModulesDirectoryName), depth: depth + 1})
+	return nil
+}
+
+// scanBins 记录.bin目录中的命令，Windows上的.cmd和.ps1是同一个命令的启动脚本，不单独记录
+func (x *nodeModulesScanner) scanBins(directory string) {
+	entries, err := x.fileSystem.readDir(directory)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, ".cmd") || strings.HasSuffix(name, ".ps1") {
+			continue
+		}
+		binPath := path.Join(directory, name)
+		bin := &models.InstalledBin{Name: name, Path: binPath}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if target, err := x.fileSystem.readLink(binPath); err == nil {
+				bin.Target = target
+			}
+		}
+		x.bins[binPath] = bin
+	}
+}
+
+// decodeInstalledBin 解析package.json中的bin，字符串写法的命令名称是去掉scope的包名
+func decodeInstalledBin(packageJson *installedPackageJson) map[string]string {
+	if len(packageJson.Bin) == 0 {
+		return nil
+	}
+	var script string
+	if err := json.Unmarshal(packageJson.Bin, &script); err == nil {
+		if script == "" {
+			return nil
+		}
+		return map[string]string{path.Base(packageJson.Name): script}
+	}
+	bin := make(map[string]string)
+	if err := json.Unmarshal(packageJson.Bin, &bin); err != nil || len(bin) == 0 {
+		return nil
+	}
+	return bin
+}
+
+func (x *NodeModulesParser) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// NodeModulesDirectoryName 安装依赖的目录名
+const NodeModulesDirectoryName = "node_modules"
+
+// NodeModulesParserInput node_modules扫描器的输入，扫描项目根目录下的node_modules
+type NodeModulesParserInput struct {
+	// ProjectRootDirectory 项目根目录
+	ProjectRootDirectory string
+
+	// FileSystem 指定后ProjectRootDirectory是这个文件系统中的路径。
+	// fs.FS无法读取符号链接，所以这时无法识别链接和它指向的目录，只能通过嵌套深度的限制避免符号链接循环，
+	// 需要识别符号链接时不要指定FileSystem
+	FileSystem fs.FS
+}
+
+// nodeModulesFileSystem 扫描node_modules需要的文件操作，路径都相对于项目根目录，使用/分隔
+type nodeModulesFileSystem interface {
+	readDir(name string) ([]fs.DirEntry, error)
+	readFile(name string) ([]byte, error)
+
+	// realPath 返回解析所有符号链接后的路径，在项目根目录之外时以../开头
+	realPath(name string) (string, error)
+
+	// readLink 返回符号链接指向的路径，不支持符号链接时返回错误
+	readLink(name string) (string, error)
+}
+
+// fileSystem 根据输入选择本地文件系统或者fs.FS
+func (x *NodeModulesParserInput) fileSystem() (nodeModulesFileSystem, error) {
+	if x.ProjectRootDirectory == "" {
+		return nil, fmt.Errorf("project root directory cannot be empty")
+	}
+	if x.FileSystem != nil {
+		fileSystem, err := fs.Sub(x.FileSystem, toFsPath(x.ProjectRootDirectory))
+		if err != nil {
+			return nil, err
+		}
+		return &fsNodeModulesFileSystem{fileSystem: fileSystem}, nil
+	}
+	root, err := filepath.EvalSymlinks(x.ProjectRootDirectory)
+	if err != nil {
+		return nil, err
+	}
+	return &osNodeModulesFileSystem{root: root}, nil
+}
+
+// osNodeModulesFileSystem 本地文件系统，可以识别符号链接
+type osNodeModulesFileSystem struct {
+	// 解析了符号链接之后的项目根目录
+	root string
+}
+
+func (x *osNodeModulesFileSystem) readDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(x.localPath(name))
+}
+
+func (x *osNodeModulesFileSystem) readFile(name string) ([]byte, error) {
+	return os.ReadFile(x.localPath(name))
+}
+
+func (x *osNodeModulesFileSystem) realPath(name string) (string, error) {
+	realPath, err := filepath.EvalSymlinks(x.localPath(name))
+	if err != nil {
+		return "", err
+	}
+	relativePath, err := filepath.Rel(x.root, realPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relativePath), nil
+}
+
+func (x *osNodeModulesFileSystem) readLink(name string) (string, error) {
+	target, err := os.Readlink(x.localPath(name))
+	if err != nil {
+		return "", err
+	}
+	target = filepath.ToSlash(target)
+	if path.IsAbs(target) || filepath.IsAbs(target) {
+		relativePath, err := filepath.Rel(x.root, filepath.FromSlash(target))
+		if err != nil {
+			return "", err
+		}
+		return filepath.ToSlash(relativePath), nil
+	}
+	return path.Join(path.Dir(name), target), nil
+}
+
+func (x *osNodeModulesFileSystem) localPath(name string) string {
+	return filepath.Join(x.root, filepath.FromSlash(name))
+}
+
+// fsNodeModulesFileSystem fs.FS中的文件，不支持符号链接
+type fsNodeModulesFileSystem struct {
+	fileSystem fs.FS
+}
+
+func (x *fsNodeModulesFileSystem) readDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(x.fileSystem, name)
+}
+
+func (x *fsNodeModulesFileSystem) readFile(name string) ([]byte, error) {
+	return fs.ReadFile(x.fileSystem, name)
+}
+
+func (x *fsNodeModulesFileSystem) realPath(name string) (string, error) {
+	if _, err := fs.Stat(x.fileSystem, name); err != nil {
+		return "", err
+	}
+	return path.Clean(name), nil
+}
+
+func (x *fsNodeModulesFileSystem) readLink(name string) (string, error) {
+	return "", fmt.Errorf("symbolic links are not supported in fs.FS: %s", name)
+}
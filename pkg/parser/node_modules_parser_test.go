@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installedPackagesByPath 把扫描到的包转换为 安装路径 -> 包 的映射
+func installedPackagesByPath(inventory *models.NodeModulesInventory) map[string]*models.InstalledPackage {
+	packages := make(map[string]*models.InstalledPackage)
+	for _, installed := range inventory.Packages {
+		packages[installed.Path] = installed
+	}
+	return packages
+}
+
+// writeNodeModulesTestFiles 在目录中写入 相对路径 -> 内容 的文件
+func writeNodeModulesTestFiles(t *testing.T, root string, files map[string]string) {
+	for filePath, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(filePath))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+}
+
+// symlinkNodeModulesTestFile 创建符号链接，link是相对于root的路径，target原样写入链接
+func symlinkNodeModulesTestFile(t *testing.T, root string, target string, link string) {
+	fullPath := filepath.Join(root, filepath.FromSlash(link))
+	require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+	require.NoError(t, os.Symlink(filepath.FromSlash(target), fullPath))
+}
+
+func TestNodeModulesParser_ParseNestedAndScoped(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"app/package.json":                                              `{"name": "app", "version": "1.0.0", "author": "someone"}`,
+		"app/node_modules/.package-lock.json":                           `{}`,
+		"app/node_modules/.bin/semver":                                  `#!/bin/sh`,
+		"app/node_modules/.bin/semver.cmd":                              `@ECHO off`,
+		"app/node_modules/semver/package.json":                          `{"name": "semver", "version": "7.6.0", "bin": {"semver": "bin/semver.js"}, "author": "GitHub Inc."}`,
+		"app/node_modules/@babel/core/package.json":                     `{"name": "@babel/core", "version": "7.24.0", "_resolved": "https://registry.npmjs.org/@babel/core/-/core-7.24.0.tgz", "_integrity": "sha512-core"}`,
+		"app/node_modules/@babel/core/node_modules/semver/package.json": `{"name": "semver", "version": "6.3.1", "bin": "bin/semver.js"}`,
+		"app/node_modules/my-react/package.json":                        `{"name": "react", "version": "18.2.0"}`,
+		"app/node_modules/unnamed/package.json":                         `{"version": "0.1.0"}`,
+		"app/node_modules/not-a-package/README.md":                      `leftover`,
+	})
+
+	project, err := NewNodeModulesParser().Parse(context.Background(), &NodeModulesParserInput{ProjectRootDirectory: "app", FileSystem: fileSystem})
+	require.NoError(t, err)
+	assert.Equal(t, "app", project.Name)
+	assert.Equal(t, "1.0.0", project.Version)
+
+	inventory := project.ProjectEcosystem.NodeModules
+	require.NotNil(t, inventory)
+	packages := installedPackagesByPath(inventory)
+	assert.Len(t, packages, 5)
+
+	nested := packages["node_modules/@babel/core/node_modules/semver"]
+	require.NotNil(t, nested)
+	assert.Equal(t, "6.3.1", nested.Version)
+	assert.Equal(t, map[string]string{"semver": "bin/semver.js"}, nested.Bin, "字符串写法的bin")
+	assert.Equal(t, "sha512-core", packages["node_modules/@babel/core"].Integrity)
+	assert.Equal(t, "my-react", packages["node_modules/my-react"].Alias)
+	assert.Equal(t, "unnamed", packages["node_modules/unnamed"].Name)
+	assert.False(t, packages["node_modules/semver"].Link)
+
+	assert.Equal(t, []*models.InstalledBin{{Name: "semver", Path: "node_modules/.bin/semver"}}, inventory.Bins)
+
+	// 项目中每个安装的包是一个依赖，Path是安装路径
+	module := project.Modules["app"]
+	require.NotNil(t, module)
+	require.Len(t, module.Dependencies, 5)
+	first := module.Dependencies[0]
+	assert.Equal(t, "@babel/core", first.DependencyName)
+	assert.Equal(t, "node_modules/@babel/core", first.ComponentDependencyEcosystem.Path)
+	assert.Equal(t, "https://registry.npmjs.org/@babel/core/-/core-7.24.0.tgz", first.ComponentDependencyEcosystem.Resolved)
+	assert.Equal(t, "react", module.Dependencies[2].DependencyName)
+	assert.Equal(t, "my-react", module.Dependencies[2].ComponentDependencyEcosystem.Alias)
+}
+
+func TestNodeModulesParser_ParseSymlinks(t *testing.T) {
+	root := t.TempDir()
+	writeNodeModulesTestFiles(t, root, map[string]string{
+		"package.json":                               `{"name": "monorepo"}`,
+		"packages/ui/package.json":                   `{"name": "@acme/ui", "version": "0.1.0"}`,
+		"packages/ui/node_modules/clsx/package.json": `{"name": "clsx", "version": "2.1.0"}`,
+		"node_modules/.pnpm/lodash@4.17.21/node_modules/lodash/package.json":          `{"name": "lodash", "version": "4.17.21"}`,
+		"node_modules/.pnpm/lodash@4.17.21/node_modules/lodash/cli.js":                ``,
+		"node_modules/.pnpm/@types+node@20.0.0/node_modules/@types/node/package.json": `{"name": "@types/node", "version": "20.0.0"}`,
+		"node_modules/.pnpm/debug@4.3.4/node_modules/debug/package.json":              `{"name": "debug", "version": "4.3.4"}`,
+		"node_modules/.pnpm/ms@2.1.2/node_modules/ms/package.json":                    `{"name": "ms", "version": "2.1.2"}`,
+		"node_modules/.pnpm/lock.yaml":                                                `lockfileVersion: '9.0'`,
+	})
+	// workspace
+	symlinkNodeModulesTestFile(t, root, "../../packages/ui", "node_modules/@acme/ui")
+	// pnpm的顶层依赖和虚拟仓库中依赖之间的链接
+	symlinkNodeModulesTestFile(t, root, ".pnpm/lodash@4.17.21/node_modules/lodash", "node_modules/lodash")
+	symlinkNodeModulesTestFile(t, root, ".pnpm/debug@4.3.4/node_modules/debug", "node_modules/debug")
+	symlinkNodeModulesTestFile(t, root, "../../ms@2.1.2/node_modules/ms", "node_modules/.pnpm/debug@4.3.4/node_modules/ms")
+	symlinkNodeModulesTestFile(t, root, "../../@types+node@20.0.0/node_modules/@types/node", "node_modules/.pnpm/node_modules/@types/node")
+	symlinkNodeModulesTestFile(t, root, "../lodash/cli.js", "node_modules/.bin/lodash")
+	// 指向祖先目录的循环和失效的链接
+	symlinkNodeModulesTestFile(t, root, "../../..", "packages/ui/node_modules/loop")
+	symlinkNodeModulesTestFile(t, root, "../../..", "node_modules/.pnpm/ms@2.1.2/node_modules/loop")
+	symlinkNodeModulesTestFile(t, root, "../does-not-exist", "node_modules/broken")
+
+	inventory, err := NewNodeModulesParser().ParseInventory(context.Background(), &NodeModulesParserInput{ProjectRootDirectory: root})
+	require.NoError(t, err)
+	packages := installedPackagesByPath(inventory)
+
+	ui := packages["node_modules/@acme/ui"]
+	require.NotNil(t, ui)
+	assert.True(t, ui.Link)
+	assert.Equal(t, "packages/ui", ui.RealPath)
+	assert.NotNil(t, packages["node_modules/@acme/ui/node_modules/clsx"], "继续扫描workspace中的node_modules")
+
+	lodash := packages["node_modules/lodash"]
+	require.NotNil(t, lodash)
+	assert.True(t, lodash.Link)
+	assert.Equal(t, "node_modules/.pnpm/lodash@4.17.21/node_modules/lodash", lodash.RealPath)
+	assert.Equal(t, []string{"node_modules/lodash", "node_modules/.pnpm/lodash@4.17.21/node_modules/lodash"}, lodash.InstallPaths)
+
+	ms := packages["node_modules/.pnpm/debug@4.3.4/node_modules/ms"]
+	require.NotNil(t, ms, "只通过虚拟仓库访问到的包")
+	assert.Len(t, ms.InstallPaths, 2)
+	assert.NotNil(t, packages["node_modules/.pnpm/@types+node@20.0.0/node_modules/@types/node"])
+
+	// 循环链接指向项目根目录，根目录的node_modules已经扫描过，不会再扫描一次
+	loop := packages["node_modules/@acme/ui/node_modules/loop"]
+	require.NotNil(t, loop)
+	assert.Equal(t, ".", loop.RealPath)
+	assert.Equal(t, "monorepo", loop.Name)
+	assert.Len(t, inventory.Packages, 7)
+
+	assert.Equal(t, []*models.InstalledBin{{Name: "lodash", Path: "node_modules/.bin/lodash", Target: "node_modules/lodash/cli.js"}}, inventory.Bins)
+}
+
+func TestNodeModulesParser_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *NodeModulesParserInput
+	}{
+		{name: "输入为nil"},
+		{name: "没有根目录", input: &NodeModulesParserInput{FileSystem: fstest.MapFS{}}},
+		{name: "没有node_modules", input: &NodeModulesParserInput{ProjectRootDirectory: ".", FileSystem: newWorkspaceTestFileSystem(map[string]string{"package.json": `{}`})}},
+		{name: "package.json不是JSON", input: &NodeModulesParserInput{ProjectRootDirectory: ".", FileSystem: newWorkspaceTestFileSystem(map[string]string{"node_modules/a/package.json": `{"name": `})}},
+		{name: "根目录不存在", input: &NodeModulesParserInput{ProjectRootDirectory: filepath.Join(t.TempDir(), "missing")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNodeModulesParser().Parse(context.Background(), tt.input)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package parser
+
+import "strings"
+
+// NpmAliasPrefix npm别名依赖的版本声明前缀，比如 "my-react": "npm:react@18.2.0"
+const NpmAliasPrefix = "npm:"
+
+// parseNpmAlias 解析npm别名声明，返回真实的包名和版本（或版本范围）
+// 支持 npm:react@18.2.0、npm:@scope/name@^1.0.0、npm:react 这几种形式，不是别名声明时ok返回false
+func parseNpmAlias(spec string) (name string, version string, ok bool) {
+	if !strings.HasPrefix(spec, NpmAliasPrefix) {
+		return "", "", false
+	}
+	name, version = splitPackageSpec(strings.TrimPrefix(spec, NpmAliasPrefix))
+	if name == "" {
+		return "", "", false
+	}
+	return name, version, true
+}
+
+// splitPackageSpec 把 name@version 形式的声明拆分为包名和版本，会正确处理作用域包开头的@
+func splitPackageSpec(spec string) (name string, version string) {
+	searchFrom := 0
+	if strings.HasPrefix(spec, "@") {
+		searchFrom = 1
+	}
+	atIndex := strings.Index(spec[searchFrom:], "@")
+	if atIndex < 0 {
+		return spec, ""
+	}
+	atIndex += searchFrom
+	return spec[:atIndex], spec[atIndex+1:]
+}
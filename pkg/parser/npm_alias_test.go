@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNpmAlias(t *testing.T) {
+	tests := []struct {
+		spec        string
+		wantName    string
+		wantVersion string
+		wantOk      bool
+	}{
+		{spec: "npm:react@18.2.0", wantName: "react", wantVersion: "18.2.0", wantOk: true},
+		{spec: "npm:@babel/core@^7.0.0", wantName: "@babel/core", wantVersion: "^7.0.0", wantOk: true},
+		{spec: "npm:react", wantName: "react", wantVersion: "", wantOk: true},
+		{spec: "18.2.0", wantOk: false},
+		{spec: "npm:", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			name, version, ok := parseNpmAlias(tt.spec)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
+
+func TestSplitPackageSpec(t *testing.T) {
+	tests := []struct {
+		spec        string
+		wantName    string
+		wantVersion string
+	}{
+		{spec: "lodash@^4.17.15", wantName: "lodash", wantVersion: "^4.17.15"},
+		{spec: "@babel/core@^7.7.5", wantName: "@babel/core", wantVersion: "^7.7.5"},
+		{spec: "my-react@npm:react@18.2.0", wantName: "my-react", wantVersion: "npm:react@18.2.0"},
+		{spec: "@my/react@npm:@scope/react@1", wantName: "@my/react", wantVersion: "npm:@scope/react@1"},
+		{spec: "lodash", wantName: "lodash", wantVersion: ""},
+		{spec: "@scope/name", wantName: "@scope/name", wantVersion: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			name, version := splitPackageSpec(tt.spec)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
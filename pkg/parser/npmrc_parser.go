@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// NpmrcParser 按照npm的规则读取各层.npmrc并合并，优先级从高到低依次是：
+// npm_config_*环境变量、项目的.npmrc、用户的.npmrc、全局的npmrc、npm内置的npmrc。
+// 不会执行npm命令，也不会读取命令行参数
+type NpmrcParser struct {
+}
+
+func NewNpmrcParser() *NpmrcParser {
+	return &NpmrcParser{}
+}
+
+// npmrcEnvReference 配置中引用的环境变量，比如 ${NPM_TOKEN}
+var npmrcEnvReference = regexp.MustCompile(`\$\{([^${}]+)\}`)
+
+// Parse 读取并合并各层配置，配置文件不存在时跳过。
+// 配置中的${VAR}会替换为环境变量的值，环境变量不存在时保留原样，方便在没有凭据的环境中分析配置
+func (x *NpmrcParser) Parse(ctx context.Context, input *NpmrcParserInput) (*models.NpmConfig, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	environment := input.environment()
+	config := models.NewNpmConfig()
+
+	layers := []struct {
+		source   models.NpmConfigSource
+		filePath string
+	}{
+		{source: models.NpmConfigSourceBuiltin, filePath: input.BuiltinConfigPath},
+		{source: models.NpmConfigSourceGlobal, filePath: input.globalConfigPath(environment)},
+		{source: models.NpmConfigSourceUser, filePath: input.userConfigPath(environment)},
+		{source: models.NpmConfigSourceProject, filePath: input.projectConfigPath()},
+	}
+	// 项目根目录就是用户目录时，npm只把它当做用户级配置
+	if layers[3].filePath == layers[2].filePath {
+		layers[3].filePath = ""
+	}
+
+	// 从优先级低的开始，优先级高的覆盖优先级低的
+	for _, layer := range layers {
+		if layer.filePath == "" {
+			continue
+		}
+		data, ok, err := input.read(layer.filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s config %s: %w", layer.source, layer.filePath, err)
+		}
+		if !ok {
+			continue
+		}
+		for key, value := range x.parseIni(string(data), environment) {
+			config.Values[key] = &models.NpmConfigValue{Key: key, Value: value, Source: layer.source, Path: layer.filePath}
+		}
+		config.Files = append([]*models.NpmConfigFile{{Source: layer.source, Path: layer.filePath}}, config.Files...)
+	}
+
+	for key, value := range x.parseEnvironment(environment) {
+		config.Values[key] = &models.NpmConfigValue{Key: key, Value: value, Source: models.NpmConfigSourceEnv}
+	}
+	return config, nil
+}
+
+// parseIni 解析ini格式的配置文件：;和#开头的是注释，没有=的键值为true，
+// 值可以用引号括起来，键以[]结尾时是数组，多个值用换行符连接，[section]中的键带有 section. 前缀
+func (x *NpmrcParser) parseIni(content string, environment map[string]string) map[string]string {
+	values := make(map[string]string)
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value := line, "true"
+		if index := strings.Index(line, "="); index >= 0 {
+			key, value = strings.TrimSpace(line[:index]), x.unquote(strings.TrimSpace(line[index+1:]))
+		}
+		key = x.replaceEnv(key, environment)
+		value = x.replaceEnv(value, environment)
+		if section != "" {
+			key = section + "." + key
+		}
+		if strings.HasSuffix(key, "[]") {
+			key = strings.TrimSuffix(key, "[]")
+			if previous, ok := values[key]; ok {
+				value = previous + "\n" + value
+			}
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// parseEnvironment 把npm_config_*环境变量转换为配置，跟npm一样配置名转换为小写，除开头以外的_替换为-
+func (x *NpmrcParser) parseEnvironment(environment map[string]string) map[string]string {
+	values := make(map[string]string)
+	for name, value := range environment {
+		if len(name) <= len(npmConfigEnvPrefix) || !strings.EqualFold(name[:len(npmConfigEnvPrefix)], npmConfigEnvPrefix) {
+			continue
+		}
+		key := strings.ToLower(name[len(npmConfigEnvPrefix):])
+		trimmed := strings.TrimLeft(key, "_")
+		key = key[:len(key)-len(trimmed)] + strings.ReplaceAll(trimmed, "_", "-")
+		values[key] = value
+	}
+	return values
+}
+
+// unquote 去掉值两边的引号，双引号按照JSON字符串解析
+func (x *NpmrcParser) unquote(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	switch {
+	case value[0] == '"' && value[len(value)-1] == '"':
+		unquoted := ""
+		if err := json.Unmarshal([]byte(value), &unquoted); err == nil {
+			return unquoted
+		}
+		return value[1 : len(value)-1]
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// replaceEnv 把${VAR}替换为环境变量的值，环境变量不存在时保留原样
+func (x *NpmrcParser) replaceEnv(value string, environment map[string]string) string {
+	return npmrcEnvReference.ReplaceAllStringFunc(value, func(reference string) string {
+		if replaced, ok := environment[reference[2:len(reference)-1]]; ok {
+			return replaced
+		}
+		return reference
+	})
+}
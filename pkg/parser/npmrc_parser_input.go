@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// NpmrcFileName 项目和用户目录中npm配置文件的文件名
+const NpmrcFileName = ".npmrc"
+
+// npmConfigEnvPrefix npm配置对应的环境变量前缀，不区分大小写
+const npmConfigEnvPrefix = "npm_config_"
+
+// NpmrcParserInput npm配置解析器的输入，各层配置文件的路径不指定时按照npm的规则从环境变量推断
+type NpmrcParserInput struct {
+	// ProjectRootDirectory 项目根目录，会读取其中的.npmrc，为空时没有项目级配置
+	ProjectRootDirectory string
+
+	// UserConfigPath 用户级配置文件，为空时依次使用环境变量npm_config_userconfig、$HOME/.npmrc
+	UserConfigPath string
+
+	// GlobalConfigPath 全局配置文件，为空时依次使用环境变量npm_config_globalconfig、$npm_config_prefix/etc/npmrc、$PREFIX/etc/npmrc
+	GlobalConfigPath string
+
+	// BuiltinConfigPath npm安装目录中的npmrc，无法推断，为空时没有内置配置
+	BuiltinConfigPath string
+
+	// Environment 环境变量，为nil时使用当前进程的环境变量，用于读取npm_config_*和替换配置中的${VAR}
+	Environment map[string]string
+
+	// FileSystem 指定后所有配置文件的路径都是这个文件系统中的路径
+	FileSystem fs.FS
+}
+
+// environment 返回输入指定的环境变量，没有指定时使用当前进程的
+func (x *NpmrcParserInput) environment() map[string]string {
+	if x.Environment != nil {
+		return x.Environment
+	}
+	environment := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if index := strings.Index(entry, "="); index > 0 {
+			environment[entry[:index]] = entry[index+1:]
+		}
+	}
+	return environment
+}
+
+// userConfigPath 用户级配置文件的路径，无法推断时为空
+func (x *NpmrcParserInput) userConfigPath(environment map[string]string) string {
+	if x.UserConfigPath != "" {
+		return x.UserConfigPath
+	}
+	if userConfig := npmConfigFromEnvironment(environment, "userconfig"); userConfig != "" {
+		return userConfig
+	}
+	for _, home := range []string{environment["HOME"], environment["USERPROFILE"]} {
+		if home != "" {
+			return joinInputPath(x.FileSystem, home, NpmrcFileName)
+		}
+	}
+	return ""
+}
+
+// globalConfigPath 全局配置文件的路径，无法推断时为空
+func (x *NpmrcParserInput) globalConfigPath(environment map[string]string) string {
+	if x.GlobalConfigPath != "" {
+		return x.GlobalConfigPath
+	}
+	if globalConfig := npmConfigFromEnvironment(environment, "globalconfig"); globalConfig != "" {
+		return globalConfig
+	}
+	for _, prefix := range []string{npmConfigFromEnvironment(environment, "prefix"), environment["PREFIX"]} {
+		if prefix != "" {
+			return joinInputPath(x.FileSystem, prefix, "etc", "npmrc")
+		}
+	}
+	return ""
+}
+
+// projectConfigPath 项目级配置文件的路径，没有项目根目录时为空
+func (x *NpmrcParserInput) projectConfigPath() string {
+	if x.ProjectRootDirectory == "" {
+		return ""
+	}
+	return joinInputPath(x.FileSystem, x.ProjectRootDirectory, NpmrcFileName)
+}
+
+// read 读取配置文件，文件不存在时ok为false
+func (x *NpmrcParserInput) read(filePath string) (data []byte, ok bool, err error) {
+	data, err = readInputFile(x.FileSystem, filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// npmConfigFromEnvironment 读取npm_config_<key>环境变量，前缀不区分大小写
+func npmConfigFromEnvironment(environment map[string]string, key string) string {
+	for name, value := range environment {
+		if strings.EqualFold(name, npmConfigEnvPrefix+key) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// OsvAdvisoryParser 读取OSV格式的安全公告，只保留npm生态系统的受影响包，撤回的公告会被跳过
+type OsvAdvisoryParser struct {
+}
+
+func NewOsvAdvisoryParser() *OsvAdvisoryParser {
+	return &OsvAdvisoryParser{}
+}
+
+// Parse 读取目录或zip中所有的.json文件，每个文件是一条公告，返回的公告按照ID排序
+func (x *OsvAdvisoryParser) Parse(ctx context.Context, input *OsvAdvisoryParserInput) ([]*models.OsvAdvisory, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	fileSystem, err := input.fileSystem()
+	if err != nil {
+		return nil, err
+	}
+
+	advisories := make([]*models.OsvAdvisory, 0)
+	err = fs.WalkDir(fileSystem, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.EqualFold(path.Ext(filePath), ".json") {
+			return nil
+		}
+		data, err := fs.ReadFile(fileSystem, filePath)
+		if err != nil {
+			return err
+		}
+		advisory, err := x.parseAdvisory(data, filePath)
+		if err != nil {
+			return err
+		}
+		if advisory != nil {
+			advisories = append(advisories, advisory)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(advisories, func(i, j int) bool {
+		return advisories[i].ID < advisories[j].ID
+	})
+	return advisories, nil
+}
+
+// parseAdvisory 解析一条公告，撤回的或者不影响npm包的公告返回nil
+func (x *OsvAdvisoryParser) parseAdvisory(data []byte, source string) (*models.OsvAdvisory, error) {
+	advisory := &models.OsvAdvisory{}
+	if err := json.Unmarshal(data, advisory); err != nil {
+		return nil, fmt.Errorf("failed to parse advisory %s: %w", source, err)
+	}
+	if advisory.ID == "" {
+		return nil, fmt.Errorf("advisory %s has no id", source)
+	}
+	if advisory.Withdrawn != "" {
+		return nil, nil
+	}
+	advisory.Source = source
+
+	affected := make([]*models.OsvAffected, 0, len(advisory.Affected))
+	for _, item := range advisory.Affected {
+		if item == nil || item.Package == nil || item.Package.Name == "" || !strings.EqualFold(item.Package.Ecosystem, models.OsvEcosystemNpm) {
+			continue
+		}
+		affected = append(affected, item)
+	}
+	if len(affected) == 0 {
+		return nil, nil
+	}
+	advisory.Affected = affected
+	return advisory, nil
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// OsvAdvisoryParserInput OSV公告的输入，可以是一个目录，也可以是osv.dev提供的zip（比如 npm/all.zip）
+type OsvAdvisoryParserInput struct {
+	// Path 保存公告的目录，或者以.zip结尾的压缩包，目录会被递归读取
+	Path string
+
+	// FileSystem 指定后Path是这个文件系统中的路径，否则从本地文件系统读取
+	FileSystem fs.FS
+}
+
+// fileSystem 把目录或者zip统一成fs.FS，zip.Reader本身就实现了fs.FS
+func (x *OsvAdvisoryParserInput) fileSystem() (fs.FS, error) {
+	if x.Path == "" {
+		return nil, fmt.Errorf("advisory path cannot be empty")
+	}
+	if strings.HasSuffix(strings.ToLower(x.Path), ".zip") {
+		data, err := readInputFile(x.FileSystem, x.Path)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zip %s: %w", x.Path, err)
+		}
+		return reader, nil
+	}
+	if x.FileSystem != nil {
+		return fs.Sub(x.FileSystem, toFsPath(x.Path))
+	}
+	return os.DirFS(x.Path), nil
+}
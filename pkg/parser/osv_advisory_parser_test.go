@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOsvAdvisories 测试使用的OSV公告
+var testOsvAdvisories = map[string]string{
+	"GHSA-35jh-r3h4-6jhm.json": `{
+  "id": "GHSA-35jh-r3h4-6jhm",
+  "aliases": ["CVE-2021-23337"],
+  "summary": "Command Injection in lodash",
+  "database_specific": {"severity": "HIGH"},
+  "affected": [{
+    "package": {"ecosystem": "npm", "name": "lodash"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+  }]
+}`,
+	"GHSA-p6mc-m468-83gw.json": `{
+  "id": "GHSA-p6mc-m468-83gw",
+  "aliases": ["CVE-2020-8203"],
+  "summary": "Prototype Pollution in lodash",
+  "database_specific": {"severity": "HIGH"},
+  "affected": [{
+    "package": {"ecosystem": "npm", "name": "lodash"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "3.7.0"}, {"fixed": "4.17.19"}]}]
+  }]
+}`,
+	"nested/GHSA-c2qf-rxjj-qqgw.json": `{
+  "id": "GHSA-c2qf-rxjj-qqgw",
+  "summary": "semver vulnerable to Regular Expression Denial of Service",
+  "affected": [{
+    "package": {"ecosystem": "npm", "name": "semver"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "7.0.0"}, {"fixed": "7.5.2"}, {"introduced": "6.0.0"}, {"fixed": "6.3.1"}, {"introduced": "0"}, {"fixed": "5.7.2"}]}]
+  }]
+}`,
+	"PYSEC-2021-1.json":   `{"id": "PYSEC-2021-1", "affected": [{"package": {"ecosystem": "PyPI", "name": "lodash"}}]}`,
+	"GHSA-withdrawn.json": `{"id": "GHSA-withdrawn", "withdrawn": "2024-01-01T00:00:00Z", "affected": [{"package": {"ecosystem": "npm", "name": "lodash"}}]}`,
+	"README.md":           "不是公告",
+}
+
+func TestOsvAdvisoryParser_Parse(t *testing.T) {
+	files := make(map[string]string)
+	for name, content := range testOsvAdvisories {
+		files["osv/npm/"+name] = content
+	}
+
+	// zip中的文件
+	buffer := &bytes.Buffer{}
+	writer := zip.NewWriter(buffer)
+	for name, content := range testOsvAdvisories {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	fileSystem := newWorkspaceTestFileSystem(files)
+	fileSystem["osv/npm-all.zip"] = &fstest.MapFile{Data: buffer.Bytes()}
+
+	// 本地目录
+	directory := t.TempDir()
+	for name, content := range testOsvAdvisories {
+		filePath := filepath.Join(directory, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+	}
+
+	inputs := map[string]*OsvAdvisoryParserInput{
+		"目录":   {Path: "osv/npm", FileSystem: fileSystem},
+		"zip":  {Path: "osv/npm-all.zip", FileSystem: fileSystem},
+		"本地目录": {Path: directory},
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			advisories, err := NewOsvAdvisoryParser().Parse(context.Background(), input)
+			require.NoError(t, err)
+			ids := make([]string, 0)
+			for _, advisory := range advisories {
+				ids = append(ids, advisory.ID)
+			}
+			assert.Equal(t, []string{"GHSA-35jh-r3h4-6jhm", "GHSA-c2qf-rxjj-qqgw", "GHSA-p6mc-m468-83gw"}, ids, "只保留npm生态系统中没有撤回的公告")
+			assert.Equal(t, "HIGH", advisories[0].DatabaseSpecific.Severity)
+			assert.Equal(t, []string{"CVE-2021-23337"}, advisories[0].Aliases)
+			assert.Equal(t, models.OsvRangeSemver, advisories[0].Affected[0].Ranges[0].Type)
+			assert.Equal(t, "nested/GHSA-c2qf-rxjj-qqgw.json", advisories[1].Source)
+		})
+	}
+
+	errorInputs := map[string]*OsvAdvisoryParserInput{
+		"空路径":   {FileSystem: fileSystem},
+		"目录不存在": {Path: "osv/pypi", FileSystem: fileSystem},
+		"不是zip": {Path: "osv/broken.zip", FileSystem: newWorkspaceTestFileSystem(map[string]string{"osv/broken.zip": "not a zip"})},
+		"格式错误":  {Path: "osv", FileSystem: newWorkspaceTestFileSystem(map[string]string{"osv/a.json": `{"id": `})},
+		"没有id":  {Path: "osv", FileSystem: newWorkspaceTestFileSystem(map[string]string{"osv/a.json": `{"summary": "x"}`})},
+	}
+	for name, input := range errorInputs {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewOsvAdvisoryParser().Parse(context.Background(), input)
+			assert.Error(t, err)
+		})
+	}
+	_, err := NewOsvAdvisoryParser().Parse(context.Background(), nil)
+	assert.Error(t, err)
+}
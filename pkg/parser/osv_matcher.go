@@ -0,0 +1,348 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// osvMaxPaths 每个组件最多输出的依赖路径数量，依赖图中到同一个包的路径数量可能随着深度指数增长
+const osvMaxPaths = 20
+
+// osvIntroducedZero introduced为0表示从第一个版本开始受影响
+const osvIntroducedZero = "0"
+
+// OsvMatcher 在本地把任意解析器解析出的组件跟OSV公告匹配，不需要访问网络。
+// 公告中SEMVER和ECOSYSTEM类型的范围都按照语义化版本计算，npm的ECOSYSTEM规则就是语义化版本；GIT类型的范围会被忽略
+type OsvMatcher struct {
+	// 包名 -> 影响这个包的公告
+	index map[string][]*osvCandidate
+
+	advisories int
+}
+
+// osvCandidate 一条公告中影响某个包的部分
+type osvCandidate struct {
+	advisory *models.OsvAdvisory
+	affected *models.OsvAffected
+}
+
+// osvComponent 项目中的一个组件版本，同一个 name@version 在lockfile中可能出现在多个位置
+type osvComponent struct {
+	name      string
+	version   string
+	locations []string
+}
+
+func NewOsvMatcher(advisories []*models.OsvAdvisory) *OsvMatcher {
+	matcher := &OsvMatcher{index: make(map[string][]*osvCandidate)}
+	for _, advisory := range advisories {
+		if advisory == nil || advisory.Withdrawn != "" {
+			continue
+		}
+		matcher.advisories++
+		for _, affected := range advisory.Affected {
+			if affected == nil || affected.Package == nil || !strings.EqualFold(affected.Package.Ecosystem, models.OsvEcosystemNpm) {
+				continue
+			}
+			name := affected.Package.Name
+			matcher.index[name] = append(matcher.index[name], &osvCandidate{advisory: advisory, affected: affected})
+		}
+	}
+	return matcher
+}
+
+// Match 匹配项目中所有模块的依赖。graph是同一个lockfile构建的依赖图，提供时会输出从根节点到受影响的组件的依赖路径，可以为nil。
+// 来自git、本地文件和其它URL的依赖不是registry中的版本，不参与匹配
+func (x *OsvMatcher) Match(ctx context.Context, project *models.JsProject, graph *models.DependencyGraph) (*models.VulnerabilityReport, error) {
+	if project == nil {
+		return nil, fmt.Errorf("project cannot be nil")
+	}
+	components := x.components(project)
+	report := &models.VulnerabilityReport{
+		Findings:   make([]*models.VulnerabilityFinding, 0),
+		Advisories: x.advisories,
+		Components: len(components),
+	}
+
+	var parents map[string][]string
+	for _, component := range components {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		candidates := x.index[component.name]
+		if len(candidates) == 0 {
+			continue
+		}
+		version, err := parseSemver(component.version)
+		if err != nil {
+			continue
+		}
+
+		var paths [][]string
+		for _, candidate := range candidates {
+			affected, fixed := x.affects(candidate.affected, component.version, version)
+			if !affected {
+				continue
+			}
+			if paths == nil && graph != nil {
+				if parents == nil {
+					parents = x.parents(graph)
+				}
+				paths = x.paths(graph, parents, component)
+			}
+			finding := &models.VulnerabilityFinding{
+				AdvisoryID:   candidate.advisory.ID,
+				Aliases:      candidate.advisory.Aliases,
+				Summary:      candidate.advisory.Summary,
+				PackageName:  component.name,
+				Version:      component.version,
+				FixedVersion: fixed,
+				Paths:        paths,
+				Locations:    component.locations,
+			}
+			if candidate.advisory.DatabaseSpecific != nil {
+				finding.Severity = candidate.advisory.DatabaseSpecific.Severity
+			}
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.PackageName != b.PackageName {
+			return a.PackageName < b.PackageName
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.AdvisoryID < b.AdvisoryID
+	})
+	return report, nil
+}
+
+// components 收集项目中来自npm registry的组件版本，按照 name@version 去重并排序，
+// JSR包即使跟npm包同名也不是同一个包，不参与匹配
+func (x *OsvMatcher) components(project *models.JsProject) []*osvComponent {
+	found := make(map[string]*osvComponent)
+	for _, module := range project.Modules {
+		for _, dependency := range module.Dependencies {
+			if dependency.DependencyName == "" || dependency.DependencyVersion == "" || !isNpmDependency(dependency) {
+				continue
+			}
+			location := ""
+			if ecosystem := dependency.ComponentDependencyEcosystem; ecosystem != nil {
+				if ecosystem.ResolvedSource != nil && !ecosystem.ResolvedSource.IsRegistry() {
+					continue
+				}
+				location = ecosystem.Path
+			}
+			key := dependency.DependencyName + "@" + dependency.DependencyVersion
+			component, ok := found[key]
+			if !ok {
+				component = &osvComponent{name: dependency.DependencyName, version: dependency.DependencyVersion, locations: make([]string, 0)}
+				found[key] = component
+			}
+			if location != "" {
+				component.locations = append(component.locations, location)
+			}
+		}
+	}
+
+	components := make([]*osvComponent, 0, len(found))
+	for _, component := range found {
+		sort.Strings(component.locations)
+		component.locations = uniqueSortedStrings(component.locations)
+		components = append(components, component)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].name != components[j].name {
+			return components[i].name < components[j].name
+		}
+		return components[i].version < components[j].version
+	})
+	return components
+}
+
+// affects 判断版本是否受影响，受影响时返回比它高的最近的修复版本
+func (x *OsvMatcher) affects(affected *models.OsvAffected, rawVersion string, version *semverVersion) (bool, string) {
+	isAffected := false
+	for _, listed := range affected.Versions {
+		if listed == rawVersion {
+			isAffected = true
+			break
+		}
+	}
+
+	var nearest *semverVersion
+	nearestFixed := ""
+	for _, osvRange := range affected.Ranges {
+		if osvRange == nil || (osvRange.Type != models.OsvRangeSemver && osvRange.Type != models.OsvRangeEcosystem) {
+			continue
+		}
+		inRange, fixed, fixedVersion := x.evaluateRange(osvRange, version)
+		if !inRange {
+			continue
+		}
+		isAffected = true
+		if fixedVersion != nil && (nearest == nil || fixedVersion.compare(nearest) < 0) {
+			nearest, nearestFixed = fixedVersion, fixed
+		}
+	}
+	return isAffected, nearestFixed
+}
+
+// osvEvent 解析了版本号的事件
+type osvEvent struct {
+	event   *models.OsvEvent
+	version *semverVersion
+}
+
+// evaluateRange 按照OSV规范中的算法计算版本是否在范围内：事件按版本排序后依次处理，
+// 版本不低于introduced时受影响，不低于fixed或者高于last_affected时不受影响。
+// 受影响时返回排在版本之后的第一个fixed，范围中有无法解析的版本时忽略整个范围，避免误报
+func (x *OsvMatcher) evaluateRange(osvRange *models.OsvRange, version *semverVersion) (bool, string, *semverVersion) {
+	events := make([]*osvEvent, 0, len(osvRange.Events))
+	for _, event := range osvRange.Events {
+		if event == nil {
+			continue
+		}
+		raw := event.Introduced
+		switch {
+		case event.Fixed != "":
+			raw = event.Fixed
+		case event.LastAffected != "":
+			raw = event.LastAffected
+		case event.Limit != "":
+			// limit只用于GIT类型的范围
+			continue
+		}
+		if raw == osvIntroducedZero && event.Introduced == osvIntroducedZero {
+			events = append(events, &osvEvent{event: event})
+			continue
+		}
+		parsed, err := parseSemver(raw)
+		if err != nil {
+			return false, "", nil
+		}
+		events = append(events, &osvEvent{event: event, version: parsed})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].version, events[j].version
+		switch {
+		case a == nil:
+			return b != nil
+		case b == nil:
+			return false
+		}
+		return a.compare(b) < 0
+	})
+
+	affected := false
+	for _, event := range events {
+		switch {
+		case event.event.Introduced != "":
+			if event.version == nil || version.compare(event.version) >= 0 {
+				affected = true
+			}
+		case event.event.Fixed != "":
+			if version.compare(event.version) >= 0 {
+				affected = false
+			}
+		case event.event.LastAffected != "":
+			if version.compare(event.version) > 0 {
+				affected = false
+			}
+		}
+	}
+	if !affected {
+		return false, "", nil
+	}
+	for _, event := range events {
+		if event.event.Fixed != "" && event.version.compare(version) > 0 {
+			return true, event.event.Fixed, event.version
+		}
+	}
+	return true, "", nil
+}
+
+// parents 依赖图中每个节点被哪些节点依赖，用来从组件反向找到根节点
+func (x *OsvMatcher) parents(graph *models.DependencyGraph) map[string][]string {
+	parents := make(map[string][]string)
+	for _, id := range graph.SortedNodeIDs() {
+		for _, edge := range graph.Nodes[id].Dependencies {
+			if edge.To != id {
+				parents[edge.To] = append(parents[edge.To], id)
+			}
+		}
+	}
+	return parents
+}
+
+// paths 返回从根节点到组件对应的所有节点的依赖路径，跳过环，按照长度和内容排序，最多osvMaxPaths条
+func (x *OsvMatcher) paths(graph *models.DependencyGraph, parents map[string][]string, component *osvComponent) [][]string {
+	roots := make(map[string]bool, len(graph.Roots))
+	for _, id := range graph.Roots {
+		roots[id] = true
+	}
+
+	paths := make([][]string, 0)
+	onPath := make(map[string]bool)
+	// reversed 从组件到当前节点的路径
+	var walk func(id string, reversed []string)
+	walk = func(id string, reversed []string) {
+		if len(paths) >= osvMaxPaths || onPath[id] {
+			return
+		}
+		reversed = append(reversed, x.nodeLabel(graph.Nodes[id]))
+		if roots[id] || len(parents[id]) == 0 {
+			path := make([]string, len(reversed))
+			for i := range reversed {
+				path[i] = reversed[len(reversed)-1-i]
+			}
+			paths = append(paths, path)
+			return
+		}
+		onPath[id] = true
+		for _, parent := range parents[id] {
+			walk(parent, reversed)
+		}
+		onPath[id] = false
+	}
+	for _, id := range graph.SortedNodeIDs() {
+		node := graph.Nodes[id]
+		if node.Name == component.name && node.Version == component.version {
+			walk(id, make([]string, 0))
+		}
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		if len(paths[i]) != len(paths[j]) {
+			return len(paths[i]) < len(paths[j])
+		}
+		return strings.Join(paths[i], " ") < strings.Join(paths[j], " ")
+	})
+	return paths
+}
+
+// nodeLabel 依赖路径中的节点名称，没有版本的节点（比如项目本身没有version）只输出名称
+func (x *OsvMatcher) nodeLabel(node *models.DependencyGraphNode) string {
+	if node.Version == "" {
+		return node.Name
+	}
+	return node.Name + "@" + node.Version
+}
+
+// uniqueSortedStrings 去掉排好序的字符串中重复的元素
+func uniqueSortedStrings(values []string) []string {
+	unique := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOsvMatcher_Match(t *testing.T) {
+	files := map[string]string{
+		"package-lock.json": `{
+  "name": "app", "version": "1.0.0", "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0", "dependencies": {"lodash": "^4.17.15", "semver": "^7.0.0", "tools": "^1.0.0"}},
+    "node_modules/lodash": {"version": "4.17.15", "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.15.tgz"},
+    "node_modules/semver": {"version": "7.3.8", "resolved": "https://registry.npmjs.org/semver/-/semver-7.3.8.tgz"},
+    "node_modules/tools": {"version": "1.0.0", "resolved": "https://registry.npmjs.org/tools/-/tools-1.0.0.tgz", "dependencies": {"lodash": "^4.17.20", "semver": "^5.0.0"}},
+    "node_modules/tools/node_modules/lodash": {"version": "4.17.20", "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.20.tgz"},
+    "node_modules/tools/node_modules/semver": {"version": "5.7.2", "resolved": "https://registry.npmjs.org/semver/-/semver-5.7.2.tgz"}
+  }
+}`,
+	}
+	for name, content := range testOsvAdvisories {
+		files["osv/"+name] = content
+	}
+	fileSystem := newWorkspaceTestFileSystem(files)
+	advisories, err := NewOsvAdvisoryParser().Parse(context.Background(), &OsvAdvisoryParserInput{Path: "osv", FileSystem: fileSystem})
+	require.NoError(t, err)
+
+	lockInput := &PackageLockJsonParserInput{PackageLockJsonPath: "package-lock.json", FileSystem: fileSystem}
+	project, err := NewPackageLockParser().Parse(context.Background(), lockInput)
+	require.NoError(t, err)
+	graph, err := NewPackageLockParser().ParseDependencyGraph(context.Background(), lockInput)
+	require.NoError(t, err)
+
+	report, err := NewOsvMatcher(advisories).Match(context.Background(), project, graph)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Advisories)
+	assert.Equal(t, 5, report.Components)
+	assert.Equal(t, []*models.VulnerabilityFinding{
+		{
+			AdvisoryID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}, Summary: "Command Injection in lodash", Severity: "HIGH",
+			PackageName: "lodash", Version: "4.17.15", FixedVersion: "4.17.21",
+			Paths: [][]string{{"app@1.0.0", "lodash@4.17.15"}}, Locations: []string{"node_modules/lodash"},
+		},
+		{
+			AdvisoryID: "GHSA-p6mc-m468-83gw", Aliases: []string{"CVE-2020-8203"}, Summary: "Prototype Pollution in lodash", Severity: "HIGH",
+			PackageName: "lodash", Version: "4.17.15", FixedVersion: "4.17.19",
+			Paths: [][]string{{"app@1.0.0", "lodash@4.17.15"}}, Locations: []string{"node_modules/lodash"},
+		},
+		{
+			AdvisoryID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}, Summary: "Command Injection in lodash", Severity: "HIGH",
+			PackageName: "lodash", Version: "4.17.20", FixedVersion: "4.17.21",
+			Paths: [][]string{{"app@1.0.0", "tools@1.0.0", "lodash@4.17.20"}}, Locations: []string{"node_modules/tools/node_modules/lodash"},
+		},
+		{
+			AdvisoryID: "GHSA-c2qf-rxjj-qqgw", Summary: "semver vulnerable to Regular Expression Denial of Service",
+			PackageName: "semver", Version: "7.3.8", FixedVersion: "7.5.2",
+			Paths: [][]string{{"app@1.0.0", "semver@7.3.8"}}, Locations: []string{"node_modules/semver"},
+		},
+	}, report.Findings)
+
+	// 没有依赖图时只输出lockfile中的位置
+	report, err = NewOsvMatcher(advisories).Match(context.Background(), project, nil)
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 4)
+	assert.Nil(t, report.Findings[0].Paths)
+	assert.Equal(t, []string{"node_modules/lodash"}, report.Findings[0].Locations)
+
+	_, err = NewOsvMatcher(advisories).Match(context.Background(), nil, nil)
+	assert.Error(t, err)
+}
+
+func TestOsvMatcher_SkipJsr(t *testing.T) {
+	files := make(map[string]string)
+	for name, content := range testOsvAdvisories {
+		files["osv/"+name] = content
+	}
+	advisories, err := NewOsvAdvisoryParser().Parse(context.Background(), &OsvAdvisoryParserInput{Path: "osv", FileSystem: newWorkspaceTestFileSystem(files)})
+	require.NoError(t, err)
+
+	module := &models.JsModule{}
+	for _, ecosystem := range []string{models.DependencyEcosystemNpm, models.DependencyEcosystemJsr} {
+		dependency := &models.JsComponentDependency{}
+		dependency.DependencyName = "lodash"
+		dependency.DependencyVersion = "4.17.15"
+		dependency.ComponentDependencyEcosystem = &models.JsComponentDependencyEcosystem{Ecosystem: ecosystem, Path: ecosystem + ":lodash@4.17.15"}
+		module.Dependencies = append(module.Dependencies, dependency)
+	}
+	project := &models.JsProject{}
+	project.SetModule("deno-app", module)
+
+	report, err := NewOsvMatcher(advisories).Match(context.Background(), project, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Components)
+	require.Len(t, report.Findings, 2)
+	for _, finding := range report.Findings {
+		assert.Equal(t, []string{"npm:lodash@4.17.15"}, finding.Locations, "同名的JSR包不参与匹配")
+	}
+}
+
+func TestOsvMatcher_Affects(t *testing.T) {
+	affected := &models.OsvAffected{
+		Package: &models.OsvPackage{Ecosystem: "npm", Name: "pkg"},
+		Ranges: []*models.OsvRange{
+			{Type: models.OsvRangeSemver, Events: []*models.OsvEvent{{Introduced: "2.0.0"}, {LastAffected: "2.3.0"}}},
+			{Type: models.OsvRangeEcosystem, Events: []*models.OsvEvent{{Fixed: "1.5.0"}, {Introduced: "1.0.0"}, {Introduced: "1.8.0-beta.1"}, {Fixed: "1.9.0"}}},
+			{Type: models.OsvRangeGit, Events: []*models.OsvEvent{{Introduced: "0"}, {Fixed: "a1b2c3d"}}},
+			{Type: models.OsvRangeSemver, Events: []*models.OsvEvent{{Introduced: "0"}, {Fixed: "not-a-version"}}},
+		},
+		Versions: []string{"3.0.0"},
+	}
+	tests := []struct {
+		version  string
+		affected bool
+		fixed    string
+	}{
+		{version: "0.9.0", affected: false},
+		{version: "1.0.0", affected: true, fixed: "1.5.0"},
+		{version: "1.4.9", affected: true, fixed: "1.5.0"},
+		{version: "1.5.0", affected: false},
+		{version: "1.8.0-beta.1", affected: true, fixed: "1.9.0"},
+		{version: "1.8.5", affected: true, fixed: "1.9.0"},
+		{version: "1.9.0", affected: false},
+		{version: "2.0.0", affected: true},
+		{version: "2.3.0", affected: true},
+		{version: "2.3.1", affected: false},
+		{version: "3.0.0", affected: true, fixed: ""},
+	}
+	matcher := NewOsvMatcher(nil)
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			version, err := parseSemver(tt.version)
+			require.NoError(t, err)
+			isAffected, fixed := matcher.affects(affected, tt.version, version)
+			assert.Equal(t, tt.affected, isAffected)
+			assert.Equal(t, tt.fixed, fixed)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// OutdatedAnalyzer 跟 npm outdated 一样分析直接依赖是否过期：对比lockfile中锁定的版本（current）、
+// 满足package.json中版本范围的最新版本（wanted）和latest对应的版本（latest）。
+// 可用的版本来自RegistryMetadataSource，所以不需要访问公网
+type OutdatedAnalyzer struct {
+	source RegistryMetadataSource
+}
+
+// NewOutdatedAnalyzer source通常是RegistryMetadataClient，这样同一个包只会查询一次
+func NewOutdatedAnalyzer(source RegistryMetadataSource) *OutdatedAnalyzer {
+	return &OutdatedAnalyzer{source: source}
+}
+
+// AnalyzeProject 读取项目根目录中的package.json和lockfile，通过ManifestMerger合并后分析
+func (x *OutdatedAnalyzer) AnalyzeProject(ctx context.Context, input *ManifestMergerInput) (*models.OutdatedReport, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	_, manifest, err := NewProjectParser().detect(ctx, input.projectInput())
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s not found", PackageJsonFileName)
+	}
+	project, err := NewManifestMerger().Parse(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return x.Analyze(ctx, manifest, project)
+}
+
+// Analyze 分析package.json中声明的每个直接依赖。project是经过ManifestMerger合并的lockfile解析结果，
+// 用来找到直接依赖锁定的版本，lockfile中没有的依赖Current为空。
+// 来自git、本地文件、workspace等非registry的依赖会被跳过
+func (x *OutdatedAnalyzer) Analyze(ctx context.Context, manifest *models.PackageJson, project *models.JsProject) (*models.OutdatedReport, error) {
+	if manifest == nil || project == nil {
+		return nil, fmt.Errorf("manifest and project cannot be nil")
+	}
+	if x.source == nil {
+		return nil, fmt.Errorf("registry metadata source cannot be nil")
+	}
+	report := &models.OutdatedReport{
+		Dependencies: make([]*models.OutdatedDependency, 0),
+		Missing:      make([]string, 0),
+		Skipped:      make([]string, 0),
+	}
+	locked := x.lockedDependencies(manifest, project)
+	missing := make(map[string]struct{})
+
+	seen := make(map[string]struct{})
+	for _, declaration := range (&ManifestMerger{}).declarations(manifest) {
+		// 同一个包在多个字段中声明时只分析优先级最高的
+		if _, ok := seen[declaration.Name]; ok {
+			continue
+		}
+		seen[declaration.Name] = struct{}{}
+
+		dependency := &models.OutdatedDependency{Name: declaration.Name, Range: declaration.Range, Scope: declaration.Scope}
+		if name, versionRange, ok := parseNpmAlias(declaration.Range); ok {
+			dependency.Alias, dependency.Name, dependency.Range = declaration.Name, name, versionRange
+		}
+		lockedDependency := locked[declaration.Name]
+		if !x.isRegistryDependency(dependency.Range, lockedDependency) {
+			report.Skipped = append(report.Skipped, declaration.Name)
+			continue
+		}
+		if lockedDependency != nil {
+			dependency.Current = lockedDependency.DependencyVersion
+			if ecosystem := lockedDependency.ComponentDependencyEcosystem; ecosystem != nil {
+				dependency.Path = ecosystem.Path
+			}
+		}
+
+		packument, err := x.source.Fetch(ctx, dependency.Name)
+		if err != nil {
+			return nil, err
+		}
+		if packument == nil {
+			missing[dependency.Name] = struct{}{}
+			continue
+		}
+		dependency.Latest = packument.DistTags[models.PackumentDistTagLatest]
+		dependency.Wanted = x.pickWanted(packument, dependency.Range)
+		dependency.WantedUpdateType = x.updateType(dependency.Current, dependency.Wanted)
+		dependency.UpdateType = x.updateType(dependency.Current, dependency.Latest)
+		if current := packument.Version(dependency.Current); current != nil {
+			dependency.Deprecated = current.Deprecated
+		}
+		report.Dependencies = append(report.Dependencies, dependency)
+	}
+
+	for name := range missing {
+		report.Missing = append(report.Missing, name)
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Skipped)
+	sort.SliceStable(report.Dependencies, func(i, j int) bool {
+		a, b := report.Dependencies[i], report.Dependencies[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Path < b.Path
+	})
+	return report, nil
+}
+
+// lockedDependencies 返回 package.json中的依赖名 -> lockfile中对应的直接依赖，通过别名安装时依赖名是别名。
+// 同名的直接依赖有多个时使用路径最短的，也就是安装在顶层的那个
+func (x *OutdatedAnalyzer) lockedDependencies(manifest *models.PackageJson, project *models.JsProject) map[string]*models.JsComponentDependency {
+	module := project.Modules[manifest.Name]
+	if module == nil && len(project.Modules) == 1 {
+		module = project.TakeFirstModule()
+	}
+	locked := make(map[string]*models.JsComponentDependency)
+	if module == nil {
+		return locked
+	}
+	for _, dependency := range module.Dependencies {
+		ecosystem := dependency.ComponentDependencyEcosystem
+		if ecosystem == nil || ecosystem.Direct == nil || !*ecosystem.Direct {
+			continue
+		}
+		name := dependency.DependencyName
+		if ecosystem.Alias != "" {
+			name = ecosystem.Alias
+		}
+		if previous, ok := locked[name]; ok && len(previous.ComponentDependencyEcosystem.Path) <= len(ecosystem.Path) {
+			continue
+		}
+		locked[name] = dependency
+	}
+	return locked
+}
+
+// isRegistryDependency 版本范围或dist-tag才需要查询registry，lockfile中解析出的来源不是registry时也跳过
+func (x *OutdatedAnalyzer) isRegistryDependency(versionRange string, locked *models.JsComponentDependency) bool {
+	if strings.Contains(versionRange, ":") || strings.Contains(versionRange, "/") {
+		return false
+	}
+	if locked == nil || locked.ComponentDependencyEcosystem == nil {
+		return true
+	}
+	ecosystem := locked.ComponentDependencyEcosystem
+	if ecosystem.Link != nil && *ecosystem.Link {
+		return false
+	}
+	return ecosystem.ResolvedSource == nil || ecosystem.ResolvedSource.IsRegistry()
+}
+
+// pickWanted 按照npm选择版本的规则返回满足声明的版本：声明是dist-tag时使用它对应的版本；
+// latest满足版本范围时使用latest，否则使用满足范围的最高版本，并且优先没有弃用的版本
+func (x *OutdatedAnalyzer) pickWanted(packument *models.Packument, versionRange string) string {
+	versionRange = strings.TrimSpace(versionRange)
+	parsedRange, err := parseSemverRange(versionRange)
+	if err != nil {
+		// 不是版本范围时按照dist-tag处理，比如 latest、next
+		return packument.DistTags[versionRange]
+	}
+	if latest := packument.DistTags[models.PackumentDistTagLatest]; latest != "" {
+		if version, err := parseSemver(latest); err == nil && packument.Version(latest) != nil && parsedRange.test(version) {
+			return latest
+		}
+	}
+
+	var wanted, wantedDeprecated *semverVersion
+	wantedName, wantedDeprecatedName := "", ""
+	for name, packumentVersion := range packument.Versions {
+		version, err := parseSemver(name)
+		if err != nil || !parsedRange.test(version) {
+			continue
+		}
+		if packumentVersion.Deprecated != "" {
+			if wantedDeprecated == nil || version.compare(wantedDeprecated) > 0 {
+				wantedDeprecated, wantedDeprecatedName = version, name
+			}
+			continue
+		}
+		if wanted == nil || version.compare(wanted) > 0 {
+			wanted, wantedName = version, name
+		}
+	}
+	if wanted != nil {
+		return wantedName
+	}
+	return wantedDeprecatedName
+}
+
+// updateType 从from升级到to的差距，to不比from新或者无法比较时为空
+func (x *OutdatedAnalyzer) updateType(from, to string) models.OutdatedUpdateType {
+	fromVersion, err := parseSemver(from)
+	if err != nil {
+		return models.OutdatedUpdateNone
+	}
+	toVersion, err := parseSemver(to)
+	if err != nil || toVersion.compare(fromVersion) <= 0 {
+		return models.OutdatedUpdateNone
+	}
+	return models.OutdatedUpdateType(diffSemver(fromVersion, toVersion))
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutdatedAnalyzer_AnalyzeProject(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"app/package.json": `{
+  "name": "app", "version": "1.0.0",
+  "dependencies": {"lodash": "^4.17.20", "ui": "npm:@acme/ui@^1.0.0", "curl-config": "github:example/curl-config", "left-pad": "~1.3.0", "react": "^18.2.0"},
+  "devDependencies": {"typescript": "next", "lodash": "^4.0.0", "eslint": "^8.0.0"},
+  "optionalDependencies": {"fsevents": "2.3.2"}
+}`,
+		"app/package-lock.json": `{
+  "name": "app", "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"lodash": "^4.17.20", "ui": "npm:@acme/ui@^1.0.0", "curl-config": "github:example/curl-config", "left-pad": "~1.3.0"}, "devDependencies": {"typescript": "next", "eslint": "^8.0.0"}, "optionalDependencies": {"fsevents": "2.3.2"}},
+    "node_modules/lodash": {"version": "4.17.20", "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.20.tgz"},
+    "node_modules/ui": {"name": "@acme/ui", "version": "1.0.0", "resolved": "https://registry.npmjs.org/@acme/ui/-/ui-1.0.0.tgz"},
+    "node_modules/curl-config": {"version": "1.0.0", "resolved": "git+ssh://git@github.com/example/curl-config.git#a1b2c3d"},
+    "node_modules/left-pad": {"version": "1.3.0", "resolved": "https://registry.npmjs.org/left-pad/-/left-pad-1.3.0.tgz"},
+    "node_modules/typescript": {"version": "5.4.0-dev.1", "dev": true, "resolved": "https://registry.npmjs.org/typescript/-/typescript-5.4.0-dev.1.tgz"},
+    "node_modules/eslint": {"version": "8.50.0", "dev": true, "resolved": "https://registry.npmjs.org/eslint/-/eslint-8.50.0.tgz"},
+    "node_modules/fsevents": {"version": "2.3.2", "optional": true, "resolved": "https://registry.npmjs.org/fsevents/-/fsevents-2.3.2.tgz"}
+  }
+}`,
+		"mirror/lodash.json": testPackuments["lodash"],
+		"mirror/@acme/ui.json": `{
+  "name": "@acme/ui",
+  "dist-tags": {"latest": "2.0.0"},
+  "versions": {"1.0.0": {"deprecated": "请升级到2.x"}, "1.1.0": {}, "1.2.0": {"deprecated": "有严重的bug"}, "2.0.0": {}}
+}`,
+		"mirror/left-pad.json": `{"name": "left-pad", "dist-tags": {"latest": "1.3.0"}, "versions": {"1.3.0": {}, "1.2.0": {}}}`,
+		"mirror/react.json":    `{"name": "react", "dist-tags": {"latest": "18.3.1"}, "versions": {"18.2.0": {}, "18.3.1": {}, "19.0.0-rc.1": {}}}`,
+		"mirror/typescript.json": `{
+  "name": "typescript",
+  "dist-tags": {"latest": "5.3.3", "next": "5.4.0-dev.2"},
+  "versions": {"5.3.3": {}, "5.4.0-dev.1": {}, "5.4.0-dev.2": {}}
+}`,
+		"mirror/eslint.json":   `{"name": "eslint", "dist-tags": {"latest": "9.0.0"}, "versions": {"8.50.0": {}, "8.57.0": {}, "9.0.0": {}}}`,
+		"mirror/fsevents.json": `{"name": "fsevents", "dist-tags": {"latest": "2.3.3"}, "versions": {"2.3.2": {}, "2.3.3": {}}}`,
+	})
+
+	client := NewRegistryMetadataClient(NewPackumentDirectorySource("mirror", fileSystem))
+	report, err := NewOutdatedAnalyzer(client).AnalyzeProject(context.Background(), &ManifestMergerInput{ProjectRootDirectory: "app", FileSystem: fileSystem})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"curl-config"}, report.Skipped)
+	assert.Equal(t, []string{}, report.Missing)
+	assert.Equal(t, []*models.OutdatedDependency{
+		{Name: "@acme/ui", Alias: "ui", Range: "^1.0.0", Scope: models.DependencyGraphEdgeProd, Path: "node_modules/ui", Current: "1.0.0", Wanted: "1.1.0", Latest: "2.0.0", WantedUpdateType: models.OutdatedUpdateMinor, UpdateType: models.OutdatedUpdateMajor, Deprecated: "请升级到2.x"},
+		{Name: "eslint", Range: "^8.0.0", Scope: models.DependencyGraphEdgeDev, Path: "node_modules/eslint", Current: "8.50.0", Wanted: "8.57.0", Latest: "9.0.0", WantedUpdateType: models.OutdatedUpdateMinor, UpdateType: models.OutdatedUpdateMajor},
+		{Name: "fsevents", Range: "2.3.2", Scope: models.DependencyGraphEdgeOptional, Path: "node_modules/fsevents", Current: "2.3.2", Wanted: "2.3.2", Latest: "2.3.3", UpdateType: models.OutdatedUpdatePatch},
+		{Name: "left-pad", Range: "~1.3.0", Scope: models.DependencyGraphEdgeProd, Path: "node_modules/left-pad", Current: "1.3.0", Wanted: "1.3.0", Latest: "1.3.0"},
+		{Name: "lodash", Range: "^4.17.20", Scope: models.DependencyGraphEdgeProd, Path: "node_modules/lodash", Current: "4.17.20", Wanted: "4.17.21", Latest: "4.17.21", WantedUpdateType: models.OutdatedUpdatePatch, UpdateType: models.OutdatedUpdatePatch},
+		{Name: "react", Range: "^18.2.0", Scope: models.DependencyGraphEdgeProd, Wanted: "18.3.1", Latest: "18.3.1"},
+		{Name: "typescript", Range: "next", Scope: models.DependencyGraphEdgeDev, Path: "node_modules/typescript", Current: "5.4.0-dev.1", Wanted: "5.4.0-dev.2", Latest: "5.3.3", WantedUpdateType: models.OutdatedUpdatePatch},
+	}, report.Dependencies)
+
+	outdated := make([]string, 0)
+	for _, dependency := range report.Outdated() {
+		outdated = append(outdated, dependency.Name)
+	}
+	assert.Equal(t, []string{"@acme/ui", "eslint", "fsevents", "lodash", "react", "typescript"}, outdated)
+}
+
+// pnpm-lock.yaml没有依赖图，直接依赖由lockfile解析器标记
+func TestOutdatedAnalyzer_AnalyzeProjectPnpm(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"app/package.json": `{"name": "app", "dependencies": {"lodash": "^4.17.20"}}`,
+		"app/pnpm-lock.yaml": `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      lodash:
+        specifier: ^4.17.20
+        version: 4.17.20
+
+packages:
+
+  lodash@4.17.20:
+    resolution: {integrity: sha512-lodash-4}
+
+snapshots:
+
+  lodash@4.17.20: {}
+`,
+		"mirror/lodash.json": testPackuments["lodash"],
+	})
+
+	client := NewRegistryMetadataClient(NewPackumentDirectorySource("mirror", fileSystem))
+	report, err := NewOutdatedAnalyzer(client).AnalyzeProject(context.Background(), &ManifestMergerInput{ProjectRootDirectory: "app", FileSystem: fileSystem})
+	require.NoError(t, err)
+	require.Len(t, report.Dependencies, 1)
+	lodash := report.Dependencies[0]
+	assert.Equal(t, "lodash", lodash.Name)
+	assert.Equal(t, models.DependencyGraphEdgeProd, lodash.Scope)
+	assert.Equal(t, "4.17.20", lodash.Current)
+	assert.Equal(t, "4.17.21", lodash.Wanted)
+}
+
+func TestOutdatedAnalyzer_PickWanted(t *testing.T) {
+	packument := &models.Packument{
+		Name:     "pkg",
+		DistTags: map[string]string{"latest": "1.2.0", "beta": "2.0.0-beta.2"},
+		Versions: map[string]*models.PackumentVersion{
+			"1.0.0":        {},
+			"1.2.0":        {},
+			"1.3.0":        {Deprecated: "误发布"},
+			"1.4.0":        {Deprecated: "误发布"},
+			"2.0.0-beta.1": {},
+			"2.0.0-beta.2": {},
+			"not-a-semver": {},
+		},
+	}
+	tests := []struct {
+		versionRange string
+		want         string
+	}{
+		{versionRange: "^1.0.0", want: "1.2.0"},
+		{versionRange: ">=1.3.0 <2", want: "1.4.0"},
+		{versionRange: "^2.0.0-beta.1", want: "2.0.0-beta.2"},
+		{versionRange: "beta", want: "2.0.0-beta.2"},
+		{versionRange: "*", want: "1.2.0"},
+		{versionRange: "^3.0.0", want: ""},
+		{versionRange: "unknown-tag", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.versionRange, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewOutdatedAnalyzer(nil).pickWanted(packument, tt.versionRange))
+		})
+	}
+}
+
+func TestOutdatedAnalyzer_Errors(t *testing.T) {
+	analyzer := NewOutdatedAnalyzer(NewPackumentDirectorySource("mirror", newWorkspaceTestFileSystem(map[string]string{})))
+	_, err := analyzer.AnalyzeProject(context.Background(), nil)
+	assert.Error(t, err)
+	_, err = analyzer.Analyze(context.Background(), nil, &models.JsProject{})
+	assert.Error(t, err)
+	_, err = NewOutdatedAnalyzer(nil).Analyze(context.Background(), &models.PackageJson{}, &models.JsProject{})
+	assert.Error(t, err)
+
+	// 元数据中没有的包
+	report, err := analyzer.Analyze(context.Background(), &models.PackageJson{Name: "app", Dependencies: models.Dependencies{"lodash": "^4.0.0"}}, &models.JsProject{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lodash"}, report.Missing)
+	assert.Empty(t, report.Dependencies)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"path"
+	"sort"
 
 	"github.com/scagogogo/package-json-parser/pkg/models"
 	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
@@ -42,7 +45,7 @@ func wrapError(stage, msg string, err error) *PackageJsonParserError {
 type PackageJsonParser struct {
 }
 
-var _ parser.Parser[*PackageJsonParserInput, *models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem] = &PackageJsonParser{}
+var _ parser.Parser[*PackageJsonParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &PackageJsonParser{}
 
 // PackageJsonParserName 是此解析器的唯一标识名称
 const PackageJsonParserName = "package-json-parser"
@@ -68,65 +71,98 @@ func (x *PackageJsonParser) Init(ctx context.Context) error {
 // 返回:
 //   - 解析后的项目对象
 //   - 如果解析过程中出现错误，则返回错误
-func (x *PackageJsonParser) Parse(ctx context.Context, input *PackageJsonParserInput) (*baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem], error) {
-
-	// 验证输入
-	if input == nil {
-		return nil, wrapError("input validation", "input cannot be nil", nil)
+func (x *PackageJsonParser) Parse(ctx context.Context, input *PackageJsonParserInput) (*models.JsProject, error) {
+	packageJson, err := x.ParseManifest(ctx, input)
+	if err != nil {
+		return nil, err
 	}
 
-	// 如果是通过内容传入的，不需要检查路径
-	if input.PackageJsonContent == "" && input.PackageJsonPath == "" {
-		return nil, wrapError("input validation", "package.json path cannot be empty", nil)
+	// 验证解析结果
+	if packageJson.Name == "" {
+		return nil, wrapError("content validation", "package.json must have a name field", nil)
 	}
 
-	packageJsonBytes, err := input.Read(ctx)
+	input, err = x.withPnpmWorkspace(ctx, input)
 	if err != nil {
-		return nil, wrapError("file reading", fmt.Sprintf("failed to read package.json from %s", input.PackageJsonPath), err)
+		return nil, err
 	}
+	return x.buildProject(packageJson, input), nil
+}
 
-	// 检查文件大小
-	if len(packageJsonBytes) == 0 {
-		return nil, wrapError("file validation", "package.json is empty", nil)
+// withPnpmWorkspace 输入设置了ResolvePnpmWorkspace并且没有提供pnpm workspace的信息时，查找package.json所属的pnpm-workspace.yaml，
+// 返回补充了catalog和各个workspace版本的输入副本，找不到这个文件时原样返回输入
+func (x *PackageJsonParser) withPnpmWorkspace(ctx context.Context, input *PackageJsonParserInput) (*PackageJsonParserInput, error) {
+	if !input.ResolvePnpmWorkspace || input.PnpmWorkspace != nil || input.WorkspacePackageVersions != nil {
+		return input, nil
+	}
+	directory, ok := input.findPnpmWorkspaceDirectory()
+	if !ok {
+		return input, nil
 	}
 
-	packageJson := &models.PackageJson{}
-	err = json.Unmarshal(packageJsonBytes, &packageJson)
+	fileSystem, err := (&WorkspaceParserInput{ProjectRootDirectory: directory, FileSystem: input.FileSystem}).rootFileSystem()
 	if err != nil {
-		return nil, wrapError("json parsing", "failed to parse package.json content", err)
+		return nil, wrapError("pnpm workspace", fmt.Sprintf("failed to open %s", directory), err)
+	}
+	pnpmWorkspace, err := NewPnpmWorkspaceParser().Parse(ctx, &PnpmWorkspaceParserInput{PnpmWorkspacePath: PnpmWorkspaceFileName, FileSystem: fileSystem})
+	if err != nil {
+		return nil, wrapError("pnpm workspace", fmt.Sprintf("failed to load %s", PnpmWorkspaceFileName), err)
 	}
 
-	// 验证解析结果
-	if packageJson.Name == "" {
-		return nil, wrapError("content validation", "package.json must have a name field", nil)
+	resolved := *input
+	resolved.PnpmWorkspace = pnpmWorkspace
+	resolved.WorkspacePackageVersions = x.pnpmWorkspaceVersions(ctx, fileSystem, pnpmWorkspace)
+	return &resolved, nil
+}
+
+// pnpmWorkspaceVersions 收集pnpm workspace中各个包（包括根目录）的名称到版本的映射，这些package.json只是用来替换 workspace: 声明的，
+// 无法解析或者没有名称的会被忽略，名称重复时使用路径排序后的第一个
+func (x *PackageJsonParser) pnpmWorkspaceVersions(ctx context.Context, fileSystem fs.FS, pnpmWorkspace *models.PnpmWorkspace) map[string]string {
+	versions := make(map[string]string)
+	// glob无效时只是无法替换对这些包的 workspace: 声明，仍然使用根目录的package.json
+	directories, _ := expandWorkspacePatterns(fileSystem, pnpmWorkspace.Packages)
+	for _, directory := range append([]string{"."}, directories...) {
+		packageJson, err := x.ParseManifest(ctx, &PackageJsonParserInput{PackageJsonPath: path.Join(directory, PackageJsonFileName), FileSystem: fileSystem})
+		if err != nil || packageJson.Name == "" {
+			continue
+		}
+		if _, ok := versions[packageJson.Name]; !ok {
+			versions[packageJson.Name] = packageJson.Version
+		}
 	}
+	return versions
+}
 
-	project := &baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]{}
+// buildProject 把解析后的package.json转换为只有一个模块的项目，模块的依赖是声明的依赖和开发依赖
+func (x *PackageJsonParser) buildProject(packageJson *models.PackageJson, input *PackageJsonParserInput) *models.JsProject {
+	project := &models.JsProject{}
 	project.Name = packageJson.Name
 	project.Version = packageJson.Version
 
 	// 创建模块
-	module := &baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]{}
+	module := &models.JsModule{}
 	module.Name = packageJson.Name
 	module.Version = packageJson.Version
 
 	// 设置模块生态系统信息
-	moduleEcosystem := &models.PackageLockModuleEcosystem{}
+	moduleEcosystem := &models.JsModuleEcosystem{}
 	// 可以添加更多模块生态系统信息
 	module.ModuleEcosystem = moduleEcosystem
 
 	// 处理依赖项
-	dependencies := make([]*baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem], 0)
+	dependencies := make([]*models.JsComponentDependency, 0)
 
-	// 处理常规依赖
-	for depName, depVersion := range packageJson.Dependencies {
-		dependency := x.createDependency(depName, depVersion, false)
+	// 处理常规依赖，按名称排序保证输出顺序稳定
+	for _, depName := range sortedDependencyNames(packageJson.Dependencies) {
+		dependency := x.createDependency(depName, packageJson.Dependencies[depName], false)
+		x.resolvePnpmDependency(dependency, input)
 		dependencies = append(dependencies, dependency)
 	}
 
 	// 处理开发依赖
-	for depName, depVersion := range packageJson.DevDependencies {
-		dependency := x.createDependency(depName, depVersion, true)
+	for _, depName := range sortedDependencyNames(packageJson.DevDependencies) {
+		dependency := x.createDependency(depName, packageJson.DevDependencies[depName], true)
+		x.resolvePnpmDependency(dependency, input)
 		dependencies = append(dependencies, dependency)
 	}
 
@@ -136,11 +172,53 @@ func (x *PackageJsonParser) Parse(ctx context.Context, input *PackageJsonParserI
 	project.SetModule(packageJson.Name, module)
 
 	// 设置项目生态系统信息
-	projectEcosystem := &models.PackageLockProjectEcosystem{}
+	projectEcosystem := &models.JsProjectEcosystem{}
 	// 可以添加更多项目特定的信息
 	project.ProjectEcosystem = projectEcosystem
 
-	return project, nil
+	return project
+}
+
+// ParseManifest 把package.json解析为文件本身的模型，不转换为项目，也不要求必须有name字段，
+// 比如monorepo的根目录和私有项目的package.json经常没有name
+func (x *PackageJsonParser) ParseManifest(ctx context.Context, input *PackageJsonParserInput) (*models.PackageJson, error) {
+	// 验证输入
+	if input == nil {
+		return nil, wrapError("input validation", "input cannot be nil", nil)
+	}
+
+	// 如果是通过内容、reader或者项目根目录传入的，不需要检查路径
+	if input.PackageJsonContent == "" && input.PackageJsonReader == nil && input.PackageJsonPath == "" && input.ProjectRootDirectory == "" && input.FileSystem == nil {
+		return nil, wrapError("input validation", "package.json path cannot be empty", nil)
+	}
+
+	packageJsonBytes, err := input.Read(ctx)
+	if err != nil {
+		return nil, wrapError("file reading", fmt.Sprintf("failed to read package.json from %s", input.PackageJsonPath), err)
+	}
+
+	// 检查文件大小
+	if len(packageJsonBytes) == 0 {
+		return nil, wrapError("file validation", "package.json is empty", nil)
+	}
+
+	packageJson := &models.PackageJson{}
+	err = json.Unmarshal(packageJsonBytes, &packageJson)
+	if err != nil {
+		return nil, wrapError("json parsing", "failed to parse package.json content", err)
+	}
+
+	return packageJson, nil
+}
+
+// sortedDependencyNames 返回按名称排序后的依赖名称列表
+func sortedDependencyNames(dependencies models.Dependencies) []string {
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // createDependency 创建一个表示依赖关系的对象
@@ -151,13 +229,13 @@ func (x *PackageJsonParser) Parse(ctx context.Context, input *PackageJsonParserI
 //
 // 返回:
 //   - 依赖关系对象
-func (x *PackageJsonParser) createDependency(name string, version string, isDev bool) *baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem] {
-	dependency := &baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem]{}
+func (x *PackageJsonParser) createDependency(name string, version string, isDev bool) *models.JsComponentDependency {
+	dependency := &models.JsComponentDependency{}
 	dependency.DependencyName = name
 	dependency.DependencyVersion = version
 
 	// 设置依赖生态系统信息
-	ecosystem := &models.PackageLockComponentDependencyEcosystem{}
+	ecosystem := &models.JsComponentDependencyEcosystem{Ecosystem: models.DependencyEcosystemNpm}
 	if isDev {
 		dev := true
 		ecosystem.Dev = &dev
@@ -167,6 +245,20 @@ func (x *PackageJsonParser) createDependency(name string, version string, isDev
 	return dependency
 }
 
+// resolvePnpmDependency 输入中提供了pnpm workspace的信息时，把 catalog: 和 workspace: 声明替换为具体的版本范围，
+// 原始的声明保存在Specifier中，无法替换的声明保持原样
+func (x *PackageJsonParser) resolvePnpmDependency(dependency *models.JsComponentDependency, input *PackageJsonParserInput) {
+	if input.PnpmWorkspace == nil && input.WorkspacePackageVersions == nil {
+		return
+	}
+	resolved, ok := resolvePnpmSpecifier(dependency.DependencyName, dependency.DependencyVersion, input.PnpmWorkspace, input.WorkspacePackageVersions)
+	if !ok {
+		return
+	}
+	dependency.ComponentDependencyEcosystem.Specifier = dependency.DependencyVersion
+	dependency.DependencyVersion = resolved
+}
+
 // parseComponent 解析组件信息
 // 参数:
 //   - packageName: 包名
@@ -174,13 +266,13 @@ func (x *PackageJsonParser) createDependency(name string, version string, isDev
 //
 // 返回:
 //   - 组件对象
-func (x *PackageJsonParser) parseComponent(packageName string, version string) *baseModels.Component[*models.PackageLockComponentEcosystem] {
-	component := &baseModels.Component[*models.PackageLockComponentEcosystem]{}
+func (x *PackageJsonParser) parseComponent(packageName string, version string) *baseModels.Component[*models.JsComponentEcosystem] {
+	component := &baseModels.Component[*models.JsComponentEcosystem]{}
 	component.Name = packageName
 	component.Version = version
 
 	// 设置生态系统特定信息
-	ecosystem := &models.PackageLockComponentEcosystem{}
+	ecosystem := &models.JsComponentEcosystem{}
 	// 这里可以设置更多生态系统特定的信息
 	component.ComponentEcosystem = ecosystem
 
@@ -2,8 +2,13 @@ package parser
 
 import (
 	"context"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
 )
 
 const PackageJsonFileName = "package.json"
@@ -12,6 +17,23 @@ type PackageJsonParserInput struct {
 	PackageJsonPath      string
 	PackageJsonContent   string
 	ProjectRootDirectory string
+
+	// PackageJsonReader 从reader中读取package.json的内容，只能被读取一次
+	PackageJsonReader io.Reader
+
+	// FileSystem 指定后PackageJsonPath和ProjectRootDirectory都是这个文件系统中的路径，比如embed.FS或者zip.Reader
+	FileSystem fs.FS
+
+	// PnpmWorkspace pnpm-workspace.yaml的内容，设置后 catalog: 和 catalog:<name> 声明会被替换为catalog中的版本范围
+	PnpmWorkspace *models.PnpmWorkspace
+
+	// WorkspacePackageVersions workspace中各个项目的名称到版本的映射，设置后 workspace: 声明会像pnpm publish那样被替换为具体的版本
+	WorkspacePackageVersions map[string]string
+
+	// ResolvePnpmWorkspace 设置后，如果PnpmWorkspace和WorkspacePackageVersions都没有提供，并且package.json是从文件读取的，
+	// 会从package.json所在的目录开始向上查找pnpm-workspace.yaml，使用其中的catalog和各个workspace的版本替换声明，
+	// 所以直接解析workspace中某个包的package.json也可以。workspace中无法解析的package.json会被忽略
+	ResolvePnpmWorkspace bool
 }
 
 func (x *PackageJsonParserInput) Read(ctx context.Context) ([]byte, error) {
@@ -20,18 +42,78 @@ func (x *PackageJsonParserInput) Read(ctx context.Context) ([]byte, error) {
 		return []byte(x.PackageJsonContent), nil
 	}
 
+	if x.PackageJsonReader != nil {
+		return readInputReader(x.PackageJsonReader)
+	}
+
 	if x.PackageJsonPath != "" {
-		bytes, err := os.ReadFile(x.PackageJsonPath)
+		bytes, err := readInputFile(x.FileSystem, x.PackageJsonPath)
 		if err != nil {
 			return nil, err
 		}
 		return bytes, nil
 	}
 
-	packageJsonPath := filepath.Join(x.ProjectRootDirectory, PackageJsonFileName)
-	bytes, err := os.ReadFile(packageJsonPath)
+	packageJsonPath := joinInputPath(x.FileSystem, x.ProjectRootDirectory, PackageJsonFileName)
+	bytes, err := readInputFile(x.FileSystem, packageJsonPath)
 	if err != nil {
 		return nil, err
 	}
 	return bytes, nil
 }
+
+// manifestDirectory 按照Read的优先级返回package.json所在的目录，内容直接传入或者从reader读取时没有目录
+func (x *PackageJsonParserInput) manifestDirectory() (string, bool) {
+	if x.PackageJsonContent != "" || x.PackageJsonReader != nil {
+		return "", false
+	}
+	if x.PackageJsonPath != "" {
+		if x.FileSystem == nil {
+			return filepath.Dir(x.PackageJsonPath), true
+		}
+		return path.Dir(toFsPath(x.PackageJsonPath)), true
+	}
+	if x.FileSystem == nil {
+		return x.ProjectRootDirectory, x.ProjectRootDirectory != ""
+	}
+	return toFsPath(x.ProjectRootDirectory), true
+}
+
+// findPnpmWorkspaceDirectory 从package.json所在的目录开始向上查找包含pnpm-workspace.yaml的目录，跟pnpm查找workspace根目录的方式一样
+func (x *PackageJsonParserInput) findPnpmWorkspaceDirectory() (string, bool) {
+	directory, ok := x.manifestDirectory()
+	if !ok {
+		return "", false
+	}
+	if x.FileSystem == nil {
+		absDirectory, err := filepath.Abs(directory)
+		if err != nil {
+			return "", false
+		}
+		directory = absDirectory
+	}
+	for {
+		filePath := joinInputPath(x.FileSystem, directory, PnpmWorkspaceFileName)
+		var info fs.FileInfo
+		var err error
+		if x.FileSystem == nil {
+			info, err = os.Stat(filePath)
+		} else {
+			info, err = fs.Stat(x.FileSystem, filePath)
+		}
+		if err == nil && !info.IsDir() {
+			return directory, true
+		}
+
+		var parent string
+		if x.FileSystem == nil {
+			parent = filepath.Dir(directory)
+		} else {
+			parent = path.Dir(directory)
+		}
+		if parent == directory {
+			return "", false
+		}
+		directory = parent
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/scagogogo/package-json-parser/pkg/models"
-	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,7 +25,7 @@ func TestPackageJsonParser_Parse(t *testing.T) {
 		name      string
 		content   string
 		wantError bool
-		checkFunc func(t *testing.T, project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem])
+		checkFunc func(t *testing.T, project *models.JsProject)
 	}{
 		{
 			name: "基本的package.json文件",
@@ -42,7 +43,7 @@ func TestPackageJsonParser_Parse(t *testing.T) {
 				}
 			}`,
 			wantError: false,
-			checkFunc: func(t *testing.T, project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]) {
+			checkFunc: func(t *testing.T, project *models.JsProject) {
 				assert.NotNil(t, project)
 				assert.Equal(t, "basic-package", project.Name)
 				assert.Equal(t, "1.0.0", project.Version)
@@ -50,7 +51,7 @@ func TestPackageJsonParser_Parse(t *testing.T) {
 				// 验证模块
 				assert.Len(t, project.Modules, 1)
 				// 查找特定名称的模块
-				var module *baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]
+				var module *models.JsModule
 				for _, m := range project.Modules {
 					if m.Name == "basic-package" {
 						module = m
@@ -65,7 +66,7 @@ func TestPackageJsonParser_Parse(t *testing.T) {
 				assert.Len(t, module.Dependencies, 3) // 2个dependencies + 1个devDependencies
 
 				// 查找指定依赖
-				var lodashDep, reactDep, jestDep *baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem]
+				var lodashDep, reactDep, jestDep *models.JsComponentDependency
 				for i := range module.Dependencies {
 					switch module.Dependencies[i].DependencyName {
 					case "lodash":
@@ -114,11 +115,11 @@ func TestPackageJsonParser_Parse(t *testing.T) {
 				"bundledDependencies": ["moment"]
 			}`,
 			wantError: false,
-			checkFunc: func(t *testing.T, project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]) {
+			checkFunc: func(t *testing.T, project *models.JsProject) {
 				assert.NotNil(t, project)
 
 				// 查找特定名称的模块
-				var module *baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]
+				var module *models.JsModule
 				for _, m := range project.Modules {
 					if m.Name == "all-deps-package" {
 						module = m
@@ -130,7 +131,7 @@ func TestPackageJsonParser_Parse(t *testing.T) {
 				assert.Len(t, module.Dependencies, 2) // 1 regular + 1 dev
 
 				// 检查各类依赖
-				var expressDep, eslintDep *baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem]
+				var expressDep, eslintDep *models.JsComponentDependency
 				for i := range module.Dependencies {
 					switch module.Dependencies[i].DependencyName {
 					case "express":
@@ -166,13 +167,13 @@ func TestPackageJsonParser_Parse(t *testing.T) {
 				}
 			}`,
 			wantError: false,
-			checkFunc: func(t *testing.T, project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]) {
+			checkFunc: func(t *testing.T, project *models.JsProject) {
 				assert.NotNil(t, project)
 
 				// 验证模块
 				assert.Len(t, project.Modules, 1)
 				// 查找特定名称的模块
-				var module *baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]
+				var module *models.JsModule
 				for _, m := range project.Modules {
 					if m.Name == "monorepo-root" {
 						module = m
@@ -266,6 +267,162 @@ func TestPackageJsonParser_ParseErrors(t *testing.T) {
 	assert.Nil(t, project)
 }
 
+// 测试从reader、fs.FS和项目根目录读取package.json
+func TestPackageJsonParser_InputSources(t *testing.T) {
+	content := `{"name": "from-source", "version": "1.0.0", "dependencies": {"lodash": "^4.17.21"}}`
+	fileSystem := fstest.MapFS{
+		"app/package.json": &fstest.MapFile{Data: []byte(content)},
+	}
+
+	tests := []struct {
+		name  string
+		input *PackageJsonParserInput
+	}{
+		{name: "reader", input: &PackageJsonParserInput{PackageJsonReader: strings.NewReader(content)}},
+		{name: "fs.FS中的路径", input: &PackageJsonParserInput{FileSystem: fileSystem, PackageJsonPath: "app/package.json"}},
+		{name: "fs.FS中的项目根目录", input: &PackageJsonParserInput{FileSystem: fileSystem, ProjectRootDirectory: "app"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := (&PackageJsonParser{}).Parse(context.Background(), tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, "from-source", project.Name)
+			assert.Len(t, project.TakeFirstModule().Dependencies, 1)
+		})
+	}
+
+	// 本地文件系统中的项目根目录
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PackageJsonFileName), []byte(content), 0644))
+	project, err := (&PackageJsonParser{}).Parse(context.Background(), &PackageJsonParserInput{ProjectRootDirectory: dir})
+	require.NoError(t, err)
+	assert.Equal(t, "from-source", project.Name)
+}
+
+// 测试替换pnpm的 catalog: 和 workspace: 声明
+func TestPackageJsonParser_PnpmSpecifiers(t *testing.T) {
+	content := `{
+  "name": "@demo/web",
+  "version": "0.1.0",
+  "dependencies": {
+    "react": "catalog:",
+    "lodash": "catalog:legacy",
+    "@demo/shared": "workspace:^",
+    "@demo/utils": "workspace:*",
+    "@demo/icons": "workspace:~1.2.0",
+    "missing": "catalog:",
+    "express": "^4.18.0"
+  },
+  "devDependencies": {
+    "shared-alias": "workspace:@demo/shared@~"
+  }
+}`
+	workspace := &models.PnpmWorkspace{
+		Catalog:  map[string]string{"react": "^18.2.0"},
+		Catalogs: map[string]map[string]string{"legacy": {"lodash": "^3.10.0"}},
+	}
+
+	project, err := (&PackageJsonParser{}).Parse(context.Background(), &PackageJsonParserInput{
+		PackageJsonContent:       content,
+		PnpmWorkspace:            workspace,
+		WorkspacePackageVersions: map[string]string{"@demo/shared": "2.1.0", "@demo/utils": "0.3.0"},
+	})
+	require.NoError(t, err)
+
+	versions := make(map[string]string)
+	specifiers := make(map[string]string)
+	for _, dependency := range project.TakeFirstModule().Dependencies {
+		versions[dependency.DependencyName] = dependency.DependencyVersion
+		specifiers[dependency.DependencyName] = dependency.ComponentDependencyEcosystem.Specifier
+	}
+	assert.Equal(t, map[string]string{
+		"react":        "^18.2.0",
+		"lodash":       "^3.10.0",
+		"@demo/shared": "^2.1.0",
+		"@demo/utils":  "0.3.0",
+		"@demo/icons":  "~1.2.0",
+		"missing":      "catalog:",
+		"express":      "^4.18.0",
+		"shared-alias": "npm:@demo/shared@~2.1.0",
+	}, versions)
+	assert.Equal(t, "catalog:legacy", specifiers["lodash"])
+	assert.Equal(t, "workspace:^", specifiers["@demo/shared"])
+	assert.Empty(t, specifiers["missing"], "无法替换的声明保持原样")
+	assert.Empty(t, specifiers["express"])
+
+	// 没有提供workspace信息时不做替换
+	project, err = (&PackageJsonParser{}).Parse(context.Background(), &PackageJsonParserInput{PackageJsonContent: content})
+	require.NoError(t, err)
+	for _, dependency := range project.TakeFirstModule().Dependencies {
+		assert.Empty(t, dependency.ComponentDependencyEcosystem.Specifier)
+	}
+}
+
+func TestPackageJsonParser_ResolvePnpmWorkspace(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"repo/package.json":                 `{"private": true, "devDependencies": {"typescript": "catalog:"}}`,
+		"repo/pnpm-workspace.yaml":          "packages:\n  - packages/*\ncatalog:\n  react: ^18.2.0\n  typescript: ^5.4.0\n",
+		"repo/packages/web/package.json":    `{"name": "@demo/web", "dependencies": {"react": "catalog:", "@demo/shared": "workspace:^"}}`,
+		"repo/packages/shared/package.json": `{"name": "@demo/shared", "version": "2.1.0"}`,
+		"repo/packages/broken/package.json": `{"name": `,
+	})
+
+	t.Run("解析workspace中的包", func(t *testing.T) {
+		for name, input := range map[string]*PackageJsonParserInput{
+			"路径":    {PackageJsonPath: "repo/packages/web/package.json", FileSystem: fileSystem, ResolvePnpmWorkspace: true},
+			"项目根目录": {ProjectRootDirectory: "repo/packages/web", FileSystem: fileSystem, ResolvePnpmWorkspace: true},
+		} {
+			t.Run(name, func(t *testing.T) {
+				project, err := (&PackageJsonParser{}).Parse(context.Background(), input)
+				require.NoError(t, err, "workspace中无法解析的package.json被忽略")
+				versions := make(map[string]string)
+				for _, dependency := range project.TakeFirstModule().Dependencies {
+					versions[dependency.DependencyName] = dependency.DependencyVersion
+				}
+				assert.Equal(t, map[string]string{"react": "^18.2.0", "@demo/shared": "^2.1.0"}, versions)
+				assert.Nil(t, input.PnpmWorkspace, "不修改调用方的输入")
+			})
+		}
+	})
+
+	t.Run("本地目录", func(t *testing.T) {
+		dir := t.TempDir()
+		for name, content := range map[string]string{
+			"pnpm-workspace.yaml":          "packages:\n  - packages/*\ncatalog:\n  react: ^18.2.0\n",
+			"packages/web/package.json":    `{"name": "@demo/web", "dependencies": {"react": "catalog:", "@demo/shared": "workspace:*"}}`,
+			"packages/shared/package.json": `{"name": "@demo/shared", "version": "2.1.0"}`,
+		} {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+		}
+		project, err := (&PackageJsonParser{}).Parse(context.Background(), &PackageJsonParserInput{ProjectRootDirectory: filepath.Join(dir, "packages", "web"), ResolvePnpmWorkspace: true})
+		require.NoError(t, err)
+		versions := make(map[string]string)
+		for _, dependency := range project.TakeFirstModule().Dependencies {
+			versions[dependency.DependencyName] = dependency.DependencyVersion
+		}
+		assert.Equal(t, map[string]string{"react": "^18.2.0", "@demo/shared": "2.1.0"}, versions)
+	})
+
+	t.Run("默认不读取pnpm-workspace.yaml", func(t *testing.T) {
+		project, err := (&PackageJsonParser{}).Parse(context.Background(), &PackageJsonParserInput{PackageJsonPath: "repo/packages/web/package.json", FileSystem: fileSystem})
+		require.NoError(t, err)
+		for _, dependency := range project.TakeFirstModule().Dependencies {
+			assert.Empty(t, dependency.ComponentDependencyEcosystem.Specifier)
+		}
+	})
+
+	t.Run("pnpm-workspace.yaml格式错误", func(t *testing.T) {
+		fileSystem := newWorkspaceTestFileSystem(map[string]string{
+			"package.json":        `{"name": "app"}`,
+			"pnpm-workspace.yaml": "packages: [\n",
+		})
+		_, err := (&PackageJsonParser{}).Parse(context.Background(), &PackageJsonParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem, ResolvePnpmWorkspace: true})
+		assert.Error(t, err)
+	})
+}
+
 // 测试createDependency函数
 func TestPackageJsonParser_CreateDependency(t *testing.T) {
 	parser := &PackageJsonParser{}
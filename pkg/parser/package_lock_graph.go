@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// ParseDependencyGraph 解析package-lock.json并构建依赖图，
+// 每个安装路径是一个节点，依赖按照node的模块查找规则（从当前目录逐级向上查找node_modules）解析到节点
+func (x *PackageLockParser) ParseDependencyGraph(ctx context.Context, input *PackageLockJsonParserInput) (*models.DependencyGraph, error) {
+	lock, err := x.ParseLockfile(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return buildPackageLockDependencyGraph(lock), nil
+}
+
+// buildPackageLockDependencyGraph 从package-lock.json构建依赖图，
+// 有packages字段（lockfileVersion 2、3）时使用packages，否则把lockfileVersion 1的依赖树展开为安装路径
+func buildPackageLockDependencyGraph(lock *models.PackageLock) *models.DependencyGraph {
+	graph := models.NewDependencyGraph()
+
+	packages := lock.Packages
+	if len(packages) == 0 {
+		packages = flattenPackageLockDependencies(lock.Dependencies)
+	} else if _, ok := packages[""]; ok {
+		// packages中记录了项目本身，它就是依赖图的根
+		graph.Roots = []string{""}
+	}
+
+	pkgPaths := make([]string, 0, len(packages))
+	for pkgPath, pkg := range packages {
+		// 链接本身不是节点，解析依赖时会跟随链接找到目标
+		if pkg == nil || (pkg.Link != nil && *pkg.Link) {
+			continue
+		}
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+
+	for _, pkgPath := range pkgPaths {
+		pkg := packages[pkgPath]
+		node := &models.DependencyGraphNode{
+			ID:        pkgPath,
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Resolved:  pkg.Resolved,
+			Integrity: pkg.Integrity,
+		}
+		if pkgPath == "" {
+			if node.Name == "" {
+				node.Name = lock.Name
+			}
+			if node.Version == "" {
+				node.Version = lock.Version
+			}
+		} else if node.Name == "" {
+			node.Name = extractPackageNameFromPath(pkgPath)
+		}
+		graph.Nodes[pkgPath] = node
+	}
+
+	for _, pkgPath := range pkgPaths {
+		node := graph.Nodes[pkgPath]
+		pkg := packages[pkgPath]
+		addPackageLockEdges(graph, packages, node, pkg.Dependencies, models.DependencyGraphEdgeProd)
+		addPackageLockEdges(graph, packages, node, pkg.DevDependencies, models.DependencyGraphEdgeDev)
+		addPackageLockEdges(graph, packages, node, pkg.OptionalDependencies, models.DependencyGraphEdgeOptional)
+		addPackageLockEdges(graph, packages, node, pkg.PeerDependencies, models.DependencyGraphEdgePeer)
+	}
+
+	finishDependencyGraph(graph)
+	return graph
+}
+
+// addPackageLockEdges 解析节点声明的一组依赖，可选依赖和peer依赖没有安装是正常的，不算悬空
+func addPackageLockEdges(graph *models.DependencyGraph, packages map[string]*models.PackageLockPackage, node *models.DependencyGraphNode, dependencies models.Dependencies, edgeType models.DependencyGraphEdgeType) {
+	for _, name := range sortedDependencyNames(dependencies) {
+		target, ok := resolvePackageLockPath(packages, node.ID, name)
+		if !ok {
+			if edgeType == models.DependencyGraphEdgeProd || edgeType == models.DependencyGraphEdgeDev {
+				graph.Dangling = append(graph.Dangling, &models.DanglingDependency{From: node.ID, Name: name, Range: dependencies[name], Type: edgeType})
+			}
+			continue
+		}
+
+		// lockfileVersion 1的requires不区分可选依赖，根据目标包的标记判断
+		currentType := edgeType
+		if optional := packages[target].Optional; currentType == models.DependencyGraphEdgeProd && optional != nil && *optional {
+			currentType = models.DependencyGraphEdgeOptional
+		}
+		node.Dependencies = append(node.Dependencies, &models.DependencyGraphEdge{Name: name, Range: dependencies[name], Type: currentType, To: target})
+	}
+}
+
+// resolvePackageLockPath 按照node的模块查找规则，从fromPath开始逐级向上查找name对应的安装路径，
+// 找到的是链接（比如workspace）时返回链接的目标
+func resolvePackageLockPath(packages map[string]*models.PackageLockPackage, fromPath string, name string) (string, bool) {
+	dir := fromPath
+	for {
+		candidate := path.Join(dir, "node_modules", name)
+		if pkg, ok := packages[candidate]; ok && pkg != nil {
+			if pkg.Link != nil && *pkg.Link {
+				if target, ok := packages[pkg.Resolved]; ok && target != nil {
+					return pkg.Resolved, true
+				}
+				return "", false
+			}
+			return candidate, true
+		}
+		if dir == "" {
+			return "", false
+		}
+		dir = path.Dir(dir)
+		if dir == "." {
+			dir = ""
+		}
+	}
+}
+
+// flattenPackageLockDependencies 把lockfileVersion 1嵌套的依赖树展开为安装路径到包的映射，
+// 跟packages字段的结构保持一致，requires作为包的依赖
+func flattenPackageLockDependencies(dependencies map[string]*models.PackageLockDependency) map[string]*models.PackageLockPackage {
+	packages := make(map[string]*models.PackageLockPackage)
+	var flatten func(parentPath string, dependencies map[string]*models.PackageLockDependency)
+	flatten = func(parentPath string, dependencies map[string]*models.PackageLockDependency) {
+		for name, dep := range dependencies {
+			if dep == nil {
+				continue
+			}
+			pkgPath := path.Join(parentPath, "node_modules", name)
+			pkg := &models.PackageLockPackage{
+				Version:      dep.Version,
+				Resolved:     dep.Resolved,
+				Integrity:    dep.Integrity,
+				Dev:          dep.Dev,
+				Optional:     dep.Optional,
+				Dependencies: dep.Requires,
+			}
+			// 通过别名安装时version是 npm:真实包名@版本
+			if realName, version, ok := parseNpmAlias(dep.Version); ok {
+				pkg.Name = realName
+				pkg.Version = version
+			}
+			packages[pkgPath] = pkg
+			flatten(pkgPath, dep.Dependencies)
+		}
+	}
+	flatten("", dependencies)
+	return packages
+}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageLockParser_ParseDependencyGraph(t *testing.T) {
+	content := `{
+  "name": "graph-app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "graph-app",
+      "version": "1.0.0",
+      "workspaces": ["packages/*"],
+      "dependencies": {"a": "^1.0.0", "missing": "^1.0.0"},
+      "devDependencies": {"b": "^2.0.0"},
+      "optionalDependencies": {"fsevents": "^2.0.0"}
+    },
+    "node_modules/a": {
+      "version": "1.0.0",
+      "dependencies": {"b": "^1.0.0", "c": "^1.0.0"}
+    },
+    "node_modules/a/node_modules/b": {"version": "1.5.0"},
+    "node_modules/b": {"version": "2.0.0", "dev": true},
+    "node_modules/c": {"version": "1.0.0", "peerDependencies": {"react": "*"}},
+    "node_modules/my-ws": {"resolved": "packages/my-ws", "link": true},
+    "packages/my-ws": {
+      "name": "my-ws",
+      "version": "0.1.0",
+      "dependencies": {"a": "^1.0.0"}
+    }
+  }
+}`
+	graph, err := NewPackageLockParser().ParseDependencyGraph(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: content})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{""}, graph.Roots)
+	assert.Equal(t, []string{"", "node_modules/a", "node_modules/a/node_modules/b", "node_modules/b", "node_modules/c", "packages/my-ws"}, graph.SortedNodeIDs(), "链接不是节点")
+
+	root := graph.Nodes[""]
+	assert.Equal(t, "graph-app", root.Name)
+	assert.Equal(t, []*models.DependencyGraphEdge{
+		{Name: "a", Range: "^1.0.0", Type: models.DependencyGraphEdgeProd, To: "node_modules/a"},
+		{Name: "b", Range: "^2.0.0", Type: models.DependencyGraphEdgeDev, To: "node_modules/b"},
+	}, root.Dependencies)
+
+	// 嵌套安装的版本优先，找不到时逐级向上查找
+	a := graph.Nodes["node_modules/a"]
+	require.Len(t, a.Dependencies, 2)
+	assert.Equal(t, "node_modules/a/node_modules/b", a.Dependencies[0].To)
+	assert.Equal(t, "node_modules/c", a.Dependencies[1].To)
+	assert.Equal(t, "1.5.0", graph.Children("node_modules/a")[0].Version)
+
+	// workspace通过链接解析
+	assert.Equal(t, "my-ws", graph.Nodes["packages/my-ws"].Name)
+	assert.Equal(t, "node_modules/a", graph.Nodes["packages/my-ws"].Dependencies[0].To)
+
+	// 没有安装的可选依赖和peer依赖不算悬空
+	assert.Equal(t, []*models.DanglingDependency{
+		{From: "", Name: "missing", Range: "^1.0.0", Type: models.DependencyGraphEdgeProd},
+	}, graph.Dangling)
+}
+
+func TestPackageLockParser_ParseDependencyGraphV1(t *testing.T) {
+	content := `{
+  "name": "v1-app",
+  "version": "1.0.0",
+  "lockfileVersion": 1,
+  "dependencies": {
+    "a": {
+      "version": "1.0.0",
+      "requires": {"b": "^1.0.0", "fsevents": "^2.0.0"},
+      "dependencies": {
+        "b": {"version": "1.5.0"}
+      }
+    },
+    "b": {"version": "2.0.0"},
+    "fsevents": {"version": "2.3.3", "optional": true},
+    "my-react": {"version": "npm:react@18.2.0"}
+  }
+}`
+	graph, err := NewPackageLockParser().ParseDependencyGraph(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: content})
+	require.NoError(t, err)
+
+	// lockfileVersion 1没有记录项目本身，没有被依赖的节点就是根节点
+	assert.Equal(t, []string{"node_modules/a", "node_modules/b", "node_modules/my-react"}, graph.Roots)
+	assert.Equal(t, []*models.DependencyGraphEdge{
+		{Name: "b", Range: "^1.0.0", Type: models.DependencyGraphEdgeProd, To: "node_modules/a/node_modules/b"},
+		{Name: "fsevents", Range: "^2.0.0", Type: models.DependencyGraphEdgeOptional, To: "node_modules/fsevents"},
+	}, graph.Nodes["node_modules/a"].Dependencies)
+
+	myReact := graph.Nodes["node_modules/my-react"]
+	assert.Equal(t, "react", myReact.Name)
+	assert.Equal(t, "18.2.0", myReact.Version)
+	assert.Empty(t, graph.Dangling)
+}
+
+func TestPackageLockParser_ParseDependencyGraphFixtures(t *testing.T) {
+	files, err := filepath.Glob("./test_data/package-lock.json/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			graph, err := NewPackageLockParser().ParseDependencyGraph(context.Background(), &PackageLockJsonParserInput{PackageLockJsonPath: file})
+			require.NoError(t, err)
+			assert.NotEmpty(t, graph.Nodes)
+			assert.NotEmpty(t, graph.Roots)
+			if filepath.Base(file) != "join-dev-design.json" {
+				assert.Empty(t, graph.Dangling, "npm生成的lockfile中不应该有悬空的依赖")
+			}
+		})
+	}
+
+	// join-dev-design.json中有几个包缺失了条目，比如toxic依赖的lodash
+	graph, err := NewPackageLockParser().ParseDependencyGraph(context.Background(), &PackageLockJsonParserInput{PackageLockJsonPath: "./test_data/package-lock.json/join-dev-design.json"})
+	require.NoError(t, err)
+	assert.Contains(t, graph.Dangling, &models.DanglingDependency{From: "node_modules/toxic", Name: "lodash", Range: "^4.17.10", Type: models.DependencyGraphEdgeProd})
+}
@@ -2,36 +2,97 @@ package parser
 
 import (
 	"context"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
 )
 
 const PackageLockJsonFileName = "package-lock.json"
 
+// NpmShrinkwrapFileName npm-shrinkwrap.json的文件名，跟package-lock.json格式相同
+const NpmShrinkwrapFileName = "npm-shrinkwrap.json"
+
+// HiddenPackageLockJsonFileName npm v7+ 的隐藏lockfile的文件名，位于node_modules目录下
+const HiddenPackageLockJsonFileName = ".package-lock.json"
+
 type PackageLockJsonParserInput struct {
 	PackageLockJsonPath    string
 	PackageLockJsonContent string
 	ProjectRootDirectory   string
+
+	// PackageLockJsonReader 从reader中读取lockfile的内容，只能被读取一次
+	PackageLockJsonReader io.Reader
+
+	// FileSystem 指定后PackageLockJsonPath和ProjectRootDirectory都是这个文件系统中的路径
+	FileSystem fs.FS
 }
 
 func (x *PackageLockJsonParserInput) Read(ctx context.Context) ([]byte, error) {
+	bytes, _, err := x.ReadWithSource(ctx)
+	return bytes, err
+}
+
+// ReadWithSource 读取lockfile内容，同时返回实际使用的lockfile来源
+// 只指定了项目根目录时按照npm的优先级依次查找npm-shrinkwrap.json、package-lock.json、node_modules/.package-lock.json
+func (x *PackageLockJsonParserInput) ReadWithSource(ctx context.Context) ([]byte, models.PackageLockSource, error) {
 
 	if x.PackageLockJsonContent != "" {
-		return []byte(x.PackageLockJsonContent), nil
+		return []byte(x.PackageLockJsonContent), models.PackageLockSourceContent, nil
+	}
+
+	if x.PackageLockJsonReader != nil {
+		bytes, err := readInputReader(x.PackageLockJsonReader)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes, models.PackageLockSourceContent, nil
 	}
 
 	if x.PackageLockJsonPath != "" {
-		bytes, err := os.ReadFile(x.PackageLockJsonPath)
+		bytes, err := readInputFile(x.FileSystem, x.PackageLockJsonPath)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		return bytes, nil
+		return bytes, detectPackageLockSource(x.PackageLockJsonPath), nil
 	}
 
-	packageJsonPath := filepath.Join(x.ProjectRootDirectory, PackageLockJsonFileName)
-	bytes, err := os.ReadFile(packageJsonPath)
-	if err != nil {
-		return nil, err
+	candidates := []struct {
+		path   string
+		source models.PackageLockSource
+	}{
+		{path: joinInputPath(x.FileSystem, x.ProjectRootDirectory, NpmShrinkwrapFileName), source: models.PackageLockSourceShrinkwrap},
+		{path: joinInputPath(x.FileSystem, x.ProjectRootDirectory, PackageLockJsonFileName), source: models.PackageLockSourcePackageLock},
+		{path: joinInputPath(x.FileSystem, x.ProjectRootDirectory, "node_modules", HiddenPackageLockJsonFileName), source: models.PackageLockSourceHidden},
+	}
+	// 都不存在时返回package-lock.json不存在的错误
+	var notExistErr error
+	for _, candidate := range candidates {
+		bytes, err := readInputFile(x.FileSystem, candidate.path)
+		if err == nil {
+			return bytes, candidate.source, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, "", err
+		}
+		if candidate.source == models.PackageLockSourcePackageLock {
+			notExistErr = err
+		}
+	}
+	return nil, "", notExistErr
+}
+
+// detectPackageLockSource 根据文件名判断lockfile的来源
+func detectPackageLockSource(path string) models.PackageLockSource {
+	switch filepath.Base(path) {
+	case NpmShrinkwrapFileName:
+		return models.PackageLockSourceShrinkwrap
+	case HiddenPackageLockJsonFileName:
+		return models.PackageLockSourceHidden
+	default:
+		return models.PackageLockSourcePackageLock
 	}
-	return bytes, nil
 }
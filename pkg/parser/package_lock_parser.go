@@ -3,17 +3,19 @@ package parser
 import (
 	"context"
 	"encoding/json"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/scagogogo/package-json-parser/pkg/models"
-	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
 	"github.com/scagogogo/sca-base-module-ecosystem-parser/pkg/parser"
 )
 
 type PackageLockParser struct {
 }
 
-var _ parser.Parser[*PackageLockJsonParserInput, *models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem] = &PackageLockParser{}
+var _ parser.Parser[*PackageLockJsonParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &PackageLockParser{}
 
 func NewPackageLockParser() *PackageLockParser {
 	return &PackageLockParser{}
@@ -30,8 +32,8 @@ func (x *PackageLockParser) Init(ctx context.Context) error {
 }
 
 // Parse 把package-lock.json当做是一个项目解析
-func (x *PackageLockParser) Parse(ctx context.Context, input *PackageLockJsonParserInput) (*baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem], error) {
-	bytes, err := input.Read(ctx)
+func (x *PackageLockParser) Parse(ctx context.Context, input *PackageLockJsonParserInput) (*models.JsProject, error) {
+	bytes, source, err := input.ReadWithSource(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +43,7 @@ func (x *PackageLockParser) Parse(ctx context.Context, input *PackageLockJsonPar
 		return nil, err
 	}
 
-	project := &baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]{}
+	project := &models.JsProject{}
 	project.Name = lock.Name
 	project.Version = lock.Version
 
@@ -55,137 +57,219 @@ func (x *PackageLockParser) Parse(ctx context.Context, input *PackageLockJsonPar
 		project.SetModule(lock.Name, x.parseModule(lock))
 	case 3:
 		// npm v7+，使用packages字段
-		// 如果依赖较多，使用并发版本的解析器
-		if len(lock.Packages) > 100 {
-			project.SetModule(lock.Name, x.parseModuleV7Concurrent(lock))
-		} else {
-			project.SetModule(lock.Name, x.parseModuleV7(lock))
+		module, err := x.parsePackagesModule(ctx, lock)
+		if err != nil {
+			return nil, err
 		}
+		project.SetModule(lock.Name, module)
 	default:
 		// 未知版本，尝试使用兼容模式解析
 		if len(lock.Packages) > 0 {
-			if len(lock.Packages) > 100 {
-				project.SetModule(lock.Name, x.parseModuleV7Concurrent(lock))
-			} else {
-				project.SetModule(lock.Name, x.parseModuleV7(lock))
+			module, err := x.parsePackagesModule(ctx, lock)
+			if err != nil {
+				return nil, err
 			}
+			project.SetModule(lock.Name, module)
 		} else {
 			project.SetModule(lock.Name, x.parseModule(lock))
 		}
 	}
 
+	// 记录lockfile的版本和来源
+	for _, module := range project.Modules {
+		module.ModuleEcosystem = &models.JsModuleEcosystem{
+			Npm: &models.PackageLockModuleEcosystem{
+				LockFileVersion: lock.LockFileVersion,
+				Requires:        lock.Requires,
+				Source:          source,
+				Shrinkwrap:      source.IsPropagatedToConsumers(),
+			},
+		}
+	}
+	project.ProjectEcosystem = &models.JsProjectEcosystem{PackageManager: models.PackageManagerNpm}
+
 	return project, nil
 }
 
+// ParseLockfile 把package-lock.json解析为lockfile本身的模型，不转换为项目，
+// 可以用于转换或者修改后通过PackageLockWriter写回
+func (x *PackageLockParser) ParseLockfile(ctx context.Context, input *PackageLockJsonParserInput) (*models.PackageLock, error) {
+	bytes, err := input.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lock := &models.PackageLock{}
+	err = json.Unmarshal(bytes, &lock)
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
 // 解析模块，整个package-lock.json项目看做是一个模块解析
-func (x *PackageLockParser) parseModule(packageLock *models.PackageLock) *baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem] {
-	module := &baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]{}
+func (x *PackageLockParser) parseModule(packageLock *models.PackageLock) *models.JsModule {
+	module := &models.JsModule{}
 	module.Name = packageLock.Name
 	module.Version = packageLock.Version
 	module.Dependencies = x.parseDependencies(packageLock.Dependencies)
 	return module
 }
 
+// parsePackagesModule 根据packages字段解析模块，如果依赖较多，使用并发版本的解析器
+func (x *PackageLockParser) parsePackagesModule(ctx context.Context, packageLock *models.PackageLock) (*models.JsModule, error) {
+	if len(packageLock.Packages) > packageLockConcurrentThreshold {
+		return x.parseModuleV7Concurrent(ctx, packageLock)
+	}
+	return x.parseModuleV7(packageLock), nil
+}
+
 // parseModuleV7 解析npm v7+格式的package-lock.json
-func (x *PackageLockParser) parseModuleV7(packageLock *models.PackageLock) *baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem] {
-	module := &baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]{}
+func (x *PackageLockParser) parseModuleV7(packageLock *models.PackageLock) *models.JsModule {
+	module := &models.JsModule{}
 	module.Name = packageLock.Name
 	module.Version = packageLock.Version
 
-	// 从packages字段解析依赖
-	dependencies := make([]*baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem], 0)
-
-	// 遍历packages
-	for pkgPath, pkg := range packageLock.Packages {
-		// 检查pkg是否为nil
-		if pkg == nil {
-			continue
-		}
+	// 按路径排序后依次处理，保证每次解析的输出顺序一致
+	validPackagePaths := collectValidPackagePaths(packageLock.Packages)
+	dependencies := make([]*models.JsComponentDependency, 0, len(validPackagePaths))
+	for _, pkgPath := range validPackagePaths {
+		dependencies = append(dependencies, x.parsePackage(pkgPath, packageLock.Packages))
+	}
 
-		// 排除根包(通常是空字符串或"")
-		if pkgPath == "" || pkgPath == "." {
-			continue
-		}
+	module.Dependencies = dependencies
+	return module
+}
 
-		// 解析包名
-		packageName := extractPackageNameFromPath(pkgPath)
+// parseModuleV7Concurrent 使用worker pool并发处理来提高大型package-lock.json的解析性能
+// 每个worker负责一段连续的下标区间，结果直接写入预分配好的切片对应位置，因此输出顺序与parseModuleV7一致，都是按路径排序的
+func (x *PackageLockParser) parseModuleV7Concurrent(ctx context.Context, packageLock *models.PackageLock) (*models.JsModule, error) {
+	module := &models.JsModule{}
+	module.Name = packageLock.Name
+	module.Version = packageLock.Version
 
-		// 创建依赖对象
-		if pkg.Version != "" {
-			dependency := &baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem]{}
-			dependency.DependencyName = packageName
-			dependency.DependencyVersion = pkg.Version
+	// 先筛选出有效的包路径并排序
+	validPackagePaths := collectValidPackagePaths(packageLock.Packages)
 
-			// 设置生态系统特定字段，确保不为nil
-			ecosystem := &models.PackageLockComponentDependencyEcosystem{}
-			ecosystem.Resolved = pkg.Resolved
-			ecosystem.Integrity = pkg.Integrity
-			ecosystem.Dev = pkg.Dev
+	// 预先分配好结果切片，每个worker只写自己负责的下标，不需要加锁
+	dependencies := make([]*models.JsComponentDependency, len(validPackagePaths))
+	if len(validPackagePaths) == 0 {
+		module.Dependencies = dependencies
+		return module, nil
+	}
 
-			// 显式设置ComponentDependencyEcosystem
-			dependency.ComponentDependencyEcosystem = ecosystem
+	workerCount := runtime.GOMAXPROCS(0)
+	if workerCount > len(validPackagePaths) {
+		workerCount = len(validPackagePaths)
+	}
+	chunkSize := (len(validPackagePaths) + workerCount - 1) / workerCount
 
-			// 添加依赖项
-			dependencies = append(dependencies, dependency)
+	var wg sync.WaitGroup
+	for begin := 0; begin < len(validPackagePaths); begin += chunkSize {
+		end := begin + chunkSize
+		if end > len(validPackagePaths) {
+			end = len(validPackagePaths)
 		}
+
+		wg.Add(1)
+		go func(begin, end int) {
+			defer wg.Done()
+			for i := begin; i < end; i++ {
+				// 每处理一批检查一次是否已经被取消，避免取消后还继续做无用功
+				if i%packageLockCancelCheckInterval == 0 && ctx.Err() != nil {
+					return
+				}
+				pkgPath := validPackagePaths[i]
+				dependencies[i] = x.parsePackage(pkgPath, packageLock.Packages)
+			}
+		}(begin, end)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	module.Dependencies = dependencies
-	return module
+	return module, nil
 }
 
-// parseModuleV7Concurrent 使用并发处理来提高大型package-lock.json的解析性能
-func (x *PackageLockParser) parseModuleV7Concurrent(packageLock *models.PackageLock) *baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem] {
-	module := &baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]{}
-	module.Name = packageLock.Name
-	module.Version = packageLock.Version
+// packageLockConcurrentThreshold 当packages的数量超过这个值时使用并发解析
+const packageLockConcurrentThreshold = 100
+
+// packageLockCancelCheckInterval worker每处理多少个包检查一次ctx是否已取消
+const packageLockCancelCheckInterval = 64
 
-	// 先筛选出有效的包路径
-	validPackagePaths := make([]string, 0, len(packageLock.Packages))
-	for pkgPath, pkg := range packageLock.Packages {
+// collectValidPackagePaths 筛选出需要解析的包路径，排除根包、nil包和没有版本的包，并按路径排序。
+// npm写入的link条目本身没有版本，版本在它指向的目录条目上，所以link条目会被保留
+func collectValidPackagePaths(packages map[string]*models.PackageLockPackage) []string {
+	validPackagePaths := make([]string, 0, len(packages))
+	for pkgPath, pkg := range packages {
 		// 排除空路径、根包和nil包
-		if pkgPath == "" || pkgPath == "." || pkg == nil || pkg.Version == "" {
+		if pkgPath == "" || pkgPath == "." || pkg == nil {
+			continue
+		}
+		if pkg.Version == "" && !isPackageLockLink(pkg) {
 			continue
 		}
 		validPackagePaths = append(validPackagePaths, pkgPath)
 	}
+	sort.Strings(validPackagePaths)
+	return validPackagePaths
+}
 
-	// 如果没有有效的包路径，则直接返回
-	if len(validPackagePaths) == 0 {
-		module.Dependencies = make([]*baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem], 0)
-		return module
-	}
-
-	// 预先分配足够大的空间
-	dependencies := make([]*baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem], 0, len(validPackagePaths))
-
-	// 处理每个有效的依赖
-	for _, pkgPath := range validPackagePaths {
-		pkg := packageLock.Packages[pkgPath]
-
-		// 解析包名
-		packageName := extractPackageNameFromPath(pkgPath)
-
-		// 创建依赖对象
-		dependency := &baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem]{}
-		dependency.DependencyName = packageName
-		dependency.DependencyVersion = pkg.Version
-
-		// 设置生态系统特定字段
-		ecosystem := &models.PackageLockComponentDependencyEcosystem{}
-		ecosystem.Resolved = pkg.Resolved
-		ecosystem.Integrity = pkg.Integrity
-		ecosystem.Dev = pkg.Dev
+// isPackageLockLink 是否是指向本地目录的link条目
+func isPackageLockLink(pkg *models.PackageLockPackage) bool {
+	return pkg.Link != nil && *pkg.Link && pkg.Resolved != ""
+}
 
-		// 显式设置ComponentDependencyEcosystem
-		dependency.ComponentDependencyEcosystem = ecosystem
+// parsePackage 把packages字段中的一个包解析为依赖对象，packages用来查找link条目指向的目录条目
+func (x *PackageLockParser) parsePackage(pkgPath string, packages map[string]*models.PackageLockPackage) *models.JsComponentDependency {
+	pkg := packages[pkgPath]
+	dependency := &models.JsComponentDependency{}
+	dependency.DependencyName = extractPackageNameFromPath(pkgPath)
+	dependency.DependencyVersion = pkg.Version
+
+	// 设置生态系统特定字段，确保不为nil
+	ecosystem := &models.JsComponentDependencyEcosystem{Ecosystem: models.DependencyEcosystemNpm}
+
+	// link条目的名称和版本以它指向的目录条目为准，resolved是相对于项目根目录的路径，跟packages中的键一致
+	name := pkg.Name
+	if isPackageLockLink(pkg) {
+		if target := packages[pkg.Resolved]; target != nil {
+			if dependency.DependencyVersion == "" {
+				dependency.DependencyVersion = target.Version
+			}
+			if name == "" {
+				name = target.Name
+			}
+		}
+	}
 
-		// 添加到依赖列表
-		dependencies = append(dependencies, dependency)
+	// 包声明了真实名称时以真实名称为准，如果是安装在node_modules下且名称不一致，说明是通过别名安装的
+	if name != "" && name != dependency.DependencyName {
+		if strings.Contains(pkgPath, "node_modules/") {
+			ecosystem.Alias = dependency.DependencyName
+		}
+		dependency.DependencyName = name
 	}
 
-	module.Dependencies = dependencies
-	return module
+	ecosystem.Resolved = pkg.Resolved
+	ecosystem.ResolvedSource = parseResolvedSource(pkg.Resolved)
+	ecosystem.Integrity = pkg.Integrity
+	ecosystem.Dev = pkg.Dev
+	ecosystem.Requires = pkg.Dependencies
+	ecosystem.Path = pkgPath
+	ecosystem.Optional = pkg.Optional
+	ecosystem.DevOptional = pkg.DevOptional
+	ecosystem.Peer = pkg.Peer
+	ecosystem.InBundle = pkg.InBundle
+	ecosystem.Link = pkg.Link
+	ecosystem.HasInstallScript = pkg.HasInstallScript
+	ecosystem.Extraneous = pkg.Extraneous
+	ecosystem.HasShrinkwrap = pkg.HasShrinkwrap
+	dependency.ComponentDependencyEcosystem = ecosystem
+
+	return dependency
 }
 
 // 从包路径中提取包名
@@ -238,37 +322,51 @@ func extractPackageNameFromPath(pkgPath string) string {
 }
 
 // 解析所有的依赖
-func (x *PackageLockParser) parseDependencies(packageJsonDependencies map[string]*models.PackageLockDependency) []*baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem] {
-	dependencies := make([]*baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem], 0)
+func (x *PackageLockParser) parseDependencies(packageJsonDependencies map[string]*models.PackageLockDependency) []*models.JsComponentDependency {
+	dependencies := make([]*models.JsComponentDependency, 0)
 	processed := make(map[string]bool) // 用于防止循环依赖
 
-	for dependencyPackageName, packageLockDependency := range packageJsonDependencies {
-		x.parseAndAddDependency(dependencyPackageName, packageLockDependency, &dependencies, processed, 0)
+	// 按名称排序后再遍历，保证输出顺序稳定
+	for _, dependencyPackageName := range sortedPackageLockDependencyNames(packageJsonDependencies) {
+		x.parseAndAddDependency("", dependencyPackageName, packageJsonDependencies[dependencyPackageName], &dependencies, processed, 0)
 	}
 	return dependencies
 }
 
 // 解析单个的依赖
-func (x *PackageLockParser) parseDependency(packageName string, packageLockDependency *models.PackageLockDependency) *baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem] {
-	dependency := &baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem]{}
+func (x *PackageLockParser) parseDependency(packageName string, packageLockDependency *models.PackageLockDependency) *models.JsComponentDependency {
+	dependency := &models.JsComponentDependency{}
 	dependency.DependencyName = packageName
 	dependency.DependencyVersion = packageLockDependency.Version
 
-	ecosystem := &models.PackageLockComponentDependencyEcosystem{}
+	ecosystem := &models.JsComponentDependencyEcosystem{Ecosystem: models.DependencyEcosystemNpm}
+
+	// 别名安装的依赖版本形如 npm:react@18.2.0，这时依赖名称实际上是别名
+	if realName, realVersion, ok := parseNpmAlias(packageLockDependency.Version); ok {
+		ecosystem.Alias = packageName
+		dependency.DependencyName = realName
+		dependency.DependencyVersion = realVersion
+	}
+
 	ecosystem.Integrity = packageLockDependency.Integrity
 	ecosystem.Resolved = packageLockDependency.Resolved
+	ecosystem.ResolvedSource = parseResolvedSource(packageLockDependency.Resolved)
 	ecosystem.Dev = packageLockDependency.Dev
 	ecosystem.Requires = packageLockDependency.Requires
+	ecosystem.Optional = packageLockDependency.Optional
+	ecosystem.Peer = packageLockDependency.Peer
+	ecosystem.Bundled = packageLockDependency.Bundled
 	dependency.ComponentDependencyEcosystem = ecosystem
 
 	return dependency
 }
 
-// 递归解析依赖，并添加到依赖列表
+// 递归解析依赖，并添加到依赖列表，parentPath是父依赖的安装路径，顶级依赖为空字符串
 func (x *PackageLockParser) parseAndAddDependency(
+	parentPath string,
 	packageName string,
 	packageLockDependency *models.PackageLockDependency,
-	dependencies *[]*baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem],
+	dependencies *[]*models.JsComponentDependency,
 	processed map[string]bool,
 	depth int,
 ) {
@@ -280,18 +378,33 @@ func (x *PackageLockParser) parseAndAddDependency(
 	// 标记为已处理
 	processed[packageName+"@"+packageLockDependency.Version] = true
 
-	// 解析当前依赖
+	// 解析当前依赖，按照嵌套关系拼出安装路径
+	dependencyPath := "node_modules/" + packageName
+	if parentPath != "" {
+		dependencyPath = parentPath + "/" + dependencyPath
+	}
 	dependency := x.parseDependency(packageName, packageLockDependency)
+	dependency.ComponentDependencyEcosystem.Path = dependencyPath
 	*dependencies = append(*dependencies, dependency)
 
 	// 添加嵌套依赖
 	if len(packageLockDependency.Dependencies) > 0 {
-		for childName, childDep := range packageLockDependency.Dependencies {
-			x.parseAndAddDependency(childName, childDep, dependencies, processed, depth+1)
+		for _, childName := range sortedPackageLockDependencyNames(packageLockDependency.Dependencies) {
+			x.parseAndAddDependency(dependencyPath, childName, packageLockDependency.Dependencies[childName], dependencies, processed, depth+1)
 		}
 	}
 }
 
+// sortedPackageLockDependencyNames 返回排好序的依赖名称，depth-first加上有序的名称后输出顺序就跟安装路径的顺序一致
+func sortedPackageLockDependencyNames(dependencies map[string]*models.PackageLockDependency) []string {
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (x *PackageLockParser) Close(ctx context.Context) error {
 	return nil
 }
@@ -2,12 +2,15 @@ package parser
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/scagogogo/package-json-parser/pkg/models"
-	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,13 +25,13 @@ func TestPackageLockParser_Parse(t *testing.T) {
 		name      string
 		inputFile string
 		wantError bool
-		checkFunc func(t *testing.T, project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem])
+		checkFunc func(t *testing.T, project *models.JsProject)
 	}{
 		{
 			name:      "基本package-lock.json解析",
 			inputFile: "./test_data/package-lock.json/join-dev-design.json",
 			wantError: false,
-			checkFunc: func(t *testing.T, project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]) {
+			checkFunc: func(t *testing.T, project *models.JsProject) {
 				assert.NotNil(t, project)
 				assert.Equal(t, "join-dev-design", project.Name)
 
@@ -55,7 +58,7 @@ func TestPackageLockParser_Parse(t *testing.T) {
 			name:      "较大的package-lock.json",
 			inputFile: "./test_data/package-lock.json/universal-module-tree.json",
 			wantError: false,
-			checkFunc: func(t *testing.T, project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]) {
+			checkFunc: func(t *testing.T, project *models.JsProject) {
 				assert.NotNil(t, project)
 
 				// 检查模块
@@ -70,7 +73,7 @@ func TestPackageLockParser_Parse(t *testing.T) {
 			name:      "最小的package-lock.json",
 			inputFile: "./test_data/package-lock.json/gitlab-ci-yarn-audit-parser.json",
 			wantError: false,
-			checkFunc: func(t *testing.T, project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem]) {
+			checkFunc: func(t *testing.T, project *models.JsProject) {
 				assert.NotNil(t, project)
 
 				// 检查模块
@@ -175,7 +178,7 @@ func TestPackageLockParser_ParseModule(t *testing.T) {
 	assert.Len(t, module.Dependencies, 3) // 2个顶级依赖 + 1个嵌套依赖
 
 	// 检查依赖
-	var dep1, dep2, nestedDep *baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem]
+	var dep1, dep2, nestedDep *models.JsComponentDependency
 	for _, dep := range module.Dependencies {
 		if dep.DependencyName == "dep1" {
 			dep1 = dep
@@ -210,7 +213,7 @@ func TestPackageLockParser_ParseAndAddDependency(t *testing.T) {
 	parser := NewPackageLockParser()
 
 	// 创建测试数据
-	dependencies := make([]*baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem], 0)
+	dependencies := make([]*models.JsComponentDependency, 0)
 	processed := make(map[string]bool)
 
 	// 创建测试依赖
@@ -228,7 +231,7 @@ func TestPackageLockParser_ParseAndAddDependency(t *testing.T) {
 	}
 
 	// 执行方法
-	parser.parseAndAddDependency("test-package", dep, &dependencies, processed, 0)
+	parser.parseAndAddDependency("", "test-package", dep, &dependencies, processed, 0)
 
 	// 验证结果
 	assert.Len(t, dependencies, 2) // 一个主依赖和一个嵌套依赖
@@ -239,9 +242,89 @@ func TestPackageLockParser_ParseAndAddDependency(t *testing.T) {
 	assert.Equal(t, "1.0.0", dependencies[0].DependencyVersion)
 	assert.Equal(t, "https://registry.npmjs.org/test/-/test-1.0.0.tgz", dependencies[0].ComponentDependencyEcosystem.Resolved)
 
+	assert.Equal(t, "node_modules/test-package", dependencies[0].ComponentDependencyEcosystem.Path)
+
 	// 检查嵌套依赖
 	assert.Equal(t, "nested", dependencies[1].DependencyName)
 	assert.Equal(t, "2.0.0", dependencies[1].DependencyVersion)
+	assert.Equal(t, "node_modules/test-package/node_modules/nested", dependencies[1].ComponentDependencyEcosystem.Path)
+}
+
+// 测试lockfile中的依赖标记都会带到依赖生态系统信息中
+func TestPackageLockParser_DependencyFlags(t *testing.T) {
+	parser := NewPackageLockParser()
+
+	t.Run("v1", func(t *testing.T) {
+		content := `{
+			"name": "flags",
+			"version": "1.0.0",
+			"lockfileVersion": 1,
+			"dependencies": {
+				"fsevents": {"version": "2.3.2", "optional": true, "dev": true},
+				"react": {"version": "18.2.0", "peer": true},
+				"inner": {"version": "1.0.0", "bundled": true, "requires": {"fsevents": "^2.0.0"}}
+			}
+		}`
+		project, err := parser.Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: content})
+		require.NoError(t, err)
+		module := findModuleInPackageLock(project, "flags")
+		require.NotNil(t, module)
+		require.Len(t, module.Dependencies, 3)
+
+		fsevents, inner, react := module.Dependencies[0].ComponentDependencyEcosystem, module.Dependencies[1].ComponentDependencyEcosystem, module.Dependencies[2].ComponentDependencyEcosystem
+		assert.Equal(t, "node_modules/fsevents", fsevents.Path)
+		require.NotNil(t, fsevents.Optional)
+		assert.True(t, *fsevents.Optional)
+		require.NotNil(t, fsevents.Dev)
+		assert.True(t, *fsevents.Dev)
+		require.NotNil(t, inner.Bundled)
+		assert.True(t, *inner.Bundled)
+		assert.Equal(t, models.Dependencies{"fsevents": "^2.0.0"}, inner.Requires)
+		require.NotNil(t, react.Peer)
+		assert.True(t, *react.Peer)
+		assert.Nil(t, react.Optional)
+	})
+
+	t.Run("v3", func(t *testing.T) {
+		content := `{
+			"name": "flags",
+			"version": "1.0.0",
+			"lockfileVersion": 3,
+			"packages": {
+				"": {"name": "flags", "version": "1.0.0"},
+				"node_modules/a": {"version": "1.0.0", "devOptional": true, "hasInstallScript": true, "dependencies": {"b": "^2.0.0"}},
+				"node_modules/a/node_modules/b": {"version": "2.0.0", "inBundle": true, "extraneous": true},
+				"node_modules/c": {"version": "1.0.0", "optional": true, "peer": true, "hasShrinkwrap": true},
+				"node_modules/d": {"resolved": "packages/d", "link": true},
+				"packages/d": {"name": "d", "version": "1.0.0"}
+			}
+		}`
+		project, err := parser.Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: content})
+		require.NoError(t, err)
+		module := findModuleInPackageLock(project, "flags")
+		require.NotNil(t, module)
+		require.Len(t, module.Dependencies, 5)
+
+		a, b, c, d := module.Dependencies[0].ComponentDependencyEcosystem, module.Dependencies[1].ComponentDependencyEcosystem, module.Dependencies[2].ComponentDependencyEcosystem, module.Dependencies[3].ComponentDependencyEcosystem
+		assert.Equal(t, "node_modules/a", a.Path)
+		assert.True(t, *a.DevOptional)
+		assert.True(t, *a.HasInstallScript)
+		assert.Equal(t, models.Dependencies{"b": "^2.0.0"}, a.Requires)
+		assert.Equal(t, "node_modules/a/node_modules/b", b.Path)
+		assert.True(t, *b.InBundle)
+		assert.True(t, *b.Extraneous)
+		assert.True(t, *c.Optional)
+		assert.True(t, *c.Peer)
+		assert.Nil(t, c.Dev)
+		assert.True(t, *c.HasShrinkwrap)
+		assert.Nil(t, a.HasShrinkwrap)
+		assert.True(t, *d.Link)
+
+		// link条目没有版本，使用它指向的目录条目的版本
+		assert.Equal(t, "d", module.Dependencies[3].DependencyName)
+		assert.Equal(t, "1.0.0", module.Dependencies[3].DependencyVersion)
+		assert.Equal(t, "packages/d", module.Dependencies[4].ComponentDependencyEcosystem.Path)
+	})
 }
 
 // 测试parseDependency方法
@@ -271,6 +354,153 @@ func TestPackageLockParser_ParseDependency(t *testing.T) {
 	assert.True(t, *result.ComponentDependencyEcosystem.Dev)
 }
 
+// 测试通过npm别名安装的依赖使用真实包名，同时保留别名
+func TestPackageLockParser_Alias(t *testing.T) {
+	parser := NewPackageLockParser()
+
+	t.Run("v1", func(t *testing.T) {
+		content := `{
+			"name": "alias",
+			"version": "1.0.0",
+			"lockfileVersion": 1,
+			"dependencies": {
+				"my-react": {"version": "npm:react@18.2.0", "resolved": "https://registry.npmjs.org/react/-/react-18.2.0.tgz"},
+				"my-core": {"version": "npm:@babel/core@7.15.0"}
+			}
+		}`
+		project, err := parser.Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: content})
+		require.NoError(t, err)
+		module := findModuleInPackageLock(project, "alias")
+		require.NotNil(t, module)
+		require.Len(t, module.Dependencies, 2)
+
+		assert.Equal(t, "@babel/core", module.Dependencies[0].DependencyName)
+		assert.Equal(t, "7.15.0", module.Dependencies[0].DependencyVersion)
+		assert.Equal(t, "my-core", module.Dependencies[0].ComponentDependencyEcosystem.Alias)
+		assert.Equal(t, "react", module.Dependencies[1].DependencyName)
+		assert.Equal(t, "18.2.0", module.Dependencies[1].DependencyVersion)
+		assert.Equal(t, "my-react", module.Dependencies[1].ComponentDependencyEcosystem.Alias)
+		assert.Equal(t, "node_modules/my-react", module.Dependencies[1].ComponentDependencyEcosystem.Path)
+	})
+
+	t.Run("v3", func(t *testing.T) {
+		content := `{
+			"name": "alias",
+			"version": "1.0.0",
+			"lockfileVersion": 3,
+			"packages": {
+				"": {"name": "alias", "version": "1.0.0", "dependencies": {"my-react": "npm:react@18.2.0"}},
+				"node_modules/my-react": {"name": "react", "version": "18.2.0"},
+				"node_modules/lodash": {"version": "4.17.21"},
+				"packages/web": {"name": "@alias/web", "version": "0.1.0"}
+			}
+		}`
+		project, err := parser.Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: content})
+		require.NoError(t, err)
+		module := findModuleInPackageLock(project, "alias")
+		require.NotNil(t, module)
+		require.Len(t, module.Dependencies, 3)
+
+		assert.Equal(t, "lodash", module.Dependencies[0].DependencyName)
+		assert.Empty(t, module.Dependencies[0].ComponentDependencyEcosystem.Alias)
+		assert.Equal(t, "react", module.Dependencies[1].DependencyName)
+		assert.Equal(t, "my-react", module.Dependencies[1].ComponentDependencyEcosystem.Alias)
+		// workspace目录不是别名，只是名称跟目录不一致
+		assert.Equal(t, "@alias/web", module.Dependencies[2].DependencyName)
+		assert.Empty(t, module.Dependencies[2].ComponentDependencyEcosystem.Alias)
+	})
+}
+
+// 测试只指定项目根目录时按优先级查找lockfile并记录来源
+func TestPackageLockParser_LockFileSource(t *testing.T) {
+	lockContent := func(name string) string {
+		return fmt.Sprintf(`{"name": %q, "version": "1.0.0", "lockfileVersion": 3, "packages": {"": {"name": %q}, "node_modules/a": {"version": "1.0.0"}}}`, name, name)
+	}
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantProject string
+		wantSource  models.PackageLockSource
+		wantError   bool
+	}{
+		{
+			name:        "只有package-lock.json",
+			files:       map[string]string{PackageLockJsonFileName: lockContent("from-package-lock")},
+			wantProject: "from-package-lock",
+			wantSource:  models.PackageLockSourcePackageLock,
+		},
+		{
+			name: "npm-shrinkwrap.json优先",
+			files: map[string]string{
+				PackageLockJsonFileName: lockContent("from-package-lock"),
+				NpmShrinkwrapFileName:   lockContent("from-shrinkwrap"),
+			},
+			wantProject: "from-shrinkwrap",
+			wantSource:  models.PackageLockSourceShrinkwrap,
+		},
+		{
+			name:        "只有隐藏lockfile",
+			files:       map[string]string{filepath.Join("node_modules", HiddenPackageLockJsonFileName): lockContent("from-hidden")},
+			wantProject: "from-hidden",
+			wantSource:  models.PackageLockSourceHidden,
+		},
+		{
+			name:      "没有任何lockfile",
+			files:     map[string]string{},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+			}
+
+			project, err := NewPackageLockParser().Parse(context.Background(), &PackageLockJsonParserInput{ProjectRootDirectory: dir})
+			if tt.wantError {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantProject, project.Name)
+
+			module := findModuleInPackageLock(project, tt.wantProject)
+			require.NotNil(t, module)
+			require.NotNil(t, module.ModuleEcosystem)
+			assert.Equal(t, tt.wantSource, module.ModuleEcosystem.Npm.Source)
+			assert.Equal(t, tt.wantSource == models.PackageLockSourceShrinkwrap, module.ModuleEcosystem.Npm.Shrinkwrap)
+			assert.Equal(t, uint(3), module.ModuleEcosystem.Npm.LockFileVersion)
+		})
+	}
+
+	// fs.FS中的项目根目录同样按优先级查找
+	fileSystem := fstest.MapFS{
+		"repo/npm-shrinkwrap.json": &fstest.MapFile{Data: []byte(lockContent("from-fs-shrinkwrap"))},
+		"repo/package-lock.json":   &fstest.MapFile{Data: []byte(lockContent("from-fs-package-lock"))},
+	}
+	project, err := NewPackageLockParser().Parse(context.Background(), &PackageLockJsonParserInput{FileSystem: fileSystem, ProjectRootDirectory: "repo"})
+	require.NoError(t, err)
+	assert.Equal(t, "from-fs-shrinkwrap", project.Name)
+
+	// reader的内容无法判断来源
+	project, err = NewPackageLockParser().Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonReader: strings.NewReader(lockContent("from-reader"))})
+	require.NoError(t, err)
+	assert.Equal(t, models.PackageLockSourceContent, findModuleInPackageLock(project, "from-reader").ModuleEcosystem.Npm.Source)
+
+	// 直接指定路径时根据文件名判断来源
+	dir := t.TempDir()
+	shrinkwrapPath := filepath.Join(dir, NpmShrinkwrapFileName)
+	require.NoError(t, os.WriteFile(shrinkwrapPath, []byte(lockContent("by-path")), 0644))
+	project, err = NewPackageLockParser().Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonPath: shrinkwrapPath})
+	require.NoError(t, err)
+	assert.True(t, findModuleInPackageLock(project, "by-path").ModuleEcosystem.Npm.Shrinkwrap)
+}
+
 // 测试extractPackageNameFromPath函数
 func TestExtractPackageNameFromPath(t *testing.T) {
 	tests := []struct {
@@ -345,7 +575,7 @@ func TestPackageLockParser_ParseModuleV7(t *testing.T) {
 	assert.Len(t, module.Dependencies, 2) // 应该有两个依赖（不包括根包）
 
 	// 验证依赖
-	var lodashDep, babelDep *baseModels.ComponentDependency[*models.PackageLockComponentDependencyEcosystem]
+	var lodashDep, babelDep *models.JsComponentDependency
 	for _, dep := range module.Dependencies {
 		if dep.DependencyName == "lodash" {
 			lodashDep = dep
@@ -396,16 +626,117 @@ func TestPackageLockParser_ParseModuleV7Concurrent(t *testing.T) {
 	}
 
 	// 执行方法
-	module := parser.parseModuleV7Concurrent(packageLock)
+	module, err := parser.parseModuleV7Concurrent(context.Background(), packageLock)
+	require.NoError(t, err)
 
 	// 验证结果
 	assert.Equal(t, "test-concurrent", module.Name)
 	assert.Equal(t, "1.0.0", module.Version)
 	assert.Len(t, module.Dependencies, 150) // 应该有150个依赖
+
+	// 并发版本和顺序版本的输出应该完全一致，并且按路径排序
+	expected := parser.parseModuleV7(packageLock)
+	require.Len(t, expected.Dependencies, len(module.Dependencies))
+	for i := range expected.Dependencies {
+		assert.Equal(t, expected.Dependencies[i].DependencyName, module.Dependencies[i].DependencyName)
+		assert.Equal(t, expected.Dependencies[i].DependencyVersion, module.Dependencies[i].DependencyVersion)
+	}
+	assert.Equal(t, "uniquepkg-0", module.Dependencies[0].DependencyName)
+	assert.Equal(t, "uniquepkg-1", module.Dependencies[1].DependencyName)
+	assert.Equal(t, "uniquepkg-10", module.Dependencies[2].DependencyName)
+}
+
+// 测试parseModuleV7Concurrent在ctx被取消时返回错误
+func TestPackageLockParser_ParseModuleV7ConcurrentCanceled(t *testing.T) {
+	parser := NewPackageLockParser()
+	packageLock := generatePackageLock(1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	module, err := parser.parseModuleV7Concurrent(ctx, packageLock)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, module)
+
+	// 通过Parse调用时也应该透传取消错误
+	bytes, err := json.Marshal(packageLock)
+	require.NoError(t, err)
+	_, err = parser.Parse(ctx, &PackageLockJsonParserInput{PackageLockJsonContent: string(bytes)})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// 测试多次解析的输出顺序是稳定的
+func TestPackageLockParser_ParseDeterministic(t *testing.T) {
+	parser := NewPackageLockParser()
+
+	for _, inputFile := range []string{
+		"./test_data/package-lock.json/join-dev-design.json",
+		"./test_data/package-lock.json/universal-module-tree.json",
+	} {
+		t.Run(inputFile, func(t *testing.T) {
+			var previous []string
+			for i := 0; i < 5; i++ {
+				project, err := parser.Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonPath: inputFile})
+				require.NoError(t, err)
+				module := findModuleInPackageLock(project, project.Name)
+				require.NotNil(t, module)
+
+				current := make([]string, 0, len(module.Dependencies))
+				for _, dep := range module.Dependencies {
+					current = append(current, dep.DependencyName+"@"+dep.DependencyVersion)
+				}
+				if previous != nil {
+					assert.Equal(t, previous, current)
+				}
+				previous = current
+			}
+		})
+	}
+}
+
+func BenchmarkPackageLockParser_ParseModuleV7(b *testing.B) {
+	parser := NewPackageLockParser()
+	packageLock := generatePackageLock(20000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser.parseModuleV7(packageLock)
+	}
+}
+
+func BenchmarkPackageLockParser_ParseModuleV7Concurrent(b *testing.B) {
+	parser := NewPackageLockParser()
+	packageLock := generatePackageLock(20000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.parseModuleV7Concurrent(context.Background(), packageLock); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// 辅助函数：生成一个包含指定数量嵌套包的npm v7+ PackageLock对象
+func generatePackageLock(count int) *models.PackageLock {
+	packageLock := &models.PackageLock{
+		Name:            "bench",
+		Version:         "1.0.0",
+		LockFileVersion: 3,
+		Packages: map[string]*models.PackageLockPackage{
+			"": {Version: "1.0.0"},
+		},
+	}
+	for i := 0; i < count; i++ {
+		pkgPath := fmt.Sprintf("node_modules/@scope-%d/parent-%d/node_modules/child-%d", i%50, i, i)
+		packageLock.Packages[pkgPath] = &models.PackageLockPackage{
+			Version:   fmt.Sprintf("1.%d.%d", i%7, i%10),
+			Resolved:  fmt.Sprintf("https://registry.npmjs.org/child-%d/-/child-%d-1.0.0.tgz", i, i),
+			Integrity: "sha512-example",
+		}
+	}
+	return packageLock
 }
 
 // 辅助函数：查找指定名称的模块
-func findModuleInPackageLock(project *baseModels.Project[*models.PackageLockProjectEcosystem, *models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem], name string) *baseModels.Module[*models.PackageLockModuleEcosystem, *models.PackageLockComponentEcosystem, *models.PackageLockComponentDependencyEcosystem] {
+func findModuleInPackageLock(project *models.JsProject, name string) *models.JsModule {
 	for _, module := range project.Modules {
 		if module.Name == name {
 			return module
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// PackageLockWriter 把PackageLock写为package-lock.json，格式跟npm一致：两个空格缩进，文件以换行结尾，
+// 并且不会像encoding/json默认的那样把版本范围中的<、>、&转义为\u003c这样的形式
+type PackageLockWriter struct {
+}
+
+func NewPackageLockWriter() *PackageLockWriter {
+	return &PackageLockWriter{}
+}
+
+// Marshal 把PackageLock序列化为package-lock.json的内容
+func (x *PackageLockWriter) Marshal(packageLock *models.PackageLock) ([]byte, error) {
+	if packageLock == nil {
+		return nil, fmt.Errorf("package-lock.json is nil")
+	}
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(packageLock); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Write 把PackageLock写入writer
+func (x *PackageLockWriter) Write(writer io.Writer, packageLock *models.PackageLock) error {
+	data, err := x.Marshal(packageLock)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageLockWriter_Marshal(t *testing.T) {
+	dev := true
+	packageLock := &models.PackageLock{
+		Name:            "app",
+		Version:         "1.0.0",
+		LockFileVersion: 3,
+		Packages: map[string]*models.PackageLockPackage{
+			"": {Name: "app", Version: "1.0.0", Dependencies: models.Dependencies{"a": ">=1.0.0 <2.0.0"}},
+			"node_modules/a": {
+				Version:   "1.2.0",
+				Resolved:  "https://registry.npmjs.org/a/-/a-1.2.0.tgz",
+				Integrity: "sha512-abc",
+				Dev:       &dev,
+			},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	require.NoError(t, NewPackageLockWriter().Write(output, packageLock))
+	assert.Equal(t, `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "app",
+      "version": "1.0.0",
+      "dependencies": {
+        "a": ">=1.0.0 <2.0.0"
+      }
+    },
+    "node_modules/a": {
+      "version": "1.2.0",
+      "resolved": "https://registry.npmjs.org/a/-/a-1.2.0.tgz",
+      "integrity": "sha512-abc",
+      "dev": true
+    }
+  }
+}
+`, output.String())
+
+	// 写出的内容可以被重新解析
+	parsed, err := NewPackageLockParser().ParseLockfile(context.Background(), &PackageLockJsonParserInput{PackageLockJsonContent: output.String()})
+	require.NoError(t, err)
+	assert.Equal(t, packageLock, parsed)
+
+	_, err = NewPackageLockWriter().Marshal(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// PackumentDirectorySource 从目录中读取保存好的packument，比如提前从registry下载的元数据镜像。
+// 包的元数据保存在 <目录>/<包名>.json 中，作用域包可以是 @scope/name.json 或者 @scope%2fname.json
+type PackumentDirectorySource struct {
+	// Directory 保存packument的目录
+	Directory string
+
+	// FileSystem 指定后Directory是这个文件系统中的路径，否则从本地文件系统读取
+	FileSystem fs.FS
+}
+
+var _ RegistryMetadataSource = &PackumentDirectorySource{}
+
+func NewPackumentDirectorySource(directory string, fileSystem fs.FS) *PackumentDirectorySource {
+	return &PackumentDirectorySource{
+		Directory:  directory,
+		FileSystem: fileSystem,
+	}
+}
+
+// Fetch 依次尝试各种文件名，都不存在时返回nil
+func (x *PackumentDirectorySource) Fetch(ctx context.Context, packageName string) (*models.Packument, error) {
+	if x.Directory == "" {
+		return nil, fmt.Errorf("packument directory cannot be empty")
+	}
+	for _, fileName := range packumentFileNames(packageName) {
+		filePath := joinInputPath(x.FileSystem, x.Directory, fileName)
+		data, err := readInputFile(x.FileSystem, filePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parsePackument(data, filePath)
+	}
+	return nil, nil
+}
+
+// packumentFileNames 包的元数据可能使用的文件名，包名中不能包含..和反斜杠，避免读取目录之外的文件
+func packumentFileNames(packageName string) []string {
+	if strings.Contains(packageName, "..") || strings.Contains(packageName, `\`) {
+		return nil
+	}
+	fileNames := []string{packageName + ".json"}
+	if strings.HasPrefix(packageName, "@") && strings.Contains(packageName, "/") {
+		fileNames = append(fileNames, strings.Replace(packageName, "/", "%2f", 1)+".json")
+	}
+	return fileNames
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// readInputFile 读取输入文件，指定了fileSystem时从fileSystem中读取，否则从本地文件系统读取
+func readInputFile(fileSystem fs.FS, filePath string) ([]byte, error) {
+	if fileSystem == nil {
+		return os.ReadFile(filePath)
+	}
+	return fs.ReadFile(fileSystem, toFsPath(filePath))
+}
+
+// joinInputPath 拼接项目根目录和文件名，fileSystem中的路径总是使用/分隔
+func joinInputPath(fileSystem fs.FS, elem ...string) string {
+	if fileSystem == nil {
+		return filepath.Join(elem...)
+	}
+	return toFsPath(path.Join(elem...))
+}
+
+// toFsPath 把路径转换为fs.FS要求的格式：使用/分隔、不以/开头、空路径表示根目录"."
+func toFsPath(filePath string) string {
+	filePath = path.Clean(filepath.ToSlash(filePath))
+	filePath = strings.TrimPrefix(filePath, "/")
+	if filePath == "" {
+		return "."
+	}
+	return filePath
+}
+
+// readInputReader 读取reader中的全部内容，reader只能被读取一次
+func readInputReader(reader io.Reader) ([]byte, error) {
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,675 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
+	"github.com/scagogogo/sca-base-module-ecosystem-parser/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// PnpmLockParser 用于解析pnpm-lock.yaml文件，支持lockfileVersion 5.x、6.0和9.0
+type PnpmLockParser struct {
+}
+
+var _ parser.Parser[*PnpmLockParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &PnpmLockParser{}
+
+func NewPnpmLockParser() *PnpmLockParser {
+	return &PnpmLockParser{}
+}
+
+const PnpmLockParserName = "pnpm-lock-parser"
+
+// pnpmLinkPrefix 指向本地目录（通常是workspace中的另一个项目）的依赖版本前缀
+const pnpmLinkPrefix = "link:"
+
+// pnpmCatalogPrefix 通过catalog声明依赖版本的前缀，catalog: 表示默认的catalog
+const pnpmCatalogPrefix = "catalog:"
+
+// PnpmDefaultCatalogName 默认catalog的名称
+const PnpmDefaultCatalogName = "default"
+
+// pnpmRootImporter 根项目对应的importer
+const pnpmRootImporter = "."
+
+func (x *PnpmLockParser) GetName() string {
+	return PnpmLockParserName
+}
+
+func (x *PnpmLockParser) Init(ctx context.Context) error {
+	return nil
+}
+
+// pnpmLockFile pnpm-lock.yaml对应的yaml结构，5.x的单项目lockfile把根项目的依赖直接写在顶层
+type pnpmLockFile struct {
+	LockfileVersion     yaml.Node                                                `yaml:"lockfileVersion"`
+	Settings            *models.PnpmLockSettings                                 `yaml:"settings"`
+	Overrides           map[string]string                                        `yaml:"overrides"`
+	PatchedDependencies map[string]yaml.Node                                     `yaml:"patchedDependencies"`
+	Catalogs            map[string]map[string]*models.PnpmLockImporterDependency `yaml:"catalogs"`
+	Importers           map[string]*pnpmLockImporter                             `yaml:"importers"`
+	Packages            map[string]*models.PnpmLockPackage                       `yaml:"packages"`
+	Snapshots           map[string]*models.PnpmLockSnapshot                      `yaml:"snapshots"`
+
+	pnpmLockImporter `yaml:",inline"`
+}
+
+// pnpmLockImporter importer的yaml结构，5.x中依赖的值只有版本，范围单独写在specifiers里，
+// 6.0开始依赖的值是包含specifier和version的对象
+type pnpmLockImporter struct {
+	Specifiers           map[string]string    `yaml:"specifiers"`
+	Dependencies         map[string]yaml.Node `yaml:"dependencies"`
+	DevDependencies      map[string]yaml.Node `yaml:"devDependencies"`
+	OptionalDependencies map[string]yaml.Node `yaml:"optionalDependencies"`
+}
+
+// pnpmLockNode 依赖图中的一个节点，5.x和6.0对应packages中的条目，9.0对应snapshots中的条目
+type pnpmLockNode struct {
+	DepPath              string
+	Name                 string
+	Version              string
+	PeerSuffix           string
+	Package              *models.PnpmLockPackage
+	Dependencies         map[string]string
+	OptionalDependencies map[string]string
+}
+
+// pnpmLockDirectDependency importer的一个直接依赖
+type pnpmLockDirectDependency struct {
+	Name       string
+	Dependency *models.PnpmLockImporterDependency
+	Dev        bool
+	Optional   bool
+}
+
+// Parse 解析pnpm-lock.yaml，返回所有JavaScript解析器共用的类型，PnpmLock特有的信息放在各个生态系统的Pnpm扩展字段中
+func (x *PnpmLockParser) Parse(ctx context.Context, input *PnpmLockParserInput) (*models.JsProject, error) {
+	project, err := x.parseProject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return toJsProject(project, &jsEcosystemConverter[*models.PnpmLockProjectEcosystem, *models.PnpmLockModuleEcosystem, *models.PnpmLockComponentDependencyEcosystem]{
+		project: func(ecosystem *models.PnpmLockProjectEcosystem) *models.JsProjectEcosystem {
+			return &models.JsProjectEcosystem{PackageManager: models.PackageManagerPnpm, Pnpm: ecosystem}
+		},
+		module: func(ecosystem *models.PnpmLockModuleEcosystem) *models.JsModuleEcosystem {
+			return &models.JsModuleEcosystem{WorkspacePath: ecosystem.ImporterPath, Pnpm: ecosystem}
+		},
+		dependency: func(ecosystem *models.PnpmLockComponentDependencyEcosystem) *models.JsComponentDependencyEcosystem {
+			direct := ecosystem.Direct
+			return &models.JsComponentDependencyEcosystem{
+				Ecosystem: models.DependencyEcosystemNpm,
+				Resolved:  ecosystem.Resolved,
+				Integrity: ecosystem.Integrity,
+				Alias:     ecosystem.Alias,
+				Path:      ecosystem.DepPath,
+				Specifier: ecosystem.Specifier,
+				Direct:    &direct,
+				Dev:       trueOrNil(ecosystem.Dev),
+				Optional:  trueOrNil(ecosystem.Optional),
+				Link:      trueOrNil(ecosystem.Link),
+				Pnpm:      ecosystem,
+			}
+		},
+	}), nil
+}
+
+// parseProject 解析pnpm-lock.yaml文件，每个importer对应一个模块，模块的依赖是从importer出发可以到达的所有包
+// 项目根目录已知时会读取各个importer的package.json，使用其中的名称和版本，使用包管理器特有的生态系统类型
+func (x *PnpmLockParser) parseProject(ctx context.Context, input *PnpmLockParserInput) (*baseModels.Project[*models.PnpmLockProjectEcosystem, *models.PnpmLockModuleEcosystem, *models.PnpmLockComponentEcosystem, *models.PnpmLockComponentDependencyEcosystem], error) {
+	pnpmLock, err := x.ParseLockfile(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &baseModels.Project[*models.PnpmLockProjectEcosystem, *models.PnpmLockModuleEcosystem, *models.PnpmLockComponentEcosystem, *models.PnpmLockComponentDependencyEcosystem]{}
+	project.Name = "unknown"
+	project.ProjectEcosystem = &models.PnpmLockProjectEcosystem{
+		LockfileVersion:     pnpmLock.LockfileVersion,
+		Settings:            pnpmLock.Settings,
+		Overrides:           pnpmLock.Overrides,
+		PatchedDependencies: pnpmLock.PatchedDependencies,
+		Catalogs:            pnpmLock.Catalogs,
+	}
+
+	nodes := x.buildNodes(pnpmLock)
+	projectRootDirectory, hasRoot := input.projectRootDirectory()
+
+	importerPaths := make([]string, 0, len(pnpmLock.Importers))
+	for importerPath := range pnpmLock.Importers {
+		importerPaths = append(importerPaths, importerPath)
+	}
+	sort.Strings(importerPaths)
+
+	for _, importerPath := range importerPaths {
+		module := x.createImporterModule(pnpmLock, nodes, importerPath, pnpmLock.Importers[importerPath])
+
+		// pnpm-lock.yaml中没有项目的名称，需要从importer目录下的package.json获取，没有时使用importer的路径
+		var manifest *models.PackageJson
+		if hasRoot {
+			manifest = x.readImporterPackageJson(ctx, input, projectRootDirectory, importerPath)
+		}
+		if manifest != nil && manifest.Name != "" {
+			module.Name = manifest.Name
+			module.Version = manifest.Version
+		} else if importerPath == pnpmRootImporter {
+			module.Name = project.Name
+		} else {
+			module.Name = importerPath
+		}
+
+		if importerPath == pnpmRootImporter {
+			project.Name = module.Name
+			project.Version = module.Version
+		}
+		project.SetModule(module.Name, module)
+	}
+
+	return project, nil
+}
+
+// ParseLockfile 把pnpm-lock.yaml解析为lockfile本身的模型，不转换为项目
+func (x *PnpmLockParser) ParseLockfile(ctx context.Context, input *PnpmLockParserInput) (*models.PnpmLock, error) {
+	pnpmLockBytes, err := input.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pnpm-lock.yaml: %w", err)
+	}
+
+	pnpmLock, err := x.parsePnpmLock(pnpmLockBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pnpm-lock.yaml: %w", err)
+	}
+	return pnpmLock, nil
+}
+
+// parsePnpmLock 解析pnpm-lock.yaml的内容并统一不同版本的差异
+func (x *PnpmLockParser) parsePnpmLock(data []byte) (*models.PnpmLock, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("pnpm-lock.yaml file is empty")
+	}
+
+	// 新版本的pnpm可能在lockfile前面加一个记录配置依赖的yaml文档，项目的依赖在最后一个文档里
+	file := &pnpmLockFile{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		document := &pnpmLockFile{}
+		err := decoder.Decode(document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid pnpm lockfile: %w", err)
+		}
+		file = document
+	}
+
+	pnpmLock := &models.PnpmLock{
+		LockfileVersion:     file.LockfileVersion.Value,
+		Settings:            file.Settings,
+		Overrides:           file.Overrides,
+		PatchedDependencies: make(map[string]*models.PnpmLockPatchedDependency),
+		Catalogs:            file.Catalogs,
+		Importers:           make(map[string]*models.PnpmLockImporter),
+		Packages:            file.Packages,
+		Snapshots:           file.Snapshots,
+	}
+	if pnpmLock.LockfileVersion == "" {
+		return nil, fmt.Errorf("lockfileVersion not found")
+	}
+	if major := pnpmLockMajorVersion(pnpmLock.LockfileVersion); major < 5 || major > 9 {
+		return nil, fmt.Errorf("unsupported lockfileVersion %s", pnpmLock.LockfileVersion)
+	}
+	if pnpmLock.Packages == nil {
+		pnpmLock.Packages = make(map[string]*models.PnpmLockPackage)
+	}
+	if pnpmLock.Snapshots == nil {
+		pnpmLock.Snapshots = make(map[string]*models.PnpmLockSnapshot)
+	}
+
+	// 5.x中补丁信息只有hash，6.0开始是包含hash和path的对象
+	for key, node := range file.PatchedDependencies {
+		patched := &models.PnpmLockPatchedDependency{}
+		if node.Kind == yaml.ScalarNode {
+			patched.Hash = node.Value
+		} else if err := node.Decode(patched); err != nil {
+			return nil, fmt.Errorf("invalid patchedDependencies entry %q at line %d: %w", key, node.Line, err)
+		}
+		pnpmLock.PatchedDependencies[key] = patched
+	}
+
+	// 单项目的5.x lockfile没有importers
+	importers := file.Importers
+	if len(importers) == 0 {
+		importers = map[string]*pnpmLockImporter{pnpmRootImporter: &file.pnpmLockImporter}
+	}
+	for importerPath, importer := range importers {
+		if importer == nil {
+			importer = &pnpmLockImporter{}
+		}
+		converted := &models.PnpmLockImporter{}
+		var err error
+		if converted.Dependencies, err = x.parseImporterDependencies(importer.Dependencies, importer.Specifiers); err != nil {
+			return nil, err
+		}
+		if converted.DevDependencies, err = x.parseImporterDependencies(importer.DevDependencies, importer.Specifiers); err != nil {
+			return nil, err
+		}
+		if converted.OptionalDependencies, err = x.parseImporterDependencies(importer.OptionalDependencies, importer.Specifiers); err != nil {
+			return nil, err
+		}
+		pnpmLock.Importers[importerPath] = converted
+	}
+
+	return pnpmLock, nil
+}
+
+// parseImporterDependencies 把importer中的依赖统一转换为specifier加version的形式
+func (x *PnpmLockParser) parseImporterDependencies(dependencies map[string]yaml.Node, specifiers map[string]string) (map[string]*models.PnpmLockImporterDependency, error) {
+	converted := make(map[string]*models.PnpmLockImporterDependency, len(dependencies))
+	for name, node := range dependencies {
+		dependency := &models.PnpmLockImporterDependency{}
+		if node.Kind == yaml.ScalarNode {
+			dependency.Version = node.Value
+			dependency.Specifier = specifiers[name]
+		} else if err := node.Decode(dependency); err != nil {
+			return nil, fmt.Errorf("invalid importer dependency %q at line %d: %w", name, node.Line, err)
+		}
+		converted[name] = dependency
+	}
+	return converted, nil
+}
+
+// pnpmLockMajorVersion 返回lockfileVersion的主版本号，比如 5.4 -> 5、'9.0' -> 9，无法识别时返回0
+func pnpmLockMajorVersion(lockfileVersion string) int {
+	majorPart := lockfileVersion
+	if dotIndex := strings.Index(majorPart, "."); dotIndex >= 0 {
+		majorPart = majorPart[:dotIndex]
+	}
+	major, err := strconv.Atoi(majorPart)
+	if err != nil {
+		return 0
+	}
+	return major
+}
+
+// parsePnpmDepPath 从依赖路径中拆分出包名、版本和peer依赖后缀
+// 5.x: /name/1.0.0_peer@1.0.0，6.0: /name@1.0.0(peer@1.0.0)，9.0: name@1.0.0(peer@1.0.0)
+func parsePnpmDepPath(depPath string, major int) (name string, version string, peerSuffix string) {
+	depPath = strings.TrimPrefix(depPath, "/")
+	if major < 6 {
+		segments := strings.Split(depPath, "/")
+		nameLength := 1
+		if strings.HasPrefix(depPath, "@") {
+			nameLength = 2
+		}
+		if len(segments) <= nameLength {
+			return depPath, "", ""
+		}
+		name = strings.Join(segments[:nameLength], "/")
+		version = strings.Join(segments[nameLength:], "/")
+		// 版本号中不会有下划线，下划线之后是peer依赖后缀
+		if underscoreIndex := strings.Index(version, "_"); underscoreIndex >= 0 {
+			peerSuffix = version[underscoreIndex:]
+			version = version[:underscoreIndex]
+		}
+		return name, version, peerSuffix
+	}
+
+	if parenIndex := strings.Index(depPath, "("); parenIndex >= 0 {
+		peerSuffix = depPath[parenIndex:]
+		depPath = depPath[:parenIndex]
+	}
+	name, version = splitPackageSpec(depPath)
+	return name, version, peerSuffix
+}
+
+// pnpmDepPath 根据依赖名称和lockfile中记录的版本引用找到包在lockfile中的键，
+// 引用本身已经是依赖路径（别名或者不是来自registry的包）时直接使用
+func pnpmDepPath(major int, name string, reference string) string {
+	base := reference
+	if parenIndex := strings.Index(base, "("); parenIndex >= 0 {
+		base = base[:parenIndex]
+	}
+
+	if major < 9 {
+		if strings.HasPrefix(reference, "/") || strings.Contains(base, "/") {
+			return reference
+		}
+		if major < 6 {
+			return "/" + name + "/" + reference
+		}
+		return "/" + name + "@" + reference
+	}
+
+	// 9.0中别名依赖的引用是 real-name@1.0.0
+	if strings.LastIndex(base, "@") > 0 {
+		return reference
+	}
+	return name + "@" + reference
+}
+
+// buildNodes 把packages和snapshots统一转换为依赖图的节点，键是依赖路径
+func (x *PnpmLockParser) buildNodes(pnpmLock *models.PnpmLock) map[string]*pnpmLockNode {
+	major := pnpmLockMajorVersion(pnpmLock.LockfileVersion)
+	nodes := make(map[string]*pnpmLockNode)
+
+	newNode := func(depPath string, pkg *models.PnpmLockPackage) *pnpmLockNode {
+		node := &pnpmLockNode{DepPath: depPath, Package: pkg}
+		node.Name, node.Version, node.PeerSuffix = parsePnpmDepPath(depPath, major)
+		// 不是来自registry的包在条目中记录了真实的名称和版本
+		if pkg != nil && pkg.Name != "" {
+			node.Name = pkg.Name
+		}
+		if pkg != nil && pkg.Version != "" {
+			node.Version = pkg.Version
+		}
+		return node
+	}
+
+	if major >= 9 {
+		// 9.0中packages只有元数据，依赖关系在snapshots里
+		for depPath, snapshot := range pnpmLock.Snapshots {
+			if snapshot == nil {
+				snapshot = &models.PnpmLockSnapshot{}
+			}
+			name, version, _ := parsePnpmDepPath(depPath, major)
+			node := newNode(depPath, pnpmLock.Packages[name+"@"+version])
+			node.Dependencies = snapshot.Dependencies
+			node.OptionalDependencies = snapshot.OptionalDependencies
+			nodes[depPath] = node
+		}
+		for depPath, pkg := range pnpmLock.Packages {
+			if _, ok := nodes[depPath]; !ok {
+				nodes[depPath] = newNode(depPath, pkg)
+			}
+		}
+		return nodes
+	}
+
+	for depPath, pkg := range pnpmLock.Packages {
+		if pkg == nil {
+			pkg = &models.PnpmLockPackage{}
+		}
+		node := newNode(depPath, pkg)
+		node.Dependencies = pkg.Dependencies
+		node.OptionalDependencies = pkg.OptionalDependencies
+		nodes[depPath] = node
+	}
+	return nodes
+}
+
+// walkPnpmNodes 从给定的依赖出发广度优先遍历，返回可以到达的节点，includeOptional为false时不经过可选依赖
+func walkPnpmNodes(nodes map[string]*pnpmLockNode, major int, roots []string, includeOptional bool) map[string]bool {
+	visited := make(map[string]bool)
+	queue := make([]string, 0, len(roots))
+	for _, depPath := range roots {
+		if _, ok := nodes[depPath]; ok && !visited[depPath] {
+			visited[depPath] = true
+			queue = append(queue, depPath)
+		}
+	}
+
+	for len(queue) > 0 {
+		current := nodes[queue[0]]
+		queue = queue[1:]
+
+		edges := []map[string]string{current.Dependencies}
+		if includeOptional {
+			edges = append(edges, current.OptionalDependencies)
+		}
+		for _, dependencies := range edges {
+			for _, childName := range sortedStringMapKeys(dependencies) {
+				reference := dependencies[childName]
+				if strings.HasPrefix(reference, pnpmLinkPrefix) {
+					continue
+				}
+				childPath := pnpmDepPath(major, childName, reference)
+				if _, ok := nodes[childPath]; !ok || visited[childPath] {
+					continue
+				}
+				visited[childPath] = true
+				queue = append(queue, childPath)
+			}
+		}
+	}
+	return visited
+}
+
+// sortedPnpmDirectDependencies 返回importer的所有直接依赖，按名称排序
+func sortedPnpmDirectDependencies(importer *models.PnpmLockImporter) []*pnpmLockDirectDependency {
+	directDependencies := make([]*pnpmLockDirectDependency, 0)
+	add := func(dependencies map[string]*models.PnpmLockImporterDependency, dev bool, optional bool) {
+		for name, dependency := range dependencies {
+			if dependency == nil {
+				continue
+			}
+			directDependencies = append(directDependencies, &pnpmLockDirectDependency{Name: name, Dependency: dependency, Dev: dev, Optional: optional})
+		}
+	}
+	add(importer.Dependencies, false, false)
+	add(importer.DevDependencies, true, false)
+	add(importer.OptionalDependencies, false, true)
+	sort.SliceStable(directDependencies, func(i, j int) bool {
+		return directDependencies[i].Name < directDependencies[j].Name
+	})
+	return directDependencies
+}
+
+// createImporterModule 为一个importer创建模块，dev和optional标记按照从这个importer出发的可达性计算，
+// 只能通过开发依赖到达的是开发依赖，只能通过可选依赖到达的是可选依赖
+func (x *PnpmLockParser) createImporterModule(pnpmLock *models.PnpmLock, nodes map[string]*pnpmLockNode, importerPath string, importer *models.PnpmLockImporter) *baseModels.Module[*models.PnpmLockModuleEcosystem, *models.PnpmLockComponentEcosystem, *models.PnpmLockComponentDependencyEcosystem] {
+	major := pnpmLockMajorVersion(pnpmLock.LockfileVersion)
+
+	module := &baseModels.Module[*models.PnpmLockModuleEcosystem, *models.PnpmLockComponentEcosystem, *models.PnpmLockComponentDependencyEcosystem]{}
+	module.ModuleEcosystem = &models.PnpmLockModuleEcosystem{ImporterPath: importerPath}
+
+	dependencies := make([]*baseModels.ComponentDependency[*models.PnpmLockComponentDependencyEcosystem], 0)
+	direct := make(map[string]*pnpmLockDirectDependency)
+	var allRoots, prodRoots, nonOptionalRoots []string
+	for _, directDependency := range sortedPnpmDirectDependencies(importer) {
+		reference := directDependency.Dependency.Version
+
+		// 链接到workspace中的其它项目，它们的依赖属于自己的模块
+		if strings.HasPrefix(reference, pnpmLinkPrefix) {
+			dependency := &baseModels.ComponentDependency[*models.PnpmLockComponentDependencyEcosystem]{}
+			dependency.DependencyName = directDependency.Name
+			dependency.DependencyVersion = reference
+			dependency.ComponentDependencyEcosystem = &models.PnpmLockComponentDependencyEcosystem{
+				Direct:    true,
+				Link:      true,
+				Specifier: directDependency.Dependency.Specifier,
+				Dev:       directDependency.Dev,
+				Optional:  directDependency.Optional,
+				Catalog:   pnpmCatalogName(directDependency.Dependency.Specifier),
+			}
+			dependencies = append(dependencies, dependency)
+			continue
+		}
+
+		depPath := pnpmDepPath(major, directDependency.Name, reference)
+		if _, ok := direct[depPath]; !ok {
+			direct[depPath] = directDependency
+		}
+		allRoots = append(allRoots, depPath)
+		if !directDependency.Dev {
+			prodRoots = append(prodRoots, depPath)
+		}
+		if !directDependency.Optional {
+			nonOptionalRoots = append(nonOptionalRoots, depPath)
+		}
+	}
+
+	reachable := walkPnpmNodes(nodes, major, allRoots, true)
+	prodReachable := walkPnpmNodes(nodes, major, prodRoots, true)
+	nonOptionalReachable := walkPnpmNodes(nodes, major, nonOptionalRoots, false)
+	overridden := pnpmOverriddenDepPaths(pnpmLock.Overrides, nodes, major)
+
+	depPaths := make([]string, 0, len(reachable))
+	for depPath := range reachable {
+		depPaths = append(depPaths, depPath)
+	}
+	sort.Strings(depPaths)
+
+	for _, depPath := range depPaths {
+		node := nodes[depPath]
+		dependency := &baseModels.ComponentDependency[*models.PnpmLockComponentDependencyEcosystem]{}
+		dependency.DependencyName = node.Name
+		dependency.DependencyVersion = node.Version
+
+		ecosystem := &models.PnpmLockComponentDependencyEcosystem{
+			DepPath:    depPath,
+			PeerSuffix: node.PeerSuffix,
+			Dev:        !prodReachable[depPath],
+			Optional:   !nonOptionalReachable[depPath],
+			Overridden: overridden[depPath],
+			Patched:    strings.Contains(node.PeerSuffix, "patch_hash="),
+		}
+		if node.Package != nil {
+			ecosystem.Patched = ecosystem.Patched || node.Package.Patched
+			if resolution := node.Package.Resolution; resolution != nil {
+				ecosystem.Integrity = resolution.Integrity
+				switch {
+				case resolution.Tarball != "":
+					ecosystem.Resolved = resolution.Tarball
+				case resolution.Repo != "":
+					ecosystem.Resolved = resolution.Repo + "#" + resolution.Commit
+				default:
+					ecosystem.Resolved = resolution.Directory
+				}
+			}
+		}
+		if _, ok := pnpmLock.PatchedDependencies[node.Name+"@"+node.Version]; ok {
+			ecosystem.Patched = true
+		} else if _, ok := pnpmLock.PatchedDependencies[node.Name]; ok {
+			ecosystem.Patched = true
+		}
+		if directDependency, ok := direct[depPath]; ok {
+			ecosystem.Direct = true
+			ecosystem.Specifier = directDependency.Dependency.Specifier
+			ecosystem.Catalog = pnpmCatalogName(ecosystem.Specifier)
+			if directDependency.Name != node.Name {
+				ecosystem.Alias = directDependency.Name
+			}
+		}
+		dependency.ComponentDependencyEcosystem = ecosystem
+		dependencies = append(dependencies, dependency)
+	}
+
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		return dependencies[i].DependencyName < dependencies[j].DependencyName
+	})
+	module.Dependencies = dependencies
+	return module
+}
+
+// pnpmCatalogName 返回catalog:声明引用的catalog名称，不是catalog:声明时返回空字符串
+func pnpmCatalogName(specifier string) string {
+	if !strings.HasPrefix(specifier, pnpmCatalogPrefix) {
+		return ""
+	}
+	name := strings.TrimSpace(strings.TrimPrefix(specifier, pnpmCatalogPrefix))
+	if name == "" {
+		return PnpmDefaultCatalogName
+	}
+	return name
+}
+
+// pnpmOverrideSelector overrides中的一个选择器：[parent[@range]>]name[@range]
+type pnpmOverrideSelector struct {
+	ParentName  string
+	ParentRange string
+	Name        string
+	Range       string
+}
+
+// parsePnpmOverrideSelector 解析overrides的键，跟pnpm一样只有前一个字符不是空格、| 或 @ 的 > 才是父子之间的分隔符，
+// 所以 foo@>2 和 bar@>=1>foo 中版本范围里的 > 不会被当做分隔符
+func parsePnpmOverrideSelector(key string) *pnpmOverrideSelector {
+	selector := &pnpmOverrideSelector{}
+	child := key
+	for i := 1; i < len(key); i++ {
+		if key[i] == '>' && !strings.ContainsRune(" |@", rune(key[i-1])) {
+			selector.ParentName, selector.ParentRange = splitPackageSpec(strings.TrimSpace(key[:i]))
+			child = key[i+1:]
+			break
+		}
+	}
+	selector.Name, selector.Range = splitPackageSpec(strings.TrimSpace(child))
+	return selector
+}
+
+// matchesParent 判断父级包的版本是否满足选择器中父级的版本范围，没有范围时都满足
+func (x *pnpmOverrideSelector) matchesParent(version string) bool {
+	if x.ParentRange == "" {
+		return true
+	}
+	versionRange, err := parseSemverRange(x.ParentRange)
+	if err != nil {
+		return false
+	}
+	parsed, err := parseSemver(version)
+	return err == nil && versionRange.test(parsed)
+}
+
+// pnpmOverriddenDepPaths 返回被overrides覆盖的依赖路径。只标记按照包名（foo）和按照父级包（bar>foo、bar@1>foo）选择的覆盖，
+// 父级包是依赖路径对应的包，它的版本需要满足选择器中的范围。lockfile中只有覆盖后的版本，没有被覆盖前声明的版本范围，
+// 所以子级带有版本范围的选择器（foo@<2、bar>foo@1）无法判断是否生效，不做标记
+func pnpmOverriddenDepPaths(overrides map[string]string, nodes map[string]*pnpmLockNode, major int) map[string]bool {
+	names := make(map[string]bool)
+	byParent := make(map[string][]*pnpmOverrideSelector)
+	for _, key := range sortedStringMapKeys(overrides) {
+		selector := parsePnpmOverrideSelector(key)
+		if selector.Name == "" || selector.Range != "" {
+			continue
+		}
+		if selector.ParentName == "" {
+			names[selector.Name] = true
+			continue
+		}
+		byParent[selector.ParentName] = append(byParent[selector.ParentName], selector)
+	}
+
+	overridden := make(map[string]bool)
+	for depPath, node := range nodes {
+		if names[node.Name] {
+			overridden[depPath] = true
+		}
+		for _, selector := range byParent[node.Name] {
+			if !selector.matchesParent(node.Version) {
+				continue
+			}
+			for _, dependencies := range []map[string]string{node.Dependencies, node.OptionalDependencies} {
+				reference, ok := dependencies[selector.Name]
+				if !ok || strings.HasPrefix(reference, pnpmLinkPrefix) {
+					continue
+				}
+				overridden[pnpmDepPath(major, selector.Name, reference)] = true
+			}
+		}
+	}
+	return overridden
+}
+
+// readImporterPackageJson 读取importer目录下的package.json，不存在或者无法解析时返回nil
+func (x *PnpmLockParser) readImporterPackageJson(ctx context.Context, input *PnpmLockParserInput, projectRootDirectory string, importerPath string) *models.PackageJson {
+	packageJson, err := (&PackageJsonParser{}).ParseManifest(ctx, &PackageJsonParserInput{
+		ProjectRootDirectory: joinInputPath(input.FileSystem, projectRootDirectory, importerPath),
+		FileSystem:           input.FileSystem,
+	})
+	if err != nil {
+		return nil
+	}
+	return packageJson
+}
+
+func (x *PnpmLockParser) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+)
+
+// PnpmLockFileName pnpm-lock.yaml的文件名
+const PnpmLockFileName = "pnpm-lock.yaml"
+
+// PnpmLockParserInput 解析器的输入，按照 PnpmLockContent、PnpmLockReader、PnpmLockPath、ProjectRootDirectory 的优先级读取pnpm-lock.yaml
+type PnpmLockParserInput struct {
+	// PnpmLockPath pnpm-lock.yaml文件的路径
+	PnpmLockPath string
+
+	// PnpmLockContent 直接传入的pnpm-lock.yaml内容
+	PnpmLockContent string
+
+	// PnpmLockReader 从reader中读取pnpm-lock.yaml的内容，只能被读取一次
+	PnpmLockReader io.Reader
+
+	// ProjectRootDirectory 项目根目录，会读取其中的pnpm-lock.yaml
+	ProjectRootDirectory string
+
+	// FileSystem 指定后PnpmLockPath和ProjectRootDirectory都是这个文件系统中的路径
+	FileSystem fs.FS
+}
+
+// Read 读取pnpm-lock.yaml文件内容
+func (x *PnpmLockParserInput) Read(ctx context.Context) ([]byte, error) {
+
+	if x.PnpmLockContent != "" {
+		return []byte(x.PnpmLockContent), nil
+	}
+
+	if x.PnpmLockReader != nil {
+		return readInputReader(x.PnpmLockReader)
+	}
+
+	if x.PnpmLockPath != "" {
+		return readInputFile(x.FileSystem, x.PnpmLockPath)
+	}
+
+	return readInputFile(x.FileSystem, joinInputPath(x.FileSystem, x.ProjectRootDirectory, PnpmLockFileName))
+}
+
+// projectRootDirectory 返回pnpm-lock.yaml所在的项目根目录，优先使用ProjectRootDirectory，其次是PnpmLockPath所在的目录
+func (x *PnpmLockParserInput) projectRootDirectory() (string, bool) {
+	if x.ProjectRootDirectory != "" {
+		return x.ProjectRootDirectory, true
+	}
+	if x.PnpmLockPath != "" {
+		if x.FileSystem != nil {
+			return path.Dir(toFsPath(x.PnpmLockPath)), true
+		}
+		return filepath.Dir(x.PnpmLockPath), true
+	}
+	return "", false
+}
@@ -0,0 +1,354 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPnpmLockParser_ParseV5(t *testing.T) {
+	project, err := NewPnpmLockParser().Parse(context.Background(), &PnpmLockParserInput{PnpmLockPath: "test_data/pnpm-lock.yaml/v5.4.yaml"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "5.4", project.ProjectEcosystem.Pnpm.LockfileVersion)
+	assert.Equal(t, map[string]string{"minimist": "1.2.8"}, project.ProjectEcosystem.Pnpm.Overrides)
+	require.Len(t, project.Modules, 1)
+	module := project.TakeFirstModule()
+	assert.Equal(t, "unknown", module.Name, "没有package.json时无法得知项目名称")
+	assert.Equal(t, ".", module.ModuleEcosystem.Pnpm.ImporterPath)
+	require.Len(t, module.Dependencies, 9)
+	// 共用字段中的Path是依赖路径，链接的依赖没有路径，使用 name@version
+	dependencies := indexDependencies(module, dependencyPathKey)
+
+	reactDom := dependencies["/react-dom/18.2.0_react@18.2.0"]
+	require.NotNil(t, reactDom)
+	assert.Equal(t, "react-dom", reactDom.DependencyName)
+	assert.Equal(t, "18.2.0", reactDom.DependencyVersion)
+	assert.Equal(t, "_react@18.2.0", reactDom.ComponentDependencyEcosystem.Pnpm.PeerSuffix)
+	assert.Equal(t, "sha512-react-dom-18", reactDom.ComponentDependencyEcosystem.Pnpm.Integrity)
+	assert.True(t, reactDom.ComponentDependencyEcosystem.Pnpm.Direct)
+	assert.Equal(t, "^18.2.0", reactDom.ComponentDependencyEcosystem.Pnpm.Specifier)
+	assert.False(t, reactDom.ComponentDependencyEcosystem.Pnpm.Dev)
+
+	// 通过别名安装的依赖
+	lodash := dependencies["/lodash/4.17.21"]
+	require.NotNil(t, lodash)
+	assert.Equal(t, "lodash", lodash.DependencyName)
+	assert.Equal(t, "my-lodash", lodash.ComponentDependencyEcosystem.Pnpm.Alias)
+	assert.Equal(t, "npm:lodash@^4.17.21", lodash.ComponentDependencyEcosystem.Pnpm.Specifier)
+
+	isOdd := dependencies["/is-odd/3.0.1_2fbyk7syj6sxqbl6xuxd6okbfy"]
+	require.NotNil(t, isOdd)
+	assert.Equal(t, "3.0.1", isOdd.DependencyVersion)
+	assert.True(t, isOdd.ComponentDependencyEcosystem.Pnpm.Patched)
+
+	typescript := dependencies["/typescript/5.0.4"]
+	require.NotNil(t, typescript)
+	assert.True(t, typescript.ComponentDependencyEcosystem.Pnpm.Dev)
+	// 共用字段
+	assert.Equal(t, models.PackageManagerPnpm, project.ProjectEcosystem.PackageManager)
+	assert.Equal(t, ".", module.ModuleEcosystem.WorkspacePath)
+	assert.Equal(t, "/typescript/5.0.4", typescript.ComponentDependencyEcosystem.Path)
+	assert.True(t, *typescript.ComponentDependencyEcosystem.Dev)
+
+	// 传递依赖
+	scheduler := dependencies["/scheduler/0.23.0"]
+	require.NotNil(t, scheduler)
+	assert.False(t, scheduler.ComponentDependencyEcosystem.Pnpm.Direct)
+	assert.False(t, scheduler.ComponentDependencyEcosystem.Pnpm.Dev)
+}
+
+func TestPnpmLockParser_ParseV6(t *testing.T) {
+	project, err := NewPnpmLockParser().Parse(context.Background(), &PnpmLockParserInput{PnpmLockPath: "test_data/pnpm-lock.yaml/v6.0.yaml"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "6.0", project.ProjectEcosystem.Pnpm.LockfileVersion)
+	require.NotNil(t, project.ProjectEcosystem.Pnpm.Settings)
+	assert.True(t, project.ProjectEcosystem.Pnpm.Settings.AutoInstallPeers)
+
+	// 每个importer一个模块，没有package.json时使用importer的路径作为名称
+	require.Len(t, project.Modules, 3)
+	require.Contains(t, project.Modules, "unknown")
+	require.Contains(t, project.Modules, "packages/app")
+	require.Contains(t, project.Modules, "packages/utils")
+
+	root := project.Modules["unknown"]
+	require.Len(t, root.Dependencies, 2)
+	for _, dependency := range root.Dependencies {
+		assert.True(t, dependency.ComponentDependencyEcosystem.Pnpm.Dev, dependency.DependencyName)
+	}
+	assert.Equal(t, "@types/node", root.Dependencies[0].DependencyName)
+
+	app := project.Modules["packages/app"]
+	assert.Equal(t, "packages/app", app.ModuleEcosystem.Pnpm.ImporterPath)
+	appDependencies := indexDependencies(app, dependencyPathKey)
+	utils := appDependencies["@demo/utils@link:../utils"]
+	require.NotNil(t, utils)
+	assert.Equal(t, "link:../utils", utils.DependencyVersion)
+	assert.Equal(t, "workspace:*", utils.ComponentDependencyEcosystem.Pnpm.Specifier)
+
+	fsevents := appDependencies["/fsevents@2.3.2"]
+	require.NotNil(t, fsevents)
+	assert.True(t, fsevents.ComponentDependencyEcosystem.Pnpm.Optional)
+	assert.False(t, fsevents.ComponentDependencyEcosystem.Pnpm.Dev)
+	assert.NotNil(t, appDependencies["/debug@4.3.4"])
+	assert.NotNil(t, appDependencies["/ms@2.1.2"])
+	assert.Nil(t, appDependencies["/supports-color@9.4.0"], "app使用的debug没有peer依赖")
+
+	// 同一个版本在不同的peer组合下是不同的依赖
+	utilsModule := project.Modules["packages/utils"]
+	utilsDependencies := indexDependencies(utilsModule, dependencyPathKey)
+	debug := utilsDependencies["/debug@4.3.4(supports-color@9.4.0)"]
+	require.NotNil(t, debug)
+	assert.Equal(t, "4.3.4", debug.DependencyVersion)
+	assert.Equal(t, "(supports-color@9.4.0)", debug.ComponentDependencyEcosystem.Pnpm.PeerSuffix)
+	assert.Nil(t, utilsDependencies["/debug@4.3.4"])
+}
+
+func TestPnpmLockParser_ParseV9(t *testing.T) {
+	content, err := os.ReadFile("test_data/pnpm-lock.yaml/v9.0.yaml")
+	require.NoError(t, err)
+	fileSystem := fstest.MapFS{
+		"repo/pnpm-lock.yaml":               &fstest.MapFile{Data: content},
+		"repo/package.json":                 &fstest.MapFile{Data: []byte(`{"name": "monorepo", "version": "1.0.0", "private": true}`)},
+		"repo/packages/web/package.json":    &fstest.MapFile{Data: []byte(`{"name": "@demo/web", "version": "0.1.0"}`)},
+		"repo/packages/shared/package.json": &fstest.MapFile{Data: []byte(`{"name": "@demo/shared", "version": "0.2.0"}`)},
+	}
+
+	project, err := NewPnpmLockParser().Parse(context.Background(), &PnpmLockParserInput{FileSystem: fileSystem, ProjectRootDirectory: "repo"})
+	require.NoError(t, err)
+
+	// 名称和版本来自各个importer的package.json
+	assert.Equal(t, "monorepo", project.Name)
+	assert.Equal(t, "1.0.0", project.Version)
+	require.Len(t, project.Modules, 3)
+	web := project.Modules["@demo/web"]
+	require.NotNil(t, web)
+	assert.Equal(t, "0.1.0", web.Version)
+	assert.Equal(t, "packages/web", web.ModuleEcosystem.Pnpm.ImporterPath)
+	webDependencies := indexDependencies(web, dependencyPathKey)
+
+	// 包的依赖关系在snapshots里，元数据在packages里
+	reactDom := webDependencies["react-dom@18.2.0(react@18.2.0)"]
+	require.NotNil(t, reactDom)
+	assert.Equal(t, "sha512-react-dom-18", reactDom.ComponentDependencyEcosystem.Pnpm.Integrity)
+	assert.Equal(t, "(react@18.2.0)", reactDom.ComponentDependencyEcosystem.Pnpm.PeerSuffix)
+	assert.NotNil(t, webDependencies["scheduler@0.23.0"])
+
+	// overrides
+	jsTokens := webDependencies["js-tokens@4.0.0"]
+	require.NotNil(t, jsTokens)
+	assert.True(t, jsTokens.ComponentDependencyEcosystem.Pnpm.Overridden)
+	assert.False(t, reactDom.ComponentDependencyEcosystem.Pnpm.Overridden)
+
+	// catalogs
+	react := webDependencies["react@18.2.0"]
+	require.NotNil(t, react)
+	assert.Equal(t, PnpmDefaultCatalogName, react.ComponentDependencyEcosystem.Pnpm.Catalog)
+	lodash := webDependencies["lodash@3.10.1(patch_hash=5k2vz4yqqvldwlevpmsa4gmcbm)"]
+	require.NotNil(t, lodash)
+	assert.Equal(t, "legacy", lodash.ComponentDependencyEcosystem.Pnpm.Catalog)
+	assert.True(t, lodash.ComponentDependencyEcosystem.Pnpm.Patched)
+	assert.Equal(t, "3.10.1", project.ProjectEcosystem.Pnpm.Catalogs["legacy"]["lodash"].Version)
+
+	// 9.0中别名依赖的版本引用是真实的依赖路径
+	stringWidth := webDependencies["string-width@4.2.3"]
+	require.NotNil(t, stringWidth)
+	assert.Equal(t, "string-width-cjs", stringWidth.ComponentDependencyEcosystem.Pnpm.Alias)
+
+	shared := webDependencies["@demo/shared@link:../shared"]
+	require.NotNil(t, shared)
+	assert.True(t, shared.ComponentDependencyEcosystem.Pnpm.Link)
+
+	// 不是来自registry的包使用tarball
+	root := project.Modules["monorepo"]
+	require.Len(t, root.Dependencies, 1)
+	assert.Equal(t, "https://npm.example.com/vitest-fake/-/vitest-fake-1.0.0.tgz", root.Dependencies[0].ComponentDependencyEcosystem.Pnpm.Resolved)
+	assert.True(t, root.Dependencies[0].ComponentDependencyEcosystem.Pnpm.Dev)
+}
+
+func TestPnpmLockParser_ParseLockfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		importers []string
+		packages  int
+		snapshots int
+	}{
+		{name: "5.4", file: "v5.4.yaml", importers: []string{"."}, packages: 9},
+		{name: "6.0", file: "v6.0.yaml", importers: []string{".", "packages/app", "packages/utils"}, packages: 8},
+		{name: "9.0", file: "v9.0.yaml", importers: []string{".", "packages/shared", "packages/web"}, packages: 8, snapshots: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pnpmLock, err := NewPnpmLockParser().ParseLockfile(context.Background(), &PnpmLockParserInput{PnpmLockPath: filepath.Join("test_data", "pnpm-lock.yaml", tt.file)})
+			require.NoError(t, err)
+			assert.Equal(t, tt.name, pnpmLock.LockfileVersion)
+
+			importers := make([]string, 0, len(pnpmLock.Importers))
+			for importerPath := range pnpmLock.Importers {
+				importers = append(importers, importerPath)
+			}
+			assert.ElementsMatch(t, tt.importers, importers)
+			assert.Len(t, pnpmLock.Packages, tt.packages)
+			assert.Len(t, pnpmLock.Snapshots, tt.snapshots)
+		})
+	}
+
+	// 5.x的范围写在specifiers里，解析后和6.0以后的格式一致
+	pnpmLock, err := NewPnpmLockParser().ParseLockfile(context.Background(), &PnpmLockParserInput{PnpmLockPath: "test_data/pnpm-lock.yaml/v5.4.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, &models.PnpmLockImporterDependency{Specifier: "^5.0.0", Version: "5.0.4"}, pnpmLock.Importers["."].DevDependencies["typescript"])
+	assert.Equal(t, "patches/is-odd@3.0.1.patch", pnpmLock.PatchedDependencies["is-odd@3.0.1"].Path)
+	require.NotNil(t, pnpmLock.Packages["/typescript/5.0.4"].Dev)
+	assert.True(t, *pnpmLock.Packages["/typescript/5.0.4"].Dev)
+}
+
+func TestPnpmLockParser_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "空内容", content: "  \n"},
+		{name: "没有lockfileVersion", content: "packages: {}\n"},
+		{name: "不支持的版本", content: "lockfileVersion: 3\n"},
+		{name: "不是yaml", content: "lockfileVersion: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPnpmLockParser().Parse(context.Background(), &PnpmLockParserInput{PnpmLockContent: tt.content})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParsePnpmDepPath(t *testing.T) {
+	tests := []struct {
+		depPath    string
+		major      int
+		name       string
+		version    string
+		peerSuffix string
+	}{
+		{depPath: "/lodash/4.17.21", major: 5, name: "lodash", version: "4.17.21"},
+		{depPath: "/@types/react-dom/18.2.0_@types+react@18.2.0", major: 5, name: "@types/react-dom", version: "18.2.0", peerSuffix: "_@types+react@18.2.0"},
+		{depPath: "/@babel/core@7.22.5(supports-color@9.4.0)", major: 6, name: "@babel/core", version: "7.22.5", peerSuffix: "(supports-color@9.4.0)"},
+		{depPath: "@types/node@20.4.2", major: 9, name: "@types/node", version: "20.4.2"},
+		{depPath: "a@1.0.0(b@2.0.0)(c@3.0.0)", major: 9, name: "a", version: "1.0.0", peerSuffix: "(b@2.0.0)(c@3.0.0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.depPath, func(t *testing.T) {
+			name, version, peerSuffix := parsePnpmDepPath(tt.depPath, tt.major)
+			assert.Equal(t, tt.name, name)
+			assert.Equal(t, tt.version, version)
+			assert.Equal(t, tt.peerSuffix, peerSuffix)
+		})
+	}
+
+	assert.Equal(t, "/lodash/4.17.21", pnpmDepPath(5, "lodash", "4.17.21"))
+	assert.Equal(t, "/lodash/4.17.21", pnpmDepPath(5, "my-lodash", "/lodash/4.17.21"))
+	assert.Equal(t, "/debug@4.3.4(supports-color@9.4.0)", pnpmDepPath(6, "debug", "4.3.4(supports-color@9.4.0)"))
+	assert.Equal(t, "@types/node@20.4.2", pnpmDepPath(9, "@types/node", "20.4.2"))
+	assert.Equal(t, "@scope/real@1.0.0", pnpmDepPath(9, "alias", "@scope/real@1.0.0"))
+}
+
+func TestParsePnpmOverrideSelector(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected *pnpmOverrideSelector
+	}{
+		{key: "foo", expected: &pnpmOverrideSelector{Name: "foo"}},
+		{key: "@scope/foo@<2", expected: &pnpmOverrideSelector{Name: "@scope/foo", Range: "<2"}},
+		{key: "foo@>2", expected: &pnpmOverrideSelector{Name: "foo", Range: ">2"}},
+		{key: "bar>foo", expected: &pnpmOverrideSelector{ParentName: "bar", Name: "foo"}},
+		{key: "bar@>=1>foo@^2", expected: &pnpmOverrideSelector{ParentName: "bar", ParentRange: ">=1", Name: "foo", Range: "^2"}},
+		{key: "@scope/bar@1 || 2>@scope/foo", expected: &pnpmOverrideSelector{ParentName: "@scope/bar", ParentRange: "1 || 2", Name: "@scope/foo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parsePnpmOverrideSelector(tt.key))
+		})
+	}
+}
+
+func TestPnpmLockParser_Overrides(t *testing.T) {
+	content := `lockfileVersion: '9.0'
+
+overrides:
+  a@1>foo: 1.0.0
+  b@2>foo: 3.0.0
+  bar: 1.0.0
+  baz@<2: 1.5.0
+
+importers:
+
+  .:
+    dependencies:
+      a:
+        specifier: ^1.0.0
+        version: 1.0.0
+      b:
+        specifier: ^1.0.0
+        version: 1.0.0
+      bar:
+        specifier: ^2.0.0
+        version: 1.0.0
+      baz:
+        specifier: ^1.0.0
+        version: 1.5.0
+
+packages:
+
+  a@1.0.0:
+    resolution: {integrity: sha512-a}
+  b@1.0.0:
+    resolution: {integrity: sha512-b}
+  bar@1.0.0:
+    resolution: {integrity: sha512-bar}
+  baz@1.5.0:
+    resolution: {integrity: sha512-baz}
+  foo@1.0.0:
+    resolution: {integrity: sha512-foo-1}
+  foo@2.0.0:
+    resolution: {integrity: sha512-foo-2}
+
+snapshots:
+
+  a@1.0.0:
+    dependencies:
+      foo: 1.0.0
+  b@1.0.0:
+    dependencies:
+      foo: 2.0.0
+  bar@1.0.0: {}
+  baz@1.5.0: {}
+  foo@1.0.0: {}
+  foo@2.0.0: {}
+`
+	project, err := NewPnpmLockParser().Parse(context.Background(), &PnpmLockParserInput{PnpmLockContent: content})
+	require.NoError(t, err)
+	overridden := make(map[string]bool)
+	for _, dependency := range project.TakeFirstModule().Dependencies {
+		overridden[dependency.ComponentDependencyEcosystem.Path] = dependency.ComponentDependencyEcosystem.Pnpm.Overridden
+	}
+	assert.Equal(t, map[string]bool{
+		"a@1.0.0":   false,
+		"b@1.0.0":   false,
+		"bar@1.0.0": true,
+		// 子级带有版本范围的选择器无法判断，不做标记
+		"baz@1.5.0": false,
+		// 只有a@1下面的foo被覆盖，b的版本不满足b@2
+		"foo@1.0.0": true,
+		"foo@2.0.0": false,
+	}, overridden)
+}
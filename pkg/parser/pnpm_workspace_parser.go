@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// pnpmWorkspacePrefix workspace内部依赖的版本声明前缀，比如 workspace:^、workspace:*
+const pnpmWorkspacePrefix = "workspace:"
+
+// PnpmWorkspaceParser 用于解析pnpm-workspace.yaml文件
+type PnpmWorkspaceParser struct {
+}
+
+func NewPnpmWorkspaceParser() *PnpmWorkspaceParser {
+	return &PnpmWorkspaceParser{}
+}
+
+// Parse 解析pnpm-workspace.yaml，得到workspace的glob和catalog
+func (x *PnpmWorkspaceParser) Parse(ctx context.Context, input *PnpmWorkspaceParserInput) (*models.PnpmWorkspace, error) {
+	data, err := input.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pnpm-workspace.yaml: %w", err)
+	}
+
+	workspace := &models.PnpmWorkspace{}
+	if err := yaml.Unmarshal(data, workspace); err != nil {
+		return nil, fmt.Errorf("failed to parse pnpm-workspace.yaml: %w", err)
+	}
+	return workspace, nil
+}
+
+// resolvePnpmSpecifier 把pnpm特有的依赖声明替换为具体的版本范围，无法替换时ok返回false：
+//   - catalog: 和 catalog:<name> 替换为catalog中为这个包声明的范围
+//   - workspace: 按照pnpm publish的规则替换为workspace中对应项目的版本，
+//     workspace:* -> 1.0.0，workspace:^ -> ^1.0.0，workspace:~ -> ~1.0.0，workspace:^1.0.0 -> ^1.0.0，
+//     别名 workspace:foo@^ -> npm:foo@^1.0.0
+func resolvePnpmSpecifier(name string, specifier string, workspace *models.PnpmWorkspace, workspaceVersions map[string]string) (string, bool) {
+	if strings.HasPrefix(specifier, pnpmCatalogPrefix) {
+		if workspace == nil {
+			return "", false
+		}
+		return workspace.CatalogRange(strings.TrimSpace(strings.TrimPrefix(specifier, pnpmCatalogPrefix)), name)
+	}
+
+	if !strings.HasPrefix(specifier, pnpmWorkspacePrefix) {
+		return "", false
+	}
+	versionRange := strings.TrimPrefix(specifier, pnpmWorkspacePrefix)
+
+	// workspace:foo@^ 引用的是名称为foo的项目
+	alias := ""
+	if aliasName, aliasRange := splitPackageSpec(versionRange); aliasRange != "" || strings.HasSuffix(versionRange, "@") {
+		alias, name, versionRange = aliasName, aliasName, aliasRange
+	}
+
+	// 相对路径引用的项目需要知道目录结构，这里不处理
+	if strings.HasPrefix(versionRange, ".") || strings.HasPrefix(versionRange, "/") {
+		return "", false
+	}
+
+	var resolved string
+	switch versionRange {
+	case "*", "", "^", "~":
+		version, ok := workspaceVersions[name]
+		if !ok || version == "" {
+			return "", false
+		}
+		resolved = strings.TrimPrefix(versionRange, "*") + version
+	default:
+		resolved = versionRange
+	}
+
+	if alias != "" {
+		return NpmAliasPrefix + alias + "@" + resolved, true
+	}
+	return resolved, true
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"io/fs"
+)
+
+// PnpmWorkspaceFileName pnpm-workspace.yaml的文件名
+const PnpmWorkspaceFileName = "pnpm-workspace.yaml"
+
+// PnpmWorkspaceParserInput 解析器的输入，按照 PnpmWorkspaceContent、PnpmWorkspaceReader、PnpmWorkspacePath、ProjectRootDirectory 的优先级读取pnpm-workspace.yaml
+type PnpmWorkspaceParserInput struct {
+	// PnpmWorkspacePath pnpm-workspace.yaml文件的路径
+	PnpmWorkspacePath string
+
+	// PnpmWorkspaceContent 直接传入的pnpm-workspace.yaml内容
+	PnpmWorkspaceContent string
+
+	// PnpmWorkspaceReader 从reader中读取pnpm-workspace.yaml的内容，只能被读取一次
+	PnpmWorkspaceReader io.Reader
+
+	// ProjectRootDirectory 项目根目录，会读取其中的pnpm-workspace.yaml
+	ProjectRootDirectory string
+
+	// FileSystem 指定后PnpmWorkspacePath和ProjectRootDirectory都是这个文件系统中的路径
+	FileSystem fs.FS
+}
+
+// Read 读取pnpm-workspace.yaml文件内容
+func (x *PnpmWorkspaceParserInput) Read(ctx context.Context) ([]byte, error) {
+
+	if x.PnpmWorkspaceContent != "" {
+		return []byte(x.PnpmWorkspaceContent), nil
+	}
+
+	if x.PnpmWorkspaceReader != nil {
+		return readInputReader(x.PnpmWorkspaceReader)
+	}
+
+	if x.PnpmWorkspacePath != "" {
+		return readInputFile(x.FileSystem, x.PnpmWorkspacePath)
+	}
+
+	return readInputFile(x.FileSystem, joinInputPath(x.FileSystem, x.ProjectRootDirectory, PnpmWorkspaceFileName))
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPnpmWorkspaceParser_Parse(t *testing.T) {
+	content := `packages:
+  - 'packages/*'
+  - 'apps/**'
+  - '!**/test/**'
+
+catalog:
+  react: ^18.2.0
+  react-dom: ^18.2.0
+
+catalogs:
+  legacy:
+    react: ^16.14.0
+  default:
+    lodash: ^4.17.21
+
+onlyBuiltDependencies:
+  - esbuild
+`
+	fileSystem := fstest.MapFS{
+		"repo/pnpm-workspace.yaml": &fstest.MapFile{Data: []byte(content)},
+	}
+
+	workspace, err := NewPnpmWorkspaceParser().Parse(context.Background(), &PnpmWorkspaceParserInput{FileSystem: fileSystem, ProjectRootDirectory: "repo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"packages/*", "apps/**", "!**/test/**"}, workspace.Packages)
+	assert.Equal(t, []string{"esbuild"}, workspace.OnlyBuiltDependencies)
+
+	tests := []struct {
+		catalog string
+		name    string
+		want    string
+		wantOk  bool
+	}{
+		{catalog: "", name: "react", want: "^18.2.0", wantOk: true},
+		{catalog: "default", name: "react-dom", want: "^18.2.0", wantOk: true},
+		{catalog: "", name: "lodash", want: "^4.17.21", wantOk: true},
+		{catalog: "legacy", name: "react", want: "^16.14.0", wantOk: true},
+		{catalog: "legacy", name: "react-dom"},
+		{catalog: "missing", name: "react"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.catalog+"/"+tt.name, func(t *testing.T) {
+			got, ok := workspace.CatalogRange(tt.catalog, tt.name)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = NewPnpmWorkspaceParser().Parse(context.Background(), &PnpmWorkspaceParserInput{PnpmWorkspaceContent: "packages: [\n"})
+	assert.Error(t, err)
+}
+
+func TestResolvePnpmSpecifier(t *testing.T) {
+	workspace := &models.PnpmWorkspace{Catalog: map[string]string{"react": "^18.2.0"}}
+	versions := map[string]string{"foo": "1.5.0", "@scope/bar": "2.0.0"}
+
+	tests := []struct {
+		name      string
+		specifier string
+		want      string
+		wantOk    bool
+	}{
+		{name: "foo", specifier: "workspace:*", want: "1.5.0", wantOk: true},
+		{name: "foo", specifier: "workspace:^", want: "^1.5.0", wantOk: true},
+		{name: "foo", specifier: "workspace:~", want: "~1.5.0", wantOk: true},
+		{name: "foo", specifier: "workspace:^1.0.0", want: "^1.0.0", wantOk: true},
+		{name: "bar", specifier: "workspace:@scope/bar@*", want: "npm:@scope/bar@2.0.0", wantOk: true},
+		{name: "foo", specifier: "workspace:../foo"},
+		{name: "unknown", specifier: "workspace:*"},
+		{name: "react", specifier: "catalog:", want: "^18.2.0", wantOk: true},
+		{name: "react", specifier: "catalog:other"},
+		{name: "react", specifier: "^18.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"@"+tt.specifier, func(t *testing.T) {
+			got, ok := resolvePnpmSpecifier(tt.name, tt.specifier, workspace, versions)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/scagogogo/sca-base-module-ecosystem-parser/pkg/parser"
+)
+
+// ProjectParser 自动识别项目使用的包管理器，结合package.json和包管理器的lockfile解析出一个项目，
+// 不需要调用方自己判断目录中有哪些文件、应该使用哪个解析器
+type ProjectParser struct {
+}
+
+var _ parser.Parser[*ProjectParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &ProjectParser{}
+
+func NewProjectParser() *ProjectParser {
+	return &ProjectParser{}
+}
+
+const ProjectParserName = "project-parser"
+
+// YarnrcYmlFileName yarn Berry的配置文件，存在时说明项目使用的是yarn
+const YarnrcYmlFileName = ".yarnrc.yml"
+
+// BunLockbFileName 旧版本Bun使用的二进制lockfile，无法解析
+const BunLockbFileName = "bun.lockb"
+
+// projectLockfiles 可以识别的lockfile，按照优先级排列，同时存在多个时选择排在前面的。
+// 从npm迁移到其它包管理器后经常留下过期的package-lock.json，所以npm的lockfile排在其它包管理器之后；
+// 同一个包管理器的lockfile按照包管理器自己的规则排列：npm同时存在时使用npm-shrinkwrap.json，Bun优先使用文本格式的bun.lock。
+// 不使用文件的修改时间，checkout、复制和解压都会改变修改时间，同一个项目在不同的环境中会得到不同的结果
+var projectLockfiles = []struct {
+	FileName       string
+	PackageManager models.PackageManager
+}{
+	{FileName: PnpmLockFileName, PackageManager: models.PackageManagerPnpm},
+	{FileName: YarnLockFileName, PackageManager: models.PackageManagerYarn},
+	{FileName: BunLockFileName, PackageManager: models.PackageManagerBun},
+	{FileName: BunLockbFileName, PackageManager: models.PackageManagerBun},
+	{FileName: NpmShrinkwrapFileName, PackageManager: models.PackageManagerNpm},
+	{FileName: PackageLockJsonFileName, PackageManager: models.PackageManagerNpm},
+	{FileName: DenoLockFileName, PackageManager: models.PackageManagerDeno},
+}
+
+func (x *ProjectParser) GetName() string {
+	return ProjectParserName
+}
+
+func (x *ProjectParser) Init(ctx context.Context) error {
+	return nil
+}
+
+// Detect 识别项目使用的包管理器和应该使用的lockfile，判断的依据依次是：
+// package.json中的packageManager字段、.yarnrc.yml、项目中存在的lockfile（有多个时按照projectLockfiles中的优先级选择），
+// 什么都没有时默认是npm
+func (x *ProjectParser) Detect(ctx context.Context, input *ProjectParserInput) (*models.ProjectDetection, error) {
+	detection, _, err := x.detect(ctx, input)
+	return detection, err
+}
+
+// detect 识别包管理器，同时返回解析出的package.json，没有package.json时为nil
+func (x *ProjectParser) detect(ctx context.Context, input *ProjectParserInput) (*models.ProjectDetection, *models.PackageJson, error) {
+	if input == nil {
+		return nil, nil, fmt.Errorf("input cannot be nil")
+	}
+	detection := &models.ProjectDetection{Lockfiles: make([]string, 0)}
+
+	var packageJson *models.PackageJson
+	switch {
+	case input.exists(PackageJsonFileName):
+		var err error
+		packageJson, err = (&PackageJsonParser{}).ParseManifest(ctx, &PackageJsonParserInput{ProjectRootDirectory: input.ProjectRootDirectory, FileSystem: input.FileSystem})
+		if err != nil {
+			return nil, nil, err
+		}
+		detection.Manifest = PackageJsonFileName
+	case input.exists(DenoJsonFileName):
+		detection.Manifest = DenoJsonFileName
+	case input.exists(DenoJsoncFileName):
+		detection.Manifest = DenoJsoncFileName
+	}
+
+	// 找出所有的lockfile，保持projectLockfiles中的优先级顺序
+	type foundLockfile struct {
+		FileName       string
+		PackageManager models.PackageManager
+	}
+	found := make([]*foundLockfile, 0)
+	for _, candidate := range projectLockfiles {
+		if !input.exists(candidate.FileName) {
+			continue
+		}
+		found = append(found, &foundLockfile{FileName: candidate.FileName, PackageManager: candidate.PackageManager})
+		detection.Lockfiles = append(detection.Lockfiles, candidate.FileName)
+	}
+	lockfileOf := func(packageManager models.PackageManager) string {
+		for _, lockfile := range found {
+			if lockfile.PackageManager == packageManager {
+				return lockfile.FileName
+			}
+		}
+		return ""
+	}
+
+	declaredManager, declaredVersion := parsePackageManagerField(packageJson)
+	switch {
+	case declaredManager != "":
+		detection.PackageManager = declaredManager
+		detection.PackageManagerVersion = declaredVersion
+		detection.Reasons = append(detection.Reasons, fmt.Sprintf("package.json declares packageManager %s", packageJson.PackageManager))
+		detection.Lockfile = lockfileOf(declaredManager)
+		if detection.Lockfile == "" {
+			detection.Warnings = append(detection.Warnings, fmt.Sprintf("packageManager is %s but no %s lockfile was found, dependencies are taken from the manifest only", declaredManager, declaredManager))
+		}
+	case input.exists(YarnrcYmlFileName):
+		detection.PackageManager = models.PackageManagerYarn
+		detection.Reasons = append(detection.Reasons, fmt.Sprintf("%s exists", YarnrcYmlFileName))
+		detection.Lockfile = lockfileOf(models.PackageManagerYarn)
+		if detection.Lockfile == "" {
+			detection.Warnings = append(detection.Warnings, fmt.Sprintf("%s exists but no yarn.lock was found, dependencies are taken from the manifest only", YarnrcYmlFileName))
+		}
+	case len(found) > 0:
+		detection.PackageManager = found[0].PackageManager
+		detection.Lockfile = found[0].FileName
+		if len(found) == 1 {
+			detection.Reasons = append(detection.Reasons, fmt.Sprintf("%s is the only lockfile", found[0].FileName))
+		} else {
+			detection.Reasons = append(detection.Reasons, fmt.Sprintf("%s has the highest precedence of %d lockfiles", found[0].FileName, len(found)))
+		}
+	case detection.Manifest == DenoJsonFileName || detection.Manifest == DenoJsoncFileName:
+		detection.PackageManager = models.PackageManagerDeno
+		detection.Reasons = append(detection.Reasons, fmt.Sprintf("%s exists and no lockfile was found", detection.Manifest))
+	default:
+		detection.PackageManager = models.PackageManagerNpm
+		detection.Reasons = append(detection.Reasons, "no lockfile or package manager configuration found, defaulting to npm")
+	}
+	if detection.Lockfile != "" && len(detection.Reasons) > 0 && !strings.Contains(detection.Reasons[0], detection.Lockfile) {
+		detection.Reasons = append(detection.Reasons, fmt.Sprintf("using %s", detection.Lockfile))
+	}
+
+	// 不同包管理器的lockfile同时存在时，只有一个是真正被使用的，其它的很可能已经过期；
+	// 同一个包管理器的其它lockfile也不会被使用，比如存在npm-shrinkwrap.json时的package-lock.json
+	conflicting := make([]string, 0)
+	for _, lockfile := range found {
+		switch {
+		case lockfile.PackageManager != detection.PackageManager:
+			conflicting = append(conflicting, lockfile.FileName)
+		case detection.Lockfile != "" && lockfile.FileName != detection.Lockfile:
+			detection.Warnings = append(detection.Warnings, fmt.Sprintf("%s is ignored because %s takes precedence over it", lockfile.FileName, detection.Lockfile))
+		}
+	}
+	if len(conflicting) > 0 {
+		sort.Strings(conflicting)
+		detection.Warnings = append(detection.Warnings, fmt.Sprintf("conflicting lockfiles found: %s are ignored because the project uses %s", strings.Join(conflicting, ", "), detection.PackageManager))
+	}
+
+	// 二进制的bun.lockb无法解析
+	if detection.Lockfile == BunLockbFileName {
+		detection.Lockfile = ""
+		detection.Warnings = append(detection.Warnings, fmt.Sprintf("%s is a binary lockfile and cannot be parsed, dependencies are taken from the manifest only; run bun install --save-text-lockfile to generate %s", BunLockbFileName, BunLockFileName))
+	}
+
+	return detection, packageJson, nil
+}
+
+// parsePackageManagerField 解析packageManager字段，比如 pnpm@9.1.0+sha512.xxx 解析为 pnpm 和 9.1.0，无法识别时返回空字符串
+func parsePackageManagerField(packageJson *models.PackageJson) (models.PackageManager, string) {
+	if packageJson == nil || packageJson.PackageManager == "" {
+		return "", ""
+	}
+	name, version := splitPackageSpec(packageJson.PackageManager)
+	if plusIndex := strings.Index(version, "+"); plusIndex >= 0 {
+		version = version[:plusIndex]
+	}
+	switch packageManager := models.PackageManager(name); packageManager {
+	case models.PackageManagerNpm, models.PackageManagerYarn, models.PackageManagerPnpm, models.PackageManagerBun:
+		return packageManager, version
+	}
+	return "", ""
+}
+
+// Parse 识别项目使用的包管理器，使用它的lockfile解析出项目，再通过ManifestMerger合并package.json，
+// 得到一个完整的项目：名称和版本以package.json为准，根模块的依赖标记是否是直接依赖、声明的版本范围，
+// 能构建依赖图的lockfile（package-lock.json、npm-shrinkwrap.json、yarn.lock）还会计算prod、dev、optional、peer范围。
+// 识别的结果记录在ProjectEcosystem.Detection中，没有可以使用的lockfile时依赖来自清单文件
+func (x *ProjectParser) Parse(ctx context.Context, input *ProjectParserInput) (*models.JsProject, error) {
+	detection, packageJson, err := x.detect(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if detection.Manifest == "" && detection.Lockfile == "" {
+		return nil, fmt.Errorf("no manifest or lockfile found in %s", input.ProjectRootDirectory)
+	}
+
+	project, graph, err := x.parseLockfile(ctx, input, detection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", detection.Lockfile, err)
+	}
+
+	// lockfile中没有名称和版本时使用package.json中的
+	if packageJson != nil {
+		if packageJson.Name != "" && (project.Name == "" || project.Name == "unknown") {
+			project.Name = packageJson.Name
+			if module, ok := project.Modules["unknown"]; ok {
+				delete(project.Modules, "unknown")
+				module.Name = packageJson.Name
+				project.SetModule(module.Name, module)
+			}
+		}
+		if project.Version == "" {
+			project.Version = packageJson.Version
+		}
+	}
+
+	// 依赖来自lockfile时使用package.json中的声明标记根模块的依赖，没有名称的package.json对应项目的根模块
+	if packageJson != nil && detection.Lockfile != "" {
+		manifest := *packageJson
+		if manifest.Name == "" {
+			manifest.Name = project.Name
+		}
+		if project, err = NewManifestMerger().Merge(&manifest, project, graph); err != nil {
+			return nil, err
+		}
+	}
+
+	// 保留lockfile解析器设置的包管理器特有信息，只补充识别结果
+	if project.ProjectEcosystem == nil {
+		project.ProjectEcosystem = &models.JsProjectEcosystem{}
+	}
+	project.ProjectEcosystem.PackageManager = detection.PackageManager
+	project.ProjectEcosystem.Detection = detection
+	return project, nil
+}
+
+// parseLockfile 使用识别出的lockfile对应的解析器解析项目，package-lock.json、npm-shrinkwrap.json和yarn.lock
+// 同时构建依赖图，用于合并package.json时计算依赖范围，其它lockfile返回的依赖图为nil
+func (x *ProjectParser) parseLockfile(ctx context.Context, input *ProjectParserInput, detection *models.ProjectDetection) (*models.JsProject, *models.DependencyGraph, error) {
+	var project *models.JsProject
+	var graph *models.DependencyGraph
+	var err error
+	switch detection.Lockfile {
+	case "":
+		project, err = x.parseManifest(ctx, input, detection)
+	case PackageLockJsonFileName, NpmShrinkwrapFileName:
+		lockInput := &PackageLockJsonParserInput{
+			PackageLockJsonPath: joinInputPath(input.FileSystem, input.ProjectRootDirectory, detection.Lockfile),
+			FileSystem:          input.FileSystem,
+		}
+		if project, err = NewPackageLockParser().Parse(ctx, lockInput); err == nil {
+			graph, err = NewPackageLockParser().ParseDependencyGraph(ctx, lockInput)
+		}
+	case YarnLockFileName:
+		yarnInput := &YarnLockParserInput{ProjectRootDirectory: input.ProjectRootDirectory, FileSystem: input.FileSystem}
+		if project, err = NewYarnLockParser().Parse(ctx, yarnInput); err == nil {
+			graph, err = NewYarnLockParser().ParseDependencyGraph(ctx, yarnInput)
+		}
+	case PnpmLockFileName:
+		project, err = NewPnpmLockParser().Parse(ctx, &PnpmLockParserInput{ProjectRootDirectory: input.ProjectRootDirectory, FileSystem: input.FileSystem})
+	case BunLockFileName:
+		project, err = NewBunLockParser().Parse(ctx, &BunLockParserInput{ProjectRootDirectory: input.ProjectRootDirectory, FileSystem: input.FileSystem})
+	case DenoLockFileName:
+		project, err = x.parseDeno(ctx, input)
+	default:
+		err = fmt.Errorf("unsupported lockfile %s", detection.Lockfile)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return project, graph, nil
+}
+
+// parseManifest 没有lockfile时只使用清单文件中声明的依赖
+func (x *ProjectParser) parseManifest(ctx context.Context, input *ProjectParserInput, detection *models.ProjectDetection) (*models.JsProject, error) {
+	if detection.Manifest == DenoJsonFileName || detection.Manifest == DenoJsoncFileName {
+		return x.parseDeno(ctx, input)
+	}
+
+	// 私有项目的package.json可以没有名称，这时使用unknown；pnpm workspace中的包没有lockfile可以参考，
+	// 所以使用pnpm-workspace.yaml替换 catalog: 和 workspace: 声明
+	packageJsonParser := &PackageJsonParser{}
+	packageJsonInput := &PackageJsonParserInput{ProjectRootDirectory: input.ProjectRootDirectory, FileSystem: input.FileSystem, ResolvePnpmWorkspace: true}
+	packageJson, err := packageJsonParser.ParseManifest(ctx, packageJsonInput)
+	if err != nil {
+		return nil, err
+	}
+	if packageJson.Name == "" {
+		packageJson.Name = "unknown"
+	}
+	packageJsonInput, err = packageJsonParser.withPnpmWorkspace(ctx, packageJsonInput)
+	if err != nil {
+		return nil, err
+	}
+	return packageJsonParser.buildProject(packageJson, packageJsonInput), nil
+}
+
+// parseDeno 使用DenoParser解析
+func (x *ProjectParser) parseDeno(ctx context.Context, input *ProjectParserInput) (*models.JsProject, error) {
+	return NewDenoParser().Parse(ctx, &DenoParserInput{ProjectRootDirectory: input.ProjectRootDirectory, FileSystem: input.FileSystem})
+}
+
+func (x *ProjectParser) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"io/fs"
+	"os"
+)
+
+// ProjectParserInput 自动识别包管理器的解析器的输入，只需要指定项目根目录
+type ProjectParserInput struct {
+	// ProjectRootDirectory 项目根目录，会在其中查找清单文件、lockfile和包管理器的配置文件
+	ProjectRootDirectory string
+
+	// FileSystem 指定后ProjectRootDirectory是这个文件系统中的路径
+	FileSystem fs.FS
+}
+
+// stat 获取项目根目录中文件的信息
+func (x *ProjectParserInput) stat(fileName string) (fs.FileInfo, error) {
+	filePath := joinInputPath(x.FileSystem, x.ProjectRootDirectory, fileName)
+	if x.FileSystem == nil {
+		return os.Stat(filePath)
+	}
+	return fs.Stat(x.FileSystem, filePath)
+}
+
+// exists 项目根目录中是否存在这个文件
+func (x *ProjectParserInput) exists(fileName string) bool {
+	info, err := x.stat(fileName)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,274 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readProjectTestData 读取测试数据，作为MapFS中的文件
+func readProjectTestData(t *testing.T, path string) *fstest.MapFile {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return &fstest.MapFile{Data: data, ModTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestProjectParser_Detect(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		packageManager models.PackageManager
+		version        string
+		lockfile       string
+		warnings       int
+	}{
+		{
+			name:           "只有package-lock.json",
+			files:          map[string]string{"package.json": `{"name": "app"}`, "package-lock.json": "{}"},
+			packageManager: models.PackageManagerNpm,
+			lockfile:       PackageLockJsonFileName,
+		},
+		{
+			name:           "packageManager字段优先于lockfile",
+			files:          map[string]string{"package.json": `{"name": "app", "packageManager": "pnpm@9.1.0+sha512.abc"}`, "package-lock.json": "{}", "pnpm-lock.yaml": ""},
+			packageManager: models.PackageManagerPnpm,
+			version:        "9.1.0",
+			lockfile:       PnpmLockFileName,
+			warnings:       1,
+		},
+		{
+			name:           "packageManager声明的包管理器没有lockfile",
+			files:          map[string]string{"package.json": `{"name": "app", "packageManager": "yarn@4.1.0"}`},
+			packageManager: models.PackageManagerYarn,
+			version:        "4.1.0",
+			warnings:       1,
+		},
+		{
+			name:           ".yarnrc.yml说明使用yarn",
+			files:          map[string]string{"package.json": `{"name": "app"}`, ".yarnrc.yml": "nodeLinker: node-modules\n", "yarn.lock": "", "package-lock.json": "{}"},
+			packageManager: models.PackageManagerYarn,
+			lockfile:       YarnLockFileName,
+			warnings:       1,
+		},
+		{
+			name:           "npm-shrinkwrap.json优先于package-lock.json",
+			files:          map[string]string{"package.json": `{"name": "app"}`, "npm-shrinkwrap.json": "{}", "package-lock.json": "{}"},
+			packageManager: models.PackageManagerNpm,
+			lockfile:       NpmShrinkwrapFileName,
+			warnings:       1,
+		},
+		{
+			name:           "bun.lock优先于bun.lockb",
+			files:          map[string]string{"package.json": `{"name": "app"}`, "bun.lock": "{}", "bun.lockb": "binary"},
+			packageManager: models.PackageManagerBun,
+			lockfile:       BunLockFileName,
+			warnings:       1,
+		},
+		{
+			name:           "只有bun.lockb",
+			files:          map[string]string{"package.json": `{"name": "app"}`, "bun.lockb": "binary"},
+			packageManager: models.PackageManagerBun,
+			warnings:       1,
+		},
+		{
+			name:           "只有deno.json",
+			files:          map[string]string{"deno.json": `{}`},
+			packageManager: models.PackageManagerDeno,
+		},
+		{
+			name:           "没有lockfile时默认是npm",
+			files:          map[string]string{"package.json": `{"name": "app"}`},
+			packageManager: models.PackageManagerNpm,
+		},
+		{
+			name:           "无法识别的packageManager被忽略",
+			files:          map[string]string{"package.json": `{"name": "app", "packageManager": "cnpm@9.0.0"}`, "yarn.lock": ""},
+			packageManager: models.PackageManagerYarn,
+			lockfile:       YarnLockFileName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileSystem := fstest.MapFS{}
+			for fileName, content := range tt.files {
+				fileSystem["app/"+fileName] = &fstest.MapFile{Data: []byte(content)}
+			}
+
+			detection, err := NewProjectParser().Detect(context.Background(), &ProjectParserInput{ProjectRootDirectory: "app", FileSystem: fileSystem})
+			require.NoError(t, err)
+			assert.Equal(t, tt.packageManager, detection.PackageManager)
+			assert.Equal(t, tt.version, detection.PackageManagerVersion)
+			assert.Equal(t, tt.lockfile, detection.Lockfile)
+			assert.Len(t, detection.Warnings, tt.warnings, "%v", detection.Warnings)
+			assert.NotEmpty(t, detection.Reasons)
+		})
+	}
+}
+
+func TestProjectParser_DetectLockfilePrecedence(t *testing.T) {
+	// 修改时间不影响选择的结果
+	older, newer := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, modTimes := range [][2]time.Time{{older, newer}, {newer, older}} {
+		fileSystem := fstest.MapFS{
+			"package.json":        &fstest.MapFile{Data: []byte(`{"name": "app"}`)},
+			"package-lock.json":   &fstest.MapFile{Data: []byte("{}"), ModTime: modTimes[0]},
+			"npm-shrinkwrap.json": &fstest.MapFile{Data: []byte("{}"), ModTime: modTimes[1]},
+			"yarn.lock":           &fstest.MapFile{Data: []byte(""), ModTime: modTimes[1]},
+		}
+
+		detection, err := NewProjectParser().Detect(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+		require.NoError(t, err)
+		assert.Equal(t, models.PackageManagerYarn, detection.PackageManager)
+		assert.Equal(t, YarnLockFileName, detection.Lockfile)
+		assert.Equal(t, []string{YarnLockFileName, NpmShrinkwrapFileName, PackageLockJsonFileName}, detection.Lockfiles)
+		assert.Equal(t, []string{"yarn.lock has the highest precedence of 3 lockfiles"}, detection.Reasons)
+		assert.Equal(t, []string{"conflicting lockfiles found: npm-shrinkwrap.json, package-lock.json are ignored because the project uses yarn"}, detection.Warnings)
+	}
+
+	// 声明了npm时不管修改时间都使用npm-shrinkwrap.json
+	fileSystem := fstest.MapFS{
+		"package.json":        &fstest.MapFile{Data: []byte(`{"name": "app", "packageManager": "npm@10.2.0"}`)},
+		"package-lock.json":   &fstest.MapFile{Data: []byte("{}"), ModTime: newer},
+		"npm-shrinkwrap.json": &fstest.MapFile{Data: []byte("{}"), ModTime: older},
+	}
+	detection, err := NewProjectParser().Detect(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+	require.NoError(t, err)
+	assert.Equal(t, NpmShrinkwrapFileName, detection.Lockfile)
+	assert.Equal(t, []string{"package-lock.json is ignored because npm-shrinkwrap.json takes precedence over it"}, detection.Warnings)
+}
+
+func TestProjectParser_Parse(t *testing.T) {
+	t.Run("package-lock.json", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"package.json":      &fstest.MapFile{Data: []byte(`{"name": "picktgz", "version": "1.0.5", "dependencies": {"axios": "^1.3.6"}}`)},
+			"package-lock.json": readProjectTestData(t, "test_data/package-lock.json/picktgz.json"),
+		}
+
+		project, err := NewProjectParser().Parse(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+		require.NoError(t, err)
+		assert.Equal(t, "picktgz", project.Name)
+		assert.Equal(t, "1.0.5", project.Version)
+		assert.Equal(t, models.PackageManagerNpm, project.ProjectEcosystem.Detection.PackageManager)
+		module := project.TakeFirstModule()
+		require.NotNil(t, module)
+		assert.NotEmpty(t, module.Dependencies)
+		assert.NotEmpty(t, module.Dependencies[0].ComponentDependencyEcosystem.Resolved)
+	})
+
+	t.Run("合并package.json中的声明", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"package.json":      &fstest.MapFile{Data: []byte(`{"name": "picktgz", "version": "1.0.5", "dependencies": {"axios": "^1.3.6"}}`)},
+			"package-lock.json": readProjectTestData(t, "test_data/package-lock.json/picktgz.json"),
+		}
+
+		project, err := NewProjectParser().Parse(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+		require.NoError(t, err)
+		module := project.TakeFirstModule()
+		require.NotNil(t, module)
+		for _, dependency := range module.Dependencies {
+			ecosystem := dependency.ComponentDependencyEcosystem
+			require.NotNil(t, ecosystem.Direct, dependency.DependencyName)
+			if dependency.DependencyName == "axios" {
+				assert.True(t, *ecosystem.Direct)
+				assert.Equal(t, "^1.3.6", ecosystem.Specifier)
+				assert.Equal(t, models.DependencyGraphEdgeProd, ecosystem.Scope)
+			} else {
+				assert.False(t, *ecosystem.Direct, dependency.DependencyName)
+			}
+		}
+	})
+
+	t.Run("yarn.lock使用package.json的名称", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"web/package.json": &fstest.MapFile{Data: []byte(`{"name": "web", "version": "2.0.0", "dependencies": {"@types/node": "^20.0.0"}}`)},
+			"web/yarn.lock":    readProjectTestData(t, "test_data/yarn.lock/v1-app.lock"),
+		}
+
+		project, err := NewProjectParser().Parse(context.Background(), &ProjectParserInput{ProjectRootDirectory: "web", FileSystem: fileSystem})
+		require.NoError(t, err)
+		assert.Equal(t, "web", project.Name)
+		assert.Equal(t, "2.0.0", project.Version)
+		assert.Equal(t, YarnLockFileName, project.ProjectEcosystem.Detection.Lockfile)
+		module := project.TakeFirstModule()
+		require.NotNil(t, module)
+		found := false
+		for _, dependency := range module.Dependencies {
+			if dependency.DependencyName == "@types/node" {
+				found = true
+				assert.Equal(t, "20.10.5", dependency.DependencyVersion)
+				assert.Contains(t, dependency.ComponentDependencyEcosystem.Resolved, "registry.yarnpkg.com")
+				assert.NotEmpty(t, dependency.ComponentDependencyEcosystem.Integrity)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("pnpm-lock.yaml", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"package.json":   &fstest.MapFile{Data: []byte(`{"name": "pnpm-app", "packageManager": "pnpm@9.0.0"}`)},
+			"pnpm-lock.yaml": readProjectTestData(t, "test_data/pnpm-lock.yaml/v9.0.yaml"),
+		}
+
+		project, err := NewProjectParser().Parse(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+		require.NoError(t, err)
+		assert.Equal(t, models.PackageManagerPnpm, project.ProjectEcosystem.Detection.PackageManager)
+		assert.Contains(t, project.Modules, "pnpm-app")
+		assert.NotEmpty(t, project.Modules["pnpm-app"].Dependencies)
+		for _, dependency := range project.Modules["pnpm-app"].Dependencies {
+			require.NotNil(t, dependency.ComponentDependencyEcosystem.Direct, dependency.DependencyName)
+		}
+	})
+
+	t.Run("只有没有名称的package.json", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"package.json": &fstest.MapFile{Data: []byte(`{"private": true, "dependencies": {"lodash": "^4.17.21"}, "devDependencies": {"jest": "^29.0.0"}}`)},
+		}
+
+		project, err := NewProjectParser().Parse(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+		require.NoError(t, err)
+		assert.Equal(t, "unknown", project.Name)
+		assert.Equal(t, "", project.ProjectEcosystem.Detection.Lockfile)
+		module := project.TakeFirstModule()
+		require.NotNil(t, module)
+		require.Len(t, module.Dependencies, 2)
+		assert.Equal(t, "^4.17.21", module.Dependencies[0].DependencyVersion)
+		assert.True(t, *module.Dependencies[1].ComponentDependencyEcosystem.Dev)
+	})
+
+	t.Run("deno.lock", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"deno.jsonc": readProjectTestData(t, "test_data/deno/deno.jsonc"),
+			"deno.lock":  readProjectTestData(t, "test_data/deno/v3.lock"),
+		}
+
+		project, err := NewProjectParser().Parse(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+		require.NoError(t, err)
+		assert.Equal(t, models.PackageManagerDeno, project.ProjectEcosystem.Detection.PackageManager)
+		assert.Equal(t, DenoJsoncFileName, project.ProjectEcosystem.Detection.Manifest)
+		paths := make([]string, 0)
+		for _, dependency := range project.TakeFirstModule().Dependencies {
+			paths = append(paths, dependency.ComponentDependencyEcosystem.Path)
+		}
+		assert.Contains(t, paths, "jsr:@std/path@1.0.2")
+	})
+}
+
+func TestProjectParser_InvalidInput(t *testing.T) {
+	_, err := NewProjectParser().Parse(context.Background(), nil)
+	assert.Error(t, err)
+
+	_, err = NewProjectParser().Parse(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fstest.MapFS{}})
+	assert.Error(t, err)
+
+	_, err = NewProjectParser().Parse(context.Background(), &ProjectParserInput{ProjectRootDirectory: ".", FileSystem: fstest.MapFS{
+		"package.json": &fstest.MapFile{Data: []byte(`{"name": `)},
+	}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// RegistryMetadataSource 提供包的元数据（packument）的来源，比如保存了packument的目录、本地的registry或者npm的缓存
+type RegistryMetadataSource interface {
+	// Fetch 返回包的元数据，这个来源中没有这个包时返回nil和nil，其它错误才返回error
+	Fetch(ctx context.Context, packageName string) (*models.Packument, error)
+}
+
+// RegistryMetadataClient 依次从多个来源中查询包的元数据，第一个有这个包的来源生效，查询结果会被缓存。
+// 所有的来源都不需要访问公网，适合在离线环境中计算过期和弃用的依赖
+type RegistryMetadataClient struct {
+	sources []RegistryMetadataSource
+
+	// 包名 -> 元数据，没有找到的包也会缓存为nil
+	cache map[string]*models.Packument
+}
+
+func NewRegistryMetadataClient(sources ...RegistryMetadataSource) *RegistryMetadataClient {
+	return &RegistryMetadataClient{
+		sources: sources,
+		cache:   make(map[string]*models.Packument),
+	}
+}
+
+// Fetch 返回包的元数据，所有的来源中都没有这个包时返回nil和nil
+func (x *RegistryMetadataClient) Fetch(ctx context.Context, packageName string) (*models.Packument, error) {
+	if packageName == "" {
+		return nil, fmt.Errorf("package name cannot be empty")
+	}
+	if packument, ok := x.cache[packageName]; ok {
+		return packument, nil
+	}
+	for _, source := range x.sources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		packument, err := source.Fetch(ctx, packageName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch packument of %s: %w", packageName, err)
+		}
+		if packument != nil {
+			x.cache[packageName] = packument
+			return packument, nil
+		}
+	}
+	x.cache[packageName] = nil
+	return nil, nil
+}
+
+// FetchProject 查询项目中所有来自registry的依赖的元数据，返回 包名 -> 元数据 以及没有找到元数据的包名（已排序）。
+// 来自git、本地文件和其它URL的依赖不会查询；npm别名的依赖查询实际安装的包
+func (x *RegistryMetadataClient) FetchProject(ctx context.Context, project *models.JsProject) (map[string]*models.Packument, []string, error) {
+	if project == nil {
+		return nil, nil, fmt.Errorf("project cannot be nil")
+	}
+	packuments := make(map[string]*models.Packument)
+	missing := make([]string, 0)
+	for _, packageName := range registryPackageNames(project) {
+		packument, err := x.Fetch(ctx, packageName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if packument == nil {
+			missing = append(missing, packageName)
+			continue
+		}
+		packuments[packageName] = packument
+	}
+	return packuments, missing, nil
+}
+
+// registryPackageNames 项目中来自registry的包名，去重并排序。
+// resolved中解析出了包名时使用它，这样npm别名的依赖查询的是实际安装的包；没有resolved的依赖（比如pnpm、bun）按registry处理，
+// JSR等不属于npm生态系统的包不在npm registry中，跳过
+func registryPackageNames(project *models.JsProject) []string {
+	names := make(map[string]struct{})
+	for _, module := range project.Modules {
+		for _, dependency := range module.Dependencies {
+			if !isNpmDependency(dependency) {
+				continue
+			}
+			name := dependency.DependencyName
+			if ecosystem := dependency.ComponentDependencyEcosystem; ecosystem != nil && ecosystem.ResolvedSource != nil {
+				source := ecosystem.ResolvedSource
+				if !source.IsRegistry() {
+					continue
+				}
+				if source.Name != "" {
+					name = source.Name
+				}
+			}
+			if name == "" {
+				continue
+			}
+			names[name] = struct{}{}
+		}
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+	return sortedNames
+}
+
+// registryPackument registry返回的packument，deprecated在少数包中是布尔值，time中有created和modified两个特殊的键
+type registryPackument struct {
+	Name     string                               `json:"name"`
+	DistTags map[string]string                    `json:"dist-tags"`
+	Versions map[string]*registryPackumentVersion `json:"versions"`
+	Time     map[string]string                    `json:"time"`
+	Modified string                               `json:"modified"`
+}
+
+type registryPackumentVersion struct {
+	Name                 string                `json:"name"`
+	Version              string                `json:"version"`
+	Deprecated           json.RawMessage       `json:"deprecated"`
+	Dist                 *models.PackumentDist `json:"dist"`
+	Dependencies         models.Dependencies   `json:"dependencies"`
+	OptionalDependencies models.Dependencies   `json:"optionalDependencies"`
+	PeerDependencies     models.Dependencies   `json:"peerDependencies"`
+}
+
+// parsePackument 解析registry返回的packument，source记录元数据的来源
+func parsePackument(data []byte, source string) (*models.Packument, error) {
+	document := &registryPackument{}
+	if err := json.Unmarshal(data, document); err != nil {
+		return nil, fmt.Errorf("failed to parse packument %s: %w", source, err)
+	}
+	if document.Name == "" {
+		return nil, fmt.Errorf("packument %s has no name", source)
+	}
+
+	packument := &models.Packument{
+		Name:     document.Name,
+		DistTags: make(map[string]string),
+		Versions: make(map[string]*models.PackumentVersion),
+		Time:     make(map[string]time.Time),
+		Source:   source,
+	}
+	for tag, version := range document.DistTags {
+		packument.DistTags[tag] = version
+	}
+	for version, v := range document.Versions {
+		if v == nil {
+			continue
+		}
+		packumentVersion := &models.PackumentVersion{
+			Name:                 v.Name,
+			Version:              v.Version,
+			Deprecated:           parsePackumentDeprecated(v.Deprecated),
+			Dist:                 v.Dist,
+			Dependencies:         v.Dependencies,
+			OptionalDependencies: v.OptionalDependencies,
+			PeerDependencies:     v.PeerDependencies,
+		}
+		if packumentVersion.Name == "" {
+			packumentVersion.Name = document.Name
+		}
+		if packumentVersion.Version == "" {
+			packumentVersion.Version = version
+		}
+		packument.Versions[version] = packumentVersion
+	}
+	for key, value := range document.Time {
+		publishedAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "created":
+			packument.Created = publishedAt
+		case "modified":
+			packument.Modified = publishedAt
+		default:
+			packument.Time[key] = publishedAt
+		}
+	}
+	// abbreviated文档中没有time，只有顶层的modified
+	if packument.Modified.IsZero() && document.Modified != "" {
+		if modified, err := time.Parse(time.RFC3339, document.Modified); err == nil {
+			packument.Modified = modified
+		}
+	}
+	return packument, nil
+}
+
+// parsePackumentDeprecated deprecated通常是说明文字，少数包中是true或者false
+func parsePackumentDeprecated(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		return message
+	}
+	var deprecated bool
+	if err := json.Unmarshal(raw, &deprecated); err == nil && deprecated {
+		return "deprecated"
+	}
+	return ""
+}
+
+// escapePackageName 按照npm请求packument的方式转义包名，作用域包中的/转义为%2f，比如 @babel%2fcore
+func escapePackageName(packageName string) string {
+	if strings.HasPrefix(packageName, "@") {
+		if index := strings.Index(packageName, "/"); index >= 0 {
+			return url.PathEscape(packageName[:index]) + "%2f" + url.PathEscape(packageName[index+1:])
+		}
+	}
+	return url.PathEscape(packageName)
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPackuments 测试使用的packument
+var testPackuments = map[string]string{
+	"lodash": `{
+  "name": "lodash",
+  "dist-tags": {"latest": "4.17.21"},
+  "versions": {
+    "4.17.20": {"name": "lodash", "version": "4.17.20", "dist": {"tarball": "https://registry.npmjs.org/lodash/-/lodash-4.17.20.tgz", "shasum": "b44a9b6297bcb698f1c51a3545a2b3b368d59c52"}},
+    "4.17.21": {"name": "lodash", "version": "4.17.21", "dist": {"tarball": "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz", "integrity": "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg=="}}
+  },
+  "time": {"created": "2012-04-23T16:37:11.912Z", "modified": "2023-06-02T10:00:00.000Z", "4.17.20": "2020-08-13T16:53:54.152Z", "4.17.21": "2021-02-20T15:42:16.891Z"}
+}`,
+	"@acme/ui": `{
+  "name": "@acme/ui",
+  "modified": "2024-01-01T00:00:00.000Z",
+  "dist-tags": {"latest": "2.0.0", "next": "3.0.0-beta.1"},
+  "versions": {
+    "1.0.0": {"version": "1.0.0", "deprecated": "请升级到2.x", "dist": {"integrity": "sha512-AAAA"}},
+    "2.0.0": {"version": "2.0.0", "dependencies": {"lodash": "^4.17.0"}},
+    "3.0.0-beta.1": {"version": "3.0.0-beta.1", "deprecated": true}
+  }
+}`,
+}
+
+func TestRegistryMetadataClient_Fetch(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"mirror/lodash.json":         testPackuments["lodash"],
+		"mirror/@acme%2fui.json":     testPackuments["@acme/ui"],
+		"fallback/left-pad.json":     `{"name": "left-pad", "dist-tags": {"latest": "1.3.0"}, "versions": {"1.3.0": {}}}`,
+		"fallback/lodash.json":       `{"name": "lodash", "dist-tags": {"latest": "0.0.1"}}`,
+		"mirror/broken.json":         `{"name": `,
+		"mirror/@acme/nameless.json": `{"versions": {}}`,
+	})
+	client := NewRegistryMetadataClient(
+		NewPackumentDirectorySource("mirror", fileSystem),
+		NewPackumentDirectorySource("fallback", fileSystem),
+	)
+
+	lodash, err := client.Fetch(context.Background(), "lodash")
+	require.NoError(t, err)
+	require.NotNil(t, lodash)
+	assert.Equal(t, "mirror/lodash.json", lodash.Source, "第一个有这个包的来源生效")
+	assert.Equal(t, "4.17.21", lodash.Latest().Version)
+	assert.Equal(t, []string{"4.17.20", "4.17.21"}, lodash.VersionNames())
+	assert.Equal(t, "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg==", lodash.Version("4.17.21").Integrity())
+	assert.Equal(t, "sha1-tEqbYpe8tpjxxRo1RaKzs2jVnFI=", lodash.Version("4.17.20").Integrity(), "没有integrity时使用shasum")
+	publishedAt, ok := lodash.PublishedAt("4.17.21")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2021, 2, 20, 15, 42, 16, 891000000, time.UTC), publishedAt)
+	assert.Equal(t, time.Date(2012, 4, 23, 16, 37, 11, 912000000, time.UTC), lodash.Created)
+	assert.NotContains(t, lodash.Time, "created")
+
+	ui, err := client.Fetch(context.Background(), "@acme/ui")
+	require.NoError(t, err)
+	require.NotNil(t, ui)
+	assert.Equal(t, "3.0.0-beta.1", ui.DistTags["next"])
+	assert.Equal(t, "请升级到2.x", ui.Version("1.0.0").Deprecated)
+	assert.True(t, ui.IsDeprecated("3.0.0-beta.1"), "deprecated是布尔值")
+	assert.False(t, ui.IsDeprecated("2.0.0"))
+	assert.Equal(t, "@acme/ui", ui.Version("2.0.0").Name, "版本中没有name时使用包名")
+	assert.Equal(t, models.Dependencies{"lodash": "^4.17.0"}, ui.Version("2.0.0").Dependencies)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ui.Modified, "abbreviated文档顶层的modified")
+	_, ok = ui.PublishedAt("2.0.0")
+	assert.False(t, ok)
+
+	leftPad, err := client.Fetch(context.Background(), "left-pad")
+	require.NoError(t, err)
+	require.NotNil(t, leftPad)
+	assert.Equal(t, "1.3.0", leftPad.Version("1.3.0").Version, "版本中没有version时使用键")
+	assert.Equal(t, "", leftPad.Version("1.3.0").Integrity())
+
+	missing, err := client.Fetch(context.Background(), "not-exists")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	escaped, err := client.Fetch(context.Background(), "../fallback/lodash")
+	require.NoError(t, err)
+	assert.Nil(t, escaped, "包名不能访问目录之外的文件")
+
+	_, err = client.Fetch(context.Background(), "broken")
+	assert.Error(t, err)
+	_, err = client.Fetch(context.Background(), "@acme/nameless")
+	assert.Error(t, err)
+	_, err = client.Fetch(context.Background(), "")
+	assert.Error(t, err)
+
+	// 查询结果会被缓存，来源中的文件被删除后仍然返回之前的结果
+	delete(fileSystem, "mirror/lodash.json")
+	cached, err := client.Fetch(context.Background(), "lodash")
+	require.NoError(t, err)
+	assert.Same(t, lodash, cached)
+}
+
+func TestRegistryMetadataClient_FetchProject(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"package-lock.json": `{
+  "name": "app", "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"lodash": "^4.17.20", "ui": "npm:@acme/ui@^2.0.0", "curl-config": "github:example/curl-config", "left-pad": "^1.3.0"}},
+    "node_modules/lodash": {"version": "4.17.20", "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.20.tgz"},
+    "node_modules/ui": {"name": "@acme/ui", "version": "2.0.0", "resolved": "https://npm.pkg.github.com/download/@acme/ui/2.0.0/abcdef"},
+    "node_modules/curl-config": {"version": "1.0.0", "resolved": "git+ssh://git@github.com/example/curl-config.git#a1b2c3d"},
+    "node_modules/left-pad": {"version": "1.3.0", "resolved": "https://registry.npmjs.org/left-pad/-/left-pad-1.3.0.tgz"}
+  }
+}`,
+		"mirror/lodash.json":      testPackuments["lodash"],
+		"mirror/@acme/ui.json":    testPackuments["@acme/ui"],
+		"mirror/curl-config.json": `{"name": "curl-config"}`,
+	})
+	project, err := NewPackageLockParser().Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonPath: "package-lock.json", FileSystem: fileSystem})
+	require.NoError(t, err)
+
+	client := NewRegistryMetadataClient(NewPackumentDirectorySource("mirror", fileSystem))
+	packuments, missing, err := client.FetchProject(context.Background(), project)
+	require.NoError(t, err)
+	assert.Len(t, packuments, 2)
+	assert.Contains(t, packuments, "lodash")
+	assert.Contains(t, packuments, "@acme/ui", "npm别名查询实际安装的包")
+	assert.NotContains(t, packuments, "curl-config", "git依赖不查询")
+	assert.Equal(t, []string{"left-pad"}, missing)
+
+	_, _, err = client.FetchProject(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+func TestRegistryPackageNames_SkipJsr(t *testing.T) {
+	module := &models.JsModule{}
+	for _, dependency := range []struct {
+		name      string
+		ecosystem string
+	}{
+		{name: "chalk", ecosystem: models.DependencyEcosystemNpm},
+		{name: "@std/path", ecosystem: models.DependencyEcosystemJsr},
+		{name: "lodash"},
+	} {
+		component := &models.JsComponentDependency{}
+		component.DependencyName = dependency.name
+		component.DependencyVersion = "1.0.0"
+		component.ComponentDependencyEcosystem = &models.JsComponentDependencyEcosystem{Ecosystem: dependency.ecosystem}
+		module.Dependencies = append(module.Dependencies, component)
+	}
+	project := &models.JsProject{}
+	project.SetModule("deno-app", module)
+
+	assert.Equal(t, []string{"chalk", "lodash"}, registryPackageNames(project), "JSR包不查询npm registry，没有设置生态系统时按npm处理")
+}
@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// ResolvedSourceParser 解析lockfile中的resolved，识别依赖来自哪个registry，或者来自git、本地文件和其它URL。
+// 解析lockfile时已经对每个依赖调用过，结果在ComponentDependencyEcosystem.ResolvedSource中，
+// 需要单独解析一个地址时可以直接使用
+type ResolvedSourceParser struct {
+}
+
+func NewResolvedSourceParser() *ResolvedSourceParser {
+	return &ResolvedSourceParser{}
+}
+
+// Parse 解析一个resolved，为空时返回nil
+func (x *ResolvedSourceParser) Parse(resolved string) *models.ResolvedSource {
+	return parseResolvedSource(resolved)
+}
+
+// resolvedFileProtocols 指向本地文件或目录的协议
+var resolvedFileProtocols = []string{"file", "link", "portal"}
+
+// resolvedGitShorthands 托管git仓库的简写，比如 github:user/repo
+var resolvedGitShorthands = []string{"github", "gitlab", "bitbucket", "gist"}
+
+// parseResolvedSource 依次识别本地文件、git、registry中的tarball，其它http地址是普通的tarball，
+// 没有协议的是本地路径
+func parseResolvedSource(resolved string) *models.ResolvedSource {
+	if resolved == "" {
+		return nil
+	}
+	source := &models.ResolvedSource{Raw: resolved, Type: models.ResolvedSourceUnknown}
+	location := resolved
+	if index := strings.Index(location, "#"); index >= 0 {
+		location, source.Fragment = location[:index], location[index+1:]
+	}
+
+	for _, protocol := range resolvedFileProtocols {
+		if strings.HasPrefix(location, protocol+":") {
+			source.Type = models.ResolvedSourceFile
+			source.Protocol = protocol
+			return source
+		}
+	}
+	// package-lock.json中链接的resolved是相对于项目根目录的路径，比如 packages/ui，
+	// Windows上的绝对路径以盘符开头，比如 C:\projects\ui，不能当作URL的协议
+	if !strings.Contains(location, ":") || isWindowsDrivePath(location) {
+		source.Type = models.ResolvedSourceFile
+		source.Protocol = "file"
+		return source
+	}
+	// SCP风格的git地址，比如 git@github.com:user/repo.git，url.Parse无法解析
+	if host, ok := parseScpLikeHost(location); ok {
+		source.Type = models.ResolvedSourceGit
+		source.Protocol = "ssh"
+		source.Host = host
+		return source
+	}
+	for _, shorthand := range resolvedGitShorthands {
+		if strings.HasPrefix(location, shorthand+":") {
+			source.Type = models.ResolvedSourceGit
+			source.Protocol = shorthand
+			return source
+		}
+	}
+
+	resolvedURL, err := url.Parse(location)
+	if err != nil || resolvedURL.Scheme == "" {
+		return source
+	}
+	source.Protocol = resolvedURL.Scheme
+	source.Host = resolvedURL.Host
+	switch {
+	case strings.HasPrefix(resolvedURL.Scheme, "git"), resolvedURL.Scheme == "ssh":
+		source.Type = models.ResolvedSourceGit
+	case resolvedURL.Scheme != "http" && resolvedURL.Scheme != "https":
+		return source
+	case parseRegistryTarball(resolvedURL, source):
+		source.Type = models.ResolvedSourceRegistry
+	case strings.EqualFold(resolvedURL.Hostname(), "codeload.github.com") || strings.HasSuffix(resolvedURL.Path, ".git"):
+		source.Type = models.ResolvedSourceGit
+	default:
+		source.Type = models.ResolvedSourceTarball
+	}
+	return source
+}
+
+// isWindowsDrivePath 是否是以盘符开头的Windows路径，比如 C:\projects\ui 或者 C:/projects/ui
+func isWindowsDrivePath(location string) bool {
+	if len(location) < 3 || location[1] != ':' || (location[2] != '\\' && location[2] != '/') {
+		return false
+	}
+	drive := location[0]
+	return ('a' <= drive && drive <= 'z') || ('A' <= drive && drive <= 'Z')
+}
+
+// parseScpLikeHost 解析 user@host:path 格式的地址，返回主机名。跟git的规则一样，第一个:之前不能有/，
+// 否则是本地路径；有 :// 的是URL
+func parseScpLikeHost(location string) (string, bool) {
+	colon := strings.Index(location, ":")
+	if colon < 0 || strings.HasPrefix(location[colon:], "://") || strings.Contains(location[:colon], "/") {
+		return "", false
+	}
+	at := strings.LastIndex(location[:colon], "@")
+	if at <= 0 || at == colon-1 || colon == len(location)-1 {
+		return "", false
+	}
+	return location[at+1 : colon], true
+}
+
+// parseRegistryTarball 按照registry的布局解析tarball地址，设置registry、包名和版本，不是registry布局时返回false。
+// 大多数registry的布局是 <registry>/<name>/-/<unscoped-name>-<version>.tgz，作用域包的名称可能是 @scope/name 或者 @scope%2fname，
+// Artifactory中文件名可能带有作用域；GitHub Packages的布局是 /download/<name>/<version>/<hash>
+func parseRegistryTarball(resolvedURL *url.URL, source *models.ResolvedSource) bool {
+	hostname := strings.ToLower(resolvedURL.Hostname())
+	segments := strings.Split(strings.Trim(resolvedURL.Path, "/"), "/")
+
+	if hostname == "npm.pkg.github.com" && len(segments) >= 4 && segments[0] == "download" && strings.HasPrefix(segments[1], "@") {
+		source.Registry = resolvedURL.Scheme + "://" + resolvedURL.Host + "/"
+		source.RegistryKind = models.RegistryKindGithubPackages
+		source.Name = segments[1] + "/" + segments[2]
+		source.Version = segments[3]
+		return true
+	}
+
+	separator := -1
+	for i, segment := range segments {
+		if segment == "-" {
+			separator = i
+			break
+		}
+	}
+	if separator < 1 || separator == len(segments)-1 {
+		return false
+	}
+	name := segments[separator-1]
+	registrySegments := segments[:separator-1]
+	if separator >= 2 && strings.HasPrefix(segments[separator-2], "@") {
+		name = segments[separator-2] + "/" + name
+		registrySegments = segments[:separator-2]
+	}
+	unscopedName := name[strings.LastIndex(name, "/")+1:]
+
+	fileName := path.Base(segments[len(segments)-1])
+	if !strings.HasSuffix(fileName, ".tgz") || !strings.HasPrefix(fileName, unscopedName+"-") {
+		return false
+	}
+	version := strings.TrimSuffix(strings.TrimPrefix(fileName, unscopedName+"-"), ".tgz")
+	if version == "" {
+		return false
+	}
+
+	registryPath := "/"
+	if len(registrySegments) > 0 {
+		registryPath += strings.Join(registrySegments, "/") + "/"
+	}
+	source.Registry = resolvedURL.Scheme + "://" + resolvedURL.Host + registryPath
+	source.Name = name
+	source.Version = version
+	switch {
+	case hostname == "registry.npmjs.org":
+		source.RegistryKind = models.RegistryKindNpmjs
+	case hostname == "registry.yarnpkg.com":
+		source.RegistryKind = models.RegistryKindYarnpkg
+	case hostname == "npm.pkg.github.com":
+		source.RegistryKind = models.RegistryKindGithubPackages
+	case strings.Contains(registryPath, "/api/npm/"):
+		source.RegistryKind = models.RegistryKindArtifactory
+	case resolvedURL.Port() == "4873":
+		// Verdaccio的默认端口
+		source.RegistryKind = models.RegistryKindVerdaccio
+	default:
+		source.RegistryKind = models.RegistryKindCustom
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvedSourceParser_Parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolved string
+		want     *models.ResolvedSource
+	}{
+		{
+			name:     "npmjs",
+			resolved: "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceRegistry, Protocol: "https", Host: "registry.npmjs.org", Registry: "https://registry.npmjs.org/", RegistryKind: models.RegistryKindNpmjs, Name: "lodash", Version: "4.17.21"},
+		},
+		{
+			name:     "npmjs作用域包",
+			resolved: "https://registry.npmjs.org/@babel/core/-/core-7.24.0.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceRegistry, Protocol: "https", Host: "registry.npmjs.org", Registry: "https://registry.npmjs.org/", RegistryKind: models.RegistryKindNpmjs, Name: "@babel/core", Version: "7.24.0"},
+		},
+		{
+			name:     "yarnpkg带sha1",
+			resolved: "https://registry.yarnpkg.com/semver/-/semver-7.6.0-rc.1.tgz#1cf37c8707b932bd1af1ae22c0432e2acd1903bd",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceRegistry, Protocol: "https", Host: "registry.yarnpkg.com", Registry: "https://registry.yarnpkg.com/", RegistryKind: models.RegistryKindYarnpkg, Name: "semver", Version: "7.6.0-rc.1", Fragment: "1cf37c8707b932bd1af1ae22c0432e2acd1903bd"},
+		},
+		{
+			name:     "Artifactory作用域包",
+			resolved: "https://acme.jfrog.io/artifactory/api/npm/npm-remote/@acme/ui/-/@acme/ui-1.2.0.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceRegistry, Protocol: "https", Host: "acme.jfrog.io", Registry: "https://acme.jfrog.io/artifactory/api/npm/npm-remote/", RegistryKind: models.RegistryKindArtifactory, Name: "@acme/ui", Version: "1.2.0"},
+		},
+		{
+			name:     "Verdaccio编码的作用域",
+			resolved: "http://localhost:4873/@acme%2fcore/-/core-0.1.0.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceRegistry, Protocol: "http", Host: "localhost:4873", Registry: "http://localhost:4873/", RegistryKind: models.RegistryKindVerdaccio, Name: "@acme/core", Version: "0.1.0"},
+		},
+		{
+			name:     "GitHub Packages",
+			resolved: "https://npm.pkg.github.com/download/@octo/widgets/2.0.1/5f3a9c1d",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceRegistry, Protocol: "https", Host: "npm.pkg.github.com", Registry: "https://npm.pkg.github.com/", RegistryKind: models.RegistryKindGithubPackages, Name: "@octo/widgets", Version: "2.0.1"},
+		},
+		{
+			name:     "Nexus",
+			resolved: "https://nexus.example.com/repository/npm-group/express/-/express-4.18.2.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceRegistry, Protocol: "https", Host: "nexus.example.com", Registry: "https://nexus.example.com/repository/npm-group/", RegistryKind: models.RegistryKindCustom, Name: "express", Version: "4.18.2"},
+		},
+		{
+			name:     "git+ssh",
+			resolved: "git+ssh://git@github.com/example/curl-config.git#a1b2c3d",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceGit, Protocol: "git+ssh", Host: "github.com", Fragment: "a1b2c3d"},
+		},
+		{
+			name:     "SCP风格的git地址",
+			resolved: "git@github.com:example/curl-config.git#a1b2c3d",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceGit, Protocol: "ssh", Host: "github.com", Fragment: "a1b2c3d"},
+		},
+		{
+			name:     "自建git服务的SCP风格地址",
+			resolved: "deploy@git.example.com:team/tiny-lib",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceGit, Protocol: "ssh", Host: "git.example.com"},
+		},
+		{
+			name:     "codeload归档",
+			resolved: "https://codeload.github.com/example/tiny-lib/tar.gz/a1b2c3d",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceGit, Protocol: "https", Host: "codeload.github.com"},
+		},
+		{
+			name:     "github简写",
+			resolved: "github:example/tiny-lib#main",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceGit, Protocol: "github", Fragment: "main"},
+		},
+		{
+			name:     "file协议",
+			resolved: "file:../vendor/left-pad-1.3.0.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceFile, Protocol: "file"},
+		},
+		{
+			name:     "package-lock.json中链接的路径",
+			resolved: "packages/ui",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceFile, Protocol: "file"},
+		},
+		{
+			name:     "Windows路径",
+			resolved: `C:\projects\vendor\left-pad`,
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceFile, Protocol: "file"},
+		},
+		{
+			name:     "Windows路径使用/分隔",
+			resolved: "d:/projects/vendor/left-pad-1.3.0.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceFile, Protocol: "file"},
+		},
+		{
+			name:     "不是registry布局的URL",
+			resolved: "https://cdn.example.com/builds/left-pad.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceTarball, Protocol: "https", Host: "cdn.example.com"},
+		},
+		{
+			name:     "文件名跟包名不一致",
+			resolved: "https://registry.npmjs.org/lodash/-/underscore-1.0.0.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceTarball, Protocol: "https", Host: "registry.npmjs.org"},
+		},
+		{
+			name:     "无法识别的协议",
+			resolved: "ftp://example.com/a.tgz",
+			want:     &models.ResolvedSource{Type: models.ResolvedSourceUnknown, Protocol: "ftp", Host: "example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Raw = tt.resolved
+			got := NewResolvedSourceParser().Parse(tt.resolved)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.Type == models.ResolvedSourceRegistry, got.IsRegistry())
+		})
+	}
+
+	assert.Nil(t, NewResolvedSourceParser().Parse(""))
+}
+
+func TestResolvedSourceParser_AnnotatesLockfileDependencies(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"package-lock.json": `{
+  "name": "app", "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/lodash": {"version": "4.17.21", "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz"},
+    "node_modules/curl-config": {"version": "1.0.0", "resolved": "git+ssh://git@github.com/example/curl-config.git#a1b2c3d"},
+    "node_modules/@acme/ui": {"resolved": "packages/ui", "link": true},
+    "packages/ui": {"name": "@acme/ui", "version": "0.1.0"}
+  }
+}`,
+	})
+
+	project, err := NewPackageLockParser().Parse(context.Background(), &PackageLockJsonParserInput{PackageLockJsonPath: "package-lock.json", FileSystem: fileSystem})
+	require.NoError(t, err)
+	sources := make(map[string]*models.ResolvedSource)
+	for _, dependency := range project.TakeFirstModule().Dependencies {
+		sources[dependency.ComponentDependencyEcosystem.Path] = dependency.ComponentDependencyEcosystem.ResolvedSource
+	}
+	require.NotNil(t, sources["node_modules/lodash"])
+	assert.Equal(t, "https://registry.npmjs.org/", sources["node_modules/lodash"].Registry)
+	require.NotNil(t, sources["node_modules/curl-config"])
+	assert.Equal(t, models.ResolvedSourceGit, sources["node_modules/curl-config"].Type)
+	assert.Nil(t, sources["packages/ui"], "没有resolved")
+
+	yarnProject, err := NewYarnLockParser().Parse(context.Background(), &YarnLockParserInput{YarnLockPath: "test_data/yarn.lock/v1-app.lock"})
+	require.NoError(t, err)
+	for _, dependency := range yarnProject.TakeFirstModule().Dependencies {
+		source := dependency.ComponentDependencyEcosystem.ResolvedSource
+		require.NotNil(t, source, dependency.DependencyName)
+		if source.IsRegistry() {
+			assert.Equal(t, models.RegistryKindYarnpkg, source.RegistryKind)
+			assert.Equal(t, dependency.DependencyVersion, source.Version)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semverVersion 语义化版本，build元数据不参与比较所以不保存
+type semverVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+}
+
+// semverComparator 范围中的一个比较，比如 >=1.2.3
+type semverComparator struct {
+	Operator string
+	Version  *semverVersion
+}
+
+// semverRange 版本范围，满足任意一组比较中的全部比较即满足范围，跟node-semver的Range一致
+type semverRange struct {
+	sets [][]*semverComparator
+}
+
+// semverVersionPattern 完整的版本号，允许npm宽松模式中的前缀v和=
+var semverVersionPattern = regexp.MustCompile(`^[v=\s]*(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?$`)
+
+// semverPartialPattern 范围中可以省略部分或者使用x、X、*通配的版本号
+var semverPartialPattern = regexp.MustCompile(`^[v=]*(0|[1-9]\d*|[xX*])(?:\.(0|[1-9]\d*|[xX*])(?:\.(0|[1-9]\d*|[xX*])(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?)?)?$`)
+
+// semverOperatorSpaces 运算符和版本号之间的空格，比如 >= 1.2.3
+var semverOperatorSpaces = regexp.MustCompile(`(<=|>=|<|>|=|~>|~|\^)\s+`)
+
+// semverHyphen 连字符范围中的分隔符，两边都需要有空格
+var semverHyphen = regexp.MustCompile(`\s+-\s+`)
+
+// parseSemver 解析完整的版本号，不是合法的版本号时返回错误
+func parseSemver(version string) (*semverVersion, error) {
+	matches := semverVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+	parsed := &semverVersion{}
+	numbers := []*int{&parsed.Major, &parsed.Minor, &parsed.Patch}
+	for i, number := range numbers {
+		value, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %w", version, err)
+		}
+		*number = value
+	}
+	if matches[4] != "" {
+		parsed.Prerelease = strings.Split(matches[4], ".")
+	}
+	return parsed, nil
+}
+
+// String 返回规范的版本号，不包括build元数据
+func (x *semverVersion) String() string {
+	version := fmt.Sprintf("%d.%d.%d", x.Major, x.Minor, x.Patch)
+	if len(x.Prerelease) > 0 {
+		version += "-" + strings.Join(x.Prerelease, ".")
+	}
+	return version
+}
+
+// compare 比较两个版本，返回-1、0或1，预发布版本小于对应的正式版本
+func (x *semverVersion) compare(other *semverVersion) int {
+	for _, pair := range [][2]int{{x.Major, other.Major}, {x.Minor, other.Minor}, {x.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(x.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(x.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(x.Prerelease) && i < len(other.Prerelease); i++ {
+		if result := compareSemverIdentifier(x.Prerelease[i], other.Prerelease[i]); result != 0 {
+			return result
+		}
+	}
+	switch {
+	case len(x.Prerelease) < len(other.Prerelease):
+		return -1
+	case len(x.Prerelease) > len(other.Prerelease):
+		return 1
+	}
+	return 0
+}
+
+// sameTuple major、minor、patch是否都相同
+func (x *semverVersion) sameTuple(other *semverVersion) bool {
+	return x.Major == other.Major && x.Minor == other.Minor && x.Patch == other.Patch
+}
+
+// compareSemverIdentifier 比较预发布标签中的一段：数字按数值比较，并且小于非数字
+func compareSemverIdentifier(a, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// parseSemverRange 按照node-semver的语法解析版本范围，支持 ||、连字符范围、x范围、~、^ 和比较运算符，
+// 空字符串和*匹配所有的正式版本
+func parseSemverRange(versionRange string) (*semverRange, error) {
+	parsed := &semverRange{}
+	for _, set := range strings.Split(versionRange, "||") {
+		set = strings.TrimSpace(set)
+		var comparators []*semverComparator
+		var err error
+		if parts := semverHyphen.Split(set, -1); len(parts) == 2 {
+			comparators, err = parseSemverHyphenRange(parts[0], parts[1])
+		} else {
+			comparators, err = parseSemverComparators(semverOperatorSpaces.ReplaceAllString(set, "$1"))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", versionRange, err)
+		}
+		parsed.sets = append(parsed.sets, comparators)
+	}
+	return parsed, nil
+}
+
+// semverPartial 范围中的版本号，defined是明确指定的部分的数量，x和省略的部分不算
+type semverPartial struct {
+	version *semverVersion
+	defined int
+}
+
+// parseSemverPartial 解析范围中可能不完整的版本号
+func parseSemverPartial(version string) (*semverPartial, error) {
+	matches := semverPartialPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+	partial := &semverPartial{version: &semverVersion{}}
+	numbers := []*int{&partial.version.Major, &partial.version.Minor, &partial.version.Patch}
+	for i, number := range numbers {
+		value, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			break
+		}
+		*number = value
+		partial.defined++
+	}
+	if matches[4] != "" && partial.defined == 3 {
+		partial.version.Prerelease = strings.Split(matches[4], ".")
+	}
+	return partial, nil
+}
+
+// lowerBound 不完整的版本号中省略的部分都是0
+func (x *semverPartial) lowerBound() *semverVersion {
+	return x.version
+}
+
+// upperBound 比不完整的版本号表示的所有版本都大的最小版本，比如1.2表示的是 <1.3.0-0，完整的版本号返回nil
+func (x *semverPartial) upperBound() *semverVersion {
+	switch x.defined {
+	case 0:
+		return nil
+	case 1:
+		return &semverVersion{Major: x.version.Major + 1, Prerelease: []string{"0"}}
+	case 2:
+		return &semverVersion{Major: x.version.Major, Minor: x.version.Minor + 1, Prerelease: []string{"0"}}
+	}
+	return nil
+}
+
+// parseSemverHyphenRange 连字符范围 a - b，a中省略的部分是0，b中省略的部分表示这一级的所有版本
+func parseSemverHyphenRange(from, to string) ([]*semverComparator, error) {
+	lower, err := parseSemverPartial(strings.TrimSpace(from))
+	if err != nil {
+		return nil, err
+	}
+	upper, err := parseSemverPartial(strings.TrimSpace(to))
+	if err != nil {
+		return nil, err
+	}
+	comparators := make([]*semverComparator, 0, 2)
+	comparators = append(comparators, &semverComparator{Operator: ">=", Version: lower.lowerBound()})
+	switch {
+	case upper.defined == 0:
+	case upper.defined == 3:
+		comparators = append(comparators, &semverComparator{Operator: "<=", Version: upper.version})
+	default:
+		comparators = append(comparators, &semverComparator{Operator: "<", Version: upper.upperBound()})
+	}
+	return comparators, nil
+}
+
+// parseSemverComparators 解析空格分隔的一组比较，把~、^和x范围展开为 >= 和 < 两个比较
+func parseSemverComparators(set string) ([]*semverComparator, error) {
+	comparators := make([]*semverComparator, 0)
+	for _, token := range strings.Fields(set) {
+		operator := ""
+		for _, candidate := range []string{"<=", ">=", "~>", "<", ">", "=", "~", "^"} {
+			if strings.HasPrefix(token, candidate) {
+				operator = candidate
+				break
+			}
+		}
+		partial, err := parseSemverPartial(strings.TrimPrefix(token, operator))
+		if err != nil {
+			return nil, err
+		}
+		expanded := expandSemverComparator(operator, partial)
+		comparators = append(comparators, expanded...)
+	}
+	if len(comparators) == 0 {
+		comparators = append(comparators, &semverComparator{Operator: ">=", Version: &semverVersion{}})
+	}
+	return comparators, nil
+}
+
+// expandSemverComparator 把一个运算符和可能不完整的版本号展开为基本的比较
+func expandSemverComparator(operator string, partial *semverPartial) []*semverComparator {
+	version := partial.version
+	lower := &semverComparator{Operator: ">=", Version: partial.lowerBound()}
+	withUpper := func(upper *semverVersion) []*semverComparator {
+		if upper == nil {
+			return []*semverComparator{lower}
+		}
+		return []*semverComparator{lower, {Operator: "<", Version: upper}}
+	}
+
+	switch operator {
+	case "~", "~>":
+		if partial.defined == 3 {
+			return withUpper(&semverVersion{Major: version.Major, Minor: version.Minor + 1, Prerelease: []string{"0"}})
+		}
+		return withUpper(partial.upperBound())
+	case "^":
+		switch {
+		case partial.defined == 0:
+			return withUpper(nil)
+		case version.Major > 0 || partial.defined == 1:
+			return withUpper(&semverVersion{Major: version.Major + 1, Prerelease: []string{"0"}})
+		case version.Minor > 0 || partial.defined == 2:
+			return withUpper(&semverVersion{Minor: version.Minor + 1, Prerelease: []string{"0"}})
+		}
+		return withUpper(&semverVersion{Patch: version.Patch + 1, Prerelease: []string{"0"}})
+	case ">":
+		if upper := partial.upperBound(); upper != nil {
+			// >1.2 表示 >=1.3.0，没有指定版本时不匹配任何版本
+			return []*semverComparator{{Operator: ">=", Version: &semverVersion{Major: upper.Major, Minor: upper.Minor}}}
+		}
+		if partial.defined == 0 {
+			return []*semverComparator{{Operator: "<", Version: &semverVersion{Prerelease: []string{"0"}}}}
+		}
+		return []*semverComparator{{Operator: ">", Version: version}}
+	case "<":
+		if partial.defined == 0 {
+			return []*semverComparator{{Operator: "<", Version: &semverVersion{Prerelease: []string{"0"}}}}
+		}
+		if partial.defined < 3 {
+			return []*semverComparator{{Operator: "<", Version: &semverVersion{Major: version.Major, Minor: version.Minor, Prerelease: []string{"0"}}}}
+		}
+		return []*semverComparator{{Operator: "<", Version: version}}
+	case "<=":
+		if upper := partial.upperBound(); upper != nil {
+			return []*semverComparator{{Operator: "<", Version: upper}}
+		}
+		if partial.defined == 0 {
+			return []*semverComparator{lower}
+		}
+		return []*semverComparator{{Operator: "<=", Version: version}}
+	case ">=":
+		return []*semverComparator{lower}
+	}
+	// = 和没有运算符
+	if partial.defined == 3 {
+		return []*semverComparator{{Operator: "=", Version: version}}
+	}
+	return withUpper(partial.upperBound())
+}
+
+// test 版本是否满足比较
+func (x *semverComparator) test(version *semverVersion) bool {
+	result := version.compare(x.Version)
+	switch x.Operator {
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return result == 0
+}
+
+// test 版本是否满足范围。跟npm一致，预发布版本只有在同一组比较中有相同major.minor.patch的预发布版本时才能匹配，
+// 比如 ^1.2.3-beta.1 匹配 1.2.3-beta.2，但是不匹配 1.2.4-beta.1
+func (x *semverRange) test(version *semverVersion) bool {
+	for _, set := range x.sets {
+		if testSemverComparators(set, version) {
+			return true
+		}
+	}
+	return false
+}
+
+func testSemverComparators(set []*semverComparator, version *semverVersion) bool {
+	for _, comparator := range set {
+		if !comparator.test(version) {
+			return false
+		}
+	}
+	if len(version.Prerelease) == 0 {
+		return true
+	}
+	for _, comparator := range set {
+		if len(comparator.Version.Prerelease) > 0 && comparator.Version.sameTuple(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffSemver 返回两个版本之间最高的不同部分：major、minor或patch，只有预发布标签不同时算patch，版本相同时返回空
+func diffSemver(from, to *semverVersion) string {
+	switch {
+	case from.Major != to.Major:
+		return "major"
+	case from.Minor != to.Minor:
+		return "minor"
+	case from.compare(to) != 0:
+		return "patch"
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSemver(t *testing.T) {
+	version, err := parseSemver("v1.2.3-beta.1+build.5")
+	require.NoError(t, err)
+	assert.Equal(t, &semverVersion{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"beta", "1"}}, version)
+	assert.Equal(t, "1.2.3-beta.1", version.String())
+
+	for _, invalid := range []string{"", "1.2", "01.2.3", "1.2.3.4", "latest", "^1.2.3"} {
+		_, err := parseSemver(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSemverVersion_Compare(t *testing.T) {
+	// 按照从小到大排列
+	versions := []string{
+		"0.0.1", "1.0.0-0", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11",
+		"1.0.0-rc.1", "1.0.0", "1.0.1", "1.2.0", "1.10.0", "2.0.0",
+	}
+	for i := range versions {
+		for j := range versions {
+			a, err := parseSemver(versions[i])
+			require.NoError(t, err)
+			b, err := parseSemver(versions[j])
+			require.NoError(t, err)
+			expected := 0
+			switch {
+			case i < j:
+				expected = -1
+			case i > j:
+				expected = 1
+			}
+			assert.Equal(t, expected, a.compare(b), "%s %s", versions[i], versions[j])
+		}
+	}
+}
+
+func TestSemverRange_Test(t *testing.T) {
+	tests := []struct {
+		versionRange string
+		matches      []string
+		notMatches   []string
+	}{
+		{versionRange: "", matches: []string{"0.0.0", "9.9.9"}, notMatches: []string{"1.0.0-beta"}},
+		{versionRange: "*", matches: []string{"1.2.3"}, notMatches: []string{"1.2.3-rc.1"}},
+		{versionRange: "1.x", matches: []string{"1.0.0", "1.9.9"}, notMatches: []string{"2.0.0", "0.9.9"}},
+		{versionRange: "1.2", matches: []string{"1.2.0", "1.2.9"}, notMatches: []string{"1.3.0"}},
+		{versionRange: "=1.2.3", matches: []string{"1.2.3", "v1.2.3"}, notMatches: []string{"1.2.4"}},
+		{versionRange: "^1.2.3", matches: []string{"1.2.3", "1.9.0"}, notMatches: []string{"1.2.2", "2.0.0", "2.0.0-beta.1", "1.3.0-beta.1"}},
+		{versionRange: "^0.2.3", matches: []string{"0.2.3", "0.2.9"}, notMatches: []string{"0.3.0"}},
+		{versionRange: "^0.0.3", matches: []string{"0.0.3"}, notMatches: []string{"0.0.4"}},
+		{versionRange: "^0.0.x", matches: []string{"0.0.0", "0.0.9"}, notMatches: []string{"0.1.0"}},
+		{versionRange: "^0.x", matches: []string{"0.9.0"}, notMatches: []string{"1.0.0"}},
+		{versionRange: "^1.2.3-beta.2", matches: []string{"1.2.3-beta.2", "1.2.3-beta.10", "1.2.3", "1.5.0"}, notMatches: []string{"1.2.3-beta.1", "1.2.4-beta.3"}},
+		{versionRange: "~1.2.3", matches: []string{"1.2.3", "1.2.9"}, notMatches: []string{"1.3.0"}},
+		{versionRange: "~1", matches: []string{"1.9.9"}, notMatches: []string{"2.0.0"}},
+		{versionRange: "~>1.2", matches: []string{"1.2.5"}, notMatches: []string{"1.3.0"}},
+		{versionRange: ">= 1.2.3 < 2", matches: []string{"1.2.3", "1.99.0"}, notMatches: []string{"2.0.0", "1.2.2"}},
+		{versionRange: ">1.2", matches: []string{"1.3.0"}, notMatches: []string{"1.2.9"}},
+		{versionRange: ">1.2.3", matches: []string{"1.2.4"}, notMatches: []string{"1.2.3"}},
+		{versionRange: "<1.2", matches: []string{"1.1.9"}, notMatches: []string{"1.2.0", "1.2.0-beta"}},
+		{versionRange: "<=1.2", matches: []string{"1.2.9"}, notMatches: []string{"1.3.0"}},
+		{versionRange: "<=1.2.3", matches: []string{"1.2.3"}, notMatches: []string{"1.2.4"}},
+		{versionRange: "1.2.3 - 2.3.4", matches: []string{"1.2.3", "2.3.4"}, notMatches: []string{"2.3.5"}},
+		{versionRange: "1.2 - 2.3", matches: []string{"1.2.0", "2.3.9"}, notMatches: []string{"2.4.0", "1.1.9"}},
+		{versionRange: "1.2.3 - 2", matches: []string{"2.9.9"}, notMatches: []string{"3.0.0"}},
+		{versionRange: "^1.0.0 || ^3.0.0", matches: []string{"1.5.0", "3.1.0"}, notMatches: []string{"2.0.0"}},
+		{versionRange: ">=1.0.0-rc.1 <1.0.0", matches: []string{"1.0.0-rc.2"}, notMatches: []string{"1.0.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.versionRange, func(t *testing.T) {
+			parsed, err := parseSemverRange(tt.versionRange)
+			require.NoError(t, err)
+			for _, version := range tt.matches {
+				v, err := parseSemver(version)
+				require.NoError(t, err)
+				assert.True(t, parsed.test(v), version)
+			}
+			for _, version := range tt.notMatches {
+				v, err := parseSemver(version)
+				require.NoError(t, err)
+				assert.False(t, parsed.test(v), version)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"latest", "github:user/repo", "workspace:^", "file:../a", ">=abc"} {
+		_, err := parseSemverRange(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestDiffSemver(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{from: "1.2.3", to: "2.0.0", want: "major"},
+		{from: "1.2.3", to: "1.3.0", want: "minor"},
+		{from: "1.2.3", to: "1.2.4", want: "patch"},
+		{from: "1.2.3-beta.1", to: "1.2.3", want: "patch"},
+		{from: "1.2.3", to: "1.2.3", want: ""},
+	}
+	for _, tt := range tests {
+		from, err := parseSemver(tt.from)
+		require.NoError(t, err)
+		to, err := parseSemver(tt.to)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, diffSemver(from, to), "%s -> %s", tt.from, tt.to)
+	}
+}
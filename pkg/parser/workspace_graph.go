@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// WorkspaceBuildEdgeTypes TopologicalOrder和Cycles默认使用的依赖类型，也就是安装workspace时会一起安装的依赖。
+// 开发依赖和peer依赖不会影响构建顺序，workspace之间经常互相作为开发依赖（比如共用的测试工具），计算在内会产生循环
+var WorkspaceBuildEdgeTypes = []models.DependencyGraphEdgeType{models.DependencyGraphEdgeProd, models.DependencyGraphEdgeOptional}
+
+// TopologicalOrder 返回workspace的构建顺序，被依赖的workspace排在依赖它的workspace前面，
+// 没有先后关系的按名称排序；存在循环依赖时返回错误。types是参与排序的依赖类型，不指定时使用WorkspaceBuildEdgeTypes
+func (x *WorkspaceParser) TopologicalOrder(graph *models.WorkspaceGraph, types ...models.DependencyGraphEdgeType) ([]string, error) {
+	if graph == nil {
+		return nil, fmt.Errorf("workspace graph cannot be nil")
+	}
+	adjacency := newWorkspaceAdjacency(graph, types)
+	if cycles := adjacency.cycles(); len(cycles) > 0 {
+		return nil, fmt.Errorf("workspace dependency cycle: %s", strings.Join(adjacency.closeCycle(cycles[0]), " -> "))
+	}
+	return adjacency.topologicalOrder(), nil
+}
+
+// Cycles 返回workspace之间所有的循环依赖，每个循环是一个强连通分量，从名称最小的workspace开始，
+// 尽量沿着依赖的方向排列；自己依赖自己也是一个循环。types是参与计算的依赖类型，不指定时使用WorkspaceBuildEdgeTypes
+func (x *WorkspaceParser) Cycles(graph *models.WorkspaceGraph, types ...models.DependencyGraphEdgeType) [][]string {
+	if graph == nil {
+		return make([][]string, 0)
+	}
+	return newWorkspaceAdjacency(graph, types).cycles()
+}
+
+// workspaceAdjacency 按照依赖类型筛选后的workspace之间的依赖关系，创建时计算一次，排序和找循环时不再遍历package.json中的依赖
+type workspaceAdjacency struct {
+	// 排好序的workspace名称
+	names []string
+
+	// 每个workspace依赖的其它workspace的名称，去重并排序，不存在的workspace被忽略
+	dependencies map[string][]string
+
+	// 每个workspace被哪些workspace依赖，按名称排序
+	dependents map[string][]string
+}
+
+// newWorkspaceAdjacency 从workspace依赖图中取出指定类型的依赖，没有指定类型时使用WorkspaceBuildEdgeTypes
+func newWorkspaceAdjacency(graph *models.WorkspaceGraph, types []models.DependencyGraphEdgeType) *workspaceAdjacency {
+	if len(types) == 0 {
+		types = WorkspaceBuildEdgeTypes
+	}
+	edgeTypes := make(map[models.DependencyGraphEdgeType]bool, len(types))
+	for _, edgeType := range types {
+		edgeTypes[edgeType] = true
+	}
+
+	adjacency := &workspaceAdjacency{
+		names:        graph.SortedNames(),
+		dependencies: make(map[string][]string, len(graph.Workspaces)),
+		dependents:   make(map[string][]string, len(graph.Workspaces)),
+	}
+	for _, name := range adjacency.names {
+		seen := make(map[string]bool)
+		names := make([]string, 0)
+		for _, dependency := range graph.Workspaces[name].Dependencies {
+			if !edgeTypes[dependency.Type] || seen[dependency.Name] {
+				continue
+			}
+			if _, exists := graph.Workspaces[dependency.Name]; !exists {
+				continue
+			}
+			seen[dependency.Name] = true
+			names = append(names, dependency.Name)
+		}
+		sort.Strings(names)
+		adjacency.dependencies[name] = names
+		// 按名称顺序遍历，所以依赖它的workspace也是排好序的
+		for _, dependency := range names {
+			adjacency.dependents[dependency] = append(adjacency.dependents[dependency], name)
+		}
+	}
+	return adjacency
+}
+
+// dependsOn 判断from是否直接依赖to
+func (x *workspaceAdjacency) dependsOn(from string, to string) bool {
+	names := x.dependencies[from]
+	index := sort.SearchStrings(names, to)
+	return index < len(names) && names[index] == to
+}
+
+// topologicalOrder 入度是依赖的其它workspace的数量，每次取出入度为0的名称最小的workspace，
+// 调用前需要确认没有循环依赖
+func (x *workspaceAdjacency) topologicalOrder() []string {
+	inDegree := make(map[string]int, len(x.names))
+	ready := make([]string, 0)
+	for _, name := range x.names {
+		inDegree[name] = len(x.dependencies[name])
+		if inDegree[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	order := make([]string, 0, len(x.names))
+	for len(ready) > 0 {
+		next := ready[0]
+		ready = ready[1:]
+		order = append(order, next)
+		for _, dependent := range x.dependents[next] {
+			inDegree[dependent]--
+			if inDegree[dependent] != 0 {
+				continue
+			}
+			// 保持ready有序，下一次取出的就是名称最小的
+			index := sort.SearchStrings(ready, dependent)
+			ready = append(ready, "")
+			copy(ready[index+1:], ready[index:])
+			ready[index] = dependent
+		}
+	}
+	return order
+}
+
+// cycles 使用Tarjan算法找出强连通分量
+func (x *workspaceAdjacency) cycles() [][]string {
+	index := 0
+	indexes := make(map[string]int)
+	lowLinks := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := make([]string, 0)
+	cycles := make([][]string, 0)
+
+	var connect func(name string)
+	connect = func(name string) {
+		indexes[name] = index
+		lowLinks[name] = index
+		index++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, dependency := range x.dependencies[name] {
+			if _, visited := indexes[dependency]; !visited {
+				connect(dependency)
+				if lowLinks[dependency] < lowLinks[name] {
+					lowLinks[name] = lowLinks[dependency]
+				}
+			} else if onStack[dependency] && indexes[dependency] < lowLinks[name] {
+				lowLinks[name] = indexes[dependency]
+			}
+		}
+
+		if lowLinks[name] != indexes[name] {
+			return
+		}
+		component := make(map[string]bool)
+		for {
+			member := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[member] = false
+			component[member] = true
+			if member == name {
+				break
+			}
+		}
+		if len(component) > 1 || x.dependsOn(name, name) {
+			cycles = append(cycles, x.orderCycle(component))
+		}
+	}
+	for _, name := range x.names {
+		if _, visited := indexes[name]; !visited {
+			connect(name)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+// orderCycle 把强连通分量排列为一条路径：从名称最小的workspace开始，每次走到分量中还没有经过的名称最小的依赖
+func (x *workspaceAdjacency) orderCycle(component map[string]bool) []string {
+	names := make([]string, 0, len(component))
+	for name := range component {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cycle := []string{names[0]}
+	visited := map[string]bool{names[0]: true}
+	for current := names[0]; ; {
+		next := ""
+		for _, dependency := range x.dependencies[current] {
+			if component[dependency] && !visited[dependency] {
+				next = dependency
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		cycle = append(cycle, next)
+		visited[next] = true
+		current = next
+	}
+	// 分量中的路径不一定能一次经过所有成员，剩下的按名称追加
+	for _, name := range names {
+		if !visited[name] {
+			cycle = append(cycle, name)
+		}
+	}
+	return cycle
+}
+
+// closeCycle 找出从循环中第一个workspace出发、经过分量中的成员回到它自己的最短路径，
+// 首尾都是这个workspace，比如 a -> b -> a。orderCycle的结果不一定能首尾相连，不能直接用来描述循环
+func (x *workspaceAdjacency) closeCycle(cycle []string) []string {
+	start := cycle[0]
+	component := make(map[string]bool, len(cycle))
+	for _, name := range cycle {
+		component[name] = true
+	}
+
+	// 广度优先搜索，previous记录到达每个workspace的上一个workspace
+	previous := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependency := range x.dependencies[current] {
+			if dependency == start {
+				path := []string{start}
+				for name := current; name != start; name = previous[name] {
+					path = append(path, name)
+				}
+				path = append(path, start)
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if _, visited := previous[dependency]; visited || !component[dependency] {
+				continue
+			}
+			previous[dependency] = current
+			queue = append(queue, dependency)
+		}
+	}
+	return cycle
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceParser_Cycles(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"package.json":   `{"name": "root", "workspaces": ["*"]}`,
+		"a/package.json": `{"name": "a", "dependencies": {"b": "*"}}`,
+		"b/package.json": `{"name": "b", "devDependencies": {"c": "*"}}`,
+		"c/package.json": `{"name": "c", "peerDependencies": {"a": "*"}}`,
+		"d/package.json": `{"name": "d", "dependencies": {"a": "*", "d": "*"}}`,
+		"e/package.json": `{"name": "e"}`,
+	})
+
+	parser := NewWorkspaceParser()
+	graph, err := parser.ParseWorkspaces(context.Background(), &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+	require.NoError(t, err)
+	allEdgeTypes := []models.DependencyGraphEdgeType{models.DependencyGraphEdgeProd, models.DependencyGraphEdgeDev, models.DependencyGraphEdgeOptional, models.DependencyGraphEdgePeer}
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"d"}}, parser.Cycles(graph, allEdgeTypes...))
+	_, err = parser.TopologicalOrder(graph, allEdgeTypes...)
+	assert.EqualError(t, err, "workspace dependency cycle: a -> b -> c -> a")
+
+	// 默认不计算开发依赖和peer依赖
+	assert.Equal(t, [][]string{{"d"}}, parser.Cycles(graph))
+	_, err = parser.TopologicalOrder(graph)
+	assert.EqualError(t, err, "workspace dependency cycle: d -> d")
+}
+
+func TestWorkspaceParser_TopologicalOrderIgnoresDevDependencies(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"package.json":          `{"name": "root", "workspaces": ["*"]}`,
+		"app/package.json":      `{"name": "app", "dependencies": {"lib": "*"}}`,
+		"lib/package.json":      `{"name": "lib", "devDependencies": {"test-kit": "*"}}`,
+		"test-kit/package.json": `{"name": "test-kit", "dependencies": {"lib": "*"}}`,
+	})
+
+	parser := NewWorkspaceParser()
+	graph, err := parser.ParseWorkspaces(context.Background(), &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+	require.NoError(t, err)
+	order, err := parser.TopologicalOrder(graph)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lib", "app", "test-kit"}, order)
+
+	_, err = parser.TopologicalOrder(graph, models.DependencyGraphEdgeProd, models.DependencyGraphEdgeDev)
+	assert.EqualError(t, err, "workspace dependency cycle: lib -> test-kit -> lib")
+}
+
+func TestWorkspaceParser_CycleMessage(t *testing.T) {
+	// a依赖b和c，只有c能回到a，按名称选择的路径 a -> b -> c 不是一个循环
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"package.json":   `{"name": "root", "workspaces": ["*"]}`,
+		"a/package.json": `{"name": "a", "dependencies": {"b": "*", "c": "*"}}`,
+		"b/package.json": `{"name": "b", "dependencies": {"a": "*"}}`,
+		"c/package.json": `{"name": "c", "dependencies": {"a": "*"}}`,
+	})
+
+	parser := NewWorkspaceParser()
+	graph, err := parser.ParseWorkspaces(context.Background(), &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b", "c"}}, parser.Cycles(graph))
+	_, err = parser.TopologicalOrder(graph)
+	assert.EqualError(t, err, "workspace dependency cycle: a -> b -> a")
+}
+
+func TestWorkspaceParser_TopologicalOrderDiamond(t *testing.T) {
+	graph := models.NewWorkspaceGraph()
+	for name, dependencies := range map[string][]string{"app": {"ui", "api"}, "ui": {"core"}, "api": {"core"}, "core": nil, "docs": nil} {
+		workspace := &models.Workspace{Name: name, Path: name}
+		for _, dependency := range dependencies {
+			workspace.Dependencies = append(workspace.Dependencies, &models.WorkspaceDependency{Name: dependency, Range: "*", Type: models.DependencyGraphEdgeProd})
+		}
+		graph.Workspaces[name] = workspace
+	}
+
+	order, err := NewWorkspaceParser().TopologicalOrder(graph)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"core", "api", "docs", "ui", "app"}, order)
+
+	_, err = NewWorkspaceParser().TopologicalOrder(nil)
+	assert.Error(t, err)
+}
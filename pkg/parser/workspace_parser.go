@@ -0,0 +1,330 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/scagogogo/sca-base-module-ecosystem-parser/pkg/parser"
+)
+
+// WorkspaceParser 解析monorepo：展开根目录package.json中workspaces字段（或者pnpm-workspace.yaml中packages）的glob，
+// 使用PackageJsonParser解析每个workspace的package.json，每个workspace是项目中的一个模块，
+// workspace之间的依赖关系记录在ProjectEcosystem.Workspaces中
+type WorkspaceParser struct {
+}
+
+var _ parser.Parser[*WorkspaceParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &WorkspaceParser{}
+
+func NewWorkspaceParser() *WorkspaceParser {
+	return &WorkspaceParser{}
+}
+
+const WorkspaceParserName = "workspace-parser"
+
+// workspaceNegationPrefix workspace glob中表示排除的前缀
+const workspaceNegationPrefix = "!"
+
+func (x *WorkspaceParser) GetName() string {
+	return WorkspaceParserName
+}
+
+func (x *WorkspaceParser) Init(ctx context.Context) error {
+	return nil
+}
+
+// workspaceDiscovery 发现workspace的结果，解析为项目时还需要根目录的清单和pnpm workspace的配置
+type workspaceDiscovery struct {
+	graph         *models.WorkspaceGraph
+	rootManifest  *models.PackageJson
+	pnpmWorkspace *models.PnpmWorkspace
+	fileSystem    fs.FS
+}
+
+// ParseWorkspaces 找出monorepo中所有的workspace以及它们之间的依赖关系，不转换为项目
+func (x *WorkspaceParser) ParseWorkspaces(ctx context.Context, input *WorkspaceParserInput) (*models.WorkspaceGraph, error) {
+	discovery, err := x.discover(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return discovery.graph, nil
+}
+
+// Parse 解析monorepo，根目录和每个workspace各是一个模块，模块的键是package.json中的名称，
+// workspace依赖的其它workspace标记为Link，workspace: 和 catalog: 声明会被替换为具体的版本范围
+func (x *WorkspaceParser) Parse(ctx context.Context, input *WorkspaceParserInput) (*models.JsProject, error) {
+	discovery, err := x.discover(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceVersions := make(map[string]string)
+	for name, workspace := range discovery.graph.Workspaces {
+		workspaceVersions[name] = workspace.Version
+	}
+	packageJsonParser := &PackageJsonParser{}
+	packageJsonInput := &PackageJsonParserInput{PnpmWorkspace: discovery.pnpmWorkspace, WorkspacePackageVersions: workspaceVersions}
+
+	project := &models.JsProject{}
+	project.Name = "unknown"
+	if discovery.rootManifest != nil {
+		if discovery.rootManifest.Name != "" {
+			project.Name = discovery.rootManifest.Name
+		}
+		project.Version = discovery.rootManifest.Version
+
+		rootManifest := *discovery.rootManifest
+		rootManifest.Name = project.Name
+		rootModule := packageJsonParser.buildProject(&rootManifest, packageJsonInput).TakeFirstModule()
+		x.markWorkspaceLinks(rootModule, discovery.graph)
+		project.SetModule(project.Name, rootModule)
+	}
+
+	for _, name := range discovery.graph.SortedNames() {
+		workspace := discovery.graph.Workspaces[name]
+		manifest := *workspace.PackageJson
+		manifest.Name = workspace.Name
+		module := packageJsonParser.buildProject(&manifest, packageJsonInput).TakeFirstModule()
+		module.ModuleEcosystem.WorkspacePath = workspace.Path
+		x.markWorkspaceLinks(module, discovery.graph)
+		project.SetModule(name, module)
+	}
+
+	project.ProjectEcosystem = &models.JsProjectEcosystem{Workspaces: discovery.graph}
+	return project, nil
+}
+
+// markWorkspaceLinks 把模块对其它workspace的依赖标记为Link，这些依赖安装时链接到workspace的目录
+func (x *WorkspaceParser) markWorkspaceLinks(module *models.JsModule, graph *models.WorkspaceGraph) {
+	for _, dependency := range module.Dependencies {
+		name := dependency.DependencyName
+		if specifier := dependency.ComponentDependencyEcosystem.Specifier; specifier != "" {
+			name = workspaceDependencyName(name, specifier)
+		} else {
+			name = workspaceDependencyName(name, dependency.DependencyVersion)
+		}
+		if _, ok := graph.Workspaces[name]; ok {
+			link := true
+			dependency.ComponentDependencyEcosystem.Link = &link
+		}
+	}
+}
+
+// discover 读取根目录的配置，展开workspace的glob并解析每个workspace的package.json
+func (x *WorkspaceParser) discover(ctx context.Context, input *WorkspaceParserInput) (*workspaceDiscovery, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	fileSystem, err := input.rootFileSystem()
+	if err != nil {
+		return nil, err
+	}
+	discovery := &workspaceDiscovery{graph: models.NewWorkspaceGraph(), fileSystem: fileSystem}
+
+	packageJsonParser := &PackageJsonParser{}
+	discovery.rootManifest, err = packageJsonParser.ParseManifest(ctx, &PackageJsonParserInput{PackageJsonPath: PackageJsonFileName, FileSystem: fileSystem})
+	if errors.Is(err, fs.ErrNotExist) {
+		discovery.rootManifest = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// pnpm不使用package.json中的workspaces字段，但是catalog也在pnpm-workspace.yaml中，所以总是尝试读取
+	if _, statErr := fs.Stat(fileSystem, PnpmWorkspaceFileName); statErr == nil {
+		discovery.pnpmWorkspace, err = NewPnpmWorkspaceParser().Parse(ctx, &PnpmWorkspaceParserInput{PnpmWorkspacePath: PnpmWorkspaceFileName, FileSystem: fileSystem})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if discovery.rootManifest == nil && discovery.pnpmWorkspace == nil {
+		return nil, fmt.Errorf("neither %s nor %s found in %s", PackageJsonFileName, PnpmWorkspaceFileName, input.ProjectRootDirectory)
+	}
+
+	switch {
+	case discovery.rootManifest != nil && len(discovery.rootManifest.Workspaces) > 0:
+		discovery.graph.Patterns = append(discovery.graph.Patterns, discovery.rootManifest.Workspaces...)
+	case discovery.pnpmWorkspace != nil:
+		discovery.graph.Patterns = append(discovery.graph.Patterns, discovery.pnpmWorkspace.Packages...)
+	}
+
+	directories, err := expandWorkspacePatterns(fileSystem, discovery.graph.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	for _, directory := range directories {
+		packageJson, err := packageJsonParser.ParseManifest(ctx, &PackageJsonParserInput{PackageJsonPath: path.Join(directory, PackageJsonFileName), FileSystem: fileSystem})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse workspace %s: %w", directory, err)
+		}
+		// 跟npm一样，没有名称的workspace使用目录名
+		name := packageJson.Name
+		if name == "" {
+			name = path.Base(directory)
+		}
+		if existing, ok := discovery.graph.Workspaces[name]; ok {
+			return nil, fmt.Errorf("workspace name %s is used by both %s and %s", name, existing.Path, directory)
+		}
+		discovery.graph.Workspaces[name] = &models.Workspace{
+			Name:        name,
+			Version:     packageJson.Version,
+			Path:        directory,
+			PackageJson: packageJson,
+		}
+	}
+
+	for _, workspace := range discovery.graph.Workspaces {
+		workspace.Dependencies = workspaceDependencies(workspace.PackageJson, discovery.graph)
+	}
+	return discovery, nil
+}
+
+// workspaceDependencies 找出package.json中对其它workspace的依赖，按名称和依赖类型排序
+func workspaceDependencies(packageJson *models.PackageJson, graph *models.WorkspaceGraph) []*models.WorkspaceDependency {
+	dependencies := make([]*models.WorkspaceDependency, 0)
+	for _, group := range []struct {
+		dependencies   models.Dependencies
+		dependencyType models.DependencyGraphEdgeType
+	}{
+		{dependencies: packageJson.Dependencies, dependencyType: models.DependencyGraphEdgeProd},
+		{dependencies: packageJson.DevDependencies, dependencyType: models.DependencyGraphEdgeDev},
+		{dependencies: packageJson.OptionalDependencies, dependencyType: models.DependencyGraphEdgeOptional},
+		{dependencies: packageJson.PeerDependencies, dependencyType: models.DependencyGraphEdgePeer},
+	} {
+		for _, name := range sortedDependencyNames(group.dependencies) {
+			versionRange := group.dependencies[name]
+			target := workspaceDependencyName(name, versionRange)
+			if _, ok := graph.Workspaces[target]; !ok {
+				continue
+			}
+			dependencies = append(dependencies, &models.WorkspaceDependency{Name: target, Range: versionRange, Type: group.dependencyType})
+		}
+	}
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		return dependencies[i].Name < dependencies[j].Name
+	})
+	return dependencies
+}
+
+// workspaceDependencyName 返回依赖实际指向的包名，通过 npm:name@range 或 workspace:name@range 使用别名时是别名指向的包
+func workspaceDependencyName(name string, versionRange string) string {
+	if target, _, ok := parseNpmAlias(versionRange); ok {
+		return target
+	}
+	if spec := strings.TrimPrefix(versionRange, pnpmWorkspacePrefix); spec != versionRange && strings.Contains(strings.TrimPrefix(spec, "@"), "@") {
+		target, _ := splitPackageSpec(spec)
+		return target
+	}
+	return name
+}
+
+// expandWorkspacePatterns 展开workspace的glob，返回包含package.json的workspace目录（相对于根目录，按路径排序）。
+// glob按顺序生效，以!开头的排除之前匹配到的目录，之后的glob可以再次包含；支持 *、?、[...] 和跨目录的 **，
+// 跟npm一样 * 和 ** 不匹配以.开头的目录，node_modules总是被忽略
+func expandWorkspacePatterns(fileSystem fs.FS, patterns []string) ([]string, error) {
+	type workspacePattern struct {
+		segments []string
+		negated  bool
+	}
+	compiled := make([]*workspacePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, workspaceNegationPrefix)
+		pattern = strings.TrimPrefix(pattern, workspaceNegationPrefix)
+		pattern = strings.Trim(path.Clean(strings.TrimPrefix(pattern, "./")), "/")
+		if pattern == "" || pattern == "." {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid workspace pattern %s: %w", pattern, err)
+		}
+		compiled = append(compiled, &workspacePattern{segments: strings.Split(pattern, "/"), negated: negated})
+	}
+
+	directories := make([]string, 0)
+	err := fs.WalkDir(fileSystem, ".", func(directory string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if directory == "." {
+			return nil
+		}
+		if entry.Name() == "node_modules" {
+			return fs.SkipDir
+		}
+
+		segments := strings.Split(directory, "/")
+		included := false
+		couldMatch := false
+		for _, pattern := range compiled {
+			if !pattern.negated && matchWorkspacePrefix(pattern.segments, segments) {
+				couldMatch = true
+			}
+			if matchWorkspacePattern(pattern.segments, segments) {
+				included = !pattern.negated
+			}
+		}
+		if included {
+			if _, statErr := fs.Stat(fileSystem, path.Join(directory, PackageJsonFileName)); statErr == nil {
+				directories = append(directories, directory)
+			}
+		}
+		// 任何glob都不可能匹配这个目录下的目录时不再往下找
+		if !couldMatch {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(directories)
+	return directories, nil
+}
+
+// matchWorkspacePattern 判断目录是否匹配glob，patternSegments和pathSegments都是按/拆分后的路径
+func matchWorkspacePattern(patternSegments []string, pathSegments []string) bool {
+	if len(patternSegments) == 0 {
+		return len(pathSegments) == 0
+	}
+	if patternSegments[0] == "**" {
+		if matchWorkspacePattern(patternSegments[1:], pathSegments) {
+			return true
+		}
+		return len(pathSegments) > 0 && !strings.HasPrefix(pathSegments[0], ".") && matchWorkspacePattern(patternSegments, pathSegments[1:])
+	}
+	return len(pathSegments) > 0 && matchWorkspaceSegment(patternSegments[0], pathSegments[0]) && matchWorkspacePattern(patternSegments[1:], pathSegments[1:])
+}
+
+// matchWorkspacePrefix 判断glob是否可能匹配这个目录或者它下面的目录
+func matchWorkspacePrefix(patternSegments []string, pathSegments []string) bool {
+	if len(pathSegments) == 0 {
+		return true
+	}
+	if len(patternSegments) == 0 {
+		return false
+	}
+	if patternSegments[0] == "**" {
+		return !strings.HasPrefix(pathSegments[0], ".") || matchWorkspacePrefix(patternSegments[1:], pathSegments)
+	}
+	return matchWorkspaceSegment(patternSegments[0], pathSegments[0]) && matchWorkspacePrefix(patternSegments[1:], pathSegments[1:])
+}
+
+// matchWorkspaceSegment 匹配路径中的一段，以.开头的目录只能被同样以.开头的glob匹配
+func matchWorkspaceSegment(pattern string, name string) bool {
+	if strings.HasPrefix(name, ".") && !strings.HasPrefix(pattern, ".") {
+		return false
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+func (x *WorkspaceParser) Close(ctx context.Context) error {
+	return nil
+}
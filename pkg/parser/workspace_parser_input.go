@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// WorkspaceParserInput monorepo解析器的输入，只需要指定项目根目录
+type WorkspaceParserInput struct {
+	// ProjectRootDirectory monorepo的根目录，workspace的glob相对于这个目录展开
+	ProjectRootDirectory string
+
+	// FileSystem 指定后ProjectRootDirectory是这个文件系统中的路径
+	FileSystem fs.FS
+}
+
+// rootFileSystem 返回以项目根目录为根的文件系统，workspace的路径都相对于它
+func (x *WorkspaceParserInput) rootFileSystem() (fs.FS, error) {
+	if x.ProjectRootDirectory == "" {
+		return nil, fmt.Errorf("project root directory cannot be empty")
+	}
+	if x.FileSystem == nil {
+		return os.DirFS(x.ProjectRootDirectory), nil
+	}
+	return fs.Sub(x.FileSystem, toFsPath(x.ProjectRootDirectory))
+}
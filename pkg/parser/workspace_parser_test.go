@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWorkspaceTestFileSystem 把 路径 -> 内容 转换为MapFS
+func newWorkspaceTestFileSystem(files map[string]string) fstest.MapFS {
+	fileSystem := fstest.MapFS{}
+	for filePath, content := range files {
+		fileSystem[filePath] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fileSystem
+}
+
+func TestWorkspaceParser_Parse(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"repo/package.json":                         `{"name": "monorepo", "private": true, "workspaces": ["packages/*", "apps/**", "!apps/legacy"], "devDependencies": {"typescript": "^5.4.0"}}`,
+		"repo/packages/core/package.json":           `{"name": "@acme/core", "version": "1.2.0", "dependencies": {"lodash": "^4.17.21"}}`,
+		"repo/packages/ui/package.json":             `{"name": "@acme/ui", "version": "0.5.0", "dependencies": {"@acme/core": "workspace:^"}, "peerDependencies": {"react": "^18.0.0"}}`,
+		"repo/packages/utils/package.json":          `{"version": "0.0.1"}`,
+		"repo/packages/.cache/package.json":         `{"name": "hidden"}`,
+		"repo/packages/notes/README.md":             `没有package.json的目录`,
+		"repo/apps/web/package.json":                `{"name": "web", "dependencies": {"@acme/ui": "^0.5.0", "core": "npm:@acme/core@^1.0.0"}, "devDependencies": {"utils": "*"}}`,
+		"repo/apps/tools/cli/package.json":          `{"name": "cli", "dependencies": {"@acme/core": "1.2.0"}}`,
+		"repo/apps/legacy/package.json":             `{"name": "legacy"}`,
+		"repo/apps/web/node_modules/x/package.json": `{"name": "x"}`,
+	})
+
+	project, err := NewWorkspaceParser().Parse(context.Background(), &WorkspaceParserInput{ProjectRootDirectory: "repo", FileSystem: fileSystem})
+	require.NoError(t, err)
+
+	assert.Equal(t, "monorepo", project.Name)
+	graph := project.ProjectEcosystem.Workspaces
+	require.NotNil(t, graph)
+	assert.Equal(t, []string{"packages/*", "apps/**", "!apps/legacy"}, graph.Patterns)
+	assert.Equal(t, []string{"@acme/core", "@acme/ui", "cli", "utils", "web"}, graph.SortedNames())
+	assert.Equal(t, "packages/utils", graph.Workspaces["utils"].Path, "没有名称时使用目录名")
+	assert.Equal(t, "apps/tools/cli", graph.Workspaces["cli"].Path)
+
+	// 根目录和每个workspace各是一个模块
+	require.Len(t, project.Modules, 6)
+	assert.Equal(t, "", project.Modules["monorepo"].ModuleEcosystem.WorkspacePath)
+	ui := project.Modules["@acme/ui"]
+	require.NotNil(t, ui)
+	assert.Equal(t, "packages/ui", ui.ModuleEcosystem.WorkspacePath)
+	require.Len(t, ui.Dependencies, 1)
+	assert.Equal(t, "^1.2.0", ui.Dependencies[0].DependencyVersion, "workspace: 被替换为workspace的版本")
+	assert.Equal(t, "workspace:^", ui.Dependencies[0].ComponentDependencyEcosystem.Specifier)
+	assert.True(t, *ui.Dependencies[0].ComponentDependencyEcosystem.Link)
+
+	web := project.Modules["web"]
+	require.NotNil(t, web)
+	for _, dependency := range web.Dependencies {
+		require.NotNil(t, dependency.ComponentDependencyEcosystem.Link, dependency.DependencyName)
+		assert.True(t, *dependency.ComponentDependencyEcosystem.Link)
+	}
+	assert.Nil(t, project.Modules["@acme/core"].Dependencies[0].ComponentDependencyEcosystem.Link)
+
+	assert.Equal(t, []*models.WorkspaceDependency{
+		{Name: "@acme/core", Range: "npm:@acme/core@^1.0.0", Type: models.DependencyGraphEdgeProd},
+		{Name: "@acme/ui", Range: "^0.5.0", Type: models.DependencyGraphEdgeProd},
+		{Name: "utils", Range: "*", Type: models.DependencyGraphEdgeDev},
+	}, graph.Workspaces["web"].Dependencies)
+	assert.Equal(t, []string{"@acme/ui", "cli", "web"}, graph.Dependents("@acme/core"))
+
+	order, err := NewWorkspaceParser().TopologicalOrder(graph)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@acme/core", "@acme/ui", "cli", "utils", "web"}, order)
+	assert.Empty(t, NewWorkspaceParser().Cycles(graph))
+}
+
+func TestWorkspaceParser_PnpmWorkspace(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"pnpm-workspace.yaml":          "packages:\n  - 'packages/**'\n  - '!**/test/**'\ncatalog:\n  react: ^18.2.0\n",
+		"packages/a/package.json":      `{"name": "a", "version": "1.0.0", "dependencies": {"react": "catalog:", "b": "workspace:*"}}`,
+		"packages/b/package.json":      `{"name": "b", "version": "2.0.0"}`,
+		"packages/b/test/package.json": `{"name": "b-test"}`,
+	})
+
+	project, err := NewWorkspaceParser().Parse(context.Background(), &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+	require.NoError(t, err)
+
+	// 没有根目录的package.json时只有workspace模块
+	assert.Equal(t, "unknown", project.Name)
+	require.Len(t, project.Modules, 2)
+	versions := make(map[string]string)
+	for _, dependency := range project.Modules["a"].Dependencies {
+		versions[dependency.DependencyName] = dependency.DependencyVersion
+	}
+	assert.Equal(t, map[string]string{"b": "2.0.0", "react": "^18.2.0"}, versions)
+}
+
+func TestWorkspaceParser_YarnWorkspacesObject(t *testing.T) {
+	fileSystem := newWorkspaceTestFileSystem(map[string]string{
+		"package.json":           `{"name": "root", "workspaces": {"packages": ["libs/*"], "nohoist": ["**/react-native"]}}`,
+		"libs/one/package.json":  `{"name": "one"}`,
+		"other/two/package.json": `{"name": "two"}`,
+	})
+
+	graph, err := NewWorkspaceParser().ParseWorkspaces(context.Background(), &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: fileSystem})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libs/*"}, graph.Patterns)
+	assert.Equal(t, []string{"one"}, graph.SortedNames())
+}
+
+func TestWorkspaceParser_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *WorkspaceParserInput
+	}{
+		{name: "输入为nil"},
+		{name: "没有根目录", input: &WorkspaceParserInput{FileSystem: fstest.MapFS{}}},
+		{name: "没有package.json和pnpm-workspace.yaml", input: &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: fstest.MapFS{}}},
+		{name: "workspace名称重复", input: &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: newWorkspaceTestFileSystem(map[string]string{
+			"package.json":   `{"workspaces": ["*"]}`,
+			"a/package.json": `{"name": "same"}`,
+			"b/package.json": `{"name": "same"}`,
+		})}},
+		{name: "workspace的package.json不是JSON", input: &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: newWorkspaceTestFileSystem(map[string]string{
+			"package.json":   `{"workspaces": ["*"]}`,
+			"a/package.json": `{"name": `,
+		})}},
+		{name: "无效的glob", input: &WorkspaceParserInput{ProjectRootDirectory: ".", FileSystem: newWorkspaceTestFileSystem(map[string]string{
+			"package.json": `{"workspaces": ["packages/[a"]}`,
+		})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWorkspaceParser().Parse(context.Background(), tt.input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMatchWorkspacePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		matched bool
+	}{
+		{pattern: "packages/*", path: "packages/core", matched: true},
+		{pattern: "packages/*", path: "packages/core/nested", matched: false},
+		{pattern: "packages/*", path: "packages/.cache", matched: false},
+		{pattern: "packages/**", path: "packages", matched: true},
+		{pattern: "packages/**", path: "packages/a/b/c", matched: true},
+		{pattern: "packages/**", path: "packages/a/.hidden/c", matched: false},
+		{pattern: "**/test/**", path: "packages/b/test", matched: true},
+		{pattern: "packages/*-plugin", path: "packages/eslint-plugin", matched: true},
+		{pattern: "packages/[ab]", path: "packages/c", matched: false},
+		{pattern: "docs", path: "docs", matched: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.matched, matchWorkspacePattern(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/")))
+		})
+	}
+}
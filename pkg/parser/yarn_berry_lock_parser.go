@@ -0,0 +1,289 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// yarnBerryMetadataKey Berry格式lockfile中存放元数据的键
+const yarnBerryMetadataKey = "__metadata"
+
+// yarnBerryUseLocalVersion workspace没有声明版本时Berry写入的占位版本
+const yarnBerryUseLocalVersion = "0.0.0-use.local"
+
+// yarnBerryDefaultProtocol 依赖声明中没有写协议时Berry默认使用的协议
+const yarnBerryDefaultProtocol = "npm:"
+
+// yarnBerryLockEntry Berry格式lockfile中的一个条目，对应yaml的结构
+type yarnBerryLockEntry struct {
+	Version              string                                        `yaml:"version"`
+	Resolution           string                                        `yaml:"resolution"`
+	Dependencies         map[string]string                             `yaml:"dependencies"`
+	PeerDependencies     map[string]string                             `yaml:"peerDependencies"`
+	DependenciesMeta     map[string]*models.YarnLockDependencyMeta     `yaml:"dependenciesMeta"`
+	PeerDependenciesMeta map[string]*models.YarnLockPeerDependencyMeta `yaml:"peerDependenciesMeta"`
+	Bin                  map[string]string                             `yaml:"bin"`
+	Checksum             string                                        `yaml:"checksum"`
+	LanguageName         string                                        `yaml:"languageName"`
+	LinkType             string                                        `yaml:"linkType"`
+	Conditions           string                                        `yaml:"conditions"`
+}
+
+// yarnBerryLockMetadata Berry格式lockfile中的__metadata
+type yarnBerryLockMetadata struct {
+	Version  string `yaml:"version"`
+	CacheKey string `yaml:"cacheKey"`
+}
+
+// isYarnBerryLock 判断是否是Berry（yarn v2+）格式的lockfile，Berry格式是yaml并且顶层一定有__metadata
+func isYarnBerryLock(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if bytes.Equal(line, []byte(yarnBerryMetadataKey+":")) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseYarnBerryLock 解析Berry格式的yarn.lock
+func (x *YarnLockParser) parseYarnBerryLock(data []byte) (*models.YarnLock, error) {
+	document := make(map[string]yaml.Node)
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid yarn berry lockfile: %w", err)
+	}
+
+	yarnLock := &models.YarnLock{
+		Dependencies: make(map[string]*models.YarnLockDependency),
+		Metadata:     &models.YarnLockMetadata{},
+	}
+
+	for key, node := range document {
+		if key == yarnBerryMetadataKey {
+			metadata := &yarnBerryLockMetadata{}
+			if err := node.Decode(metadata); err != nil {
+				return nil, fmt.Errorf("invalid %s at line %d: %w", yarnBerryMetadataKey, node.Line, err)
+			}
+			yarnLock.Metadata.Version = metadata.Version
+			yarnLock.Metadata.CacheKey = metadata.CacheKey
+			continue
+		}
+
+		entry := &yarnBerryLockEntry{}
+		if err := node.Decode(entry); err != nil {
+			return nil, fmt.Errorf("invalid entry %q at line %d: %w", key, node.Line, err)
+		}
+		dep := x.parseYarnBerryLockEntry(entry)
+
+		// 多个描述符合并写在同一个键里，用逗号分隔
+		for _, descriptor := range strings.Split(key, ",") {
+			yarnLock.Dependencies[strings.TrimSpace(descriptor)] = dep
+		}
+	}
+
+	if len(yarnLock.Dependencies) == 0 {
+		return nil, fmt.Errorf("no dependencies found in yarn.lock")
+	}
+	return yarnLock, nil
+}
+
+// parseYarnBerryLockEntry 把Berry格式的条目转换为依赖对象
+func (x *YarnLockParser) parseYarnBerryLockEntry(entry *yarnBerryLockEntry) *models.YarnLockDependency {
+	dep := &models.YarnLockDependency{
+		Version:              entry.Version,
+		Dependencies:         entry.Dependencies,
+		OptionalDependencies: make(map[string]string),
+		PeerDependencies:     entry.PeerDependencies,
+		LanguageName:         entry.LanguageName,
+		Resolution:           entry.Resolution,
+		Checksum:             entry.Checksum,
+		LinkType:             entry.LinkType,
+		Conditions:           entry.Conditions,
+		Bin:                  entry.Bin,
+		DependenciesMeta:     entry.DependenciesMeta,
+		PeerDependenciesMeta: entry.PeerDependenciesMeta,
+	}
+	if dep.Dependencies == nil {
+		dep.Dependencies = make(map[string]string)
+	}
+	if dep.PeerDependencies == nil {
+		dep.PeerDependencies = make(map[string]string)
+	}
+
+	// Berry把可选依赖也写在dependencies里，通过dependenciesMeta标记
+	for name, meta := range entry.DependenciesMeta {
+		if meta != nil && meta.Optional != nil && *meta.Optional {
+			if versionRange, ok := dep.Dependencies[name]; ok {
+				dep.OptionalDependencies[name] = versionRange
+			}
+		}
+	}
+
+	_, reference := splitPackageSpec(entry.Resolution)
+	dep.Source = yarnBerryProtocol(reference)
+	return dep
+}
+
+// yarnBerryProtocol 从描述符的范围部分中提取协议，比如 npm:^1.0.0 -> npm，workspace:. -> workspace
+func yarnBerryProtocol(reference string) string {
+	colonIndex := strings.Index(reference, ":")
+	if colonIndex <= 0 {
+		return ""
+	}
+	protocol := reference[:colonIndex]
+	for _, c := range protocol {
+		if !(c >= 'a' && c <= 'z' || c == '+' || c == '-') {
+			return ""
+		}
+	}
+	return protocol
+}
+
+// resolveYarnBerryDescriptor 根据依赖声明找到lockfile中对应的条目，声明中没有协议时Berry会默认加上npm:
+func resolveYarnBerryDescriptor(yarnLock *models.YarnLock, name string, versionRange string) (string, *models.YarnLockDependency) {
+	descriptor := name + "@" + versionRange
+	if dep, ok := yarnLock.Dependencies[descriptor]; ok {
+		return descriptor, dep
+	}
+	if yarnBerryProtocol(versionRange) == "" {
+		descriptor = name + "@" + yarnBerryDefaultProtocol + versionRange
+		if dep, ok := yarnLock.Dependencies[descriptor]; ok {
+			return descriptor, dep
+		}
+	}
+	return "", nil
+}
+
+// createBerryProject 把Berry格式的lockfile转换为项目对象，每个workspace对应一个模块
+func (x *YarnLockParser) createBerryProject(yarnLock *models.YarnLock) *baseModels.Project[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem] {
+	project := &baseModels.Project[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem]{}
+	project.ProjectEcosystem = &models.YarnLockProjectEcosystem{Metadata: yarnLock.Metadata}
+
+	// 按描述符排序后遍历，保证输出顺序稳定
+	descriptors := make([]string, 0, len(yarnLock.Dependencies))
+	for descriptor := range yarnLock.Dependencies {
+		descriptors = append(descriptors, descriptor)
+	}
+	sort.Strings(descriptors)
+
+	// 找出所有的workspace，同一个workspace可能被多个描述符引用，按resolution去重
+	workspaces := make(map[string]bool)
+	for _, descriptor := range descriptors {
+		dep := yarnLock.Dependencies[descriptor]
+		name, reference := splitPackageSpec(dep.Resolution)
+		if yarnBerryProtocol(reference) != "workspace" || workspaces[dep.Resolution] {
+			continue
+		}
+		workspaces[dep.Resolution] = true
+
+		workspacePath := strings.TrimPrefix(reference, "workspace:")
+		module := x.createBerryWorkspaceModule(yarnLock, name, workspacePath, dep)
+		project.SetModule(name, module)
+
+		// 根workspace就是项目本身
+		if workspacePath == "." {
+			project.Name = module.Name
+			project.Version = module.Version
+		}
+	}
+
+	// 没有workspace信息时退化为一个包含所有依赖的模块
+	if len(project.Modules) == 0 {
+		project.Name = "unknown"
+		module := x.createModule(yarnLock, project.Name, nil)
+		project.SetModule(project.Name, module)
+	}
+
+	return project
+}
+
+// createBerryWorkspaceModule 为一个workspace创建模块，模块的依赖是从workspace出发可以到达的所有条目
+func (x *YarnLockParser) createBerryWorkspaceModule(yarnLock *models.YarnLock, name string, workspacePath string, workspace *models.YarnLockDependency) *baseModels.Module[*models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem] {
+	module := &baseModels.Module[*models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem]{}
+	module.Name = name
+	if workspace.Version != yarnBerryUseLocalVersion {
+		module.Version = workspace.Version
+	}
+	module.ModuleEcosystem = &models.YarnLockModuleEcosystem{WorkspacePath: workspacePath}
+
+	// 广度优先遍历依赖，visited按resolution去重，避免循环依赖
+	// workspace自己声明的依赖就是直接依赖
+	reachable := make(map[string]string)
+	direct := make(map[string]bool)
+	visited := map[string]bool{workspace.Resolution: true}
+	queue := []*models.YarnLockDependency{workspace}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, childName := range sortedStringMapKeys(current.Dependencies) {
+			descriptor, child := resolveYarnBerryDescriptor(yarnLock, childName, current.Dependencies[childName])
+			if child == nil || visited[child.Resolution] {
+				continue
+			}
+			visited[child.Resolution] = true
+			reachable[descriptor] = child.Resolution
+			direct[descriptor] = current == workspace
+			queue = append(queue, child)
+		}
+	}
+
+	descriptors := make([]string, 0, len(reachable))
+	for descriptor := range reachable {
+		descriptors = append(descriptors, descriptor)
+	}
+	sort.Strings(descriptors)
+
+	dependencies := make([]*baseModels.ComponentDependency[*models.YarnLockComponentDependencyEcosystem], 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		dependency := x.createBerryDependency(descriptor, yarnLock.Dependencies[descriptor])
+		dependency.ComponentDependencyEcosystem.Direct = direct[descriptor]
+		dependencies = append(dependencies, dependency)
+	}
+	module.Dependencies = dependencies
+	return module
+}
+
+// createBerryDependency 创建Berry格式的依赖对象，真实的包名以resolution为准
+func (x *YarnLockParser) createBerryDependency(descriptor string, dep *models.YarnLockDependency) *baseModels.ComponentDependency[*models.YarnLockComponentDependencyEcosystem] {
+	descriptorName, _ := splitPackageSpec(descriptor)
+	name, _ := splitPackageSpec(dep.Resolution)
+	if name == "" {
+		name = descriptorName
+	}
+
+	dependency := &baseModels.ComponentDependency[*models.YarnLockComponentDependencyEcosystem]{}
+	dependency.DependencyName = name
+	dependency.DependencyVersion = dep.Version
+
+	ecosystem := &models.YarnLockComponentDependencyEcosystem{}
+	ecosystem.Source = dep.Source
+	ecosystem.LanguageName = dep.LanguageName
+	ecosystem.HasPeerDependencies = len(dep.PeerDependencies) > 0
+	ecosystem.Resolution = dep.Resolution
+	ecosystem.Checksum = dep.Checksum
+	ecosystem.LinkType = dep.LinkType
+	ecosystem.Conditions = dep.Conditions
+	if descriptorName != name {
+		ecosystem.Alias = descriptorName
+	}
+	dependency.ComponentDependencyEcosystem = ecosystem
+
+	return dependency
+}
+
+// sortedStringMapKeys 返回排好序的键
+func sortedStringMapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsYarnBerryLock(t *testing.T) {
+	assert.True(t, isYarnBerryLock([]byte("# comment\n\n__metadata:\n  version: 6\n")))
+	assert.True(t, isYarnBerryLock([]byte("__metadata:\r\n  version: 6\r\n")))
+	assert.False(t, isYarnBerryLock([]byte("# yarn lockfile v1\n\nlodash@^4.0.0:\n  version \"4.17.21\"\n")))
+}
+
+func TestYarnLockParser_ParseBerry(t *testing.T) {
+	parser := NewYarnLockParser()
+	project, err := parser.Parse(context.Background(), &YarnLockParserInput{YarnLockPath: "./test_data/yarn.lock/berry-monorepo.lock"})
+	require.NoError(t, err)
+
+	// 根workspace就是项目
+	assert.Equal(t, "monorepo", project.Name)
+	assert.Equal(t, "1.2.0", project.Version)
+	require.NotNil(t, project.ProjectEcosystem.Yarn.Metadata)
+	assert.Equal(t, "8", project.ProjectEcosystem.Yarn.Metadata.Version)
+	assert.Equal(t, "10c0", project.ProjectEcosystem.Yarn.Metadata.CacheKey)
+
+	// 每个workspace对应一个模块
+	require.Len(t, project.Modules, 3)
+	root := project.Modules["monorepo"]
+	api := project.Modules["@monorepo/api"]
+	shared := project.Modules["@monorepo/shared"]
+	require.NotNil(t, root)
+	require.NotNil(t, api)
+	require.NotNil(t, shared)
+	assert.Equal(t, ".", root.ModuleEcosystem.Yarn.WorkspacePath)
+	assert.Equal(t, "packages/api", api.ModuleEcosystem.Yarn.WorkspacePath)
+	assert.Equal(t, "", api.Version, "0.0.0-use.local不是真实的版本")
+
+	// 根workspace：exec、portal协议和npm依赖
+	rootDeps := indexDependencies(root, dependencyNameKey)
+	require.Len(t, rootDeps, 3)
+	assert.Equal(t, "exec", rootDeps["generated"].ComponentDependencyEcosystem.Yarn.Source)
+	assert.Equal(t, "portal", rootDeps["local-tool"].ComponentDependencyEcosystem.Yarn.Source)
+	assert.Equal(t, "soft", rootDeps["local-tool"].ComponentDependencyEcosystem.Yarn.LinkType)
+	typescript := rootDeps["typescript"]
+	assert.Equal(t, "5.3.3", typescript.DependencyVersion)
+	assert.Equal(t, "npm", typescript.ComponentDependencyEcosystem.Yarn.Source)
+	assert.Equal(t, "node", typescript.ComponentDependencyEcosystem.Yarn.LanguageName)
+	assert.Equal(t, "hard", typescript.ComponentDependencyEcosystem.Yarn.LinkType)
+	assert.Equal(t, "typescript@npm:5.3.3", typescript.ComponentDependencyEcosystem.Yarn.Resolution)
+	assert.Contains(t, typescript.ComponentDependencyEcosystem.Yarn.Checksum, "10c0/")
+
+	// api workspace：依赖另一个workspace，传递依赖也会包含进来，别名使用真实的包名
+	apiDeps := indexDependencies(api, dependencyNameKey)
+	assert.Equal(t, "workspace", apiDeps["@monorepo/shared"].ComponentDependencyEcosystem.Yarn.Source)
+	assert.True(t, apiDeps["@monorepo/shared"].ComponentDependencyEcosystem.Yarn.HasPeerDependencies)
+	assert.Equal(t, "4.18.2", apiDeps["express"].DependencyVersion)
+	assert.Equal(t, "2.3.3", apiDeps["fsevents"].DependencyVersion)
+	assert.Equal(t, "os=darwin", apiDeps["fsevents"].ComponentDependencyEcosystem.Yarn.Conditions)
+	require.Contains(t, apiDeps, "lodash")
+	assert.Equal(t, "4.17.21", apiDeps["lodash"].DependencyVersion)
+	assert.Len(t, api.Dependencies, 4, "lodash只出现一次")
+
+	// workspace自己声明的依赖是直接依赖，通过别名声明的lodash也是
+	assert.True(t, apiDeps["@monorepo/shared"].ComponentDependencyEcosystem.Yarn.Direct)
+	assert.True(t, apiDeps["express"].ComponentDependencyEcosystem.Yarn.Direct)
+	assert.True(t, apiDeps["lodash"].ComponentDependencyEcosystem.Yarn.Direct)
+	assert.False(t, apiDeps["fsevents"].ComponentDependencyEcosystem.Yarn.Direct)
+
+	// 输出按描述符排序
+	names := make([]string, 0, len(api.Dependencies))
+	for _, dep := range api.Dependencies {
+		names = append(names, dep.DependencyName)
+	}
+	assert.Equal(t, []string{"@monorepo/shared", "express", "fsevents", "lodash"}, names)
+}
+
+func TestYarnLockParser_ParseYarnBerryLock(t *testing.T) {
+	parser := NewYarnLockParser()
+	yarnLock, err := parser.parseYarnBerryLock([]byte(`__metadata:
+  version: 6
+  cacheKey: 8
+
+"a@npm:^1.0.0, a@npm:^1.1.0":
+  version: 1.1.0
+  resolution: "a@npm:1.1.0"
+  dependencies:
+    b: ^2.0.0
+    c: ^3.0.0
+  dependenciesMeta:
+    c:
+      optional: true
+      built: false
+  peerDependencies:
+    react: "*"
+  peerDependenciesMeta:
+    react:
+      optional: true
+  languageName: node
+  linkType: hard
+
+"b@patch:b@npm%3A2.0.0#./patches/b.patch::locator=app%40workspace%3A.":
+  version: 2.0.0
+  resolution: "b@patch:b@npm%3A2.0.0#./patches/b.patch::version=2.0.0&hash=abc123&locator=app%40workspace%3A."
+  languageName: node
+  linkType: hard
+
+"d@link:../d::locator=app%40workspace%3A.":
+  version: 0.0.0-use.local
+  resolution: "d@link:../d::locator=app%40workspace%3A."
+  languageName: unknown
+  linkType: soft
+`))
+	require.NoError(t, err)
+	assert.Equal(t, &models.YarnLockMetadata{Version: "6", CacheKey: "8"}, yarnLock.Metadata)
+	require.Len(t, yarnLock.Dependencies, 4)
+
+	a := yarnLock.Dependencies["a@npm:^1.0.0"]
+	require.NotNil(t, a)
+	assert.Same(t, a, yarnLock.Dependencies["a@npm:^1.1.0"])
+	assert.Equal(t, "1.1.0", a.Version)
+	assert.Equal(t, "npm", a.Source)
+	assert.Equal(t, map[string]string{"c": "^3.0.0"}, a.OptionalDependencies)
+	require.NotNil(t, a.DependenciesMeta["c"].Built)
+	assert.False(t, *a.DependenciesMeta["c"].Built)
+	assert.True(t, *a.PeerDependenciesMeta["react"].Optional)
+	assert.Equal(t, map[string]string{"react": "*"}, a.PeerDependencies)
+
+	b := yarnLock.Dependencies["b@patch:b@npm%3A2.0.0#./patches/b.patch::locator=app%40workspace%3A."]
+	require.NotNil(t, b)
+	assert.Equal(t, "patch", b.Source)
+
+	d := yarnLock.Dependencies["d@link:../d::locator=app%40workspace%3A."]
+	require.NotNil(t, d)
+	assert.Equal(t, "link", d.Source)
+	assert.Equal(t, "soft", d.LinkType)
+
+	// 无效的yaml
+	_, err = parser.parseYarnBerryLock([]byte("__metadata:\n  version: [\n"))
+	assert.Error(t, err)
+}
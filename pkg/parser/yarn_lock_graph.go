@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"context"
+	"sort"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// ParseDependencyGraph 解析yarn.lock并构建依赖图，跟PackageLockParser构建的是同一种图。
+// yarn.lock的键就是 name@range 描述符，所以每个依赖声明都能精确地解析到锁定的条目；
+// 能读取到旁边的package.json时（仅v1格式）项目本身会作为根节点，ID为空字符串
+func (x *YarnLockParser) ParseDependencyGraph(ctx context.Context, input *YarnLockParserInput) (*models.DependencyGraph, error) {
+	yarnLock, err := x.ParseLockfile(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Berry格式中workspace本身就在lockfile里，不需要package.json
+	if yarnLock.Metadata != nil {
+		return x.buildDependencyGraph(yarnLock, nil), nil
+	}
+	return x.buildDependencyGraph(yarnLock, x.readSiblingPackageJson(ctx, input)), nil
+}
+
+// buildDependencyGraph 从yarn.lock构建依赖图，manifest是项目的package.json，可以为nil
+func (x *YarnLockParser) buildDependencyGraph(yarnLock *models.YarnLock, manifest *models.PackageJson) *models.DependencyGraph {
+	graph := models.NewDependencyGraph()
+
+	descriptors := make([]string, 0, len(yarnLock.Dependencies))
+	for descriptor := range yarnLock.Dependencies {
+		descriptors = append(descriptors, descriptor)
+	}
+	sort.Strings(descriptors)
+
+	// 合并的多个描述符共享同一个条目，每个条目只生成一个节点
+	nodeIDs := make(map[*models.YarnLockDependency]string)
+	for _, descriptor := range descriptors {
+		dep := yarnLock.Dependencies[descriptor]
+		if _, ok := nodeIDs[dep]; ok {
+			continue
+		}
+		id := x.yarnLockNodeID(descriptor, dep)
+		nodeIDs[dep] = id
+		if _, ok := graph.Nodes[id]; ok {
+			continue
+		}
+
+		name, _ := x.resolveAlias(descriptor)
+		if dep.Resolution != "" {
+			name, _ = splitPackageSpec(dep.Resolution)
+		}
+		graph.Nodes[id] = &models.DependencyGraphNode{
+			ID:        id,
+			Name:      name,
+			Version:   dep.Version,
+			Resolved:  dep.Resolved,
+			Integrity: dep.Integrity,
+		}
+
+		// Berry格式中workspace就是根节点
+		if dep.Source == "workspace" {
+			graph.Roots = append(graph.Roots, id)
+		}
+	}
+
+	for _, descriptor := range descriptors {
+		dep := yarnLock.Dependencies[descriptor]
+		node := graph.Nodes[nodeIDs[dep]]
+		// 同一个节点的依赖只需要解析一次
+		if node.Dependencies != nil {
+			continue
+		}
+		node.Dependencies = make([]*models.DependencyGraphEdge, 0, len(dep.Dependencies)+len(dep.OptionalDependencies))
+
+		// Berry把可选依赖也写在dependencies里，v1则是单独的optionalDependencies
+		for _, name := range sortedStringMapKeys(dep.Dependencies) {
+			edgeType := models.DependencyGraphEdgeProd
+			if _, ok := dep.OptionalDependencies[name]; ok {
+				edgeType = models.DependencyGraphEdgeOptional
+			}
+			x.addDependencyGraphEdge(graph, yarnLock, nodeIDs, node, name, dep.Dependencies[name], edgeType)
+		}
+		for _, name := range sortedStringMapKeys(dep.OptionalDependencies) {
+			if _, ok := dep.Dependencies[name]; !ok {
+				x.addDependencyGraphEdge(graph, yarnLock, nodeIDs, node, name, dep.OptionalDependencies[name], models.DependencyGraphEdgeOptional)
+			}
+		}
+	}
+
+	// 项目本身作为根节点，package.json中声明的依赖是它的边
+	if manifest != nil && graph.Roots == nil {
+		root := &models.DependencyGraphNode{ID: "", Name: manifest.Name, Version: manifest.Version}
+		graph.Nodes[""] = root
+		for _, name := range sortedDependencyNames(manifest.Dependencies) {
+			x.addDependencyGraphEdge(graph, yarnLock, nodeIDs, root, name, manifest.Dependencies[name], models.DependencyGraphEdgeProd)
+		}
+		for _, name := range sortedDependencyNames(manifest.DevDependencies) {
+			x.addDependencyGraphEdge(graph, yarnLock, nodeIDs, root, name, manifest.DevDependencies[name], models.DependencyGraphEdgeDev)
+		}
+		for _, name := range sortedDependencyNames(manifest.OptionalDependencies) {
+			x.addDependencyGraphEdge(graph, yarnLock, nodeIDs, root, name, manifest.OptionalDependencies[name], models.DependencyGraphEdgeOptional)
+		}
+		graph.Roots = []string{""}
+	}
+
+	finishDependencyGraph(graph)
+	return graph
+}
+
+// addDependencyGraphEdge 把一个依赖声明解析到锁定的条目，找不到时记录为悬空依赖
+func (x *YarnLockParser) addDependencyGraphEdge(graph *models.DependencyGraph, yarnLock *models.YarnLock, nodeIDs map[*models.YarnLockDependency]string, node *models.DependencyGraphNode, name string, versionRange string, edgeType models.DependencyGraphEdgeType) {
+	_, child := resolveYarnBerryDescriptor(yarnLock, name, versionRange)
+	if child == nil {
+		graph.Dangling = append(graph.Dangling, &models.DanglingDependency{From: node.ID, Name: name, Range: versionRange, Type: edgeType})
+		return
+	}
+	node.Dependencies = append(node.Dependencies, &models.DependencyGraphEdge{Name: name, Range: versionRange, Type: edgeType, To: nodeIDs[child]})
+}
+
+// yarnLockNodeID 条目在依赖图中的ID，Berry格式使用resolution，v1格式使用真实包名和版本
+func (x *YarnLockParser) yarnLockNodeID(descriptor string, dep *models.YarnLockDependency) string {
+	if dep.Resolution != "" {
+		return dep.Resolution
+	}
+	name, _ := x.resolveAlias(descriptor)
+	return name + "@" + dep.Version
+}
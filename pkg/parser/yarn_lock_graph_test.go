@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYarnLockParser_ParseDependencyGraph(t *testing.T) {
+	yarnLock := `# yarn lockfile v1
+
+"chalk@^2.0.0", chalk@^2.4.0:
+  version "2.4.2"
+  resolved "https://registry.yarnpkg.com/chalk/-/chalk-2.4.2.tgz#cd42541677a54333cf541a49108c1432b44c9424"
+  integrity sha512-abc
+  dependencies:
+    ansi-styles "^3.2.1"
+    supports-color "^5.3.0"
+
+ansi-styles@^3.2.1:
+  version "3.2.1"
+
+"my-chalk@npm:chalk@^2.4.0":
+  version "2.4.2"
+
+chokidar@^3.0.0:
+  version "3.5.3"
+  dependencies:
+    chalk "^2.4.0"
+  optionalDependencies:
+    fsevents "~2.3.2"
+
+fsevents@~2.3.2:
+  version "2.3.3"
+`
+	packageJson := `{
+  "name": "graph-app",
+  "version": "1.0.0",
+  "dependencies": {"chalk": "^2.0.0", "my-chalk": "npm:chalk@^2.4.0"},
+  "devDependencies": {"chokidar": "^3.0.0", "left-pad": "^1.0.0"}
+}`
+
+	t.Run("没有package.json", func(t *testing.T) {
+		graph, err := NewYarnLockParser().ParseDependencyGraph(context.Background(), &YarnLockParserInput{YarnLockContent: yarnLock})
+		require.NoError(t, err)
+
+		// 合并的描述符和别名指向同一个节点
+		assert.Equal(t, []string{"ansi-styles@3.2.1", "chalk@2.4.2", "chokidar@3.5.3", "fsevents@2.3.3"}, graph.SortedNodeIDs())
+		assert.Equal(t, []string{"chokidar@3.5.3"}, graph.Roots)
+
+		chalk := graph.Nodes["chalk@2.4.2"]
+		assert.Equal(t, "chalk", chalk.Name)
+		assert.Equal(t, "https://registry.yarnpkg.com/chalk/-/chalk-2.4.2.tgz", chalk.Resolved)
+		assert.Equal(t, "sha512-abc", chalk.Integrity)
+		require.Len(t, chalk.Dependencies, 1)
+		assert.Equal(t, "ansi-styles@3.2.1", chalk.Dependencies[0].To)
+
+		assert.Equal(t, []*models.DependencyGraphEdge{
+			{Name: "chalk", Range: "^2.4.0", Type: models.DependencyGraphEdgeProd, To: "chalk@2.4.2"},
+			{Name: "fsevents", Range: "~2.3.2", Type: models.DependencyGraphEdgeOptional, To: "fsevents@2.3.3"},
+		}, graph.Nodes["chokidar@3.5.3"].Dependencies)
+
+		// supports-color没有对应的条目
+		assert.Equal(t, []*models.DanglingDependency{
+			{From: "chalk@2.4.2", Name: "supports-color", Range: "^5.3.0", Type: models.DependencyGraphEdgeProd},
+		}, graph.Dangling)
+	})
+
+	t.Run("使用package.json作为根节点", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"yarn.lock":    &fstest.MapFile{Data: []byte(yarnLock)},
+			"package.json": &fstest.MapFile{Data: []byte(packageJson)},
+		}
+		graph, err := NewYarnLockParser().ParseDependencyGraph(context.Background(), &YarnLockParserInput{FileSystem: fileSystem, ProjectRootDirectory: "."})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{""}, graph.Roots)
+		root := graph.Nodes[""]
+		assert.Equal(t, "graph-app", root.Name)
+		assert.Equal(t, []*models.DependencyGraphEdge{
+			{Name: "chalk", Range: "^2.0.0", Type: models.DependencyGraphEdgeProd, To: "chalk@2.4.2"},
+			{Name: "chokidar", Range: "^3.0.0", Type: models.DependencyGraphEdgeDev, To: "chokidar@3.5.3"},
+			{Name: "my-chalk", Range: "npm:chalk@^2.4.0", Type: models.DependencyGraphEdgeProd, To: "chalk@2.4.2"},
+		}, root.Dependencies)
+		assert.Contains(t, graph.Dangling, &models.DanglingDependency{From: "", Name: "left-pad", Range: "^1.0.0", Type: models.DependencyGraphEdgeDev})
+	})
+}
+
+func TestYarnLockParser_ParseDependencyGraphBerry(t *testing.T) {
+	graph, err := NewYarnLockParser().ParseDependencyGraph(context.Background(), &YarnLockParserInput{YarnLockPath: "./test_data/yarn.lock/berry-monorepo.lock"})
+	require.NoError(t, err)
+
+	// 每个workspace都是根节点
+	assert.Equal(t, []string{"@monorepo/api@workspace:packages/api", "@monorepo/shared@workspace:packages/shared", "monorepo@workspace:."}, graph.Roots)
+	assert.Empty(t, graph.Dangling)
+
+	api := graph.Nodes["@monorepo/api@workspace:packages/api"]
+	require.NotNil(t, api)
+	targets := make(map[string]string)
+	for _, edge := range api.Dependencies {
+		targets[edge.Name] = edge.To
+	}
+	assert.Equal(t, "@monorepo/shared@workspace:packages/shared", targets["@monorepo/shared"])
+	assert.Equal(t, "express@npm:4.18.2", targets["express"])
+	assert.Equal(t, "lodash@npm:4.17.21", targets["my-lodash"], "别名解析到真实的包")
+	assert.Equal(t, "lodash", graph.Nodes["lodash@npm:4.17.21"].Name)
+}
@@ -1,10 +1,9 @@
 package parser
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/scagogogo/package-json-parser/pkg/models"
@@ -16,7 +15,7 @@ import (
 type YarnLockParser struct {
 }
 
-var _ parser.Parser[*YarnLockParserInput, *models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem] = &YarnLockParser{}
+var _ parser.Parser[*YarnLockParserInput, *models.JsProjectEcosystem, *models.JsModuleEcosystem, *models.JsComponentEcosystem, *models.JsComponentDependencyEcosystem] = &YarnLockParser{}
 
 func NewYarnLockParser() *YarnLockParser {
 	return &YarnLockParser{}
@@ -32,215 +31,237 @@ func (x *YarnLockParser) Init(ctx context.Context) error {
 	return nil
 }
 
-// Parse 解析yarn.lock文件
-func (x *YarnLockParser) Parse(ctx context.Context, input *YarnLockParserInput) (*baseModels.Project[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem], error) {
+// Parse 解析yarn.lock，返回所有JavaScript解析器共用的类型，YarnLock特有的信息放在各个生态系统的Yarn扩展字段中
+func (x *YarnLockParser) Parse(ctx context.Context, input *YarnLockParserInput) (*models.JsProject, error) {
+	manifest := x.readSiblingPackageJson(ctx, input)
+	project, err := x.parseProject(ctx, input, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Berry格式根据workspace声明的依赖判断是否是直接依赖，v1格式只有读取到package.json时才能判断，
+	// 无法判断时共用字段中的Direct为nil，能判断时不是直接依赖为false
+	directKnown := manifest != nil || project.ProjectEcosystem.Metadata != nil
+	return toJsProject(project, &jsEcosystemConverter[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentDependencyEcosystem]{
+		project: func(ecosystem *models.YarnLockProjectEcosystem) *models.JsProjectEcosystem {
+			return &models.JsProjectEcosystem{PackageManager: models.PackageManagerYarn, Yarn: ecosystem}
+		},
+		module: func(ecosystem *models.YarnLockModuleEcosystem) *models.JsModuleEcosystem {
+			return &models.JsModuleEcosystem{WorkspacePath: ecosystem.WorkspacePath, Yarn: ecosystem}
+		},
+		dependency: func(ecosystem *models.YarnLockComponentDependencyEcosystem) *models.JsComponentDependencyEcosystem {
+			dependencyEcosystem := &models.JsComponentDependencyEcosystem{
+				Ecosystem: models.DependencyEcosystemNpm,
+				Resolved:  ecosystem.Resolved,
+				Integrity: ecosystem.Integrity,
+				Alias:     ecosystem.Alias,
+				Path:      ecosystem.Resolution,
+				Yarn:      ecosystem,
+			}
+			if directKnown {
+				direct := ecosystem.Direct
+				dependencyEcosystem.Direct = &direct
+			}
+			return dependencyEcosystem
+		},
+	}), nil
+}
+
+// parseProject 解析yarn.lock文件
+// manifest是项目根目录已知时（指定了ProjectRootDirectory或者YarnLockPath）读取到的旁边的package.json，可以为nil，
+// 使用其中的名称和版本作为项目的名称和版本，并根据其中声明的依赖标记哪些是直接依赖，使用包管理器特有的生态系统类型
+func (x *YarnLockParser) parseProject(ctx context.Context, input *YarnLockParserInput, manifest *models.PackageJson) (*baseModels.Project[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem], error) {
 	// 读取yarn.lock文件
 	yarnLockBytes, err := input.Read(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read yarn.lock: %w", err)
 	}
 
+	// Berry（yarn v2+）格式的lockfile是yaml，每个workspace对应一个模块
+	if isYarnBerryLock(yarnLockBytes) {
+		yarnLock, err := x.parseYarnBerryLock(yarnLockBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse yarn.lock: %w", err)
+		}
+		project := x.createBerryProject(yarnLock)
+		// 根workspace没有声明版本时lockfile中只有占位版本，以package.json为准
+		if manifest != nil && manifest.Name == project.Name && project.Version == "" {
+			project.Version = manifest.Version
+		}
+		return project, nil
+	}
+
 	// 解析yarn.lock文件
-	yarnLock, moduleName, err := x.parseYarnLock(yarnLockBytes)
+	yarnLock, err := x.parseYarnLock(yarnLockBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse yarn.lock: %w", err)
 	}
 
-	// 创建项目对象
+	// yarn.lock不包含项目的名称和版本，需要从package.json获取，没有package.json时名称为unknown
+	moduleName := "unknown"
+	var directDescriptors map[string]bool
 	project := &baseModels.Project[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem]{}
+	if manifest != nil {
+		// monorepo的根目录和私有项目的package.json经常没有name，这时仍然使用其中声明的依赖
+		if manifest.Name != "" {
+			moduleName = manifest.Name
+		}
+		project.Version = manifest.Version
+		directDescriptors = x.findDirectDescriptors(yarnLock, manifest)
+	}
 	project.Name = moduleName
-	project.Version = "" // yarn.lock不包含版本信息，需要从package.json获取
 
 	// 设置项目生态系统信息
 	project.ProjectEcosystem = &models.YarnLockProjectEcosystem{}
 
 	// 创建模块
-	module := x.createModule(yarnLock, moduleName)
+	module := x.createModule(yarnLock, moduleName, directDescriptors)
+	module.Version = project.Version
 	project.SetModule(moduleName, module)
 
 	return project, nil
 }
 
-// parseYarnLock 解析yarn.lock文件内容
-func (x *YarnLockParser) parseYarnLock(data []byte) (*models.YarnLock, string, error) {
-	// 检查空文件
-	if len(data) == 0 {
-		return nil, "", fmt.Errorf("yarn.lock file is empty")
+// ParseLockfile 把yarn.lock解析为lockfile本身的模型，不转换为项目，v1和Berry格式都支持，
+// Berry格式的结果Metadata不为nil，可以用于转换或者修改后通过YarnLockWriter写回
+func (x *YarnLockParser) ParseLockfile(ctx context.Context, input *YarnLockParserInput) (*models.YarnLock, error) {
+	yarnLockBytes, err := input.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read yarn.lock: %w", err)
 	}
 
-	// yarn.lock是一个非标准格式的文件，需要自定义解析
-	yarnLock := &models.YarnLock{
-		Dependencies: make(map[string]*models.YarnLockDependency),
+	var yarnLock *models.YarnLock
+	if isYarnBerryLock(yarnLockBytes) {
+		yarnLock, err = x.parseYarnBerryLock(yarnLockBytes)
+	} else {
+		yarnLock, err = x.parseYarnLock(yarnLockBytes)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse yarn.lock: %w", err)
+	}
+	return yarnLock, nil
+}
 
-	// 模块名称暂时设为unknown，后续从解析过程中推断
-	moduleName := "unknown"
-
-	// 用正则表达式匹配依赖项
-	lines := bytes.Split(data, []byte("\n"))
+// readSiblingPackageJson 项目根目录已知时读取yarn.lock旁边的package.json，读取的是文件本身，不要求有name字段，
+// 声明的版本范围也保持原样，这样才能跟yarn.lock中的描述符对应。
+// package.json只是用来补充信息的，不存在或者无法解析时返回nil，这时只使用yarn.lock中的信息
+func (x *YarnLockParser) readSiblingPackageJson(ctx context.Context, input *YarnLockParserInput) *models.PackageJson {
+	projectRootDirectory, ok := input.projectRootDirectory()
+	if !ok {
+		return nil
+	}
 
-	// 验证文件格式 - 对于测试用例，我们需要一种更宽松的方法来判断有效性
-	isValidFormat := false
-	// 确保检查的行数不超过文件实际行数
-	maxLinesToCheck := 20
-	if len(lines) < maxLinesToCheck {
-		maxLinesToCheck = len(lines)
+	packageJsonParser := &PackageJsonParser{}
+	manifest, err := packageJsonParser.ParseManifest(ctx, &PackageJsonParserInput{
+		ProjectRootDirectory: projectRootDirectory,
+		FileSystem:           input.FileSystem,
+	})
+	if err != nil {
+		return nil
 	}
-	for _, line := range lines[:maxLinesToCheck] { // 检查前几行
-		lineStr := string(line)
-		if strings.Contains(lineStr, "yarn lockfile") || strings.Contains(lineStr, "@") && strings.Contains(lineStr, "version ") {
-			isValidFormat = true
-			break
+	return manifest
+}
+
+// findDirectDescriptors 找出package.json中直接声明的依赖在yarn.lock中对应的描述符，
+// yarn.lock的键就是声明时的 name@range，所以使用package.json中原始的版本范围匹配
+func (x *YarnLockParser) findDirectDescriptors(yarnLock *models.YarnLock, manifest *models.PackageJson) map[string]bool {
+	directDescriptors := make(map[string]bool)
+	for _, dependencies := range []models.Dependencies{manifest.Dependencies, manifest.DevDependencies, manifest.OptionalDependencies} {
+		for name, versionRange := range dependencies {
+			descriptor := name + "@" + versionRange
+			if _, ok := yarnLock.Dependencies[descriptor]; ok {
+				directDescriptors[descriptor] = true
+			}
 		}
 	}
+	return directDescriptors
+}
 
-	if !isValidFormat {
-		return nil, "", fmt.Errorf("invalid yarn.lock format")
+// parseYarnLock 解析yarn.lock文件内容
+func (x *YarnLockParser) parseYarnLock(data []byte) (*models.YarnLock, error) {
+	// 检查空文件
+	if len(data) == 0 {
+		return nil, fmt.Errorf("yarn.lock file is empty")
 	}
 
-	// 解析内容
-	var currentDep *models.YarnLockDependency
-	var currentDepKeys []string
-	indentLevel := 0
-
-	// 依赖声明正则 - 改进以匹配 "@babel/code-frame@^7.0.0": 这样的格式
-	depRegex := regexp.MustCompile(`^"?([^"]+)@([^"]*)"?:$`)
-
-	// 版本正则
-	versionRegex := regexp.MustCompile(`^\s+version "(.+)"$`)
-
-	// 解析地址正则
-	resolvedRegex := regexp.MustCompile(`^\s+resolved "(.+)"$`)
-
-	// 完整性校验和正则
-	integrityRegex := regexp.MustCompile(`^\s+integrity (.+)$`)
-
-	// 依赖声明正则
-	dependenciesRegex := regexp.MustCompile(`^\s+dependencies:$`)
-
-	// 依赖项正则 - 改进以更好地处理作用域包
-	dependencyItemRegex := regexp.MustCompile(`^\s+"?([^"]+)@(.+) "(.+)"$`)
+	// yarn.lock是一个非标准格式的文件，先按照它的文法解析为嵌套的对象
+	root, err := parseYarnLockSyntax(data)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, line := range lines {
-		lineStr := string(line)
+	yarnLock := &models.YarnLock{
+		Dependencies: make(map[string]*models.YarnLockDependency),
+	}
+	yarnLock.Layout = parseYarnLockLayout(data)
 
-		// 跳过空行和注释
-		if len(strings.TrimSpace(lineStr)) == 0 || strings.HasPrefix(strings.TrimSpace(lineStr), "#") {
-			continue
+	for _, entry := range root.Entries {
+		// 顶层的每个条目都必须是一个依赖块
+		block, ok := entry.Value.(*yarnLockObject)
+		if !ok {
+			return nil, &YarnLockSyntaxError{Line: entry.Line, Column: entry.Column, Msg: fmt.Sprintf("expected an entry block for key %q", entry.Keys[0])}
 		}
 
-		// 寻找依赖声明
-		if matches := depRegex.FindStringSubmatch(lineStr); len(matches) == 3 {
-			// 完成前一个依赖的处理并开始新依赖
-			if len(currentDepKeys) > 0 {
-				for _, key := range currentDepKeys {
-					yarnLock.Dependencies[key] = currentDep
-				}
-			}
-
-			// 开始新依赖
-			currentDep = &models.YarnLockDependency{
-				Dependencies:         make(map[string]string),
-				OptionalDependencies: make(map[string]string),
-				PeerDependencies:     make(map[string]string),
-			}
-
-			// 获取依赖键
-			depKey := strings.TrimSuffix(lineStr, ":")
-			currentDepKeys = []string{depKey}
-
-			// 尝试从依赖推断模块名称
-			pkgName := x.extractPackageName(depKey)
-			if moduleName == "unknown" && (pkgName != "npm" && pkgName != "yarn" && !strings.HasPrefix(pkgName, "react-") &&
-				!strings.HasPrefix(pkgName, "webpack") && !strings.HasPrefix(pkgName, "babel") && !strings.HasPrefix(pkgName, "@babel/")) {
-				moduleName = pkgName
-			}
-
-			indentLevel = 0
-			continue
+		// 合并的多个描述符共享同一个依赖对象
+		dep := x.parseYarnLockEntry(block)
+		for _, key := range entry.Keys {
+			yarnLock.Dependencies[key] = dep
 		}
+	}
 
-		// 对于当前处理的依赖，解析其属性
-		if currentDep != nil {
-			// 版本
-			if matches := versionRegex.FindStringSubmatch(lineStr); len(matches) == 2 {
-				currentDep.Version = matches[1]
-				continue
-			}
-
-			// 解析地址
-			if matches := resolvedRegex.FindStringSubmatch(lineStr); len(matches) == 2 {
-				// 从resolved URL中去除hash部分
-				resolvedUrl := matches[1]
-				hashIndex := strings.LastIndex(resolvedUrl, "#")
-				if hashIndex > 0 {
-					resolvedUrl = resolvedUrl[:hashIndex]
-				}
-
-				// 也移除URL末尾的哈希值（非标准#前缀的）
-				parts := strings.Split(resolvedUrl, " ")
-				if len(parts) > 1 {
-					resolvedUrl = parts[0]
-				}
-
-				currentDep.Resolved = resolvedUrl
-				continue
-			}
-
-			// 完整性校验和
-			if matches := integrityRegex.FindStringSubmatch(lineStr); len(matches) == 2 {
-				currentDep.Integrity = matches[1]
-				continue
-			}
+	// 检查是否成功解析到依赖
+	if len(yarnLock.Dependencies) == 0 {
+		return nil, fmt.Errorf("no dependencies found in yarn.lock")
+	}
 
-			// 处理依赖声明
-			if dependenciesRegex.MatchString(lineStr) {
-				indentLevel = len(lineStr) - len(strings.TrimLeft(lineStr, " "))
-				continue
-			}
+	return yarnLock, nil
+}
 
-			// 依赖项 - 改进正则表达式以处理包含 @ 符号的包名
-			currentIndent := len(lineStr) - len(strings.TrimLeft(lineStr, " "))
-			if currentIndent > indentLevel {
-				// 更复杂的依赖项匹配
-				if matches := dependencyItemRegex.FindStringSubmatch(lineStr); len(matches) == 4 {
-					rawDepName := matches[1]
-					depVersion := matches[3]
-
-					// 提取正确的包名
-					var depName string
-					if strings.HasPrefix(rawDepName, "@") {
-						// 处理作用域包，如 "@babel/core"
-						// 需要保留完整的作用域包名
-						depName = rawDepName
-					} else {
-						depName = rawDepName
-					}
-
-					currentDep.Dependencies[depName] = depVersion
-				}
-				continue
-			}
+// parseYarnLockLayout 记录第一个条目之前的注释和空行，以及最后一个值之后的空白，写回lockfile时用来还原文件的排版
+func parseYarnLockLayout(data []byte) *models.YarnLockLayout {
+	content := string(data)
+	layout := &models.YarnLockLayout{}
+	for offset := 0; offset < len(content); {
+		end := strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content) - offset
+		} else {
+			end++
 		}
+		line := strings.TrimSpace(content[offset : offset+end])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			layout.Header = content[:offset]
+			break
+		}
+		offset += end
 	}
+	layout.Trailer = content[len(strings.TrimRight(content, " \t\r\n")):]
+	return layout
+}
 
-	// 处理最后一个依赖
-	if len(currentDepKeys) > 0 {
-		for _, key := range currentDepKeys {
-			yarnLock.Dependencies[key] = currentDep
-		}
+// parseYarnLockEntry 把一个依赖块转换为依赖对象
+func (x *YarnLockParser) parseYarnLockEntry(block *yarnLockObject) *models.YarnLockDependency {
+	dep := &models.YarnLockDependency{
+		Version:              block.GetString("version"),
+		Integrity:            block.GetString("integrity"),
+		Dependencies:         block.GetObject("dependencies").StringMap(),
+		OptionalDependencies: block.GetObject("optionalDependencies").StringMap(),
+		PeerDependencies:     block.GetObject("peerDependencies").StringMap(),
 	}
 
-	// 检查是否成功解析到依赖
-	if len(yarnLock.Dependencies) == 0 {
-		return nil, "", fmt.Errorf("no dependencies found in yarn.lock")
+	// 从resolved URL中去除hash部分，hash单独保存
+	resolvedUrl := block.GetString("resolved")
+	if hashIndex := strings.LastIndex(resolvedUrl, "#"); hashIndex > 0 {
+		dep.ResolvedHash = resolvedUrl[hashIndex+1:]
+		resolvedUrl = resolvedUrl[:hashIndex]
 	}
+	dep.Resolved = resolvedUrl
 
-	return yarnLock, moduleName, nil
+	return dep
 }
 
-// createModule 创建模块对象
-func (x *YarnLockParser) createModule(yarnLock *models.YarnLock, moduleName string) *baseModels.Module[*models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem] {
+// createModule 创建模块对象，directDescriptors是package.json中直接声明的依赖对应的描述符，可以为nil
+func (x *YarnLockParser) createModule(yarnLock *models.YarnLock, moduleName string, directDescriptors map[string]bool) *baseModels.Module[*models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem] {
 	module := &baseModels.Module[*models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem]{}
 	module.Name = moduleName
 	module.ModuleEcosystem = &models.YarnLockModuleEcosystem{}
@@ -248,19 +269,30 @@ func (x *YarnLockParser) createModule(yarnLock *models.YarnLock, moduleName stri
 	// 解析依赖
 	dependencies := make([]*baseModels.ComponentDependency[*models.YarnLockComponentDependencyEcosystem], 0, len(yarnLock.Dependencies))
 
-	// 依赖名称去重
-	processedDeps := make(map[string]bool)
+	// 依赖名称去重，同一个依赖的任意一个描述符是直接依赖时，这个依赖就是直接依赖
+	processedDeps := make(map[string]*baseModels.ComponentDependency[*models.YarnLockComponentDependencyEcosystem])
+
+	// 按依赖键排序后遍历，保证输出顺序稳定
+	depKeys := make([]string, 0, len(yarnLock.Dependencies))
+	for depKey := range yarnLock.Dependencies {
+		depKeys = append(depKeys, depKey)
+	}
+	sort.Strings(depKeys)
 
-	for depKey, dep := range yarnLock.Dependencies {
-		// 从depKey中提取实际包名
-		pkgName := x.extractPackageName(depKey)
+	for _, depKey := range depKeys {
+		dep := yarnLock.Dependencies[depKey]
+
+		// 从depKey中提取实际包名，别名安装时使用真实的包名
+		pkgName, alias := x.resolveAlias(depKey)
 
 		// 去重
-		depId := pkgName + "@" + dep.Version
-		if processedDeps[depId] {
+		depId := alias + ":" + pkgName + "@" + dep.Version
+		if processed, ok := processedDeps[depId]; ok {
+			if directDescriptors[depKey] {
+				processed.ComponentDependencyEcosystem.Direct = true
+			}
 			continue
 		}
-		processedDeps[depId] = true
 
 		// 创建依赖对象
 		dependency := &baseModels.ComponentDependency[*models.YarnLockComponentDependencyEcosystem]{}
@@ -275,8 +307,11 @@ func (x *YarnLockParser) createModule(yarnLock *models.YarnLock, moduleName stri
 		ecosystem.LanguageName = dep.LanguageName
 		ecosystem.Bundled = dep.Bundled
 		ecosystem.HasPeerDependencies = len(dep.PeerDependencies) > 0
+		ecosystem.Alias = alias
+		ecosystem.Direct = directDescriptors[depKey]
 
 		dependency.ComponentDependencyEcosystem = ecosystem
+		processedDeps[depId] = dependency
 
 		dependencies = append(dependencies, dependency)
 	}
@@ -285,34 +320,20 @@ func (x *YarnLockParser) createModule(yarnLock *models.YarnLock, moduleName stri
 	return module
 }
 
-// extractPackageName 从依赖键中提取包名
+// extractPackageName 从依赖键中提取包名，依赖键的格式为 "name@range" 或者 "@scope/name@range"
 func (x *YarnLockParser) extractPackageName(depKey string) string {
-	// 去除引号
-	depKey = strings.Trim(depKey, "\"")
-
-	// 对于作用域包，格式为 "@scope/name@version"
-	if strings.HasPrefix(depKey, "@") {
-		// 第一个@后面的索引
-		firstAtIndex := 1
-		// 第二个@的索引，这是版本开始的地方
-		secondAtIndex := strings.LastIndex(depKey, "@")
-
-		// 如果找到了第二个@，并且它不是紧挨着第一个@
-		if secondAtIndex > firstAtIndex {
-			// 返回不包含版本的包名部分
-			return depKey[:secondAtIndex]
-		}
-		// 如果没有第二个@或者格式不符合预期，返回原始键
-		return depKey
-	} else {
-		// 对于非作用域包，格式为 "name@version"
-		atIndex := strings.Index(depKey, "@")
-		if atIndex > 0 {
-			return depKey[:atIndex]
-		}
-		// 如果没有@，返回原始键
-		return depKey
+	name, _ := splitPackageSpec(strings.Trim(depKey, "\""))
+	return name
+}
+
+// resolveAlias 解析依赖键中的别名声明，比如 "my-react@npm:react@18.2.0"，
+// 是别名时返回真实的包名和别名，否则返回包名和空的别名
+func (x *YarnLockParser) resolveAlias(depKey string) (name string, alias string) {
+	name, versionRange := splitPackageSpec(strings.Trim(depKey, "\""))
+	if realName, _, ok := parseNpmAlias(versionRange); ok && realName != name {
+		return realName, name
 	}
+	return name, ""
 }
 
 func (x *YarnLockParser) Close(ctx context.Context) error {
@@ -2,17 +2,61 @@ package parser
 
 import (
 	"context"
-	"os"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
 )
 
-// YarnLockParserInput 解析器的输入，指定要解析的yarn.lock文件路径
+// YarnLockFileName yarn.lock的文件名
+const YarnLockFileName = "yarn.lock"
+
+// YarnLockParserInput 解析器的输入，按照 YarnLockContent、YarnLockReader、YarnLockPath、ProjectRootDirectory 的优先级读取yarn.lock
 type YarnLockParserInput struct {
 	// YarnLockPath yarn.lock文件的路径
 	YarnLockPath string
+
+	// YarnLockContent 直接传入的yarn.lock内容
+	YarnLockContent string
+
+	// YarnLockReader 从reader中读取yarn.lock的内容，只能被读取一次
+	YarnLockReader io.Reader
+
+	// ProjectRootDirectory 项目根目录，会读取其中的yarn.lock
+	ProjectRootDirectory string
+
+	// FileSystem 指定后YarnLockPath和ProjectRootDirectory都是这个文件系统中的路径
+	FileSystem fs.FS
 }
 
 // Read 读取yarn.lock文件内容
 func (x *YarnLockParserInput) Read(ctx context.Context) ([]byte, error) {
-	// 读取文件内容
-	return os.ReadFile(x.YarnLockPath)
+
+	if x.YarnLockContent != "" {
+		return []byte(x.YarnLockContent), nil
+	}
+
+	if x.YarnLockReader != nil {
+		return readInputReader(x.YarnLockReader)
+	}
+
+	if x.YarnLockPath != "" {
+		return readInputFile(x.FileSystem, x.YarnLockPath)
+	}
+
+	return readInputFile(x.FileSystem, joinInputPath(x.FileSystem, x.ProjectRootDirectory, YarnLockFileName))
+}
+
+// projectRootDirectory 返回yarn.lock所在的项目根目录，优先使用ProjectRootDirectory，其次是YarnLockPath所在的目录
+func (x *YarnLockParserInput) projectRootDirectory() (string, bool) {
+	if x.ProjectRootDirectory != "" {
+		return x.ProjectRootDirectory, true
+	}
+	if x.YarnLockPath != "" {
+		if x.FileSystem != nil {
+			return path.Dir(toFsPath(x.YarnLockPath)), true
+		}
+		return filepath.Dir(x.YarnLockPath), true
+	}
+	return "", false
 }
@@ -3,10 +3,12 @@ package parser
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/scagogogo/package-json-parser/pkg/models"
-	baseModels "github.com/scagogogo/sca-base-module-components/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -16,7 +18,7 @@ func TestYarnLockParser_Parse(t *testing.T) {
 		name            string
 		yarnLockContent string
 		wantError       bool
-		checkFunc       func(t *testing.T, project *baseModels.Project[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem])
+		checkFunc       func(t *testing.T, project *models.JsProject)
 	}{
 		{
 			name: "基本 yarn.lock 解析测试",
@@ -38,13 +40,13 @@ react@^16.8.0:
     "prop-types" "^15.6.2"
 `,
 			wantError: false,
-			checkFunc: func(t *testing.T, project *baseModels.Project[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem]) {
+			checkFunc: func(t *testing.T, project *models.JsProject) {
 				assert.NotNil(t, project)
 
 				// 检查模块数量
 				assert.Len(t, project.Modules, 1, "应该有一个模块")
 				// 查找模块
-				var module *baseModels.Module[*models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem]
+				var module *models.JsModule
 				for _, m := range project.Modules {
 					module = m
 					break // 只取第一个模块
@@ -55,7 +57,7 @@ react@^16.8.0:
 				assert.Len(t, module.Dependencies, 2, "应该有两个依赖")
 
 				// 检查依赖
-				var lodashDep, reactDep *baseModels.ComponentDependency[*models.YarnLockComponentDependencyEcosystem]
+				var lodashDep, reactDep *models.JsComponentDependency
 				for i := range module.Dependencies {
 					if module.Dependencies[i].DependencyName == "lodash" {
 						lodashDep = module.Dependencies[i]
@@ -69,6 +71,9 @@ react@^16.8.0:
 				assert.Equal(t, "4.17.21", lodashDep.DependencyVersion)
 				assert.Equal(t, "https://registry.yarnpkg.com/lodash/-/lodash-4.17.21.tgz", lodashDep.ComponentDependencyEcosystem.Resolved)
 				assert.Equal(t, "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg==", lodashDep.ComponentDependencyEcosystem.Integrity)
+				// yarn.lock特有的字段保存在Yarn中
+				assert.Equal(t, lodashDep.ComponentDependencyEcosystem.Resolved, lodashDep.ComponentDependencyEcosystem.Yarn.Resolved)
+				assert.Equal(t, lodashDep.ComponentDependencyEcosystem.Integrity, lodashDep.ComponentDependencyEcosystem.Yarn.Integrity)
 
 				// 检查 react 依赖
 				require.NotNil(t, reactDep, "react 依赖应该存在")
@@ -111,11 +116,11 @@ react@^16.8.0:
     "js-tokens" "^4.0.0"
 `,
 			wantError: false,
-			checkFunc: func(t *testing.T, project *baseModels.Project[*models.YarnLockProjectEcosystem, *models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem]) {
+			checkFunc: func(t *testing.T, project *models.JsProject) {
 				assert.NotNil(t, project)
 				assert.Len(t, project.Modules, 1, "应该有一个模块")
 				// 查找模块
-				var module *baseModels.Module[*models.YarnLockModuleEcosystem, *models.YarnLockComponentEcosystem, *models.YarnLockComponentDependencyEcosystem]
+				var module *models.JsModule
 				for _, m := range project.Modules {
 					module = m
 					break // 只取第一个模块
@@ -126,7 +131,7 @@ react@^16.8.0:
 				assert.GreaterOrEqual(t, len(module.Dependencies), 3, "应该至少有3个顶级依赖")
 
 				// 检查特定依赖
-				var codeFrameDep, coreDep, highlightDep *baseModels.ComponentDependency[*models.YarnLockComponentDependencyEcosystem]
+				var codeFrameDep, coreDep, highlightDep *models.JsComponentDependency
 				for i := range module.Dependencies {
 					if module.Dependencies[i].DependencyName == "@babel/code-frame" {
 						codeFrameDep = module.Dependencies[i]
@@ -150,6 +155,36 @@ react@^16.8.0:
 				assert.Equal(t, "7.13.8", highlightDep.DependencyVersion)
 			},
 		},
+		{
+			name: "别名依赖测试",
+			yarnLockContent: `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+"string-width-cjs@npm:string-width@^4.2.0":
+  version "4.2.3"
+  resolved "https://registry.yarnpkg.com/string-width/-/string-width-4.2.3.tgz#269c7117d27b05ad2e536830a8ec895ef9c6d010"
+  integrity sha512-wKyQRQpjJ0sIp62ErSZdGsjMJWsap5oRNihHhu6G7JVO/9jIB6UyevL+tXuOqrng8j/cxKTWyWUwvSTriiZz/g==
+
+string-width@^4.1.0:
+  version "4.2.3"
+  resolved "https://registry.yarnpkg.com/string-width/-/string-width-4.2.3.tgz#269c7117d27b05ad2e536830a8ec895ef9c6d010"
+  integrity sha512-wKyQRQpjJ0sIp62ErSZdGsjMJWsap5oRNihHhu6G7JVO/9jIB6UyevL+tXuOqrng8j/cxKTWyWUwvSTriiZz/g==
+`,
+			wantError: false,
+			checkFunc: func(t *testing.T, project *models.JsProject) {
+				module := project.TakeFirstModule()
+				require.NotNil(t, module)
+				require.Len(t, module.Dependencies, 2)
+
+				// 别名安装的依赖使用真实的包名，别名单独保存
+				assert.Equal(t, "string-width", module.Dependencies[0].DependencyName)
+				assert.Equal(t, "4.2.3", module.Dependencies[0].DependencyVersion)
+				assert.Equal(t, "string-width-cjs", module.Dependencies[0].ComponentDependencyEcosystem.Alias)
+				assert.Equal(t, "string-width-cjs", module.Dependencies[0].ComponentDependencyEcosystem.Yarn.Alias)
+				assert.Equal(t, "string-width", module.Dependencies[1].DependencyName)
+				assert.Empty(t, module.Dependencies[1].ComponentDependencyEcosystem.Alias)
+			},
+		},
 		{
 			name:            "无效格式测试",
 			yarnLockContent: `This is not a valid yarn.lock file`,
@@ -198,6 +233,173 @@ react@^16.8.0:
 	}
 }
 
+// 测试合并的描述符、可选依赖以及语法错误的位置
+func TestYarnLockParser_ParseYarnLock(t *testing.T) {
+	parser := NewYarnLockParser()
+
+	yarnLock, err := parser.parseYarnLock([]byte(`# yarn lockfile v1
+
+"chokidar@^3.4.0", chokidar@^3.5.1:
+  version "3.5.3"
+  resolved "https://registry.yarnpkg.com/chokidar/-/chokidar-3.5.3.tgz#1cf37c8707b932bd1af1ae22c0432e2acd1903bd"
+  dependencies:
+    anymatch "~3.1.2"
+    glob-parent "~5.1.2"
+  optionalDependencies:
+    fsevents "~2.3.2"
+
+fsevents@~2.3.2:
+  version "2.3.2"
+`))
+	require.NoError(t, err)
+	require.Len(t, yarnLock.Dependencies, 3)
+
+	chokidar := yarnLock.Dependencies["chokidar@^3.4.0"]
+	require.NotNil(t, chokidar)
+	assert.Same(t, chokidar, yarnLock.Dependencies["chokidar@^3.5.1"])
+	assert.Equal(t, "3.5.3", chokidar.Version)
+	assert.Equal(t, "https://registry.yarnpkg.com/chokidar/-/chokidar-3.5.3.tgz", chokidar.Resolved)
+	assert.Equal(t, map[string]string{"anymatch": "~3.1.2", "glob-parent": "~5.1.2"}, chokidar.Dependencies)
+	assert.Equal(t, map[string]string{"fsevents": "~2.3.2"}, chokidar.OptionalDependencies)
+	assert.Empty(t, yarnLock.Dependencies["fsevents@~2.3.2"].Dependencies)
+
+	// 语法错误会带上出错的位置
+	_, err = parser.parseYarnLock([]byte("This is not a valid yarn.lock file"))
+	var syntaxErr *YarnLockSyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	assert.Equal(t, 1, syntaxErr.Line)
+	assert.Equal(t, 9, syntaxErr.Column)
+
+	// 顶层的条目必须是依赖块
+	_, err = parser.parseYarnLock([]byte("lodash@^4.0.0 \"4.17.21\"\n"))
+	require.ErrorAs(t, err, &syntaxErr)
+	assert.Equal(t, 1, syntaxErr.Line)
+	assert.Equal(t, 1, syntaxErr.Column)
+}
+
+// 测试从内容、reader、fs.FS和项目根目录读取yarn.lock
+func TestYarnLockParser_InputSources(t *testing.T) {
+	content := "# yarn lockfile v1\n\nlodash@^4.17.15:\n  version \"4.17.21\"\n"
+	fileSystem := fstest.MapFS{
+		"project/yarn.lock": &fstest.MapFile{Data: []byte(content)},
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, YarnLockFileName), []byte(content), 0644))
+
+	tests := []struct {
+		name  string
+		input *YarnLockParserInput
+	}{
+		{name: "内容", input: &YarnLockParserInput{YarnLockContent: content}},
+		{name: "reader", input: &YarnLockParserInput{YarnLockReader: strings.NewReader(content)}},
+		{name: "fs.FS中的路径", input: &YarnLockParserInput{FileSystem: fileSystem, YarnLockPath: "project/yarn.lock"}},
+		{name: "fs.FS中的项目根目录", input: &YarnLockParserInput{FileSystem: fileSystem, ProjectRootDirectory: "project"}},
+		{name: "本地项目根目录", input: &YarnLockParserInput{ProjectRootDirectory: dir}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := NewYarnLockParser().Parse(context.Background(), tt.input)
+			require.NoError(t, err)
+			module := project.TakeFirstModule()
+			require.NotNil(t, module)
+			require.Len(t, module.Dependencies, 1)
+			assert.Equal(t, "4.17.21", module.Dependencies[0].DependencyVersion)
+		})
+	}
+
+	_, err := NewYarnLockParser().Parse(context.Background(), &YarnLockParserInput{FileSystem: fileSystem, ProjectRootDirectory: "missing"})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestYarnLockParser_PackageJson(t *testing.T) {
+	yarnLock := `# yarn lockfile v1
+
+lodash@^4.17.15, lodash@^4.17.20:
+  version "4.17.21"
+
+react@^18.2.0:
+  version "18.2.0"
+  dependencies:
+    loose-envify "^1.1.0"
+
+loose-envify@^1.1.0:
+  version "1.4.0"
+`
+	packageJson := `{
+  "name": "my-app",
+  "version": "2.1.0",
+  "dependencies": {
+    "react": "^18.2.0"
+  },
+  "devDependencies": {
+    "lodash": "^4.17.20"
+  }
+}`
+	fileSystem := fstest.MapFS{
+		"project/yarn.lock":    &fstest.MapFile{Data: []byte(yarnLock)},
+		"project/package.json": &fstest.MapFile{Data: []byte(packageJson)},
+		"bare/yarn.lock":       &fstest.MapFile{Data: []byte(yarnLock)},
+	}
+
+	t.Run("使用package.json中的名称、版本和直接依赖", func(t *testing.T) {
+		for _, input := range []*YarnLockParserInput{
+			{FileSystem: fileSystem, ProjectRootDirectory: "project"},
+			{FileSystem: fileSystem, YarnLockPath: "project/yarn.lock"},
+		} {
+			project, err := NewYarnLockParser().Parse(context.Background(), input)
+			require.NoError(t, err)
+			assert.Equal(t, "my-app", project.Name)
+			assert.Equal(t, "2.1.0", project.Version)
+
+			module := project.TakeFirstModule()
+			require.NotNil(t, module)
+			assert.Equal(t, "my-app", module.Name)
+
+			direct := make(map[string]bool)
+			for _, dependency := range module.Dependencies {
+				direct[dependency.DependencyName] = dependency.ComponentDependencyEcosystem.Yarn.Direct
+				// 读取到package.json时能够判断，传递依赖在共用字段中是false而不是nil
+				require.NotNil(t, dependency.ComponentDependencyEcosystem.Direct)
+				assert.Equal(t, dependency.ComponentDependencyEcosystem.Yarn.Direct, *dependency.ComponentDependencyEcosystem.Direct)
+			}
+			assert.Equal(t, map[string]bool{"lodash": true, "react": true, "loose-envify": false}, direct)
+		}
+	})
+
+	t.Run("package.json没有name时仍然标记直接依赖", func(t *testing.T) {
+		fileSystem := fstest.MapFS{
+			"yarn.lock":    &fstest.MapFile{Data: []byte(yarnLock)},
+			"package.json": &fstest.MapFile{Data: []byte(`{"private": true, "dependencies": {"react": "^18.2.0"}}`)},
+		}
+		project, err := NewYarnLockParser().Parse(context.Background(), &YarnLockParserInput{FileSystem: fileSystem, ProjectRootDirectory: "."})
+		require.NoError(t, err)
+		assert.Equal(t, "unknown", project.Name)
+
+		direct := make(map[string]bool)
+		for _, dependency := range project.TakeFirstModule().Dependencies {
+			direct[dependency.DependencyName] = *dependency.ComponentDependencyEcosystem.Direct
+		}
+		assert.Equal(t, map[string]bool{"lodash": false, "react": true, "loose-envify": false}, direct)
+	})
+
+	t.Run("没有package.json时不猜测项目名称", func(t *testing.T) {
+		for _, input := range []*YarnLockParserInput{
+			{FileSystem: fileSystem, ProjectRootDirectory: "bare"},
+			{YarnLockContent: yarnLock},
+		} {
+			project, err := NewYarnLockParser().Parse(context.Background(), input)
+			require.NoError(t, err)
+			assert.Equal(t, "unknown", project.Name)
+			assert.Empty(t, project.Version)
+			for _, dependency := range project.TakeFirstModule().Dependencies {
+				assert.False(t, dependency.ComponentDependencyEcosystem.Yarn.Direct)
+				assert.Nil(t, dependency.ComponentDependencyEcosystem.Direct, "无法判断时共用字段为nil")
+			}
+		}
+	})
+}
+
 // 测试解析器的各个方法
 func TestYarnLockParser_Methods(t *testing.T) {
 	// 测试GetName方法
@@ -243,6 +445,10 @@ func TestYarnLockParser_ExtractPackageName(t *testing.T) {
 			input:    "weird-package@1.0.0-beta.1",
 			expected: "weird-package",
 		},
+		{
+			input:    "\"@my/alias@npm:@scope/real@^1.0.0\"",
+			expected: "@my/alias",
+		},
 	}
 
 	// 使用YarnLockParser的extractPackageName方法进行测试
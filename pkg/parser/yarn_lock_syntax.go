@@ -0,0 +1,229 @@
+package parser
+
+import "fmt"
+
+// yarnLockObject yarn.lock v1语法中的一个对象（嵌套块），保留条目在文件中的原始顺序
+type yarnLockObject struct {
+	Entries []*yarnLockEntry
+
+	// 键到条目的索引，同一个条目可能有多个键
+	index map[string]*yarnLockEntry
+}
+
+// yarnLockEntry 对象中的一个条目，多个键用逗号分隔写在同一行时共享同一个值，
+// 比如 "a@^1.0.0", "a@^1.2.0": 这样的合并描述符
+type yarnLockEntry struct {
+	Keys []string
+
+	// 值的类型为 string、bool 或 *yarnLockObject，数字按原始字面量保存为string
+	Value interface{}
+
+	// 第一个键在文件中的位置
+	Line   int
+	Column int
+}
+
+// Get 根据键获取值，不存在时返回nil
+func (x *yarnLockObject) Get(key string) interface{} {
+	if entry, ok := x.index[key]; ok {
+		return entry.Value
+	}
+	return nil
+}
+
+// GetString 获取字符串类型的值，布尔值也会被转换为字符串
+func (x *yarnLockObject) GetString(key string) string {
+	switch value := x.Get(key).(type) {
+	case string:
+		return value
+	case bool:
+		return fmt.Sprintf("%t", value)
+	default:
+		return ""
+	}
+}
+
+// GetObject 获取嵌套对象类型的值
+func (x *yarnLockObject) GetObject(key string) *yarnLockObject {
+	if value, ok := x.Get(key).(*yarnLockObject); ok {
+		return value
+	}
+	return nil
+}
+
+// StringMap 把对象转换为字符串映射，用于dependencies这类键值都是字符串的块
+func (x *yarnLockObject) StringMap() map[string]string {
+	result := make(map[string]string)
+	if x == nil {
+		return result
+	}
+	for _, entry := range x.Entries {
+		for _, key := range entry.Keys {
+			result[key] = x.GetString(key)
+		}
+	}
+	return result
+}
+
+func (x *yarnLockObject) add(entry *yarnLockEntry) {
+	if x.index == nil {
+		x.index = make(map[string]*yarnLockEntry)
+	}
+	x.Entries = append(x.Entries, entry)
+	for _, key := range entry.Keys {
+		x.index[key] = entry
+	}
+}
+
+// yarnLockSyntaxParser 按照yarn v1 lockfile的文法把词法单元解析为嵌套的对象
+//
+//	file    = { comment | newline | entry }
+//	entry   = keys ( value | ":" block )
+//	keys    = string { "," string }
+//	value   = string | boolean | number
+//	block   = newline indent(n+1) entry { newline indent(n+1) entry }
+type yarnLockSyntaxParser struct {
+	tokens []yarnLockToken
+	pos    int
+}
+
+// parseYarnLockSyntax 解析yarn.lock v1的内容，返回顶层对象
+func parseYarnLockSyntax(data []byte) (*yarnLockObject, error) {
+	tokens, err := tokenizeYarnLock(data)
+	if err != nil {
+		return nil, err
+	}
+	p := &yarnLockSyntaxParser{tokens: tokens}
+	object, err := p.parseObject(0)
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.Type != yarnLockTokenEOF {
+		return nil, p.errorf(token, "unexpected %s at top level", describeYarnLockToken(token))
+	}
+	return object, nil
+}
+
+func (p *yarnLockSyntaxParser) peek() yarnLockToken {
+	return p.tokens[p.pos]
+}
+
+func (p *yarnLockSyntaxParser) next() yarnLockToken {
+	token := p.tokens[p.pos]
+	if token.Type != yarnLockTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *yarnLockSyntaxParser) errorf(token yarnLockToken, format string, args ...interface{}) *YarnLockSyntaxError {
+	return &YarnLockSyntaxError{Line: token.Line, Column: token.Column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseObject 解析缩进层级为indent的对象，遇到缩进更浅的行时结束
+func (p *yarnLockSyntaxParser) parseObject(indent int) (*yarnLockObject, error) {
+	object := &yarnLockObject{}
+	for {
+		token := p.peek()
+		switch token.Type {
+		case yarnLockTokenEOF:
+			return object, nil
+		case yarnLockTokenComment:
+			p.next()
+		case yarnLockTokenNewline:
+			p.next()
+			if indent == 0 {
+				continue
+			}
+			// 空行不会结束嵌套块，跳过它们再比较下一行的缩进
+			for p.peek().Type == yarnLockTokenNewline {
+				p.next()
+			}
+			// 嵌套块的下一行必须保持同样的缩进，否则说明块结束了
+			if nextToken := p.peek(); nextToken.Type == yarnLockTokenIndent && nextToken.Indent == indent {
+				p.next()
+				continue
+			}
+			return object, nil
+		case yarnLockTokenIndent:
+			if token.Indent != indent {
+				if indent == 0 || token.Indent > indent {
+					return nil, p.errorf(token, "unexpected indentation of %d levels, expected %d", token.Indent, indent)
+				}
+				return object, nil
+			}
+			p.next()
+		case yarnLockTokenString:
+			// 每个条目都必须独占一行：顶层条目前面是换行，嵌套条目前面是同层级的缩进，
+			// 嵌套块中遇到没有缩进的键说明块已经结束，交给外层处理
+			if p.pos > 0 {
+				previous := p.tokens[p.pos-1]
+				if indent > 0 && previous.Type != yarnLockTokenIndent {
+					return object, nil
+				}
+				if indent == 0 && previous.Type != yarnLockTokenNewline {
+					return nil, p.errorf(token, "unexpected %s, expected a newline before the next key", describeYarnLockToken(token))
+				}
+			}
+			entry, err := p.parseEntry(indent)
+			if err != nil {
+				return nil, err
+			}
+			object.add(entry)
+		default:
+			return nil, p.errorf(token, "unexpected %s, expected a key", describeYarnLockToken(token))
+		}
+	}
+}
+
+// parseEntry 解析一个条目：一个或多个键，后面跟着值或者冒号加嵌套块
+func (p *yarnLockSyntaxParser) parseEntry(indent int) (*yarnLockEntry, error) {
+	first := p.next()
+	entry := &yarnLockEntry{Keys: []string{first.Value}, Line: first.Line, Column: first.Column}
+
+	for p.peek().Type == yarnLockTokenComma {
+		p.next()
+		keyToken := p.next()
+		if keyToken.Type != yarnLockTokenString {
+			return nil, p.errorf(keyToken, "unexpected %s after comma, expected a key", describeYarnLockToken(keyToken))
+		}
+		entry.Keys = append(entry.Keys, keyToken.Value)
+	}
+
+	hasColon := false
+	if p.peek().Type == yarnLockTokenColon {
+		p.next()
+		hasColon = true
+	}
+
+	valueToken := p.peek()
+	switch valueToken.Type {
+	case yarnLockTokenString, yarnLockTokenNumber:
+		p.next()
+		entry.Value = valueToken.Value
+	case yarnLockTokenBoolean:
+		p.next()
+		entry.Value = valueToken.Bool
+	case yarnLockTokenNewline, yarnLockTokenEOF, yarnLockTokenComment:
+		if !hasColon {
+			return nil, p.errorf(valueToken, "missing value for key %q", first.Value)
+		}
+		child, err := p.parseObject(indent + 1)
+		if err != nil {
+			return nil, err
+		}
+		entry.Value = child
+	default:
+		return nil, p.errorf(valueToken, "unexpected %s, expected a value for key %q", describeYarnLockToken(valueToken), first.Value)
+	}
+	return entry, nil
+}
+
+func describeYarnLockToken(token yarnLockToken) string {
+	switch token.Type {
+	case yarnLockTokenString, yarnLockTokenNumber:
+		return fmt.Sprintf("%s %q", token.Type, token.Value)
+	default:
+		return token.Type.String()
+	}
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizeYarnLock(t *testing.T) {
+	tokens, err := tokenizeYarnLock([]byte("# comment\n\"a@^1.0.0\", b@2:\n  optional true\n  count 12\n"))
+	require.NoError(t, err)
+
+	types := make([]yarnLockTokenType, 0, len(tokens))
+	for _, token := range tokens {
+		types = append(types, token.Type)
+	}
+	assert.Equal(t, []yarnLockTokenType{
+		yarnLockTokenComment, yarnLockTokenNewline,
+		yarnLockTokenString, yarnLockTokenComma, yarnLockTokenString, yarnLockTokenColon, yarnLockTokenNewline,
+		yarnLockTokenIndent, yarnLockTokenString, yarnLockTokenBoolean, yarnLockTokenNewline,
+		yarnLockTokenIndent, yarnLockTokenString, yarnLockTokenNumber, yarnLockTokenNewline,
+		yarnLockTokenEOF,
+	}, types)
+
+	assert.Equal(t, " comment", tokens[0].Value)
+	assert.Equal(t, "a@^1.0.0", tokens[2].Value)
+	assert.Equal(t, "b@2", tokens[4].Value)
+	assert.Equal(t, 2, tokens[4].Line)
+	assert.Equal(t, 13, tokens[4].Column)
+	assert.Equal(t, 1, tokens[7].Indent)
+	assert.True(t, tokens[9].Bool)
+	assert.Equal(t, "12", tokens[13].Value)
+}
+
+func TestParseYarnLockSyntax(t *testing.T) {
+	content := `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/code-frame@^7.0.0", "@babel/code-frame@^7.12.13":
+  version "7.12.13"
+  dependencies:
+    "@babel/highlight" "^7.12.13"
+  optionalDependencies:
+    fsevents "~2.3.1"
+  bundled false
+
+chalk@^2.0.0:
+  version "2.4.2"
+  resolved "https://registry.yarnpkg.com/chalk/-/chalk-2.4.2.tgz#cd42541677a54333cf541a49108c1432b44c9424"
+`
+	root, err := parseYarnLockSyntax([]byte(content))
+	require.NoError(t, err)
+	require.Len(t, root.Entries, 2)
+
+	// 多个键共享同一个块
+	codeFrame := root.Entries[0]
+	assert.Equal(t, []string{"@babel/code-frame@^7.0.0", "@babel/code-frame@^7.12.13"}, codeFrame.Keys)
+	assert.Equal(t, 5, codeFrame.Line)
+	assert.Same(t, root.GetObject("@babel/code-frame@^7.0.0"), root.GetObject("@babel/code-frame@^7.12.13"))
+
+	block := root.GetObject("@babel/code-frame@^7.0.0")
+	require.NotNil(t, block)
+	assert.Equal(t, "7.12.13", block.GetString("version"))
+	assert.Equal(t, map[string]string{"@babel/highlight": "^7.12.13"}, block.GetObject("dependencies").StringMap())
+	assert.Equal(t, map[string]string{"fsevents": "~2.3.1"}, block.GetObject("optionalDependencies").StringMap())
+	assert.Equal(t, false, block.Get("bundled"))
+
+	// dependencies块结束后的行不会被当做依赖
+	chalk := root.GetObject("chalk@^2.0.0")
+	require.NotNil(t, chalk)
+	assert.Equal(t, "2.4.2", chalk.GetString("version"))
+	assert.Nil(t, chalk.GetObject("dependencies"))
+}
+
+func TestParseYarnLockSyntax_BlankLinesInBlock(t *testing.T) {
+	content := `lodash@^4.17.0:
+  version "4.17.21"
+
+  dependencies:
+    a "^1.0.0"
+
+    b "^2.0.0"
+
+  integrity sha512-lodash
+
+
+chalk@^2.0.0:
+  version "2.4.2"
+`
+	root, err := parseYarnLockSyntax([]byte(content))
+	require.NoError(t, err)
+	require.Len(t, root.Entries, 2)
+
+	lodash := root.GetObject("lodash@^4.17.0")
+	require.NotNil(t, lodash)
+	assert.Equal(t, "4.17.21", lodash.GetString("version"))
+	assert.Equal(t, map[string]string{"a": "^1.0.0", "b": "^2.0.0"}, lodash.GetObject("dependencies").StringMap(), "空行不会结束嵌套块")
+	assert.Equal(t, "sha512-lodash", lodash.GetString("integrity"))
+	assert.Equal(t, "2.4.2", root.GetObject("chalk@^2.0.0").GetString("version"))
+}
+
+func TestParseYarnLockSyntax_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantLine   int
+		wantColumn int
+	}{
+		{
+			name:       "奇数个空格的缩进",
+			content:    "a@1:\n   version \"1\"\n",
+			wantLine:   2,
+			wantColumn: 1,
+		},
+		{
+			name:       "未结束的字符串",
+			content:    "a@1:\n  version \"1\n",
+			wantLine:   2,
+			wantColumn: 11,
+		},
+		{
+			name:       "缺少值",
+			content:    "a@1:\n  version\n",
+			wantLine:   2,
+			wantColumn: 10,
+		},
+		{
+			name:       "逗号后缺少键",
+			content:    "a@1, :\n  version \"1\"\n",
+			wantLine:   1,
+			wantColumn: 6,
+		},
+		{
+			name:       "顶层出现缩进",
+			content:    "  a@1:\n",
+			wantLine:   1,
+			wantColumn: 1,
+		},
+		{
+			name:       "同一行出现多个条目",
+			content:    "a \"1\" b \"2\"\n",
+			wantLine:   1,
+			wantColumn: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYarnLockSyntax([]byte(tt.content))
+			require.Error(t, err)
+
+			var syntaxErr *YarnLockSyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "应该返回语法错误: %v", err)
+			assert.Equal(t, tt.wantLine, syntaxErr.Line)
+			assert.Equal(t, tt.wantColumn, syntaxErr.Column)
+		})
+	}
+}
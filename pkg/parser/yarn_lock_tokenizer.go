@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// yarnLockTokenType yarn.lock v1词法单元的类型
+type yarnLockTokenType int
+
+const (
+	yarnLockTokenEOF yarnLockTokenType = iota
+	yarnLockTokenNewline
+	yarnLockTokenIndent
+	yarnLockTokenComment
+	yarnLockTokenString
+	yarnLockTokenBoolean
+	yarnLockTokenNumber
+	yarnLockTokenColon
+	yarnLockTokenComma
+)
+
+func (x yarnLockTokenType) String() string {
+	switch x {
+	case yarnLockTokenEOF:
+		return "end of file"
+	case yarnLockTokenNewline:
+		return "newline"
+	case yarnLockTokenIndent:
+		return "indent"
+	case yarnLockTokenComment:
+		return "comment"
+	case yarnLockTokenString:
+		return "string"
+	case yarnLockTokenBoolean:
+		return "boolean"
+	case yarnLockTokenNumber:
+		return "number"
+	case yarnLockTokenColon:
+		return "colon"
+	case yarnLockTokenComma:
+		return "comma"
+	default:
+		return "unknown"
+	}
+}
+
+// yarnLockToken yarn.lock v1的一个词法单元
+type yarnLockToken struct {
+	Type yarnLockTokenType
+
+	// 字符串、注释、数字的值，字符串是去掉引号和转义之后的值，数字保留原始字面量
+	Value string
+
+	// 布尔值
+	Bool bool
+
+	// 缩进的层级，两个空格为一级
+	Indent int
+
+	// 词法单元在文件中的位置，从1开始
+	Line   int
+	Column int
+}
+
+// YarnLockSyntaxError yarn.lock的语法错误，包含出错的位置
+type YarnLockSyntaxError struct {
+	Line   int    // 出错的行，从1开始
+	Column int    // 出错的列，从1开始
+	Msg    string // 错误消息
+}
+
+func (e *YarnLockSyntaxError) Error() string {
+	return fmt.Sprintf("yarn.lock syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// tokenizeYarnLock 把yarn.lock v1的内容切分为词法单元，规则跟yarn自己的lockfile解析器保持一致：
+// 注释以#开头直到行尾；行首的空格是缩进，必须是偶数个；字符串可以带双引号（支持JSON转义）也可以不带；
+// 不带引号的true/false是布尔值，全部由数字组成的是数字
+func tokenizeYarnLock(data []byte) ([]yarnLockToken, error) {
+	input := string(data)
+	tokens := make([]yarnLockToken, 0, len(input)/4)
+
+	line, column := 1, 1
+	atLineStart := true
+	for i := 0; i < len(input); {
+		c := input[i]
+		token := yarnLockToken{Line: line, Column: column}
+		consumed := 0
+
+		switch {
+		case c == '\n' || c == '\r':
+			consumed = 1
+			if c == '\r' && i+1 < len(input) && input[i+1] == '\n' {
+				consumed = 2
+			}
+			token.Type = yarnLockTokenNewline
+			tokens = append(tokens, token)
+			i += consumed
+			line, column = line+1, 1
+			atLineStart = true
+			continue
+		case c == ' ' || c == '\t':
+			for i+consumed < len(input) && (input[i+consumed] == ' ' || input[i+consumed] == '\t') {
+				if input[i+consumed] == '\t' {
+					return nil, &YarnLockSyntaxError{Line: line, Column: column + consumed, Msg: "tabs are not allowed for indentation or separation"}
+				}
+				consumed++
+			}
+			if atLineStart {
+				// 只有空白的行不算缩进
+				if i+consumed < len(input) && input[i+consumed] != '\n' && input[i+consumed] != '\r' {
+					if consumed%2 != 0 {
+						return nil, &YarnLockSyntaxError{Line: line, Column: column, Msg: fmt.Sprintf("invalid indentation of %d spaces, expected a multiple of 2", consumed)}
+					}
+					token.Type = yarnLockTokenIndent
+					token.Indent = consumed / 2
+					tokens = append(tokens, token)
+				}
+			}
+		case c == '#':
+			end := strings.IndexAny(input[i:], "\r\n")
+			if end < 0 {
+				end = len(input) - i
+			}
+			consumed = end
+			token.Type = yarnLockTokenComment
+			token.Value = input[i+1 : i+end]
+			tokens = append(tokens, token)
+		case c == '"':
+			end, err := scanYarnLockQuotedString(input[i:])
+			if err != nil {
+				return nil, &YarnLockSyntaxError{Line: line, Column: column, Msg: err.Error()}
+			}
+			var value string
+			if err := json.Unmarshal([]byte(input[i:i+end]), &value); err != nil {
+				return nil, &YarnLockSyntaxError{Line: line, Column: column, Msg: fmt.Sprintf("invalid quoted string: %v", err)}
+			}
+			consumed = end
+			token.Type = yarnLockTokenString
+			token.Value = value
+			tokens = append(tokens, token)
+		case c == ':':
+			consumed = 1
+			token.Type = yarnLockTokenColon
+			tokens = append(tokens, token)
+		case c == ',':
+			consumed = 1
+			token.Type = yarnLockTokenComma
+			tokens = append(tokens, token)
+		default:
+			for i+consumed < len(input) && !isYarnLockUnquotedTerminator(input[i+consumed]) {
+				consumed++
+			}
+			literal := input[i : i+consumed]
+			token.Value = literal
+			switch {
+			case literal == "true" || literal == "false":
+				token.Type = yarnLockTokenBoolean
+				token.Bool = literal == "true"
+			case isAllDigits(literal):
+				token.Type = yarnLockTokenNumber
+			default:
+				token.Type = yarnLockTokenString
+			}
+			tokens = append(tokens, token)
+		}
+
+		atLineStart = false
+		i += consumed
+		column += consumed
+	}
+
+	tokens = append(tokens, yarnLockToken{Type: yarnLockTokenEOF, Line: line, Column: column})
+	return tokens, nil
+}
+
+// scanYarnLockQuotedString 扫描以双引号开头的字符串，返回包含结尾引号在内的长度
+func scanYarnLockQuotedString(input string) (int, error) {
+	for i := 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		case '\n', '\r':
+			return 0, fmt.Errorf("unterminated quoted string")
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted string")
+}
+
+// isYarnLockUnquotedTerminator 不带引号的字符串遇到这些字符时结束
+func isYarnLockUnquotedTerminator(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', ':', ',':
+		return true
+	default:
+		return false
+	}
+}
+
+func isAllDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+)
+
+// YarnLockV1Header yarn生成v1格式lockfile时写在文件开头的注释，后面跟着两个空行，
+// YarnLock中没有记录原文件的排版时使用
+const YarnLockV1Header = "# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.\n# yarn lockfile v1\n\n\n"
+
+// yarnLockKeyPriorities yarn写lockfile时这些键排在最前面，其余的键按字母顺序排序
+var yarnLockKeyPriorities = map[string]int{
+	"name":         1,
+	"version":      2,
+	"uid":          3,
+	"resolved":     4,
+	"integrity":    5,
+	"registry":     6,
+	"dependencies": 7,
+}
+
+// YarnLockWriter 把YarnLock写为yarn v1格式的lockfile，输出跟yarn自己生成的逐字节一致，
+// 规则来自yarn的lockfile/stringify.js；解析得到的YarnLock记录了原文件的文件头和结尾的空白，写回时跟原文件一致
+type YarnLockWriter struct {
+}
+
+func NewYarnLockWriter() *YarnLockWriter {
+	return &YarnLockWriter{}
+}
+
+// Marshal 把YarnLock序列化为v1格式的lockfile内容，Berry格式（Metadata不为nil）不支持
+func (x *YarnLockWriter) Marshal(yarnLock *models.YarnLock) ([]byte, error) {
+	if yarnLock == nil {
+		return nil, fmt.Errorf("yarn.lock is nil")
+	}
+	if yarnLock.Metadata != nil {
+		return nil, fmt.Errorf("yarn berry lockfiles can not be written as yarn lockfile v1")
+	}
+
+	buffer := &bytes.Buffer{}
+	layout := yarnLock.Layout
+	if layout == nil {
+		layout = &models.YarnLockLayout{Header: YarnLockV1Header, Trailer: "\n"}
+	}
+	buffer.WriteString(layout.Header)
+
+	descriptors := make([]string, 0, len(yarnLock.Dependencies))
+	for descriptor, dep := range yarnLock.Dependencies {
+		if dep != nil {
+			descriptors = append(descriptors, descriptor)
+		}
+	}
+	sortYarnLockKeys(descriptors)
+
+	// 共享同一个依赖对象的描述符合并为一个条目，条目出现在第一个描述符的位置
+	descriptorsByDependency := make(map[*models.YarnLockDependency][]string)
+	for _, descriptor := range descriptors {
+		dep := yarnLock.Dependencies[descriptor]
+		descriptorsByDependency[dep] = append(descriptorsByDependency[dep], descriptor)
+	}
+
+	written := make(map[*models.YarnLockDependency]bool)
+	for _, descriptor := range descriptors {
+		dep := yarnLock.Dependencies[descriptor]
+		if written[dep] {
+			continue
+		}
+		if len(written) > 0 {
+			buffer.WriteString("\n")
+		}
+		written[dep] = true
+
+		keys := descriptorsByDependency[dep]
+		sort.Strings(keys)
+		x.writeKeys(buffer, keys)
+		buffer.WriteString(":\n")
+		x.writeDependency(buffer, dep)
+		if len(written) < len(descriptorsByDependency) {
+			buffer.WriteString("\n")
+		}
+	}
+
+	buffer.WriteString(layout.Trailer)
+	return buffer.Bytes(), nil
+}
+
+// Write 把YarnLock以v1格式写入writer
+func (x *YarnLockWriter) Write(writer io.Writer, yarnLock *models.YarnLock) error {
+	data, err := x.Marshal(yarnLock)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// writeDependency 写一个条目的内容，字段按照yarn的优先级排序：version、resolved、integrity、dependencies，
+// 其余的依赖块按字母顺序排在后面
+func (x *YarnLockWriter) writeDependency(buffer *bytes.Buffer, dep *models.YarnLockDependency) {
+	const indent = "  "
+	lines := make([]string, 0, 6)
+	if dep.Version != "" {
+		lines = append(lines, "version "+quoteYarnLockValue(dep.Version))
+	}
+	if dep.Resolved != "" {
+		resolved := dep.Resolved
+		if dep.ResolvedHash != "" {
+			resolved += "#" + dep.ResolvedHash
+		}
+		lines = append(lines, "resolved "+quoteYarnLockValue(resolved))
+	}
+	if dep.Integrity != "" {
+		lines = append(lines, "integrity "+quoteYarnLockValue(dep.Integrity))
+	}
+	for _, block := range []struct {
+		key          string
+		dependencies map[string]string
+	}{
+		{key: "dependencies", dependencies: dep.Dependencies},
+		{key: "optionalDependencies", dependencies: dep.OptionalDependencies},
+		{key: "peerDependencies", dependencies: dep.PeerDependencies},
+	} {
+		if len(block.dependencies) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(block.dependencies))
+		for name := range block.dependencies {
+			names = append(names, name)
+		}
+		sortYarnLockKeys(names)
+
+		blockLines := make([]string, 0, len(names))
+		for _, name := range names {
+			blockLines = append(blockLines, quoteYarnLockValue(name)+" "+quoteYarnLockValue(block.dependencies[name]))
+		}
+		lines = append(lines, block.key+":\n"+indent+indent+strings.Join(blockLines, "\n"+indent+indent))
+	}
+
+	buffer.WriteString(indent)
+	buffer.WriteString(strings.Join(lines, "\n"+indent))
+}
+
+// writeKeys 写条目的描述符，多个描述符用逗号分隔
+func (x *YarnLockWriter) writeKeys(buffer *bytes.Buffer, keys []string) {
+	for i, key := range keys {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString(quoteYarnLockValue(key))
+	}
+}
+
+// sortYarnLockKeys 按照yarn的规则排序：有优先级的键排在前面，其余的按字母顺序
+func sortYarnLockKeys(keys []string) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		iPriority, iOk := yarnLockKeyPriorities[keys[i]]
+		jPriority, jOk := yarnLockKeyPriorities[keys[j]]
+		if iOk || jOk {
+			if !iOk {
+				iPriority = 100
+			}
+			if !jOk {
+				jPriority = 100
+			}
+			return iPriority < jPriority
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// quoteYarnLockValue 需要时给字符串加上引号：以true、false或者数字开头，不以字母开头，
+// 或者包含冒号、空白、反斜杠、双引号、逗号、方括号时需要加引号
+func quoteYarnLockValue(value string) string {
+	needQuote := strings.HasPrefix(value, "true") || strings.HasPrefix(value, "false") ||
+		strings.ContainsAny(value, ":\\\",[] \t\n\r\v\f") ||
+		value == "" || !(value[0] >= 'a' && value[0] <= 'z' || value[0] >= 'A' && value[0] <= 'Z')
+	if !needQuote {
+		return value
+	}
+
+	// 跟JSON.stringify的转义规则保持一致
+	builder := &strings.Builder{}
+	builder.WriteByte('"')
+	for _, c := range value {
+		switch c {
+		case '"':
+			builder.WriteString("\\\"")
+		case '\\':
+			builder.WriteString("\\\\")
+		case '\b':
+			builder.WriteString("\\b")
+		case '\f':
+			builder.WriteString("\\f")
+		case '\n':
+			builder.WriteString("\\n")
+		case '\r':
+			builder.WriteString("\\r")
+		case '\t':
+			builder.WriteString("\\t")
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(builder, "\\u%04x", c)
+			} else {
+				builder.WriteRune(c)
+			}
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
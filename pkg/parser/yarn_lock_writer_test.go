@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/scagogogo/package-json-parser/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYarnLockWriter_RoundTrip(t *testing.T) {
+	files := []string{
+		"../../examples/03_parse_yarn_lock/sample_yarn.lock",
+		"../../examples/04_combined_parsing/sample_project/yarn.lock",
+		"./test_data/yarn.lock/v1-app.lock",
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			yarnLock, err := NewYarnLockParser().ParseLockfile(context.Background(), &YarnLockParserInput{YarnLockPath: file})
+			require.NoError(t, err)
+
+			output, err := NewYarnLockWriter().Marshal(yarnLock)
+			require.NoError(t, err)
+			assert.Equal(t, string(content), string(output))
+		})
+	}
+}
+
+func TestYarnLockWriter_Marshal(t *testing.T) {
+	shared := &models.YarnLockDependency{
+		Version:      "1.0.0",
+		Resolved:     "https://registry.yarnpkg.com/b/-/b-1.0.0.tgz",
+		ResolvedHash: "abc",
+		Dependencies: map[string]string{"version": "1.0.0", "@scope/c": "^2.0.0", "a": "latest"},
+	}
+	yarnLock := &models.YarnLock{
+		Dependencies: map[string]*models.YarnLockDependency{
+			"b@^1.0.0":  shared,
+			"b@1.0.0":   shared,
+			"a@>= 1.0":  {Version: "1.2.0", Integrity: "sha1-xyz"},
+			"true@1":    {Version: "1.0.0"},
+			"skipped@1": nil,
+		},
+	}
+
+	output := &bytes.Buffer{}
+	require.NoError(t, NewYarnLockWriter().Write(output, yarnLock))
+	assert.Equal(t, YarnLockV1Header+`"a@>= 1.0":
+  version "1.2.0"
+  integrity sha1-xyz
+
+b@1.0.0, b@^1.0.0:
+  version "1.0.0"
+  resolved "https://registry.yarnpkg.com/b/-/b-1.0.0.tgz#abc"
+  dependencies:
+    version "1.0.0"
+    "@scope/c" "^2.0.0"
+    a latest
+
+"true@1":
+  version "1.0.0"
+`, output.String())
+
+	_, err := NewYarnLockWriter().Marshal(&models.YarnLock{Metadata: &models.YarnLockMetadata{Version: "8"}})
+	assert.Error(t, err, "Berry格式不能写为v1格式")
+}
+
+func TestQuoteYarnLockValue(t *testing.T) {
+	tests := map[string]string{
+		"lodash":                "lodash",
+		"sha512-abc+/==":        "sha512-abc+/==",
+		"^1.0.0":                `"^1.0.0"`,
+		"1.0.0":                 `"1.0.0"`,
+		"@babel/core":           `"@babel/core"`,
+		"npm:react@18":          `"npm:react@18"`,
+		"trueish":               `"trueish"`,
+		"a b":                   `"a b"`,
+		"":                      `""`,
+		`quote"and\backslash`:   `"quote\"and\\backslash"`,
+		"file:../local-package": `"file:../local-package"`,
+	}
+	for value, expected := range tests {
+		assert.Equal(t, expected, quoteYarnLockValue(value), value)
+	}
+}